@@ -26,6 +26,8 @@ type NFT struct {
 	Collection      string `json:"collection"`
 	Descr           string `json:"descr"`
 	ContractAddress string `json:"contract_address"`
+	// The amount of the ERC-1155 token that the owner holds, it's empty or "1" for the unique nft.
+	Amount string `json:"amount"`
 
 	RelatedUrl string `json:"related_url"`
 }
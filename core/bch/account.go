@@ -0,0 +1,146 @@
+package bch
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/btc"
+)
+
+// The BIP44 derive path of Bitcoin Cash, the coin type is 145.
+const DerivePath = "m/44'/145'/0'/0/0"
+
+type Account struct {
+	privateKey *btcec.PrivateKey
+	address    string
+	Chainnet   string
+}
+
+func NewAccountWithMnemonic(mnemonic, chainnet string) (*Account, error) {
+	privateKey, err := btc.Derivation(mnemonic, DerivePath)
+	if err != nil {
+		return nil, err
+	}
+	return newAccount(privateKey, chainnet)
+}
+
+// AccountWithPrivateKey
+// @param prikey the hex or WIF private key
+func AccountWithPrivateKey(prikey, chainnet string) (*Account, error) {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	var privateKey *btcec.PrivateKey
+	wif, err := btcutil.DecodeWIF(prikey)
+	if err != nil {
+		seed, err := types.HexDecodeString(prikey)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, _ = btcec.PrivKeyFromBytes(seed)
+	} else {
+		if !wif.IsForNet(net) {
+			return nil, fmt.Errorf("the specified chainnet does not match the wif private key")
+		}
+		privateKey = wif.PrivKey
+	}
+	return newAccount(privateKey, chainnet)
+}
+
+func newAccount(privateKey *btcec.PrivateKey, chainnet string) (*Account, error) {
+	address, err := EncodePublicDataToAddress(privateKey.PubKey().SerializeCompressed(), chainnet)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		privateKey: privateKey,
+		address:    address,
+		Chainnet:   chainnet,
+	}, nil
+}
+
+func (a *Account) DerivePath() string {
+	return DerivePath
+}
+
+// LegacyAddress
+// @return the legacy P2PKH address, which is the same format as the Bitcoin's.
+func (a *Account) LegacyAddress() (string, error) {
+	return ToLegacyAddress(a.address, a.Chainnet)
+}
+
+func (a *Account) WIFPrivateKeyString() (*base.OptionalString, error) {
+	net, err := netParamsOf(a.Chainnet)
+	if err != nil {
+		return nil, err
+	}
+	str, err := btc.PrivateKeyToWIF(a.privateKey, net)
+	if err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(str), nil
+}
+
+// MARK - Implement the protocol Account
+
+// @return privateKey data
+func (a *Account) PrivateKey() ([]byte, error) {
+	return a.privateKey.Serialize(), nil
+}
+
+// @return privateKey string that will start with 0x.
+func (a *Account) PrivateKeyHex() (string, error) {
+	return types.HexEncodeToString(a.privateKey.Serialize()), nil
+}
+
+// @return publicKey data
+func (a *Account) PublicKey() []byte {
+	return a.privateKey.PubKey().SerializeCompressed()
+}
+
+// @return publicKey string that will start with 0x.
+func (a *Account) PublicKeyHex() string {
+	return types.HexEncodeToString(a.PublicKey())
+}
+
+// @return the CashAddr address, e.g. "bitcoincash:qp..."
+func (a *Account) Address() string {
+	return a.address
+}
+
+// TODO: function not implement yet.
+func (a *Account) Sign(message []byte, password string) ([]byte, error) {
+	return nil, base.ErrUnsupportedFunction
+}
+
+// TODO: function not implement yet.
+func (a *Account) SignHex(messageHex string, password string) (*base.OptionalString, error) {
+	return nil, base.ErrUnsupportedFunction
+}
+
+// MARK - Implement the protocol AddressUtil
+
+// @param publicKey can start with 0x or not.
+func (a *Account) EncodePublicKeyToAddress(publicKey string) (string, error) {
+	return EncodePublicKeyToAddress(publicKey, a.Chainnet)
+}
+
+func (a *Account) DecodeAddressToPublicKey(address string) (string, error) {
+	return "", ErrDecodeAddress
+}
+
+func (a *Account) IsValidAddress(address string) bool {
+	return IsValidAddress(address, a.Chainnet)
+}
+
+func AsBitcoinCashAccount(account base.Account) *Account {
+	if r, ok := account.(*Account); ok {
+		return r
+	} else {
+		return nil
+	}
+}
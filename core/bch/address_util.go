@@ -0,0 +1,159 @@
+package bch
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+var (
+	ErrDecodeAddress = errors.New("Bitcoin Cash cannot support decode address to public key")
+)
+
+type Util struct {
+	Chainnet string
+}
+
+func NewUtilWithChainnet(chainnet string) (*Util, error) {
+	if isValidChain(chainnet) {
+		return &Util{Chainnet: chainnet}, nil
+	} else {
+		return nil, ErrUnsupportedChain
+	}
+}
+
+// MARK - Implement the protocol Util
+
+// @param publicKey can start with 0x or not.
+// @return the CashAddr address
+func (u *Util) EncodePublicKeyToAddress(publicKey string) (string, error) {
+	return EncodePublicKeyToAddress(publicKey, u.Chainnet)
+}
+
+// @return the CashAddr address
+func (u *Util) EncodePublicDataToAddress(public []byte) (string, error) {
+	return EncodePublicDataToAddress(public, u.Chainnet)
+}
+
+// Warning: Bitcoin Cash cannot support decode address to public key
+func (u *Util) DecodeAddressToPublicKey(address string) (string, error) {
+	return "", ErrDecodeAddress
+}
+
+func (u *Util) IsValidAddress(address string) bool {
+	return IsValidAddress(address, u.Chainnet)
+}
+
+// MARK - like Util
+
+// @param publicKey can start with 0x or not.
+func EncodePublicKeyToAddress(publicKey, chainnet string) (string, error) {
+	pubData, err := types.HexDecodeString(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return EncodePublicDataToAddress(pubData, chainnet)
+}
+
+// EncodePublicDataToAddress
+// @return the P2PKH CashAddr address of the compressed public key
+func EncodePublicDataToAddress(public []byte, chainnet string) (string, error) {
+	prefix, err := cashAddrPrefixOf(chainnet)
+	if err != nil {
+		return "", err
+	}
+	pubkey, err := btcec.ParsePubKey(public)
+	if err != nil {
+		return "", err
+	}
+	return EncodeCashAddr(prefix, CashAddrTypeP2PKH, btcutil.Hash160(pubkey.SerializeCompressed()))
+}
+
+// IsValidAddress
+// Both the CashAddr address (with or without the prefix) and the legacy address are valid.
+func IsValidAddress(address, chainnet string) bool {
+	_, _, err := decodeAddress(address, chainnet)
+	return err == nil
+}
+
+// IsCashAddress
+// @return true if the address is a valid CashAddr address, the prefix can be omitted.
+func IsCashAddress(address, chainnet string) bool {
+	prefix, err := cashAddrPrefixOf(chainnet)
+	if err != nil {
+		return false
+	}
+	_, _, err = DecodeCashAddr(address, prefix)
+	return err == nil
+}
+
+// ToCashAddress
+// Convert the legacy address to the CashAddr address, the CashAddr address will be returned with the prefix.
+func ToCashAddress(address, chainnet string) (string, error) {
+	addrType, hash, err := decodeAddress(address, chainnet)
+	if err != nil {
+		return "", err
+	}
+	prefix, err := cashAddrPrefixOf(chainnet)
+	if err != nil {
+		return "", err
+	}
+	return EncodeCashAddr(prefix, addrType, hash)
+}
+
+// ToLegacyAddress
+// Convert the CashAddr address to the legacy address.
+func ToLegacyAddress(address, chainnet string) (string, error) {
+	addr, err := decodeToLegacy(address, chainnet)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
+
+// decodeAddress
+// @return the CashAddr type and the hash of the CashAddr or legacy address.
+func decodeAddress(address, chainnet string) (addrType byte, hash []byte, err error) {
+	prefix, err := cashAddrPrefixOf(chainnet)
+	if err != nil {
+		return
+	}
+	if addrType, hash, err = DecodeCashAddr(address, prefix); err == nil {
+		return
+	}
+	net, _ := netParamsOf(chainnet)
+	addr, err := btcutil.DecodeAddress(address, net)
+	if err != nil || !addr.IsForNet(net) {
+		return 0, nil, errors.New("invalid address")
+	}
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return CashAddrTypeP2PKH, addr.ScriptAddress(), nil
+	case *btcutil.AddressScriptHash:
+		return CashAddrTypeP2SH, addr.ScriptAddress(), nil
+	}
+	return 0, nil, errors.New("invalid address")
+}
+
+func decodeToLegacy(address, chainnet string) (btcutil.Address, error) {
+	addrType, hash, err := decodeAddress(address, chainnet)
+	if err != nil {
+		return nil, err
+	}
+	net, _ := netParamsOf(chainnet)
+	if addrType == CashAddrTypeP2SH {
+		return btcutil.NewAddressScriptHashFromHash(hash, net)
+	}
+	return btcutil.NewAddressPubKeyHash(hash, net)
+}
+
+func addressToPkScript(address, chainnet string) ([]byte, error) {
+	addr, err := decodeToLegacy(address, chainnet)
+	if err != nil {
+		return nil, err
+	}
+	return txscript.PayToAddrScript(addr)
+}
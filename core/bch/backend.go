@@ -0,0 +1,73 @@
+package bch
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/btc"
+)
+
+var (
+	ErrBackendNotConfigured = errors.New("the backend service of the BCH chainnet is not configured")
+)
+
+// Backend is the services that a chainnet depends on.
+type Backend struct {
+	// The esplora compatible api url, only the `/scripthash/:hash` apis are used to query the address,
+	// so it doesn't matter which address format the service supports.
+	EsploraUrl string `json:"esploraUrl"`
+}
+
+func NewBackend(esploraUrl string) *Backend {
+	return &Backend{EsploraUrl: esploraUrl}
+}
+
+func (b *Backend) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(b)
+}
+
+// There is no default public service, the backend must be configured by `SetBackend` before using the chain.
+var customBackends = make(map[string]Backend)
+var backendLock sync.RWMutex
+
+// SetBackend
+// Configure the services of the chainnet.
+// @param backend the custom backend, nil to remove the configured services.
+func SetBackend(chainnet string, backend *Backend) error {
+	if !isValidChain(chainnet) {
+		return ErrUnsupportedChain
+	}
+	backendLock.Lock()
+	defer backendLock.Unlock()
+	if backend == nil {
+		delete(customBackends, chainnet)
+	} else {
+		customBackends[chainnet] = *backend
+	}
+	return nil
+}
+
+// BackendOf
+// @return the services that the chainnet is using.
+func BackendOf(chainnet string) (*Backend, error) {
+	if !isValidChain(chainnet) {
+		return nil, ErrUnsupportedChain
+	}
+	backendLock.RLock()
+	backend := customBackends[chainnet]
+	backendLock.RUnlock()
+	return &backend, nil
+}
+
+func esploraClientOf(chainnet string) (*btc.EsploraClient, error) {
+	backend, err := BackendOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	if backend.EsploraUrl == "" {
+		return nil, ErrBackendNotConfigured
+	}
+	return btc.NewEsploraClient(strings.TrimSuffix(backend.EsploraUrl, "/")), nil
+}
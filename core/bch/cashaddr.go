@@ -0,0 +1,125 @@
+package bch
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+)
+
+// https://github.com/bitcoincashorg/bitcoincash.org/blob/master/spec/cashaddr.md
+
+const (
+	CashAddrTypeP2PKH byte = 0
+	CashAddrTypeP2SH  byte = 1
+
+	cashAddrCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	// the size code of the 160 bits hash
+	cashAddrSize160     byte = 0
+	cashAddrChecksumLen      = 8
+)
+
+var (
+	ErrInvalidCashAddr = errors.New("invalid CashAddr address")
+)
+
+func cashAddrPolymod(values []byte) uint64 {
+	c := uint64(1)
+	for _, d := range values {
+		c0 := byte(c >> 35)
+		c = ((c & 0x07ffffffff) << 5) ^ uint64(d)
+		if c0&0x01 != 0 {
+			c ^= 0x98f2bc8e61
+		}
+		if c0&0x02 != 0 {
+			c ^= 0x79b76d99e2
+		}
+		if c0&0x04 != 0 {
+			c ^= 0xf33e5fb3c4
+		}
+		if c0&0x08 != 0 {
+			c ^= 0xae2eabe2a8
+		}
+		if c0&0x10 != 0 {
+			c ^= 0x1e4f43e470
+		}
+	}
+	return c ^ 1
+}
+
+func cashAddrPrefixExpand(prefix string) []byte {
+	res := make([]byte, len(prefix)+1)
+	for i := 0; i < len(prefix); i++ {
+		res[i] = prefix[i] & 0x1f
+	}
+	return res
+}
+
+// EncodeCashAddr
+// @param prefix e.g. "bitcoincash"
+// @param addrType `CashAddrTypeP2PKH` or `CashAddrTypeP2SH`
+// @param hash the 20 bytes hash160 of the public key or script
+// @return the address with the prefix, e.g. "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a"
+func EncodeCashAddr(prefix string, addrType byte, hash []byte) (string, error) {
+	if len(hash) != 20 || addrType > CashAddrTypeP2SH {
+		return "", ErrInvalidCashAddr
+	}
+	version := addrType<<3 | cashAddrSize160
+	payload, err := bech32.ConvertBits(append([]byte{version}, hash...), 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	values := append(cashAddrPrefixExpand(prefix), payload...)
+	mod := cashAddrPolymod(append(values, make([]byte, cashAddrChecksumLen)...))
+
+	var sb strings.Builder
+	sb.WriteString(prefix + ":")
+	for _, v := range payload {
+		sb.WriteByte(cashAddrCharset[v])
+	}
+	for i := 0; i < cashAddrChecksumLen; i++ {
+		sb.WriteByte(cashAddrCharset[(mod>>(5*(7-i)))&0x1f])
+	}
+	return sb.String(), nil
+}
+
+// DecodeCashAddr
+// @param address the address with or without the prefix
+// @param prefix the expected prefix, e.g. "bitcoincash"
+// @return the address type and the 20 bytes hash
+func DecodeCashAddr(address, prefix string) (addrType byte, hash []byte, err error) {
+	if strings.ToLower(address) != address && strings.ToUpper(address) != address {
+		return 0, nil, ErrInvalidCashAddr // mixed case
+	}
+	address = strings.ToLower(address)
+	if idx := strings.LastIndexByte(address, ':'); idx >= 0 {
+		if address[:idx] != prefix {
+			return 0, nil, ErrInvalidCashAddr
+		}
+		address = address[idx+1:]
+	}
+	if len(address) <= cashAddrChecksumLen {
+		return 0, nil, ErrInvalidCashAddr
+	}
+	values := make([]byte, len(address))
+	for i := 0; i < len(address); i++ {
+		idx := strings.IndexByte(cashAddrCharset, address[i])
+		if idx < 0 {
+			return 0, nil, ErrInvalidCashAddr
+		}
+		values[i] = byte(idx)
+	}
+	if cashAddrPolymod(append(cashAddrPrefixExpand(prefix), values...)) != 0 {
+		return 0, nil, ErrInvalidCashAddr
+	}
+	data, err := bech32.ConvertBits(values[:len(values)-cashAddrChecksumLen], 5, 8, false)
+	if err != nil || len(data) != 21 {
+		return 0, nil, ErrInvalidCashAddr
+	}
+	version := data[0]
+	addrType = version >> 3
+	if version&0x80 != 0 || version&0x07 != cashAddrSize160 || addrType > CashAddrTypeP2SH {
+		return 0, nil, ErrInvalidCashAddr
+	}
+	return addrType, data[1:], nil
+}
@@ -0,0 +1,60 @@
+package bch
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashAddr(t *testing.T) {
+	// https://github.com/bitcoincashorg/bitcoincash.org/blob/master/spec/cashaddr.md#examples-of-address-translation
+	cases := []struct {
+		legacy   string
+		cashAddr string
+		addrType byte
+	}{
+		{"1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu", "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", CashAddrTypeP2PKH},
+		{"1KXrWXciRDZUpQwQmuM1DbwsKDLYAYsVLR", "bitcoincash:qr95sy3j9xwd2ap32xkykttr4cvcu7as4y0qverfuy", CashAddrTypeP2PKH},
+		{"3CWFddi6m4ndiGyKqzYvsFYagqDLPVMTzC", "bitcoincash:ppm2qsznhks23z7629mms6s4cwef74vcwvn0h829pq", CashAddrTypeP2SH},
+		{"3LDsS579y7sruadqu11beEJoTjdFiFCdX4", "bitcoincash:pr95sy3j9xwd2ap32xkykttr4cvcu7as4yc93ky28e", CashAddrTypeP2SH},
+	}
+	for _, c := range cases {
+		addrType, hash, err := DecodeCashAddr(c.cashAddr, "bitcoincash")
+		require.NoError(t, err)
+		require.Equal(t, c.addrType, addrType)
+		encoded, err := EncodeCashAddr("bitcoincash", addrType, hash)
+		require.NoError(t, err)
+		require.Equal(t, c.cashAddr, encoded)
+
+		cashAddr, err := ToCashAddress(c.legacy, ChainMainnet)
+		require.NoError(t, err)
+		require.Equal(t, c.cashAddr, cashAddr)
+		legacy, err := ToLegacyAddress(c.cashAddr, ChainMainnet)
+		require.NoError(t, err)
+		require.Equal(t, c.legacy, legacy)
+
+		withoutPrefix := strings.TrimPrefix(c.cashAddr, "bitcoincash:")
+		require.True(t, IsCashAddress(withoutPrefix, ChainMainnet))
+		require.True(t, IsValidAddress(strings.ToUpper(c.cashAddr), ChainMainnet))
+		require.True(t, IsValidAddress(c.legacy, ChainMainnet))
+		require.False(t, IsValidAddress(c.cashAddr, ChainTestnet))
+	}
+
+	hash, _ := hex.DecodeString("f5bf48b397dae70be82b3cca4793a8e3ec0b3d59")
+	testAddr, err := EncodeCashAddr("bchtest", CashAddrTypeP2PKH, hash)
+	require.NoError(t, err)
+	require.True(t, IsValidAddress(testAddr, ChainTestnet))
+
+	invalids := []string{
+		"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6b", // checksum
+		"bitcoincash:Qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", // mixed case
+		"bchtest:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a",     // prefix
+		"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6",  // length
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+	}
+	for _, address := range invalids {
+		require.False(t, IsValidAddress(address, ChainMainnet), address)
+	}
+}
@@ -0,0 +1,59 @@
+package bch
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	ChainMainnet = "mainnet"
+	ChainTestnet = "testnet"
+)
+
+var (
+	ErrUnsupportedChain = errors.New("Unsupported BCH chainnet")
+)
+
+func isValidChain(chainnet string) bool {
+	switch chainnet {
+	case ChainMainnet, ChainTestnet:
+		return true
+	default:
+		return false
+	}
+}
+
+// netParamsOf
+// The legacy address and the WIF private key of Bitcoin Cash are the same as Bitcoin.
+func netParamsOf(chainnet string) (*chaincfg.Params, error) {
+	switch chainnet {
+	case ChainMainnet:
+		return &chaincfg.MainNetParams, nil
+	case ChainTestnet:
+		return &chaincfg.TestNet3Params, nil
+	}
+	return nil, ErrUnsupportedChain
+}
+
+// cashAddrPrefixOf
+// @return the prefix of the CashAddr address
+func cashAddrPrefixOf(chainnet string) (string, error) {
+	switch chainnet {
+	case ChainMainnet:
+		return "bitcoincash", nil
+	case ChainTestnet:
+		return "bchtest", nil
+	}
+	return "", ErrUnsupportedChain
+}
+
+func nameOf(chainnet string) (string, error) {
+	switch chainnet {
+	case ChainMainnet:
+		return "BCH", nil
+	case ChainTestnet:
+		return "tBCH", nil
+	}
+	return "", ErrUnsupportedChain
+}
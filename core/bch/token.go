@@ -0,0 +1,34 @@
+package bch
+
+import "github.com/coming-chat/wallet-SDK/core/base"
+
+// MARK - Implement the protocol Token
+
+func (c *Chain) Chain() base.Chain {
+	return c
+}
+
+func (c *Chain) TokenInfo() (*base.TokenInfo, error) {
+	name, err := nameOf(c.Chainnet)
+	if err != nil {
+		return nil, err
+	}
+	return &base.TokenInfo{
+		Name:    name,
+		Symbol:  name,
+		Decimal: 8,
+	}, nil
+}
+
+// BuildTransfer
+// The utxos will be selected automatically, and the network fee is calculated with the `MinFeeRate`.
+// @param amount the satoshi amount to transfer
+func (c *Chain) BuildTransfer(sender, receiver, amount string) (txn base.Transaction, err error) {
+	return c.BuildTransferWithFeeRate(sender, receiver, amount, MinFeeRate)
+}
+func (c *Chain) CanTransferAll() bool {
+	return true
+}
+func (c *Chain) BuildTransferAll(sender, receiver string) (txn base.Transaction, err error) {
+	return c.BuildTransferAllWithFeeRate(sender, receiver, MinFeeRate)
+}
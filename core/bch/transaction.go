@@ -0,0 +1,220 @@
+package bch
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/btc"
+	"github.com/coming-chat/wallet-SDK/util/hexutil"
+)
+
+const (
+	// The default minimum relay fee rate 1 sat/byte of Bitcoin Cash Node.
+	MinFeeRate int64 = 1
+
+	// SigHashForkID is the flag that all the Bitcoin Cash signatures must contain,
+	// the signature hash is calculated with the BIP143 algorithm, see
+	// https://github.com/bitcoincashorg/bitcoincash.org/blob/master/spec/replay-protected-sighash.md
+	SigHashForkID    txscript.SigHashType = 0x40
+	sigHashAllForkID                      = txscript.SigHashAll | SigHashForkID
+
+	// The size of the P2PKH signature script signed by the compressed public key.
+	p2pkhSignatureScriptSize int64 = 1 + 73 + 1 + 33
+)
+
+var (
+	ErrInsufficientBalance = btc.ErrInsufficientBalance
+	ErrDustAmount          = btc.ErrDustAmount
+	ErrNotSenderAccount    = errors.New("the account is not the sender of the transaction")
+)
+
+// Transaction is the unsigned P2PKH transaction that transfers BCH,
+// all the inputs are spent from the sender, and the change returns to the sender.
+type Transaction struct {
+	chainnet string
+	utxoTx   *btc.UtxoTransaction
+}
+
+// BuildTransferWithFeeRate
+// Build a P2PKH transfer transaction, the utxos of the sender will be selected automatically.
+// @param amount the satoshi amount to transfer
+// @param feeRate sat/byte, it's at least `MinFeeRate`.
+func (c *Chain) BuildTransferWithFeeRate(sender, receiver, amount string, feeRate int64) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil || value <= 0 {
+		return nil, errors.New("invalid transfer amount")
+	}
+	utxos, err := c.fetchUtxos(sender)
+	if err != nil {
+		return
+	}
+	return NewTransactionWithUtxos(c.Chainnet, sender, receiver, value, feeRate, utxos)
+}
+
+// BuildTransferAllWithFeeRate
+// Build a transaction that spend all the utxos of the sender to the receiver.
+// @param feeRate sat/byte, it's at least `MinFeeRate`.
+func (c *Chain) BuildTransferAllWithFeeRate(sender, receiver string, feeRate int64) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	utxos, err := c.fetchUtxos(sender)
+	if err != nil {
+		return
+	}
+	txn, err = newTransaction(c.Chainnet, sender)
+	if err != nil {
+		return
+	}
+	receiverScript, err := addressToPkScript(receiver, c.Chainnet)
+	if err != nil {
+		return
+	}
+	if err = txn.utxoTx.SpendAll(utxos, receiverScript, base.Max(feeRate, MinFeeRate)); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// NewTransactionWithUtxos
+// Build the transfer transaction with the specified utxos, the utxos are selected by the bitcoin's coin selection.
+// @param amount the satoshi amount to transfer
+// @param feeRate sat/byte, it's at least `MinFeeRate`.
+func NewTransactionWithUtxos(chainnet, sender, receiver string, amount, feeRate int64, utxos []*UTXO) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	txn, err = newTransaction(chainnet, sender)
+	if err != nil {
+		return
+	}
+	receiverScript, err := addressToPkScript(receiver, chainnet)
+	if err != nil {
+		return
+	}
+	if err = txn.utxoTx.AddOutput(receiverScript, amount); err != nil {
+		return nil, err
+	}
+	if err = txn.utxoTx.Fund(utxos, base.Max(feeRate, MinFeeRate)); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+func newTransaction(chainnet, sender string) (*Transaction, error) {
+	addrType, _, err := decodeAddress(sender, chainnet)
+	if err != nil {
+		return nil, err
+	}
+	if addrType != CashAddrTypeP2PKH {
+		return nil, errors.New("only the P2PKH sender is supported")
+	}
+	senderScript, err := addressToPkScript(sender, chainnet)
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{
+		chainnet: chainnet,
+		utxoTx:   btc.NewUtxoTransaction(senderScript, estimateTxSize),
+	}, nil
+}
+
+// estimateTxSize
+// @return the size of the P2PKH transaction after all inputs are signed, the inputs must be unsigned.
+func estimateTxSize(tx *wire.MsgTx) int64 {
+	return int64(tx.SerializeSize()) + int64(len(tx.TxIn))*p2pkhSignatureScriptSize
+}
+
+// Fee
+// @return the network fee (satoshi) of the transaction
+func (t *Transaction) Fee() int64 {
+	return t.utxoTx.Fee()
+}
+
+// EstimateSize
+// @return the size of the transaction after all inputs are signed
+func (t *Transaction) EstimateSize() int64 {
+	return t.utxoTx.EstimateSize()
+}
+
+// MARK - Implement the protocol Transaction
+
+func (t *Transaction) SignWithAccount(account base.Account) (signedTxn *base.OptionalString, err error) {
+	txn, err := t.SignedTransactionWithAccount(account)
+	if err != nil {
+		return nil, err
+	}
+	return txn.HexString()
+}
+
+func (t *Transaction) SignedTransactionWithAccount(account base.Account) (signedTxn base.SignedTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	bchAcc := AsBitcoinCashAccount(account)
+	if bchAcc == nil {
+		return nil, base.ErrInvalidAccountType
+	}
+	if bchAcc.Chainnet != t.chainnet {
+		return nil, ErrNotSenderAccount
+	}
+	accountScript, err := addressToPkScript(bchAcc.address, bchAcc.Chainnet)
+	if err != nil {
+		return
+	}
+	if !bytes.Equal(accountScript, t.utxoTx.SenderScript) {
+		return nil, ErrNotSenderAccount
+	}
+
+	tx := t.utxoTx.MsgTx.Copy()
+	sigHashes := txscript.NewTxSigHashes(tx, t.utxoTx.PrevOuts)
+	for i, in := range tx.TxIn {
+		prevOut := t.utxoTx.PrevOuts.FetchPrevOutput(in.PreviousOutPoint)
+		in.SignatureScript, err = signatureScript(tx, sigHashes, i, prevOut, bchAcc.privateKey)
+		if err != nil {
+			return
+		}
+	}
+	return &SignedTransaction{msgTx: tx}, nil
+}
+
+// signatureScript
+// Sign the P2PKH input with the SIGHASH_ALL|SIGHASH_FORKID.
+func signatureScript(tx *wire.MsgTx, sigHashes *txscript.TxSigHashes, idx int, prevOut *wire.TxOut, privateKey *btcec.PrivateKey) ([]byte, error) {
+	hash, err := calcSignatureHash(tx, sigHashes, idx, prevOut)
+	if err != nil {
+		return nil, err
+	}
+	signature := ecdsa.Sign(privateKey, hash)
+	sig := append(signature.Serialize(), byte(sigHashAllForkID))
+	return txscript.NewScriptBuilder().AddData(sig).AddData(privateKey.PubKey().SerializeCompressed()).Script()
+}
+
+// calcSignatureHash
+// The Bitcoin Cash's signature hash is the BIP143 digest with the fork id (0) in the sighash type.
+func calcSignatureHash(tx *wire.MsgTx, sigHashes *txscript.TxSigHashes, idx int, prevOut *wire.TxOut) ([]byte, error) {
+	return txscript.CalcWitnessSigHash(prevOut.PkScript, sigHashes, sigHashAllForkID, tx, idx, prevOut.Value)
+}
+
+type SignedTransaction struct {
+	msgTx *wire.MsgTx
+}
+
+func (t *SignedTransaction) HexString() (res *base.OptionalString, err error) {
+	var buf bytes.Buffer
+	if err := t.msgTx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(hexutil.HexEncodeToString(buf.Bytes())), nil
+}
+
+// TxHash
+// @return the hash of the signed transaction
+func (t *SignedTransaction) TxHash() string {
+	return t.msgTx.TxHash().String()
+}
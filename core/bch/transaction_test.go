@@ -0,0 +1,142 @@
+package bch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/coming-chat/wallet-SDK/core/btc"
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "antenna chaos arrive hungry distance human question history decade deal impose color"
+
+func newTestUtxos(values ...int64) []*UTXO {
+	utxos := make([]*UTXO, len(values))
+	for i, value := range values {
+		hash := chainhash.DoubleHashH([]byte{byte(i)})
+		utxos[i] = &UTXO{Txid: hash.String(), Vout: int64(i), Value: value}
+	}
+	return utxos
+}
+
+// verifyTransaction check the signature of the SIGHASH_FORKID manually,
+// because the script engine of btcd doesn't support it.
+func verifyTransaction(t *testing.T, txn *Transaction, signed *SignedTransaction) {
+	sigHashes := txscript.NewTxSigHashes(signed.msgTx, txn.utxoTx.PrevOuts)
+	for i, in := range signed.msgTx.TxIn {
+		pushes, err := txscript.PushedData(in.SignatureScript)
+		require.NoError(t, err)
+		require.Len(t, pushes, 2)
+		sig, pubkeyData := pushes[0], pushes[1]
+		require.Equal(t, byte(0x41), sig[len(sig)-1])
+
+		signature, err := ecdsa.ParseDERSignature(sig[:len(sig)-1])
+		require.NoError(t, err)
+		pubkey, err := btcec.ParsePubKey(pubkeyData)
+		require.NoError(t, err)
+		prevOut := txn.utxoTx.PrevOuts.FetchPrevOutput(in.PreviousOutPoint)
+		hash, err := calcSignatureHash(signed.msgTx, sigHashes, i, prevOut)
+		require.NoError(t, err)
+		require.True(t, signature.Verify(hash, pubkey))
+	}
+}
+
+func TestAccount(t *testing.T) {
+	account, err := AccountWithPrivateKey("0x0000000000000000000000000000000000000000000000000000000000000001", ChainMainnet)
+	require.NoError(t, err)
+	legacy, err := account.LegacyAddress()
+	require.NoError(t, err)
+	require.Equal(t, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", legacy)
+	require.Regexp(t, "^bitcoincash:q", account.Address())
+
+	wif, err := account.WIFPrivateKeyString()
+	require.NoError(t, err)
+	wifAccount, err := AccountWithPrivateKey(wif.Value, ChainMainnet)
+	require.NoError(t, err)
+	require.Equal(t, account.Address(), wifAccount.Address())
+
+	account, err = NewAccountWithMnemonic(testMnemonic, ChainTestnet)
+	require.NoError(t, err)
+	require.Regexp(t, "^bchtest:q", account.Address())
+	require.True(t, account.IsValidAddress(account.Address()))
+	require.Equal(t, "m/44'/145'/0'/0/0", account.DerivePath())
+
+	// the same key as the bitcoin's BIP44 account with the coin type 145
+	privateKey, err := btc.Derivation(testMnemonic, "m/44'/145'/0'/0/0")
+	require.NoError(t, err)
+	require.Equal(t, privateKey.PubKey().SerializeCompressed(), account.PublicKey())
+}
+
+func TestTransaction_Sign(t *testing.T) {
+	account, err := NewAccountWithMnemonic(testMnemonic, ChainMainnet)
+	require.NoError(t, err)
+	receiver := "bitcoincash:ppm2qsznhks23z7629mms6s4cwef74vcwvn0h829pq"
+
+	txn, err := NewTransactionWithUtxos(ChainMainnet, account.Address(), receiver, 150000, 2, newTestUtxos(100000, 20000, 100000))
+	require.NoError(t, err)
+	require.Len(t, txn.utxoTx.MsgTx.TxIn, 2)
+	require.Len(t, txn.utxoTx.MsgTx.TxOut, 2)
+	require.Equal(t, txn.EstimateSize()*2, txn.Fee())
+
+	signedTxn, err := txn.SignedTransactionWithAccount(account)
+	require.NoError(t, err)
+	signed := signedTxn.(*SignedTransaction)
+	verifyTransaction(t, txn, signed)
+	require.LessOrEqual(t, int64(signed.msgTx.SerializeSize()), txn.EstimateSize())
+
+	// the legacy sender address is the same sender
+	legacy, err := account.LegacyAddress()
+	require.NoError(t, err)
+	txn, err = NewTransactionWithUtxos(ChainMainnet, legacy, receiver, 150000, 2, newTestUtxos(200000))
+	require.NoError(t, err)
+	_, err = txn.SignedTransactionWithAccount(account)
+	require.NoError(t, err)
+
+	other, err := NewAccountWithMnemonic(testMnemonic, ChainTestnet)
+	require.NoError(t, err)
+	_, err = txn.SignedTransactionWithAccount(other)
+	require.Equal(t, ErrNotSenderAccount, err)
+
+	_, err = NewTransactionWithUtxos(ChainMainnet, account.Address(), receiver, 200000, 2, newTestUtxos(200000))
+	require.Equal(t, ErrInsufficientBalance, err)
+	_, err = NewTransactionWithUtxos(ChainMainnet, receiver, account.Address(), 10000, 2, newTestUtxos(200000))
+	require.Error(t, err)
+}
+
+func TestChain_Backend(t *testing.T) {
+	defer SetBackend(ChainMainnet, nil)
+
+	chain, err := NewChainWithChainnet(ChainMainnet)
+	require.NoError(t, err)
+	account, err := NewAccountWithMnemonic(testMnemonic, ChainMainnet)
+	require.NoError(t, err)
+	_, err = chain.BalanceOfAccount(account)
+	require.EqualError(t, err, ErrBackendNotConfigured.Error())
+
+	pkScript, err := addressToPkScript(account.Address(), ChainMainnet)
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/scripthash/"+btc.ScriptHash(pkScript)+"/utxo", r.URL.Path)
+		_, _ = w.Write([]byte(`[{"txid":"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b","vout":0,"value":20000},
+			{"txid":"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b","vout":1,"value":500000}]`))
+	}))
+	defer server.Close()
+	require.NoError(t, SetBackend(ChainMainnet, NewBackend(server.URL)))
+	backend, err := BackendOf(ChainMainnet)
+	require.NoError(t, err)
+	require.Equal(t, server.URL, backend.EsploraUrl)
+
+	txn, err := chain.BuildTransferAll(account.Address(), "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu")
+	require.NoError(t, err)
+	fee, err := chain.EstimateTransactionFee(txn)
+	require.NoError(t, err)
+	require.Equal(t, "342", fee.Value)
+	signed, err := txn.SignedTransactionWithAccount(account)
+	require.NoError(t, err)
+	verifyTransaction(t, txn.(*Transaction), signed.(*SignedTransaction))
+}
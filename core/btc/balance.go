@@ -55,18 +55,11 @@ func BatchQueryBalance(addresses *base.StringArray, chainnet string) (*base.Stri
 // queryBalance
 // query the balance according to the address.
 func queryBalance(address, chainnet string) (string, error) {
-	host, err := scanHostOf(chainnet)
+	client, err := esploraClientOf(chainnet)
 	if err != nil {
 		return "0", err
 	}
-	url := host + "/address/" + address
-
-	response, err := httpUtil.Request(http.MethodGet, url, nil, nil)
-	if err != nil {
-		return "0", base.MapAnyToBasicError(err)
-	}
-
-	return parseBalanceResponse(response)
+	return client.QueryBalance(address)
 }
 
 // queryBalancePubkey
@@ -120,3 +113,44 @@ func QueryBalance(address, chainnet string) (string, error) {
 func QueryBalancePubkey(pubkey, chainnet string) (string, error) {
 	return queryBalancePubkey(pubkey, chainnet)
 }
+
+type addressStats struct {
+	Address string `json:"address"`
+	// The count of the confirmed and mempool transactions
+	TxCount int64 `json:"txCount"`
+	// The confirmed balance
+	Balance int64 `json:"balance"`
+}
+
+// queryAddressStats
+// query the transaction count and balance of the address with the esplora api `/address/:address`
+func queryAddressStats(address, chainnet string) (*addressStats, error) {
+	host, err := scanHostOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	response, err := httpUtil.Request(http.MethodGet, host+"/address/"+address, nil, nil)
+	if err != nil {
+		return nil, base.MapAnyToBasicError(err)
+	}
+	if response.Code != http.StatusOK {
+		return nil, fmt.Errorf("code: %d, body: %s", response.Code, string(response.Body))
+	}
+	type stats struct {
+		TxCount      int64 `json:"tx_count"`
+		FundedTxoSum int64 `json:"funded_txo_sum"`
+		SpentTxoSum  int64 `json:"spent_txo_sum"`
+	}
+	var resp struct {
+		ChainStats   stats `json:"chain_stats"`
+		MempoolStats stats `json:"mempool_stats"`
+	}
+	if err = json.Unmarshal(response.Body, &resp); err != nil {
+		return nil, ErrHttpResponseParse
+	}
+	return &addressStats{
+		Address: address,
+		TxCount: resp.ChainStats.TxCount + resp.MempoolStats.TxCount,
+		Balance: max(0, resp.ChainStats.FundedTxoSum-resp.ChainStats.SpentTxoSum),
+	}, nil
+}
@@ -0,0 +1,190 @@
+package btc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+const (
+	bip322MessageTag = "BIP0322-signed-message"
+)
+
+// SignMessageBip322
+// Sign the message with the BIP322 simple format, which is the base64 encoded witness of the virtual to_sign transaction.
+// The legacy and nested segwit addresses require the scriptSig, so the full format will be used.
+// https://github.com/bitcoin/bips/blob/master/bip-0322.mediawiki
+// @param msg The message to create a signature of.
+// @return The signature of the message encoded in base64.
+func (a *Account) SignMessageBip322(msg string) (*base.OptionalString, error) {
+	toSign, err := a.signBip322(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(toSign.TxIn[0].SignatureScript) > 0 {
+		return encodeBip322Full(toSign)
+	}
+	var buf bytes.Buffer
+	if err = writeTxWitness(&buf, toSign.TxIn[0].Witness); err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
+
+// SignMessageBip322Full
+// Sign the message with the BIP322 full format, which is the base64 encoded virtual to_sign transaction.
+// @param msg The message to create a signature of.
+// @return The signature of the message encoded in base64.
+func (a *Account) SignMessageBip322Full(msg string) (*base.OptionalString, error) {
+	toSign, err := a.signBip322(msg)
+	if err != nil {
+		return nil, err
+	}
+	return encodeBip322Full(toSign)
+}
+
+func (a *Account) signBip322(msg string) (*wire.MsgTx, error) {
+	pkScript, err := addressToPkScript(a.address, a.chain)
+	if err != nil {
+		return nil, err
+	}
+	toSpend := bip322ToSpend(msg, pkScript)
+	toSign := bip322ToSign(toSpend)
+	fetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+	isComing := a.addressType == AddressTypeComingTaproot
+	if err = signInput(toSign, 0, a.privateKey, fetcher, isComing); err != nil {
+		return nil, err
+	}
+	return toSign, nil
+}
+
+// VerifyMessageBip322
+// Verify the BIP322 simple or full signature of the address, the signature of every address type is supported.
+// @param signature The signature encoded in base64.
+func VerifyMessageBip322(address, message, signature, chainnet string) bool {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return false
+	}
+	pkScript, err := addressToPkScript(address, net)
+	if err != nil {
+		return false
+	}
+	sigBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	toSpend := bip322ToSpend(message, pkScript)
+	toSign, err := decodeBip322Signature(sigBytes, toSpend)
+	if err != nil {
+		return false
+	}
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+	sigHashes := txscript.NewTxSigHashes(toSign, fetcher)
+	engine, err := txscript.NewEngine(pkScript, toSign, 0, txscript.StandardVerifyFlags, nil, sigHashes, 0, fetcher)
+	if err != nil {
+		return false
+	}
+	return engine.Execute() == nil
+}
+
+// Bip322MessageHash
+// @return the tagged hash of the message
+func Bip322MessageHash(message string) []byte {
+	return chainhash.TaggedHash([]byte(bip322MessageTag), []byte(message))[:]
+}
+
+// bip322ToSpend
+// The virtual transaction that the output is spent by the signature.
+func bip322ToSpend(message string, pkScript []byte) *wire.MsgTx {
+	tx := wire.NewMsgTx(0)
+	sigScript := append([]byte{txscript.OP_0, txscript.OP_DATA_32}, Bip322MessageHash(message)...)
+	in := wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex), sigScript, nil)
+	in.Sequence = 0
+	tx.AddTxIn(in)
+	tx.AddTxOut(wire.NewTxOut(0, pkScript))
+	return tx
+}
+
+// bip322ToSign
+// The unsigned virtual transaction that spends the to_spend transaction.
+func bip322ToSign(toSpend *wire.MsgTx) *wire.MsgTx {
+	tx := wire.NewMsgTx(0)
+	hash := toSpend.TxHash()
+	in := wire.NewTxIn(wire.NewOutPoint(&hash, 0), nil, nil)
+	in.Sequence = 0
+	tx.AddTxIn(in)
+	tx.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+	return tx
+}
+
+// decodeBip322Signature
+// @return the signed to_sign transaction, the signature is decoded as the full format first, and then the simple format.
+func decodeBip322Signature(sigBytes []byte, toSpend *wire.MsgTx) (*wire.MsgTx, error) {
+	expected := bip322ToSign(toSpend)
+	full := wire.NewMsgTx(0)
+	err := full.Deserialize(bytes.NewReader(sigBytes))
+	if err == nil && len(full.TxIn) > 0 && full.TxIn[0].PreviousOutPoint == expected.TxIn[0].PreviousOutPoint {
+		if (full.Version != 0 && full.Version != 2) || len(full.TxIn) != 1 || len(full.TxOut) != 1 ||
+			full.TxOut[0].Value != 0 || !bytes.Equal(full.TxOut[0].PkScript, expected.TxOut[0].PkScript) {
+			return nil, errors.New("invalid bip322 to_sign transaction")
+		}
+		return full, nil
+	}
+
+	witness, err := readTxWitness(bytes.NewReader(sigBytes))
+	if err != nil {
+		return nil, err
+	}
+	expected.TxIn[0].Witness = witness
+	return expected, nil
+}
+
+func encodeBip322Full(toSign *wire.MsgTx) (*base.OptionalString, error) {
+	var buf bytes.Buffer
+	if err := toSign.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
+
+// writeTxWitness
+// The consensus encoding of the witness stack
+func writeTxWitness(buf *bytes.Buffer, witness wire.TxWitness) error {
+	if err := wire.WriteVarInt(buf, 0, uint64(len(witness))); err != nil {
+		return err
+	}
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(buf, 0, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readTxWitness(r *bytes.Reader) (wire.TxWitness, error) {
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 || count > uint64(r.Len()) {
+		return nil, errors.New("invalid bip322 witness")
+	}
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		witness[i], err = wire.ReadVarBytes(r, 0, txscript.MaxScriptSize, "witness")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("invalid bip322 witness")
+	}
+	return witness, nil
+}
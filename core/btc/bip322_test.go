@@ -0,0 +1,58 @@
+package btc
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBip322_Vectors(t *testing.T) {
+	require.Equal(t, "c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1", hex.EncodeToString(Bip322MessageHash("")))
+	require.Equal(t, "f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a", hex.EncodeToString(Bip322MessageHash("Hello World")))
+
+	address := "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"
+	account, err := AccountWithPrivateKey("L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k", ChainMainnet, AddressTypeNativeSegwit)
+	require.NoError(t, err)
+	require.Equal(t, address, account.Address())
+	pkScript, err := addressToPkScript(address, account.chain)
+	require.NoError(t, err)
+
+	toSpend := bip322ToSpend("", pkScript)
+	require.Equal(t, "c5680aa69bb8d860bf82d4e9cd3504b55dde018de765a91bb566283c545a99a7", toSpend.TxHash().String())
+	require.Equal(t, "1e9654e951a5ba44c8604c4de6c67fd78a27e81dcadcfe1edf638ba3aaebaed6", bip322ToSign(toSpend).TxHash().String())
+	toSpend = bip322ToSpend("Hello World", pkScript)
+	require.Equal(t, "b79d196740ad5217771c1098fc4a4b51e0535c32236c71f1ea4d61a2d603352b", toSpend.TxHash().String())
+	require.Equal(t, "88737ae86f2077145f93cc4b153ae9a1cb8d56afa511988c149c5c8c9d93bddf", bip322ToSign(toSpend).TxHash().String())
+
+	require.True(t, VerifyMessageBip322(address, "", "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=", ChainMainnet))
+	require.True(t, VerifyMessageBip322(address, "Hello World", "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=", ChainMainnet))
+	require.False(t, VerifyMessageBip322(address, "Hello World", "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=", ChainMainnet))
+}
+
+func TestAccount_SignMessageBip322(t *testing.T) {
+	mnemonic := "antenna chaos arrive hungry distance human question history decade deal impose color"
+	message := "hello world~"
+	for _, addrType := range []AddressType{AddressTypeComingTaproot, AddressTypeNativeSegwit,
+		AddressTypeNestedSegwit, AddressTypeTaproot, AddressTypeLegacy} {
+		t.Run(AddressTypeDescription(addrType), func(t *testing.T) {
+			account, err := NewAccountWithMnemonic(mnemonic, ChainSignet, addrType)
+			require.NoError(t, err)
+
+			simple, err := account.SignMessageBip322(message)
+			require.NoError(t, err)
+			require.True(t, VerifyMessageBip322(account.Address(), message, simple.Value, ChainSignet))
+			full, err := account.SignMessageBip322Full(message)
+			require.NoError(t, err)
+			require.True(t, VerifyMessageBip322(account.Address(), message, full.Value, ChainSignet))
+
+			require.False(t, VerifyMessageBip322(account.Address(), message+"!", simple.Value, ChainSignet))
+			require.False(t, VerifyMessageBip322(account.Address(), message+"!", full.Value, ChainSignet))
+			other, err := NewAccountWithMnemonic(mnemonic, ChainSignet, AddressTypeTaproot)
+			require.NoError(t, err)
+			if addrType != AddressTypeTaproot {
+				require.False(t, VerifyMessageBip322(other.Address(), message, simple.Value, ChainSignet))
+			}
+		})
+	}
+}
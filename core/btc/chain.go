@@ -1,10 +1,14 @@
 package btc
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/btcsuite/btcd/wire"
 	"github.com/coming-chat/wallet-SDK/core/base"
@@ -13,6 +17,9 @@ import (
 
 type Chain struct {
 	*Util
+
+	// the transaction status is verified by the spv proof if it's not nil.
+	spv *spvVerifier
 }
 
 func NewChainWithChainnet(chainnet string) (*Chain, error) {
@@ -72,6 +79,9 @@ func (c *Chain) SendSignedTransaction(signedTxn base.SignedTransaction) (hash *b
 	if brc20MintTxn, ok := signedTxn.(*Brc20MintTransaction); ok {
 		return brc20MintTxn.PublishWithChain(c)
 	}
+	if etchTxn, ok := signedTxn.(*SignedCommitRevealTransaction); ok {
+		return etchTxn.PublishWithChain(c)
+	}
 	if psbtTxn, ok := signedTxn.(*SignedPsbtTransaction); ok {
 		msgTx, err := PsbtPacketToMsgTx(&psbtTxn.Packet)
 		if err != nil {
@@ -89,6 +99,9 @@ func (c *Chain) sendWireMsgTx(tx *wire.MsgTx) (hash *base.OptionalString, err er
 	defer base.CatchPanicAndMapToBasicError(&err)
 
 	client, err := rpcClientOf(c.Chainnet)
+	if err == ErrRpcCredentialsNotConfigured {
+		return sendWireMsgTxByEsplora(tx, c.Chainnet)
+	}
 	if err != nil {
 		return
 	}
@@ -99,23 +112,64 @@ func (c *Chain) sendWireMsgTx(tx *wire.MsgTx) (hash *base.OptionalString, err er
 	return base.NewOptionalString(hashv.String()), nil
 }
 
+// sendWireMsgTxByEsplora
+// Broadcast the transaction by the esplora, it's used if the rpc credentials are not configured.
+func sendWireMsgTxByEsplora(tx *wire.MsgTx, chainnet string) (*base.OptionalString, error) {
+	client, err := esploraClientOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	hash, err := client.SendRawTransaction(hex.EncodeToString(buf.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(hash), nil
+}
+
 // Fetch transaction details through transaction hash
 // Note: The input parsing of bitcoin is very complex and the network cost is relatively high,
 // So only the status and timestamp can be queried.
+// The success status is reported after the spv proof is verified if the `EnableSpvVerification` is called.
 func (c *Chain) FetchTransactionDetail(hash string) (*base.TransactionDetail, error) {
+	if c.spv != nil {
+		return c.fetchTransactionDetailSpv(hash)
+	}
 	return fetchTransactionDetail(hash, c.Chainnet)
 }
 
 func (c *Chain) FetchTransactionStatus(hash string) base.TransactionStatus {
+	if c.spv != nil {
+		detail, err := c.fetchTransactionDetailSpv(hash)
+		if err != nil {
+			return base.TransactionStatusNone
+		}
+		return detail.Status
+	}
 	return fetchTransactionStatus(hash, c.Chainnet)
 }
 
 func (c *Chain) BatchFetchTransactionStatus(hashListString string) string {
+	if c.spv != nil {
+		hashList := strings.Split(hashListString, ",")
+		statuses, _ := base.MapListConcurrentStringToString(hashList, func(s string) (string, error) {
+			return strconv.Itoa(c.FetchTransactionStatus(s)), nil
+		})
+		return strings.Join(statuses, ",")
+	}
 	return sdkBatchTransactionStatus(hashListString, c.Chainnet)
 }
 
 func (c *Chain) EstimateTransactionFee(transaction base.Transaction) (fee *base.OptionalString, err error) {
-	return nil, base.ErrUnsupportedFunction
+	txn, ok := transaction.(*Transaction)
+	if !ok {
+		return nil, base.ErrUnsupportedFunction
+	}
+	feeValue := txn.TotalInputValue() - txn.TotalOutputValue()
+	return base.NewOptionalString(strconv.FormatInt(feeValue, 10)), nil
 }
 func (c *Chain) EstimateTransactionFeeUsePublicKey(transaction base.Transaction, pubkey string) (fee *base.OptionalString, err error) {
 	return c.EstimateTransactionFee(transaction)
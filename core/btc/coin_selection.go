@@ -0,0 +1,290 @@
+package btc
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const (
+	// The maximum number of the branch and bound tries, same as bitcoin core.
+	bnbTotalTries = 100000
+	// The iterations of the knapsack's stochastic approximation, same as bitcoin core.
+	knapsackIterations = 1000
+)
+
+var (
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrDustAmount          = errors.New("transfer amount is too small (dust)")
+)
+
+// coinSelectionParams describes the size of the transaction being funded.
+// All sizes are virtual size.
+type coinSelectionParams struct {
+	feeRate int64
+	// The size of the transaction without any input and change output.
+	baseSize int64
+	// The size of an input spent from the sender.
+	inputSize int64
+	// The size of the change output.
+	changeOutputSize int64
+	// Change value less than the threshold will be dropped to the network fee.
+	dustThreshold int64
+}
+
+func (p *coinSelectionParams) effectiveValue(u *UTXO) int64 {
+	return u.Value - p.feeRate*p.inputSize
+}
+
+// The fee of creating the change output now and spending it in the future.
+func (p *coinSelectionParams) costOfChange() int64 {
+	return p.feeRate * (p.changeOutputSize + p.inputSize)
+}
+
+type coinSelectionResult struct {
+	utxos []*UTXO
+	// The network fee, not include the change output's fee if no change.
+	fee    int64
+	change int64
+}
+
+func (r *coinSelectionResult) totalValue() int64 {
+	total := int64(0)
+	for _, u := range r.utxos {
+		total += u.Value
+	}
+	return total
+}
+
+// selectCoins choose utxos to pay the target amount.
+// It will try the branch and bound algorithm first to find a changeless solution,
+// and fallback to the knapsack solver when bnb failed.
+func selectCoins(utxos []*UTXO, target int64, params *coinSelectionParams) (*coinSelectionResult, error) {
+	selectionTarget := target + params.feeRate*params.baseSize
+	selected := selectCoinsBnB(utxos, selectionTarget, params)
+	if selected == nil {
+		random := rand.New(rand.NewSource(time.Now().UnixNano()))
+		selected = selectCoinsKnapsack(utxos, selectionTarget, params, random)
+	}
+	if selected == nil {
+		return nil, ErrInsufficientBalance
+	}
+	return finishCoinSelection(selected, target, params)
+}
+
+// finishCoinSelection calculate the network fee and the change of the selected utxos.
+func finishCoinSelection(selected []*UTXO, target int64, params *coinSelectionParams) (*coinSelectionResult, error) {
+	res := &coinSelectionResult{utxos: selected}
+	fee := params.feeRate * (params.baseSize + int64(len(selected))*params.inputSize)
+	excess := res.totalValue() - target - fee
+	if excess < 0 {
+		return nil, ErrInsufficientBalance
+	}
+	changeFee := params.feeRate * params.changeOutputSize
+	if change := excess - changeFee; change >= params.dustThreshold {
+		res.change = change
+		res.fee = fee + changeFee
+	} else {
+		res.fee = fee + excess
+	}
+	return res, nil
+}
+
+// selectCoinsBnB
+// Search the utxos' subset that's effective value is between [target, target + costOfChange],
+// the selected subset need not a change output.
+// https://github.com/bitcoin/bitcoin/blob/master/src/wallet/coinselection.cpp
+func selectCoinsBnB(utxos []*UTXO, target int64, params *coinSelectionParams) []*UTXO {
+	pool := make([]*UTXO, 0, len(utxos))
+	totalAvailable := int64(0)
+	for _, u := range utxos {
+		if v := params.effectiveValue(u); v > 0 {
+			pool = append(pool, u)
+			totalAvailable += v
+		}
+	}
+	if totalAvailable < target {
+		return nil
+	}
+	sort.SliceStable(pool, func(i, j int) bool {
+		return params.effectiveValue(pool[i]) > params.effectiveValue(pool[j])
+	})
+	values := make([]int64, len(pool))
+	for i, u := range pool {
+		values[i] = params.effectiveValue(u)
+	}
+
+	var (
+		costOfChange  = params.costOfChange()
+		currentValue  = int64(0)
+		bestWaste     = int64(-1)
+		currentSelect = make([]bool, len(pool))
+		bestSelect    []bool
+		lookahead     = totalAvailable
+	)
+	for tries, index := 0, 0; tries < bnbTotalTries; tries, index = tries+1, index+1 {
+		backtrack := false
+		switch {
+		case currentValue+lookahead < target,
+			currentValue > target+costOfChange:
+			backtrack = true
+		case currentValue >= target:
+			// The waste is just the excess since the fee rate of spending now is same as the future.
+			waste := currentValue - target
+			if bestWaste < 0 || waste <= bestWaste {
+				bestWaste = waste
+				bestSelect = append([]bool{}, currentSelect...)
+			}
+			backtrack = true
+		}
+
+		if backtrack {
+			// Walk backwards to find the last included utxo that still needs to have its omission branch traversed.
+			index--
+			for index >= 0 && !currentSelect[index] {
+				lookahead += values[index]
+				index--
+			}
+			if index < 0 {
+				break
+			}
+			currentSelect[index] = false
+			currentValue -= values[index]
+			continue
+		}
+
+		if index >= len(pool) {
+			// It's impossible because the lookahead check will backtrack first.
+			break
+		}
+		// Moving forwards, continuing down this branch
+		lookahead -= values[index]
+		// Avoid searching a branch if the previous utxo has the same value and same waste and was excluded.
+		if currentValue > 0 && !currentSelect[index-1] && values[index] == values[index-1] {
+			continue
+		}
+		currentSelect[index] = true
+		currentValue += values[index]
+	}
+
+	if bestSelect == nil {
+		return nil
+	}
+	res := []*UTXO{}
+	for i, selected := range bestSelect {
+		if selected {
+			res = append(res, pool[i])
+		}
+	}
+	return res
+}
+
+// selectCoinsKnapsack
+// The knapsack solver of bitcoin core, it's result will have a change output in most cases.
+func selectCoinsKnapsack(utxos []*UTXO, target int64, params *coinSelectionParams, random *rand.Rand) []*UTXO {
+	minChange := params.feeRate*params.changeOutputSize + params.dustThreshold
+
+	pool := make([]*UTXO, 0, len(utxos))
+	for _, u := range utxos {
+		if params.effectiveValue(u) > 0 {
+			pool = append(pool, u)
+		}
+	}
+	random.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+	var (
+		lowestLarger *UTXO
+		applicable   = []*UTXO{}
+		totalLower   = int64(0)
+	)
+	for _, u := range pool {
+		value := params.effectiveValue(u)
+		switch {
+		case value == target:
+			return []*UTXO{u}
+		case value < target+minChange:
+			applicable = append(applicable, u)
+			totalLower += value
+		case lowestLarger == nil || value < params.effectiveValue(lowestLarger):
+			lowestLarger = u
+		}
+	}
+
+	if totalLower == target {
+		return applicable
+	}
+	if totalLower < target {
+		if lowestLarger == nil {
+			return nil
+		}
+		return []*UTXO{lowestLarger}
+	}
+
+	sort.SliceStable(applicable, func(i, j int) bool {
+		return params.effectiveValue(applicable[i]) > params.effectiveValue(applicable[j])
+	})
+	values := make([]int64, len(applicable))
+	for i, u := range applicable {
+		values[i] = params.effectiveValue(u)
+	}
+	bestSelect, bestValue := approximateBestSubset(values, totalLower, target, random)
+	if bestValue != target && totalLower >= target+minChange {
+		bestSelect, bestValue = approximateBestSubset(values, totalLower, target+minChange, random)
+	}
+
+	// If we have a bigger coin and (either the stochastic approximation didn't find a good solution,
+	// or the next bigger coin is closer), return the bigger coin
+	if lowestLarger != nil &&
+		((bestValue != target && bestValue < target+minChange) || params.effectiveValue(lowestLarger) <= bestValue) {
+		return []*UTXO{lowestLarger}
+	}
+	res := []*UTXO{}
+	for i, selected := range bestSelect {
+		if selected {
+			res = append(res, applicable[i])
+		}
+	}
+	return res
+}
+
+func approximateBestSubset(values []int64, totalLower, target int64, random *rand.Rand) ([]bool, int64) {
+	bestSelect := make([]bool, len(values))
+	for i := range bestSelect {
+		bestSelect[i] = true
+	}
+	bestValue := totalLower
+
+	included := make([]bool, len(values))
+	for rep := 0; rep < knapsackIterations && bestValue != target; rep++ {
+		for i := range included {
+			included[i] = false
+		}
+		total := int64(0)
+		reachedTarget := false
+		for pass := 0; pass < 2 && !reachedTarget; pass++ {
+			for i, value := range values {
+				// The solver here uses a randomized algorithm,
+				// the randomness serves no real security purpose but is just
+				// needed to prevent degenerate behavior
+				if included[i] {
+					continue
+				}
+				if (pass == 0 && random.Intn(2) == 0) || pass == 1 {
+					total += value
+					included[i] = true
+					if total >= target {
+						reachedTarget = true
+						if total < bestValue {
+							bestValue = total
+							copy(bestSelect, included)
+						}
+						total -= value
+						included[i] = false
+					}
+				}
+			}
+		}
+	}
+	return bestSelect, bestValue
+}
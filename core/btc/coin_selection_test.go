@@ -0,0 +1,111 @@
+package btc
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUtxos(values ...int64) []*UTXO {
+	utxos := make([]*UTXO, len(values))
+	for i, v := range values {
+		utxos[i] = &UTXO{
+			Txid:  "7ad33b3012aef3e153c65b57449ae4b279017aa99a6b581ea070903b3ca2b73f",
+			Vout:  int64(i),
+			Value: v,
+		}
+	}
+	return utxos
+}
+
+func sumUtxos(utxos []*UTXO) int64 {
+	total := int64(0)
+	for _, u := range utxos {
+		total += u.Value
+	}
+	return total
+}
+
+func TestSelectCoinsBnB(t *testing.T) {
+	params := &coinSelectionParams{
+		feeRate:          1,
+		baseSize:         10,
+		inputSize:        10,
+		changeOutputSize: 10,
+		dustThreshold:    294,
+	}
+	// effective values: 990, 1990, 2990, 3990, 4990
+	utxos := newTestUtxos(1000, 2000, 3000, 4000, 5000)
+
+	selected := selectCoinsBnB(utxos, 6980, params)
+	require.NotNil(t, selected)
+	total := int64(0)
+	for _, u := range selected {
+		total += params.effectiveValue(u)
+	}
+	require.GreaterOrEqual(t, total, int64(6980))
+	require.LessOrEqual(t, total, int64(6980)+params.costOfChange())
+
+	// exact match is impossible
+	selected = selectCoinsBnB(newTestUtxos(10000), 5000, params)
+	require.Nil(t, selected)
+
+	// insufficient
+	selected = selectCoinsBnB(utxos, 100000, params)
+	require.Nil(t, selected)
+}
+
+func TestSelectCoinsKnapsack(t *testing.T) {
+	params := &coinSelectionParams{
+		feeRate:          2,
+		baseSize:         10,
+		inputSize:        68,
+		changeOutputSize: 31,
+		dustThreshold:    294,
+	}
+	random := rand.New(rand.NewSource(1))
+
+	utxos := newTestUtxos(1000, 3000, 5000, 100000)
+	selected := selectCoinsKnapsack(utxos, 7000, params, random)
+	require.NotNil(t, selected)
+	total := int64(0)
+	for _, u := range selected {
+		total += params.effectiveValue(u)
+	}
+	require.GreaterOrEqual(t, total, int64(7000))
+
+	// lowest larger
+	selected = selectCoinsKnapsack(utxos, 20000, params, random)
+	require.Equal(t, selected, utxos[3:])
+
+	// insufficient
+	selected = selectCoinsKnapsack(utxos, 200000, params, random)
+	require.Nil(t, selected)
+}
+
+func TestSelectCoins(t *testing.T) {
+	params := &coinSelectionParams{
+		feeRate:          3,
+		baseSize:         43,
+		inputSize:        68,
+		changeOutputSize: 31,
+		dustThreshold:    294,
+	}
+
+	for _, target := range []int64{1000, 8000, 12345, 50000, 109000} {
+		utxos := newTestUtxos(546, 1200, 5000, 8000, 20000, 80000)
+		res, err := selectCoins(utxos, target, params)
+		require.NoError(t, err)
+
+		minFee := params.feeRate * (params.baseSize + int64(len(res.utxos))*params.inputSize)
+		require.GreaterOrEqual(t, res.fee, minFee)
+		require.Equal(t, sumUtxos(res.utxos), target+res.fee+res.change)
+		if res.change > 0 {
+			require.GreaterOrEqual(t, res.change, params.dustThreshold)
+		}
+	}
+
+	_, err := selectCoins(newTestUtxos(1000, 2000), 3000, params)
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+}
@@ -0,0 +1,96 @@
+package btc
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/btc/ordinal"
+)
+
+// commitReveal
+// The commit psbt sends to the tapscript commit address at output 0,
+// and the reveal transaction spends it at input `commitIndex` with the script path.
+type commitReveal struct {
+	commitPsbt  *PsbtTransaction
+	commit      *ordinal.TapscriptCommit
+	reveal      *wire.MsgTx
+	commitIndex int
+	// The previous outputs of the reveal's other inputs in order, they are signed with the key path.
+	revealPrevOuts []*wire.TxOut
+}
+
+func (c *commitReveal) signWithAccount(account base.Account) (*SignedCommitRevealTransaction, error) {
+	btcAccount, ok := account.(*Account)
+	if !ok {
+		return nil, base.ErrInvalidAccountType
+	}
+	if !btcAccount.privateKey.PubKey().IsEqual(c.commit.InternalKey) {
+		return nil, errors.New("the account does not match the commit public key")
+	}
+	commitPsbt := &PsbtTransaction{Packet: c.commitPsbt.Packet}
+	signedCommit, err := commitPsbt.SignedTransactionWithAccount(account)
+	if err != nil {
+		return nil, err
+	}
+	signedCommitPsbt := signedCommit.(*SignedPsbtTransaction)
+	commitTx, err := PsbtPacketToMsgTx(&signedCommitPsbt.Packet)
+	if err != nil {
+		return nil, err
+	}
+
+	reveal := c.reveal.Copy()
+	reveal.TxIn[c.commitIndex].PreviousOutPoint = wire.OutPoint{Hash: commitTx.TxHash(), Index: 0}
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	fetcher.AddPrevOut(reveal.TxIn[c.commitIndex].PreviousOutPoint, commitTx.TxOut[0])
+	others := make([]int, 0, len(c.revealPrevOuts))
+	for i := range reveal.TxIn {
+		if i != c.commitIndex {
+			others = append(others, i)
+		}
+	}
+	if len(others) != len(c.revealPrevOuts) {
+		return nil, errors.New("the previous outputs of the reveal inputs are missing")
+	}
+	for i, prevOut := range c.revealPrevOuts {
+		fetcher.AddPrevOut(reveal.TxIn[others[i]].PreviousOutPoint, prevOut)
+	}
+	isComing := btcAccount.addressType == AddressTypeComingTaproot
+	for _, i := range others {
+		if err = signInput(reveal, i, btcAccount.privateKey, fetcher, isComing); err != nil {
+			return nil, err
+		}
+	}
+	if err = c.commit.SignInput(reveal, c.commitIndex, fetcher, btcAccount.privateKey); err != nil {
+		return nil, err
+	}
+	return &SignedCommitRevealTransaction{
+		Commit: signedCommitPsbt,
+		Reveal: &SignedTransaction{msgTx: reveal},
+	}, nil
+}
+
+type SignedCommitRevealTransaction struct {
+	Commit *SignedPsbtTransaction
+	Reveal *SignedTransaction
+}
+
+// HexString
+// @return the reveal transaction hex, which should be published after the commit transaction.
+func (t *SignedCommitRevealTransaction) HexString() (res *base.OptionalString, err error) {
+	return t.Reveal.HexString()
+}
+
+// PublishWithChain
+// Publish the commit transaction
+func (t *SignedCommitRevealTransaction) PublishWithChain(c *Chain) (hash *base.OptionalString, err error) {
+	return c.SendSignedTransaction(t.Commit)
+}
+
+// PublishRevealWithChain
+// Publish the reveal transaction, it should be called after the commit transaction is published,
+// the runes etching reveal can only be accepted after the commit transaction has 6 confirmations.
+func (t *SignedCommitRevealTransaction) PublishRevealWithChain(c *Chain) (hash *base.OptionalString, err error) {
+	return c.SendSignedTransaction(t.Reveal)
+}
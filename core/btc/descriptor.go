@@ -0,0 +1,512 @@
+package btc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/coming-chat/wallet-SDK/util/hexutil"
+)
+
+const (
+	descriptorChecksumLength = 8
+	descriptorInputCharset   = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+var (
+	ErrInvalidDescriptor         = errors.New("invalid output descriptor")
+	ErrInvalidDescriptorChecksum = errors.New("invalid output descriptor checksum")
+	ErrInvalidExtendedKey        = errors.New("invalid extended public key")
+)
+
+// The versions of the SLIP-0132 extended public keys
+var (
+	xpubVersion = [4]byte{0x04, 0x88, 0xb2, 0x1e}
+	ypubVersion = [4]byte{0x04, 0x9d, 0x7c, 0xb2}
+	zpubVersion = [4]byte{0x04, 0xb2, 0x47, 0x46}
+	tpubVersion = [4]byte{0x04, 0x35, 0x87, 0xcf}
+	upubVersion = [4]byte{0x04, 0x4a, 0x52, 0x62}
+	vpubVersion = [4]byte{0x04, 0x5f, 0x1c, 0xf6}
+)
+
+// Descriptor is the output script descriptor (BIP380) that can derive addresses.
+// Supported: pkh(KEY), wpkh(KEY), sh(wpkh(KEY)), tr(KEY), and multi/sortedmulti wrapped by sh, wsh or sh(wsh).
+// The key can be a hex public key, or an extended public key with a derivation path ends with `/*`,
+// the multipath step `/<0;1>/*` (BIP389) is used to derive the receive and change addresses.
+type Descriptor struct {
+	Chainnet string `json:"chainnet"`
+
+	body      string
+	netParams *chaincfg.Params
+	// The script wrapper, "", "sh", "wsh" or "sh(wsh"
+	wrapper string
+	// The script function, "pkh", "wpkh", "tr", "multi" or "sortedmulti"
+	function  string
+	threshold int
+	keys      []*descriptorKey
+}
+
+type descriptorKey struct {
+	pubkey *btcec.PublicKey
+	extKey *hdkeychain.ExtendedKey
+	path   []uint32
+	// The branches of the multipath step, only one branch if there is no multipath step.
+	branches []uint32
+	// The index of the multipath step in the path, -1 if there is no multipath step.
+	multipath int
+	wildcard  bool
+}
+
+// NewDescriptor
+// @param descriptor the output descriptor, the checksum is optional.
+func NewDescriptor(descriptor, chainnet string) (*Descriptor, error) {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	body := strings.TrimSpace(descriptor)
+	if idx := strings.LastIndex(body, "#"); idx >= 0 {
+		checksum := body[idx+1:]
+		body = body[:idx]
+		if expected, err := DescriptorChecksum(body); err != nil || checksum != expected {
+			return nil, ErrInvalidDescriptorChecksum
+		}
+	}
+	d := &Descriptor{Chainnet: chainnet, body: body, netParams: net}
+	if err = d.parse(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// NewDescriptorWithExtendedKey
+// Create the descriptor that derive the receive addresses with `/0/*` and the change addresses with `/1/*`.
+// @param extendedKey the account's extended public key, the xpub/ypub/zpub (or tpub/upub/vpub for the test networks).
+// @param addressType the address type, it should not be AddressTypeComingTaproot.
+func NewDescriptorWithExtendedKey(extendedKey string, addressType AddressType, chainnet string) (*Descriptor, error) {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parseExtendedPubkey(extendedKey, net)
+	if err != nil {
+		return nil, err
+	}
+	keyExpr := key.String() + "/<0;1>/*"
+	var body string
+	switch addressType {
+	case AddressTypeLegacy:
+		body = "pkh(" + keyExpr + ")"
+	case AddressTypeNestedSegwit:
+		body = "sh(wpkh(" + keyExpr + "))"
+	case AddressTypeNativeSegwit:
+		body = "wpkh(" + keyExpr + ")"
+	case AddressTypeTaproot:
+		body = "tr(" + keyExpr + ")"
+	default:
+		return nil, errors.New("unsupported address type")
+	}
+	return NewDescriptor(body, chainnet)
+}
+
+// ExtendedKeyAddressType
+// @return the address type indicated by the key's version, xpub/tpub: Legacy, ypub/upub: NestedSegwit, zpub/vpub: NativeSegwit.
+func ExtendedKeyAddressType(extendedKey string) (AddressType, error) {
+	version, err := extendedKeyVersion(extendedKey)
+	if err != nil {
+		return 0, err
+	}
+	switch version {
+	case xpubVersion, tpubVersion:
+		return AddressTypeLegacy, nil
+	case ypubVersion, upubVersion:
+		return AddressTypeNestedSegwit, nil
+	case zpubVersion, vpubVersion:
+		return AddressTypeNativeSegwit, nil
+	}
+	return 0, ErrInvalidExtendedKey
+}
+
+// IsValidExtendedKey
+// @return true if the key is a extended public key of the chainnet.
+func IsValidExtendedKey(extendedKey, chainnet string) bool {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return false
+	}
+	_, err = parseExtendedPubkey(extendedKey, net)
+	return err == nil
+}
+
+func IsValidDescriptor(descriptor, chainnet string) bool {
+	_, err := NewDescriptor(descriptor, chainnet)
+	return err == nil
+}
+
+// DescriptorChecksum
+// @return the 8 characters checksum of the descriptor (BIP380)
+func DescriptorChecksum(descriptor string) (string, error) {
+	var symbols []uint64
+	var groups []uint64
+	for _, c := range descriptor {
+		v := strings.IndexRune(descriptorInputCharset, c)
+		if v < 0 {
+			return "", ErrInvalidDescriptor
+		}
+		symbols = append(symbols, uint64(v&31))
+		groups = append(groups, uint64(v>>5))
+		if len(groups) == 3 {
+			symbols = append(symbols, groups[0]*9+groups[1]*3+groups[2])
+			groups = groups[:0]
+		}
+	}
+	switch len(groups) {
+	case 1:
+		symbols = append(symbols, groups[0])
+	case 2:
+		symbols = append(symbols, groups[0]*3+groups[1])
+	}
+	symbols = append(symbols, make([]uint64, descriptorChecksumLength)...)
+	checksum := descriptorPolymod(symbols) ^ 1
+	res := make([]byte, descriptorChecksumLength)
+	for i := range res {
+		res[i] = descriptorChecksumCharset[(checksum>>(5*(7-i)))&31]
+	}
+	return string(res), nil
+}
+
+func descriptorPolymod(symbols []uint64) uint64 {
+	generator := []uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+	chk := uint64(1)
+	for _, value := range symbols {
+		top := chk >> 35
+		chk = (chk&0x7ffffffff)<<5 ^ value
+		for i, g := range generator {
+			if (top>>i)&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
+
+// String
+// @return the descriptor with checksum
+func (d *Descriptor) String() string {
+	checksum, _ := DescriptorChecksum(d.body)
+	return d.body + "#" + checksum
+}
+
+// IsRange
+// @return true if the descriptor can derive multiple addresses.
+func (d *Descriptor) IsRange() bool {
+	for _, key := range d.keys {
+		if key.wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// HasChange
+// @return true if the descriptor has the multipath step to derive the change addresses.
+func (d *Descriptor) HasChange() bool {
+	return len(d.keys[0].branches) > 1
+}
+
+// ReceiveAddress
+// @param index the index of the wildcard, it will be ignored if the descriptor is not range.
+func (d *Descriptor) ReceiveAddress(index int) (string, error) {
+	return d.addressAt(0, index)
+}
+
+// ChangeAddress
+// @param index the index of the wildcard, it will be ignored if the descriptor is not range.
+func (d *Descriptor) ChangeAddress(index int) (string, error) {
+	if !d.HasChange() {
+		return "", errors.New("the descriptor has no change branch")
+	}
+	return d.addressAt(1, index)
+}
+
+func (d *Descriptor) addressAt(branch, index int) (string, error) {
+	if index < 0 || index >= hdkeychain.HardenedKeyStart {
+		return "", errors.New("invalid derivation index")
+	}
+	pubkeys := make([]*btcec.PublicKey, len(d.keys))
+	for i, key := range d.keys {
+		pubkey, err := key.derive(branch, uint32(index))
+		if err != nil {
+			return "", err
+		}
+		pubkeys[i] = pubkey
+	}
+	address, err := d.addressOf(pubkeys)
+	if err != nil {
+		return "", err
+	}
+	return address.EncodeAddress(), nil
+}
+
+func (d *Descriptor) addressOf(pubkeys []*btcec.PublicKey) (btcutil.Address, error) {
+	switch d.function {
+	case "pkh":
+		return btcutil.NewAddressPubKeyHash(btcutil.Hash160(pubkeys[0].SerializeCompressed()), d.netParams)
+	case "wpkh":
+		hash := btcutil.Hash160(pubkeys[0].SerializeCompressed())
+		if d.wrapper == "" {
+			return btcutil.NewAddressWitnessPubKeyHash(hash, d.netParams)
+		}
+		return btcutil.NewAddressScriptHash(append([]byte{txscript.OP_0, txscript.OP_DATA_20}, hash...), d.netParams)
+	case "tr":
+		tapKey := txscript.ComputeTaprootKeyNoScript(pubkeys[0])
+		return btcutil.NewAddressTaproot(schnorr.SerializePubKey(tapKey), d.netParams)
+	}
+
+	// multi and sortedmulti
+	if d.function == "sortedmulti" {
+		sort.Slice(pubkeys, func(i, j int) bool {
+			return bytes.Compare(pubkeys[i].SerializeCompressed(), pubkeys[j].SerializeCompressed()) < 0
+		})
+	}
+	builder := txscript.NewScriptBuilder().AddInt64(int64(d.threshold))
+	for _, key := range pubkeys {
+		builder.AddData(key.SerializeCompressed())
+	}
+	script, err := builder.AddInt64(int64(len(pubkeys))).AddOp(txscript.OP_CHECKMULTISIG).Script()
+	if err != nil {
+		return nil, err
+	}
+	if d.wrapper == "sh" {
+		return btcutil.NewAddressScriptHash(script, d.netParams)
+	}
+	scriptHash := sha256.Sum256(script)
+	if d.wrapper == "wsh" {
+		return btcutil.NewAddressWitnessScriptHash(scriptHash[:], d.netParams)
+	}
+	return btcutil.NewAddressScriptHash(append([]byte{txscript.OP_0, txscript.OP_DATA_32}, scriptHash[:]...), d.netParams)
+}
+
+func (d *Descriptor) parse() error {
+	expr := d.body
+	for _, wrapper := range []string{"sh(wsh", "sh", "wsh"} {
+		if inner, ok := unwrapDescriptor(expr, wrapper); ok {
+			d.wrapper, expr = wrapper, inner
+			break
+		}
+	}
+	for _, function := range []string{"pkh", "wpkh", "tr", "multi", "sortedmulti"} {
+		if args, ok := unwrapDescriptor(expr, function); ok {
+			d.function = function
+			expr = args
+			break
+		}
+	}
+	switch d.function {
+	case "pkh", "tr":
+		if d.wrapper != "" {
+			return ErrInvalidDescriptor
+		}
+	case "wpkh":
+		if d.wrapper != "" && d.wrapper != "sh" {
+			return ErrInvalidDescriptor
+		}
+	case "multi", "sortedmulti":
+		// The bare multisig has no address.
+		if d.wrapper == "" {
+			return ErrInvalidDescriptor
+		}
+	default:
+		return ErrInvalidDescriptor
+	}
+
+	args := strings.Split(expr, ",")
+	if d.function == "multi" || d.function == "sortedmulti" {
+		threshold, err := strconv.Atoi(args[0])
+		args = args[1:]
+		maxKeys := maxMultisigKeys
+		if d.wrapper == "sh" {
+			maxKeys = 15 // limited by the redeem script's size
+		}
+		if err != nil || threshold <= 0 || threshold > len(args) || len(args) > maxKeys {
+			return ErrInvalidMultisigThreshold
+		}
+		d.threshold = threshold
+	} else if len(args) != 1 {
+		return ErrInvalidDescriptor
+	}
+	for _, arg := range args {
+		key, err := parseDescriptorKey(arg, d.netParams, d.function == "tr")
+		if err != nil {
+			return err
+		}
+		if len(d.keys) > 0 && len(key.branches) != len(d.keys[0].branches) {
+			return errors.New("the multipath steps of the keys should have the same length")
+		}
+		d.keys = append(d.keys, key)
+	}
+	return nil
+}
+
+// unwrapDescriptor
+// @return the arguments of `name(args)`
+func unwrapDescriptor(expr, name string) (string, bool) {
+	closing := strings.Repeat(")", strings.Count(name, "(")+1)
+	if strings.HasPrefix(expr, name+"(") && strings.HasSuffix(expr, closing) {
+		return expr[len(name)+1 : len(expr)-len(closing)], true
+	}
+	return "", false
+}
+
+// parseDescriptorKey
+// The key expression is `[fingerprint/origin path]KEY[/path][/<a;b>][/*]`, the private key is not allowed.
+func parseDescriptorKey(expr string, net *chaincfg.Params, xonly bool) (*descriptorKey, error) {
+	if strings.HasPrefix(expr, "[") {
+		end := strings.Index(expr, "]")
+		if end < 0 {
+			return nil, ErrInvalidDescriptor
+		}
+		expr = expr[end+1:]
+	}
+	steps := strings.Split(expr, "/")
+	key := &descriptorKey{branches: []uint32{0}, multipath: -1}
+	if len(steps) == 1 {
+		data, err := hexutil.HexDecodeString(expr)
+		if err != nil {
+			return nil, ErrInvalidDescriptor
+		}
+		if xonly && len(data) == schnorr.PubKeyBytesLen {
+			key.pubkey, err = schnorr.ParsePubKey(data)
+		} else if len(data) == btcec.PubKeyBytesLenCompressed {
+			key.pubkey, err = btcec.ParsePubKey(data)
+		} else {
+			err = ErrInvalidDescriptor
+		}
+		if err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+
+	extKey, err := parseExtendedPubkey(steps[0], net)
+	if err != nil {
+		return nil, err
+	}
+	key.extKey = extKey
+	for i, step := range steps[1:] {
+		isLast := i == len(steps)-2
+		switch {
+		case step == "*" && isLast:
+			key.wildcard = true
+		case strings.HasPrefix(step, "<") && strings.HasSuffix(step, ">") && len(key.branches) == 1:
+			key.branches = key.branches[:0]
+			for _, s := range strings.Split(step[1:len(step)-1], ";") {
+				n, err := parseDescriptorStep(s)
+				if err != nil {
+					return nil, err
+				}
+				key.branches = append(key.branches, n)
+			}
+			if len(key.branches) < 2 {
+				return nil, ErrInvalidDescriptor
+			}
+			key.multipath = len(key.path)
+			key.path = append(key.path, key.branches[0])
+		default:
+			n, err := parseDescriptorStep(step)
+			if err != nil {
+				return nil, err
+			}
+			key.path = append(key.path, n)
+		}
+	}
+	return key, nil
+}
+
+// parseDescriptorStep
+// The hardened step can't be derived from the public key.
+func parseDescriptorStep(step string) (uint32, error) {
+	if strings.HasSuffix(step, "'") || strings.HasSuffix(step, "h") {
+		return 0, errors.New("the hardened derivation is not supported by the public key")
+	}
+	n, err := strconv.ParseUint(step, 10, 32)
+	if err != nil || n >= hdkeychain.HardenedKeyStart {
+		return 0, ErrInvalidDescriptor
+	}
+	return uint32(n), nil
+}
+
+func (k *descriptorKey) derive(branch int, index uint32) (*btcec.PublicKey, error) {
+	if k.extKey == nil {
+		return k.pubkey, nil
+	}
+	if branch >= len(k.branches) {
+		return nil, ErrInvalidDescriptor
+	}
+	key := k.extKey
+	var err error
+	for i, n := range k.path {
+		if i == k.multipath {
+			n = k.branches[branch]
+		}
+		if key, err = key.Derive(n); err != nil {
+			return nil, err
+		}
+	}
+	if k.wildcard {
+		if key, err = key.Derive(index); err != nil {
+			return nil, err
+		}
+	}
+	return key.ECPubKey()
+}
+
+func extendedKeyVersion(extendedKey string) ([4]byte, error) {
+	var version [4]byte
+	data := base58.Decode(extendedKey)
+	if len(data) < 4 {
+		return version, ErrInvalidExtendedKey
+	}
+	copy(version[:], data[:4])
+	return version, nil
+}
+
+// parseExtendedPubkey
+// The SLIP-0132 versions are accepted, and the key will be converted to the xpub/tpub version.
+func parseExtendedPubkey(extendedKey string, net *chaincfg.Params) (*hdkeychain.ExtendedKey, error) {
+	version, err := extendedKeyVersion(extendedKey)
+	if err != nil {
+		return nil, err
+	}
+	isMainnet := net.Net == chaincfg.MainNetParams.Net
+	switch version {
+	case xpubVersion, ypubVersion, zpubVersion:
+		if !isMainnet {
+			return nil, ErrInvalidExtendedKey
+		}
+	case tpubVersion, upubVersion, vpubVersion:
+		if isMainnet {
+			return nil, ErrInvalidExtendedKey
+		}
+	default:
+		return nil, ErrInvalidExtendedKey
+	}
+	key, err := hdkeychain.NewKeyFromString(extendedKey)
+	if err != nil || key.IsPrivate() {
+		return nil, ErrInvalidExtendedKey
+	}
+	return key.CloneWithVersion(net.HDPublicKeyID[:])
+}
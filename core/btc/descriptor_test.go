@@ -0,0 +1,192 @@
+package btc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/stretchr/testify/require"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// accountExtendedKey
+// @return the extended public key of the BIP44 account path
+func accountExtendedKey(t *testing.T, mnemonic, path string) string {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	require.NoError(t, err)
+	key, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	dPath, err := accounts.ParseDerivationPath(path)
+	require.NoError(t, err)
+	for _, n := range dPath {
+		key, err = key.Derive(n)
+		require.NoError(t, err)
+	}
+	pub, err := key.Neuter()
+	require.NoError(t, err)
+	return pub.String()
+}
+
+func TestDescriptorChecksum(t *testing.T) {
+	checksum, err := DescriptorChecksum("raw(deadbeef)")
+	require.NoError(t, err)
+	require.Equal(t, "89f8spxm", checksum)
+
+	desc := "wpkh(02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9)"
+	d, err := NewDescriptor(desc, ChainMainnet)
+	require.NoError(t, err)
+	_, err = NewDescriptor(d.String(), ChainMainnet)
+	require.NoError(t, err)
+	_, err = NewDescriptor(desc+"#00000000", ChainMainnet)
+	require.ErrorIs(t, err, ErrInvalidDescriptorChecksum)
+}
+
+func TestDescriptorWithExtendedKey_BIP84(t *testing.T) {
+	zpub := "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+	addressType, err := ExtendedKeyAddressType(zpub)
+	require.NoError(t, err)
+	require.Equal(t, AddressTypeNativeSegwit, addressType)
+
+	d, err := NewDescriptorWithExtendedKey(zpub, addressType, ChainMainnet)
+	require.NoError(t, err)
+	require.True(t, d.IsRange())
+	require.True(t, d.HasChange())
+
+	addr, err := d.ReceiveAddress(0)
+	require.NoError(t, err)
+	require.Equal(t, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", addr)
+	addr, err = d.ReceiveAddress(1)
+	require.NoError(t, err)
+	require.Equal(t, "bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g", addr)
+	addr, err = d.ChangeAddress(0)
+	require.NoError(t, err)
+	require.Equal(t, "bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el", addr)
+
+	require.False(t, IsValidExtendedKey(zpub, ChainSignet))
+}
+
+func TestDescriptor_AddressTypes(t *testing.T) {
+	mnemonic := "antenna chaos arrive hungry distance human question history decade deal impose color"
+	tests := []struct {
+		addressType AddressType
+		accountPath string
+		descriptor  string
+	}{
+		{AddressTypeLegacy, "m/44'/0'/0'", "pkh(%s/0/*)"},
+		{AddressTypeNestedSegwit, "m/49'/0'/0'", "sh(wpkh(%s/0/*))"},
+		{AddressTypeNativeSegwit, "m/84'/0'/0'", "wpkh(%s/0/*)"},
+		{AddressTypeTaproot, "m/86'/0'/0'", "tr(%s/0/*)"},
+	}
+	for _, tt := range tests {
+		t.Run(AddressTypeDescription(tt.addressType), func(t *testing.T) {
+			account, err := NewAccountWithMnemonic(mnemonic, ChainMainnet, tt.addressType)
+			require.NoError(t, err)
+			xpub := accountExtendedKey(t, mnemonic, tt.accountPath)
+
+			d, err := NewDescriptorWithExtendedKey(xpub, tt.addressType, ChainMainnet)
+			require.NoError(t, err)
+			addr, err := d.ReceiveAddress(0)
+			require.NoError(t, err)
+			require.Equal(t, account.Address(), addr)
+
+			// the same address with the origin and without the multipath
+			d, err = NewDescriptor(fmt.Sprintf(tt.descriptor, "[d34db33f/"+tt.accountPath[2:]+"]"+xpub), ChainMainnet)
+			require.NoError(t, err)
+			require.False(t, d.HasChange())
+			addr, err = d.ReceiveAddress(0)
+			require.NoError(t, err)
+			require.Equal(t, account.Address(), addr)
+		})
+	}
+}
+
+func TestDescriptor_Multisig(t *testing.T) {
+	signers := newTestSigners(t)
+	pubkeys := testPubkeys(signers...).AnyArray
+	wallet, err := NewMultisigWallet(2, testPubkeys(signers...), ChainSignet)
+	require.NoError(t, err)
+
+	for _, keys := range [][]string{pubkeys, {pubkeys[2], pubkeys[0], pubkeys[1]}} {
+		d, err := NewDescriptor("wsh(sortedmulti(2,"+strings.Join(keys, ",")+"))", ChainSignet)
+		require.NoError(t, err)
+		require.False(t, d.IsRange())
+		addr, err := d.ReceiveAddress(0)
+		require.NoError(t, err)
+		require.Equal(t, wallet.Address(), addr)
+	}
+
+	sorted := wallet.PublicKeys().AnyArray
+	d, err := NewDescriptor("wsh(multi(2,"+strings.Join([]string{sorted[2], sorted[1], sorted[0]}, ",")+"))", ChainSignet)
+	require.NoError(t, err)
+	addr, err := d.ReceiveAddress(0)
+	require.NoError(t, err)
+	require.NotEqual(t, wallet.Address(), addr)
+
+	for _, desc := range []string{
+		"sh(sortedmulti(2," + strings.Join(pubkeys, ",") + "))",
+		"sh(wsh(sortedmulti(2," + strings.Join(pubkeys, ",") + ")))",
+	} {
+		d, err = NewDescriptor(desc, ChainSignet)
+		require.NoError(t, err)
+		addr, err = d.ReceiveAddress(0)
+		require.NoError(t, err)
+		require.Equal(t, "2", addr[:1])
+	}
+
+	for _, desc := range []string{
+		"sortedmulti(2," + strings.Join(pubkeys, ",") + ")",
+		"wsh(sortedmulti(4," + strings.Join(pubkeys, ",") + "))",
+		"wsh(pkh(" + pubkeys[0] + "))",
+		"wpkh(xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi/0/*)",
+	} {
+		_, err = NewDescriptor(desc, ChainSignet)
+		require.Error(t, err, desc)
+	}
+}
+
+func TestDescriptorWallet_Scan(t *testing.T) {
+	zpub := "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+	wallet, err := NewDescriptorWalletWithWatchKey(zpub, ChainMainnet)
+	require.NoError(t, err)
+	wallet.GapLimit = 5
+
+	used := map[string]int64{}
+	for _, index := range []int{0, 3, 8} {
+		addr, err := wallet.Descriptor.ReceiveAddress(index)
+		require.NoError(t, err)
+		used[addr] = 1000
+	}
+	// out of the gap limit
+	addr, err := wallet.Descriptor.ReceiveAddress(14)
+	require.NoError(t, err)
+	used[addr] = 1000
+	addr, err = wallet.Descriptor.ChangeAddress(1)
+	require.NoError(t, err)
+	used[addr] = 500
+
+	fetched := 0
+	res, err := wallet.scan(func(addresses []string) ([]*addressStats, error) {
+		fetched += len(addresses)
+		stats := make([]*addressStats, len(addresses))
+		for i, addr := range addresses {
+			stats[i] = &addressStats{Address: addr}
+			if balance, ok := used[addr]; ok {
+				stats[i].TxCount = 1
+				stats[i].Balance = balance
+			}
+		}
+		return stats, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4, res.Addresses.Count())
+	require.Equal(t, int64(3500), res.Balance)
+	require.Equal(t, 9, res.NextReceiveIndex)
+	require.Equal(t, 2, res.NextChangeIndex)
+	next, err := wallet.Descriptor.ReceiveAddress(9)
+	require.NoError(t, err)
+	require.Equal(t, next, res.NextReceiveAddress)
+	require.Equal(t, 15+10, fetched)
+}
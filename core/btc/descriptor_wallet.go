@@ -0,0 +1,249 @@
+package btc
+
+import (
+	"strconv"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/base/inter"
+)
+
+const (
+	// The default count of consecutive unused addresses to stop the scan (BIP44)
+	DefaultGapLimit = 20
+)
+
+// DescriptorWallet is the watch-only wallet that derives the addresses from the output descriptor.
+type DescriptorWallet struct {
+	Descriptor *Descriptor `json:"-"`
+	// The count of consecutive unused addresses to stop the scan, the DefaultGapLimit will be used if it <= 0
+	GapLimit int `json:"gapLimit"`
+}
+
+func NewDescriptorWallet(descriptor, chainnet string) (*DescriptorWallet, error) {
+	d, err := NewDescriptor(descriptor, chainnet)
+	if err != nil {
+		return nil, err
+	}
+	return &DescriptorWallet{Descriptor: d, GapLimit: DefaultGapLimit}, nil
+}
+
+// NewDescriptorWalletWithExtendedKey
+// @param extendedKey the account's extended public key, see `NewDescriptorWithExtendedKey`
+func NewDescriptorWalletWithExtendedKey(extendedKey string, addressType AddressType, chainnet string) (*DescriptorWallet, error) {
+	d, err := NewDescriptorWithExtendedKey(extendedKey, addressType, chainnet)
+	if err != nil {
+		return nil, err
+	}
+	return &DescriptorWallet{Descriptor: d, GapLimit: DefaultGapLimit}, nil
+}
+
+// NewDescriptorWalletWithWatchKey
+// @param watchKey the output descriptor, or the extended public key whose address type is indicated by the version (xpub/ypub/zpub)
+func NewDescriptorWalletWithWatchKey(watchKey, chainnet string) (*DescriptorWallet, error) {
+	if addressType, err := ExtendedKeyAddressType(watchKey); err == nil {
+		return NewDescriptorWalletWithExtendedKey(watchKey, addressType, chainnet)
+	}
+	return NewDescriptorWallet(watchKey, chainnet)
+}
+
+// IsValidWatchKey
+// @return true if the key is a valid output descriptor or extended public key of the chainnet
+func IsValidWatchKey(watchKey, chainnet string) bool {
+	return IsValidExtendedKey(watchKey, chainnet) || IsValidDescriptor(watchKey, chainnet)
+}
+
+type DescriptorAddress struct {
+	Address  string `json:"address"`
+	IsChange bool   `json:"isChange"`
+	Index    int    `json:"index"`
+	TxCount  int64  `json:"txCount"`
+	// The confirmed balance
+	Balance int64 `json:"balance"`
+}
+
+type DescriptorAddressArray struct {
+	inter.AnyArray[*DescriptorAddress]
+}
+
+type DescriptorScanResult struct {
+	// The used addresses
+	Addresses *DescriptorAddressArray `json:"addresses"`
+	// The total confirmed balance of the used addresses
+	Balance int64 `json:"balance"`
+
+	NextReceiveIndex   int    `json:"nextReceiveIndex"`
+	NextReceiveAddress string `json:"nextReceiveAddress"`
+	// The change index and address is empty if the descriptor has no change branch
+	NextChangeIndex   int    `json:"nextChangeIndex"`
+	NextChangeAddress string `json:"nextChangeAddress"`
+}
+
+func (r *DescriptorScanResult) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(r)
+}
+
+// DescriptorUTXO is the utxo with the derived address that owns it.
+type DescriptorUTXO struct {
+	*UTXO
+	Address  string `json:"address"`
+	IsChange bool   `json:"isChange"`
+	Index    int    `json:"index"`
+}
+
+type DescriptorUTXOArray struct {
+	inter.AnyArray[*DescriptorUTXO]
+}
+
+func (a *DescriptorUTXOArray) TotalValue() int64 {
+	total := int64(0)
+	for _, u := range a.AnyArray {
+		total += u.Value
+	}
+	return total
+}
+
+type addressStatsFetcher func(addresses []string) ([]*addressStats, error)
+
+// Scan
+// Derive the receive and change addresses until the count of consecutive unused addresses reaches the gap limit.
+func (w *DescriptorWallet) Scan() (res *DescriptorScanResult, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+	return w.scan(w.fetchAddressStats)
+}
+
+// QueryBalance
+// @return the total confirmed balance of all the derived addresses
+func (w *DescriptorWallet) QueryBalance() (*base.Balance, error) {
+	res, err := w.Scan()
+	if err != nil {
+		return nil, err
+	}
+	balance := strconv.FormatInt(res.Balance, 10)
+	return &base.Balance{Total: balance, Usable: balance}, nil
+}
+
+// FetchUtxos
+// Query all the unspent outputs (include the mempool's) of the used addresses.
+func (w *DescriptorWallet) FetchUtxos() (arr *DescriptorUTXOArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	res, err := w.scan(w.fetchAddressStats)
+	if err != nil {
+		return
+	}
+	list := make([]any, res.Addresses.Count())
+	for i, addr := range res.Addresses.AnyArray {
+		list[i] = addr
+	}
+	utxosList, err := base.MapListConcurrent(list, 10, func(i interface{}) (interface{}, error) {
+		return fetchUtxos(i.(*DescriptorAddress).Address, w.Descriptor.Chainnet)
+	})
+	if err != nil {
+		return
+	}
+	arr = &DescriptorUTXOArray{}
+	for i, utxos := range utxosList {
+		addr := res.Addresses.AnyArray[i]
+		for _, utxo := range utxos.([]*UTXO) {
+			arr.Append(&DescriptorUTXO{UTXO: utxo, Address: addr.Address, IsChange: addr.IsChange, Index: addr.Index})
+		}
+	}
+	return arr, nil
+}
+
+func (w *DescriptorWallet) fetchAddressStats(addresses []string) ([]*addressStats, error) {
+	list := make([]any, len(addresses))
+	for i, addr := range addresses {
+		list[i] = addr
+	}
+	res, err := base.MapListConcurrent(list, 10, func(i interface{}) (interface{}, error) {
+		return queryAddressStats(i.(string), w.Descriptor.Chainnet)
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]*addressStats, len(res))
+	for i, s := range res {
+		stats[i] = s.(*addressStats)
+	}
+	return stats, nil
+}
+
+func (w *DescriptorWallet) scan(fetcher addressStatsFetcher) (*DescriptorScanResult, error) {
+	res := &DescriptorScanResult{Addresses: &DescriptorAddressArray{}}
+	next, err := w.scanBranch(false, fetcher, res)
+	if err != nil {
+		return nil, err
+	}
+	res.NextReceiveIndex = next
+	if res.NextReceiveAddress, err = w.Descriptor.ReceiveAddress(next); err != nil {
+		return nil, err
+	}
+	if w.Descriptor.HasChange() {
+		if next, err = w.scanBranch(true, fetcher, res); err != nil {
+			return nil, err
+		}
+		res.NextChangeIndex = next
+		if res.NextChangeAddress, err = w.Descriptor.ChangeAddress(next); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// scanBranch
+// The used addresses are appended to the result.
+// @return the index of the first unused address after the last used one.
+func (w *DescriptorWallet) scanBranch(isChange bool, fetcher addressStatsFetcher, res *DescriptorScanResult) (int, error) {
+	gap := w.GapLimit
+	if gap <= 0 {
+		gap = DefaultGapLimit
+	}
+	if !w.Descriptor.IsRange() {
+		gap = 1
+	}
+	branch := 0
+	if isChange {
+		branch = 1
+	}
+
+	lastUsed := -1
+	for start := 0; start-lastUsed-1 < gap; start += gap {
+		addresses := make([]string, gap)
+		for i := range addresses {
+			address, err := w.Descriptor.addressAt(branch, start+i)
+			if err != nil {
+				return 0, err
+			}
+			addresses[i] = address
+		}
+		stats, err := fetcher(addresses)
+		if err != nil {
+			return 0, err
+		}
+		for i, s := range stats {
+			if start+i-lastUsed > gap {
+				break // the addresses after the gap are not belong to the wallet
+			}
+			if s.TxCount == 0 {
+				continue
+			}
+			lastUsed = start + i
+			res.Balance += s.Balance
+			res.Addresses.Append(&DescriptorAddress{
+				Address:  s.Address,
+				IsChange: isChange,
+				Index:    start + i,
+				TxCount:  s.TxCount,
+				Balance:  s.Balance,
+			})
+		}
+		if !w.Descriptor.IsRange() {
+			break
+		}
+	}
+	if !w.Descriptor.IsRange() {
+		return 0, nil
+	}
+	return lastUsed + 1, nil
+}
@@ -0,0 +1,250 @@
+package btc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/pkg/httpUtil"
+)
+
+// EsploraClient is the client of the esplora (electrs) REST api.
+// It's also used by the other UTXO chains (e.g. Litecoin, Bitcoin Cash) that have the esplora compatible api.
+type EsploraClient struct {
+	Url string
+}
+
+func NewEsploraClient(url string) *EsploraClient {
+	return &EsploraClient{Url: strings.TrimSuffix(url, "/")}
+}
+
+func esploraClientOf(chainnet string) (*EsploraClient, error) {
+	host, err := scanHostOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	return NewEsploraClient(host), nil
+}
+
+// ScriptHash
+// @return the hash that the esplora api `/scripthash/:hash` used, it's the reversed sha256 of the script.
+func ScriptHash(pkScript []byte) string {
+	hash := sha256.Sum256(pkScript)
+	slices.Reverse(hash[:])
+	return hex.EncodeToString(hash[:])
+}
+
+func (c *EsploraClient) get(path string) ([]byte, error) {
+	response, err := httpUtil.Request(http.MethodGet, c.Url+path, nil, nil)
+	if err != nil {
+		return nil, base.MapAnyToBasicError(err)
+	}
+	if response.Code != http.StatusOK {
+		return nil, fmt.Errorf("code: %d, body: %s", response.Code, string(response.Body))
+	}
+	return response.Body, nil
+}
+
+// QueryBalance
+// @return the confirmed balance of the address
+func (c *EsploraClient) QueryBalance(address string) (string, error) {
+	return c.queryBalance("/address/" + address)
+}
+
+// QueryScriptBalance
+// @return the confirmed balance of the output script
+func (c *EsploraClient) QueryScriptBalance(pkScript []byte) (string, error) {
+	return c.queryBalance("/scripthash/" + ScriptHash(pkScript))
+}
+
+func (c *EsploraClient) queryBalance(path string) (string, error) {
+	response, err := httpUtil.Request(http.MethodGet, c.Url+path, nil, nil)
+	if err != nil {
+		return "0", base.MapAnyToBasicError(err)
+	}
+	return parseBalanceResponse(response)
+}
+
+// FetchUtxos
+// Query all the unspent outputs (include the mempool's) of the address.
+func (c *EsploraClient) FetchUtxos(address string) ([]*UTXO, error) {
+	return c.fetchUtxos("/address/" + address + "/utxo")
+}
+
+// FetchScriptUtxos
+// Query all the unspent outputs (include the mempool's) of the output script.
+func (c *EsploraClient) FetchScriptUtxos(pkScript []byte) ([]*UTXO, error) {
+	return c.fetchUtxos("/scripthash/" + ScriptHash(pkScript) + "/utxo")
+}
+
+func (c *EsploraClient) fetchUtxos(path string) ([]*UTXO, error) {
+	body, err := c.get(path)
+	if err != nil {
+		return nil, err
+	}
+	var utxos []*UTXO
+	if err = json.Unmarshal(body, &utxos); err != nil {
+		return nil, ErrHttpResponseParse
+	}
+	return utxos, nil
+}
+
+// FetchRawTransaction
+// Query the transaction with the esplora api `/tx/:txid/hex`
+func (c *EsploraClient) FetchRawTransaction(txid string) (*wire.MsgTx, error) {
+	body, err := c.get("/tx/" + txid + "/hex")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeTx(strings.TrimSpace(string(body)))
+}
+
+// FetchTransactionStatus
+// Query the status with the esplora api `/tx/:txid/status`, only the status and timestamp are returned.
+func (c *EsploraClient) FetchTransactionStatus(txid string) (*base.TransactionDetail, error) {
+	txid = strings.TrimPrefix(txid, "0x")
+	status, err := c.fetchStatus(txid)
+	if err != nil {
+		return nil, err
+	}
+	detail := &base.TransactionDetail{
+		HashString: txid,
+		Status:     base.TransactionStatusPending,
+	}
+	if status.Confirmed {
+		detail.Status = base.TransactionStatusSuccess
+		detail.FinishTimestamp = status.BlockTime
+	}
+	return detail, nil
+}
+
+func (c *EsploraClient) fetchStatus(txid string) (*UTXOStatus, error) {
+	body, err := c.get("/tx/" + txid + "/status")
+	if err != nil {
+		return nil, err
+	}
+	var status UTXOStatus
+	if err = json.Unmarshal(body, &status); err != nil {
+		return nil, ErrHttpResponseParse
+	}
+	return &status, nil
+}
+
+// MerkleProof is the result of the esplora api `/tx/:txid/merkle-proof`
+type MerkleProof struct {
+	BlockHeight int64    `json:"block_height"`
+	Merkle      []string `json:"merkle"`
+	Pos         int64    `json:"pos"`
+}
+
+// FetchMerkleProof
+// Query the merkle inclusion proof of the confirmed transaction with the esplora api `/tx/:txid/merkle-proof`
+func (c *EsploraClient) FetchMerkleProof(txid string) (*MerkleProof, error) {
+	body, err := c.get("/tx/" + strings.TrimPrefix(txid, "0x") + "/merkle-proof")
+	if err != nil {
+		return nil, err
+	}
+	var proof MerkleProof
+	if err = json.Unmarshal(body, &proof); err != nil {
+		return nil, ErrHttpResponseParse
+	}
+	return &proof, nil
+}
+
+type esploraBlock struct {
+	Id                string `json:"id"`
+	Height            int64  `json:"height"`
+	Version           int32  `json:"version"`
+	Timestamp         int64  `json:"timestamp"`
+	Bits              uint32 `json:"bits"`
+	Nonce             uint32 `json:"nonce"`
+	MerkleRoot        string `json:"merkle_root"`
+	PreviousBlockHash string `json:"previousblockhash"`
+}
+
+// FetchBlockHeaders
+// Query the headers with the esplora api `/blocks/:start_height`, the headers are rebuilt from the block fields.
+// @return at most 10 headers, the header at index i is at height `startHeight - i`
+func (c *EsploraClient) FetchBlockHeaders(startHeight int64) ([]*wire.BlockHeader, error) {
+	body, err := c.get("/blocks/" + strconv.FormatInt(startHeight, 10))
+	if err != nil {
+		return nil, err
+	}
+	var blocks []*esploraBlock
+	if err = json.Unmarshal(body, &blocks); err != nil {
+		return nil, ErrHttpResponseParse
+	}
+	headers := make([]*wire.BlockHeader, 0, len(blocks))
+	for i, block := range blocks {
+		if block.Height != startHeight-int64(i) {
+			return nil, ErrHttpResponseParse
+		}
+		merkleRoot, err := chainhash.NewHashFromStr(block.MerkleRoot)
+		if err != nil {
+			return nil, ErrHttpResponseParse
+		}
+		prevBlock := &chainhash.Hash{}
+		if block.PreviousBlockHash != "" {
+			if prevBlock, err = chainhash.NewHashFromStr(block.PreviousBlockHash); err != nil {
+				return nil, ErrHttpResponseParse
+			}
+		}
+		headers = append(headers, &wire.BlockHeader{
+			Version:    block.Version,
+			PrevBlock:  *prevBlock,
+			MerkleRoot: *merkleRoot,
+			Timestamp:  time.Unix(block.Timestamp, 0),
+			Bits:       block.Bits,
+			Nonce:      block.Nonce,
+		})
+	}
+	return headers, nil
+}
+
+// SendRawTransaction
+// Broadcast the transaction with the esplora api `POST /tx`
+// @return the hash of the transaction
+func (c *EsploraClient) SendRawTransaction(txHex string) (string, error) {
+	txHex = strings.TrimPrefix(txHex, "0x")
+	response, err := httpUtil.Request(http.MethodPost, c.Url+"/tx", nil, []byte(txHex))
+	if err != nil {
+		return "", base.MapAnyToBasicError(err)
+	}
+	if response.Code != http.StatusOK {
+		return "", fmt.Errorf("code: %d, body: %s", response.Code, string(response.Body))
+	}
+	return strings.TrimSpace(string(response.Body)), nil
+}
+
+// SuggestFeeRate
+// Query the fee rates with the esplora api `/fee-estimates`,
+// the rates of confirming in 1, 3 and 6 blocks are used as the high, average and low rates.
+func (c *EsploraClient) SuggestFeeRate() (*FeeRate, error) {
+	body, err := c.get("/fee-estimates")
+	if err != nil {
+		return nil, err
+	}
+	var estimates map[string]float64
+	if err = json.Unmarshal(body, &estimates); err != nil {
+		return nil, ErrHttpResponseParse
+	}
+	rateOf := func(target int) int64 {
+		// use the rate of the nearest target that is not slower
+		for t := target; t > 0; t-- {
+			if rate, ok := estimates[strconv.Itoa(t)]; ok {
+				return max(1, int64(rate+0.5))
+			}
+		}
+		return 1
+	}
+	return &FeeRate{Low: rateOf(6), Average: rateOf(3), High: rateOf(1)}, nil
+}
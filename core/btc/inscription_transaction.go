@@ -0,0 +1,243 @@
+package btc
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/btc/ordinal"
+	"github.com/coming-chat/wallet-SDK/util/hexutil"
+)
+
+const (
+	// The default value of the output that receives the inscription.
+	InscriptionPostage = 546
+)
+
+type InscriptionParams struct {
+	ContentType string `json:"contentType"`
+	// The hex string of the content body
+	Body string `json:"body"`
+	// The json string of the metadata, it will be encoded to CBOR.
+	MetadataJson    string `json:"metadataJson"`
+	Metaprotocol    string `json:"metaprotocol"`
+	ContentEncoding string `json:"contentEncoding"`
+	// The value of the inscription output, default is InscriptionPostage
+	Postage int64 `json:"postage"`
+
+	// The parent inscription id like `{txid}i{index}`, optional.
+	// The parent must be held by the sender, it will be spent by the first input of the reveal transaction and
+	// returned to the sender by the first output, so the inscribed sats of the parent keep their offsets.
+	Parent string `json:"parent"`
+	// The current location of the parent inscription like `{txid}:{vout}`, required if the parent is set.
+	ParentOutput string `json:"parentOutput"`
+	// The value of the parent's output.
+	ParentValue int64 `json:"parentValue"`
+}
+
+func NewInscriptionParams(contentType, body string) *InscriptionParams {
+	return &InscriptionParams{ContentType: contentType, Body: body}
+}
+
+func NewInscriptionParamsWithJsonString(str string) (*InscriptionParams, error) {
+	var o InscriptionParams
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func (j *InscriptionParams) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(j)
+}
+
+func (p *InscriptionParams) toInscription() (*ordinal.Inscription, error) {
+	body, err := hexutil.HexDecodeString(p.Body)
+	if err != nil {
+		return nil, err
+	}
+	inscription := ordinal.NewInscription(p.ContentType, body)
+	inscription.Metaprotocol = p.Metaprotocol
+	inscription.ContentEncoding = p.ContentEncoding
+	if p.MetadataJson != "" {
+		if err = inscription.SetJsonMetadata(p.MetadataJson); err != nil {
+			return nil, err
+		}
+	}
+	if p.Parent != "" {
+		inscription.Parents = []string{p.Parent}
+	}
+	return inscription, nil
+}
+
+// BuildInscriptionTransaction
+// Build the commit psbt and the reveal transaction to inscribe the content locally.
+// @param senderPubkey the public key of the sender, it will be the owner of the commit output.
+// @param receiver the receiver of the inscription, use the sender if it's empty.
+// @param feeRate sat/vB, the suggest average fee rate will be used if it <= 0
+func (c *Chain) BuildInscriptionTransaction(sender, senderPubkey, receiver string, params *InscriptionParams, feeRate int64) (txn *InscriptionTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	pubkeyBytes, err := hexutil.HexDecodeString(senderPubkey)
+	if err != nil {
+		return
+	}
+	pubkey, err := btcec.ParsePubKey(pubkeyBytes)
+	if err != nil {
+		return
+	}
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchSpendableUtxos(sender, nil)
+	if err != nil {
+		return
+	}
+	return buildInscription(c.Chainnet, sender, pubkey, receiver, params, feeRate, utxos)
+}
+
+func buildInscription(chainnet string, sender string, pubkey *btcec.PublicKey, receiver string,
+	params *InscriptionParams, feeRate int64, utxos []*UTXO) (*InscriptionTransaction, error) {
+	if params == nil {
+		return nil, errors.New("invalid inscription params")
+	}
+	inscription, err := params.toInscription()
+	if err != nil {
+		return nil, err
+	}
+	if receiver == "" {
+		receiver = sender
+	}
+	postage := params.Postage
+	if postage <= 0 {
+		postage = InscriptionPostage
+	}
+
+	commitTxn, err := NewTransaction(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	senderAddr, err := btcutil.DecodeAddress(sender, commitTxn.netParams)
+	if err != nil {
+		return nil, err
+	}
+	senderScript, err := txscript.PayToAddrScript(senderAddr)
+	if err != nil {
+		return nil, err
+	}
+	receiverScript, err := addressToPkScript(receiver, commitTxn.netParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// The parent inscription is spent by the reveal transaction before the commit output, and returned to the sender
+	// with the same value, the sats are transferred in order, so the parent is never paid as the fee.
+	var extraInputs []*wire.TxIn
+	var extraOutputs, revealPrevOuts []*wire.TxOut
+	if params.Parent != "" {
+		if params.ParentValue <= 0 {
+			return nil, errors.New("invalid parent value")
+		}
+		parentIn, err := parentInput(params.ParentOutput, senderScript)
+		if err != nil {
+			return nil, err
+		}
+		parentOut := wire.NewTxOut(params.ParentValue, senderScript)
+		extraInputs = append(extraInputs, parentIn)
+		extraOutputs = append(extraOutputs, parentOut)
+		revealPrevOuts = append(revealPrevOuts, parentOut)
+	}
+	reveal, err := ordinal.NewInscriptionReveal(inscription, pubkey, receiverScript, postage, feeRate,
+		commitTxn.netParams, extraInputs, extraOutputs)
+	if err != nil {
+		return nil, err
+	}
+
+	commitTxn.addOutputScript(reveal.Commit.PkScript, postage+reveal.Fee)
+	if err = fundTransaction(commitTxn, senderAddr, utxos, feeRate); err != nil {
+		return nil, err
+	}
+	commitPsbt, err := commitTxn.ToPsbtTransaction()
+	if err != nil {
+		return nil, err
+	}
+	reveal.SetCommitOutPoint(commitTxn.msgTx.TxHash(), 0)
+
+	return &InscriptionTransaction{
+		Commit:        commitPsbt,
+		CommitFee:     commitTxn.TotalInputValue() - commitTxn.TotalOutputValue(),
+		RevealFee:     reveal.Fee,
+		CommitAddress: reveal.Commit.Address,
+		InscriptionId: reveal.Tx.TxHash().String() + "i0",
+
+		commitReveal: &commitReveal{
+			commitPsbt:     commitPsbt,
+			commit:         reveal.Commit,
+			reveal:         reveal.Tx,
+			commitIndex:    reveal.CommitIndex,
+			revealPrevOuts: revealPrevOuts,
+		},
+	}, nil
+}
+
+// parentInput
+// The placeholder signature of the input is filled according to the owner's script, which is used to estimate the reveal fee.
+func parentInput(output string, ownerScript []byte) (*wire.TxIn, error) {
+	parts := strings.Split(output, ":")
+	if len(parts) != 2 {
+		return nil, errors.New("invalid parent output")
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, errors.New("invalid parent output")
+	}
+	point, err := outPoint(parts[0], uint32(index))
+	if err != nil {
+		return nil, err
+	}
+	in := wire.NewTxIn(point, nil, nil)
+	switch {
+	case txscript.IsPayToTaproot(ownerScript):
+		in.Witness = wire.TxWitness{make([]byte, 64)}
+	case txscript.IsPayToPubKeyHash(ownerScript):
+		in.SignatureScript = make([]byte, 106)
+	case txscript.IsPayToScriptHash(ownerScript):
+		in.Witness = wire.TxWitness{make([]byte, 108)}
+		in.SignatureScript = make([]byte, 23)
+	default:
+		in.Witness = wire.TxWitness{make([]byte, 108)}
+	}
+	return in, nil
+}
+
+type InscriptionTransaction struct {
+	// The commit psbt
+	Commit        *PsbtTransaction `json:"-"`
+	CommitFee     int64            `json:"commitFee"`
+	RevealFee     int64            `json:"revealFee"`
+	CommitAddress string           `json:"commitAddress"`
+	// The inscription id will be changed if the commit transaction is not segwit,
+	// the signed reveal transaction's id always takes precedence.
+	InscriptionId string `json:"inscriptionId"`
+
+	commitReveal *commitReveal
+}
+
+func (t *InscriptionTransaction) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(t)
+}
+
+func (t *InscriptionTransaction) SignWithAccount(account base.Account) (signedTxn *base.OptionalString, err error) {
+	return nil, base.ErrUnsupportedFunction
+}
+
+// SignedTransactionWithAccount
+// Sign the commit psbt and the reveal transaction, the parent inscription will be signed with the key path.
+func (t *InscriptionTransaction) SignedTransactionWithAccount(account base.Account) (signedTxn base.SignedTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+	return t.commitReveal.signWithAccount(account)
+}
@@ -0,0 +1,69 @@
+package btc
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/btc/ordinal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInscription(t *testing.T) {
+	parent := "6fb976ab49dcec017f1e201e84395983204ae1a7c2abf7ced0a85d692e442799i0"
+	for _, addressType := range []AddressType{AddressTypeTaproot, AddressTypeNativeSegwit} {
+		account, err := NewAccountWithMnemonic("antenna chaos arrive hungry distance human question history decade deal impose color", ChainSignet, addressType)
+		require.NoError(t, err)
+		params := &InscriptionParams{
+			ContentType:  "text/plain;charset=utf-8",
+			Body:         "0x68656c6c6f",
+			MetadataJson: `{"name":"hello"}`,
+			Parent:       parent,
+			ParentOutput: "6fb976ab49dcec017f1e201e84395983204ae1a7c2abf7ced0a85d692e442799:1",
+			ParentValue:  546,
+		}
+		// the reveal fee is greater than the parent value at the realistic fee rate
+		txn, err := buildInscription(ChainSignet, account.Address(), account.privateKey.PubKey(), "", params, 50, newTestUtxos(100000))
+		require.NoError(t, err)
+		require.Greater(t, txn.RevealFee, params.ParentValue)
+
+		signed, err := txn.SignedTransactionWithAccount(account)
+		require.NoError(t, err)
+		signedTxn := signed.(*SignedCommitRevealTransaction)
+		commitTx, err := PsbtPacketToMsgTx(&signedTxn.Commit.Packet)
+		require.NoError(t, err)
+		reveal := signedTxn.Reveal.msgTx
+		// the parent is spent and returned at index 0 like ord, the commit output is spent at index 1
+		require.Equal(t, 2, len(reveal.TxIn))
+		require.Equal(t, 2, len(reveal.TxOut))
+		require.Equal(t, "6fb976ab49dcec017f1e201e84395983204ae1a7c2abf7ced0a85d692e442799:1", reveal.TxIn[0].PreviousOutPoint.String())
+		require.Equal(t, params.ParentValue, reveal.TxOut[0].Value)
+		require.Equal(t, commitTx.TxHash(), reveal.TxIn[1].PreviousOutPoint.Hash)
+		require.Equal(t, int64(InscriptionPostage), reveal.TxOut[1].Value)
+		require.Equal(t, commitTx.TxOut[0].Value-reveal.TxOut[1].Value, txn.RevealFee)
+		receiverScript, err := addressToPkScript(account.Address(), &chaincfg.SigNetParams)
+		require.NoError(t, err)
+		require.Equal(t, receiverScript, reveal.TxOut[1].PkScript)
+
+		// verify all reveal inputs
+		fetcher := txscript.NewMultiPrevOutFetcher(map[wire.OutPoint]*wire.TxOut{
+			reveal.TxIn[0].PreviousOutPoint: reveal.TxOut[0],
+			reveal.TxIn[1].PreviousOutPoint: commitTx.TxOut[0],
+		})
+		sigHashes := txscript.NewTxSigHashes(reveal, fetcher)
+		for i, in := range reveal.TxIn {
+			prevOut := fetcher.FetchPrevOutput(in.PreviousOutPoint)
+			engine, err := txscript.NewEngine(prevOut.PkScript, reveal, i, txscript.StandardVerifyFlags, nil,
+				sigHashes, prevOut.Value, fetcher)
+			require.NoError(t, err)
+			require.NoError(t, engine.Execute())
+		}
+
+		ord, err := ordinal.DecodeOrdFromWitness(reveal.TxIn[1].Witness[1])
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(ord.Content))
+		require.Equal(t, []string{parent}, ord.Parents)
+		require.Equal(t, reveal.TxHash().String()+"i0", txn.InscriptionId)
+	}
+}
@@ -16,4 +16,7 @@ var (
 	_ base.SignedTransaction = (*SignedPsbtTransaction)(nil)
 	_ base.Transaction       = (*Transaction)(nil)
 	_ base.SignedTransaction = (*SignedTransaction)(nil)
+	_ base.Transaction       = (*RunesEtchTransaction)(nil)
+	_ base.SignedTransaction = (*SignedCommitRevealTransaction)(nil)
+	_ base.Transaction       = (*InscriptionTransaction)(nil)
 )
@@ -0,0 +1,278 @@
+package btc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/util/hexutil"
+)
+
+const (
+	// The max number of public keys of the standard multisig script
+	maxMultisigKeys = 20
+)
+
+var (
+	ErrInvalidMultisigThreshold = errors.New("invalid multisig threshold")
+	ErrNotMultisigSigner        = errors.New("the account is not a signer of the multisig wallet")
+)
+
+// MultisigWallet is the m-of-n P2WSH multisig wallet,
+// the public keys are sorted lexicographically (BIP67), so the address is independent of the keys' order.
+type MultisigWallet struct {
+	Chainnet  string `json:"chainnet"`
+	Threshold int    `json:"threshold"`
+
+	pubkeys       []*btcec.PublicKey
+	witnessScript []byte
+	address       btcutil.Address
+}
+
+// NewMultisigWallet
+// @param threshold the number of signatures required, 1 <= threshold <= count of pubkeys
+// @param pubkeys the compressed public keys hex of all signers
+func NewMultisigWallet(threshold int, pubkeys *base.StringArray, chainnet string) (*MultisigWallet, error) {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseSortedPubkeys(pubkeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > maxMultisigKeys || threshold <= 0 || threshold > len(keys) {
+		return nil, ErrInvalidMultisigThreshold
+	}
+
+	builder := txscript.NewScriptBuilder().AddInt64(int64(threshold))
+	for _, key := range keys {
+		builder.AddData(key.SerializeCompressed())
+	}
+	witnessScript, err := builder.AddInt64(int64(len(keys))).AddOp(txscript.OP_CHECKMULTISIG).Script()
+	if err != nil {
+		return nil, err
+	}
+	scriptHash := sha256.Sum256(witnessScript)
+	address, err := btcutil.NewAddressWitnessScriptHash(scriptHash[:], net)
+	if err != nil {
+		return nil, err
+	}
+	return &MultisigWallet{
+		Chainnet:      chainnet,
+		Threshold:     threshold,
+		pubkeys:       keys,
+		witnessScript: witnessScript,
+		address:       address,
+	}, nil
+}
+
+func (w *MultisigWallet) Address() string {
+	return w.address.EncodeAddress()
+}
+
+// PublicKeys
+// @return the sorted compressed public keys hex
+func (w *MultisigWallet) PublicKeys() *base.StringArray {
+	return serializePubkeys(w.pubkeys)
+}
+
+func (w *MultisigWallet) WitnessScriptHex() string {
+	return hexutil.HexEncodeToString(w.witnessScript)
+}
+
+// BuildMultisigTransferPsbt
+// Build the unsigned psbt that transfer from the multisig wallet, the witness script is filled in every input.
+// @param amount the satoshi amount to transfer
+// @param feeRate sat/vB, the suggest average fee rate will be used if it <= 0
+func (c *Chain) BuildMultisigTransferPsbt(wallet *MultisigWallet, receiver, amount string, feeRate int64) (txn *PsbtTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil || value <= 0 {
+		return nil, errors.New("invalid transfer amount")
+	}
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchSpendableUtxos(wallet.Address(), nil)
+	if err != nil {
+		return
+	}
+	return buildMultisigTransfer(wallet, receiver, value, feeRate, utxos)
+}
+
+func buildMultisigTransfer(wallet *MultisigWallet, receiver string, amount, feeRate int64, utxos []*UTXO) (*PsbtTransaction, error) {
+	txn, err := NewTransaction(wallet.Chainnet)
+	if err != nil {
+		return nil, err
+	}
+	if err = txn.AddOutput(receiver, amount); err != nil {
+		return nil, err
+	}
+	if err = fundTransactionWithEstimator(txn, wallet.address, utxos, feeRate, wallet.estimateTxSize); err != nil {
+		return nil, err
+	}
+	psbtTxn, err := txn.ToPsbtTransaction()
+	if err != nil {
+		return nil, err
+	}
+	for i := range psbtTxn.Packet.Inputs {
+		psbtTxn.Packet.Inputs[i].WitnessScript = wallet.witnessScript
+	}
+	return psbtTxn, nil
+}
+
+// estimateTxSize
+// The witness of the input is `<empty> <signature>... <witness script>`
+func (w *MultisigWallet) estimateTxSize(tx *wire.MsgTx) int64 {
+	witnessSize := 1 + 73*w.Threshold + len(w.witnessScript)
+	return estimateTxSize(tx, witnessSize, 0)
+}
+
+// SignPsbt
+// Add the account's partial signatures to the inputs that spend the multisig wallet.
+// @param psbtHex the psbt in hex or base64
+// @return the psbt hex with the partial signatures
+func (w *MultisigWallet) SignPsbt(psbtHex string, account *Account) (signedPsbt *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if !containsPubkey(w.pubkeys, account.privateKey.PubKey()) {
+		return nil, ErrNotMultisigSigner
+	}
+	packet, err := DecodePsbtTxToPacket(psbtHex)
+	if err != nil {
+		return
+	}
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		return
+	}
+	fetcher, err := psbtPrevOutFetcher(packet)
+	if err != nil {
+		return
+	}
+	sigHashes := txscript.NewTxSigHashes(packet.UnsignedTx, fetcher)
+	pubkey := account.privateKey.PubKey().SerializeCompressed()
+	signed := false
+	for i, in := range packet.Inputs {
+		if !bytes.Equal(in.WitnessScript, w.witnessScript) {
+			continue
+		}
+		sig, err := txscript.RawTxInWitnessSignature(packet.UnsignedTx, sigHashes, i, in.WitnessUtxo.Value,
+			w.witnessScript, txscript.SigHashAll, account.privateKey)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = updater.Sign(i, sig, pubkey, nil, nil); err != nil {
+			return nil, err
+		}
+		signed = true
+	}
+	if !signed {
+		return nil, errors.New("no input spends the multisig wallet")
+	}
+	return encodePsbtHex(packet)
+}
+
+// FinalizePsbt
+// Finalize the inputs that spend the multisig wallet when they have enough partial signatures,
+// the other inputs must have been finalized.
+// @param psbtHex the combined psbt in hex or base64
+func (w *MultisigWallet) FinalizePsbt(psbtHex string) (signedTxn *SignedPsbtTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	packet, err := DecodePsbtTxToPacket(psbtHex)
+	if err != nil {
+		return
+	}
+	for i, in := range packet.Inputs {
+		if !bytes.Equal(in.WitnessScript, w.witnessScript) || in.FinalScriptWitness != nil {
+			continue
+		}
+		// The finalizer requires exactly threshold signatures, which are ordered as the keys in the script.
+		sigs := make([]*psbt.PartialSig, 0, w.Threshold)
+		for _, key := range w.pubkeys {
+			for _, sig := range in.PartialSigs {
+				if len(sigs) < w.Threshold && bytes.Equal(sig.PubKey, key.SerializeCompressed()) {
+					sigs = append(sigs, sig)
+				}
+			}
+		}
+		if len(sigs) < w.Threshold {
+			return nil, errors.New("input " + strconv.Itoa(i) + " has not enough signatures")
+		}
+		packet.Inputs[i].PartialSigs = sigs
+	}
+	if err = EnsurePsbtFinalize(packet); err != nil {
+		return
+	}
+	return &SignedPsbtTransaction{*packet}, nil
+}
+
+// parseSortedPubkeys
+// @return the compressed public keys sorted lexicographically
+func parseSortedPubkeys(pubkeys *base.StringArray) ([]*btcec.PublicKey, error) {
+	if pubkeys == nil || pubkeys.Count() == 0 {
+		return nil, errors.New("empty public keys")
+	}
+	keys := make([]*btcec.PublicKey, 0, pubkeys.Count())
+	for _, hex := range pubkeys.AnyArray {
+		data, err := hexutil.HexDecodeString(hex)
+		if err != nil {
+			return nil, err
+		}
+		key, err := btcec.ParsePubKey(data)
+		if err != nil {
+			return nil, err
+		}
+		if containsPubkey(keys, key) {
+			return nil, errors.New("duplicate public key: " + hex)
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i].SerializeCompressed(), keys[j].SerializeCompressed()) < 0
+	})
+	return keys, nil
+}
+
+func serializePubkeys(keys []*btcec.PublicKey) *base.StringArray {
+	arr := base.NewStringArray()
+	for _, key := range keys {
+		arr.Append(hexutil.HexEncodeToString(key.SerializeCompressed()))
+	}
+	return arr
+}
+
+func containsPubkey(keys []*btcec.PublicKey, key *btcec.PublicKey) bool {
+	for _, k := range keys {
+		if k.IsEqual(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// psbtPrevOutFetcher
+// The witness utxo of every input is required.
+func psbtPrevOutFetcher(packet *psbt.Packet) (*txscript.MultiPrevOutFetcher, error) {
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, in := range packet.UnsignedTx.TxIn {
+		utxo := packet.Inputs[i].WitnessUtxo
+		if utxo == nil {
+			return nil, errors.New("input " + strconv.Itoa(i) + " has no witness utxo")
+		}
+		fetcher.AddPrevOut(in.PreviousOutPoint, utxo)
+	}
+	return fetcher, nil
+}
@@ -0,0 +1,91 @@
+package btc
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/util/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSigners
+// @return three accounts with different keys
+func newTestSigners(t *testing.T) []*Account {
+	mnemonic := "antenna chaos arrive hungry distance human question history decade deal impose color"
+	accounts := []*Account{}
+	for _, addrType := range []AddressType{AddressTypeTaproot, AddressTypeNativeSegwit, AddressTypeLegacy} {
+		account, err := NewAccountWithMnemonic(mnemonic, ChainSignet, addrType)
+		require.NoError(t, err)
+		accounts = append(accounts, account)
+	}
+	return accounts
+}
+
+func testPubkeys(accounts ...*Account) *base.StringArray {
+	arr := base.NewStringArray()
+	for _, account := range accounts {
+		arr.Append(hexutil.HexEncodeToString(account.privateKey.PubKey().SerializeCompressed()))
+	}
+	return arr
+}
+
+func verifyPsbtTransaction(t *testing.T, signed *SignedPsbtTransaction) {
+	packet := signed.Packet
+	tx, err := psbt.Extract(&packet)
+	require.NoError(t, err)
+	fetcher, err := psbtPrevOutFetcher(&packet)
+	require.NoError(t, err)
+	verifyTransaction(t, tx, fetcher)
+}
+
+func TestMultisigWallet(t *testing.T) {
+	signers := newTestSigners(t)
+	receiver := "tb1pqtguh4mt0206qr7t3pze5zf4st4v3xtvqfhgv7q7j6ymnv7gtutqy4nrud"
+
+	wallet, err := NewMultisigWallet(2, testPubkeys(signers...), ChainSignet)
+	require.NoError(t, err)
+	reversed, err := NewMultisigWallet(2, testPubkeys(signers[2], signers[1], signers[0]), ChainSignet)
+	require.NoError(t, err)
+	require.Equal(t, wallet.Address(), reversed.Address())
+	require.Equal(t, wallet.WitnessScriptHex(), reversed.WitnessScriptHex())
+
+	_, err = NewMultisigWallet(4, testPubkeys(signers...), ChainSignet)
+	require.ErrorIs(t, err, ErrInvalidMultisigThreshold)
+	_, err = NewMultisigWallet(1, testPubkeys(signers[0], signers[0]), ChainSignet)
+	require.Error(t, err)
+
+	txn, err := buildMultisigTransfer(wallet, receiver, 150000, 10, newTestUtxos(100000, 100000))
+	require.NoError(t, err)
+	require.Len(t, txn.Packet.UnsignedTx.TxIn, 2)
+	unsigned, err := encodePsbtHex(&txn.Packet)
+	require.NoError(t, err)
+
+	// the signers sign separately
+	signed0, err := wallet.SignPsbt(unsigned.Value, signers[0])
+	require.NoError(t, err)
+	signed2, err := wallet.SignPsbt(unsigned.Value, signers[2])
+	require.NoError(t, err)
+
+	_, err = wallet.FinalizePsbt(signed0.Value)
+	require.Error(t, err)
+
+	combined, err := CombinePsbts(&base.StringArray{AnyArray: []string{signed0.Value, signed2.Value}})
+	require.NoError(t, err)
+	signedTxn, err := wallet.FinalizePsbt(combined.Value)
+	require.NoError(t, err)
+	verifyPsbtTransaction(t, signedTxn)
+}
+
+func TestMultisigWallet_NotSigner(t *testing.T) {
+	signers := newTestSigners(t)
+	wallet, err := NewMultisigWallet(1, testPubkeys(signers[:2]...), ChainSignet)
+	require.NoError(t, err)
+
+	txn, err := buildMultisigTransfer(wallet, wallet.Address(), 50000, 1, newTestUtxos(100000))
+	require.NoError(t, err)
+	unsigned, err := encodePsbtHex(&txn.Packet)
+	require.NoError(t, err)
+	_, err = wallet.SignPsbt(unsigned.Value, signers[2])
+	require.EqualError(t, err, ErrNotMultisigSigner.Error())
+}
@@ -0,0 +1,401 @@
+package btc
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/util/hexutil"
+)
+
+// The psbt input fields of MuSig2 (BIP373)
+const (
+	psbtInMusig2ParticipantPubkeys byte = 0x1a
+	psbtInMusig2PubNonce           byte = 0x1b
+	psbtInMusig2PartialSig         byte = 0x1c
+)
+
+// Musig2Wallet is the n-of-n taproot wallet spent with the key path.
+// The public keys are sorted and aggregated (BIP327), and the aggregated key is tweaked without script path (BIP86),
+// so the address looks like a single key taproot address.
+//
+// The signing flow with psbt:
+//  1. Build the psbt by `Chain.BuildMusig2TransferPsbt`.
+//  2. Every signer adds it's public nonces by `Musig2Signer.AddNonces`.
+//  3. Combine the psbts by `CombinePsbts`, then every signer adds it's partial signatures by `Musig2Signer.PartialSign`.
+//  4. Combine the psbts again, and aggregate the partial signatures by `Musig2Wallet.FinalizePsbt`.
+type Musig2Wallet struct {
+	Chainnet string `json:"chainnet"`
+
+	pubkeys []*btcec.PublicKey
+	aggKey  *musig2.AggregateKey
+	address btcutil.Address
+}
+
+// NewMusig2Wallet
+// @param pubkeys the compressed public keys hex of all signers
+func NewMusig2Wallet(pubkeys *base.StringArray, chainnet string) (*Musig2Wallet, error) {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseSortedPubkeys(pubkeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) < 2 {
+		return nil, errors.New("musig2 requires at least 2 public keys")
+	}
+	aggKey, _, _, err := musig2.AggregateKeys(keys, true, musig2.WithBIP86KeyTweak())
+	if err != nil {
+		return nil, err
+	}
+	address, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(aggKey.FinalKey), net)
+	if err != nil {
+		return nil, err
+	}
+	return &Musig2Wallet{
+		Chainnet: chainnet,
+		pubkeys:  keys,
+		aggKey:   aggKey,
+		address:  address,
+	}, nil
+}
+
+func (w *Musig2Wallet) Address() string {
+	return w.address.EncodeAddress()
+}
+
+// PublicKeys
+// @return the sorted compressed public keys hex
+func (w *Musig2Wallet) PublicKeys() *base.StringArray {
+	return serializePubkeys(w.pubkeys)
+}
+
+// InternalKey
+// @return the x-only aggregated public key hex before tweaked
+func (w *Musig2Wallet) InternalKey() string {
+	return hexutil.HexEncodeToString(schnorr.SerializePubKey(w.aggKey.PreTweakedKey))
+}
+
+// BuildMusig2TransferPsbt
+// Build the unsigned psbt that transfer from the musig2 wallet, the participants are filled in every input.
+// @param amount the satoshi amount to transfer
+// @param feeRate sat/vB, the suggest average fee rate will be used if it <= 0
+func (c *Chain) BuildMusig2TransferPsbt(wallet *Musig2Wallet, receiver, amount string, feeRate int64) (txn *PsbtTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil || value <= 0 {
+		return nil, errors.New("invalid transfer amount")
+	}
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchSpendableUtxos(wallet.Address(), nil)
+	if err != nil {
+		return
+	}
+	return buildMusig2Transfer(wallet, receiver, value, feeRate, utxos)
+}
+
+func buildMusig2Transfer(wallet *Musig2Wallet, receiver string, amount, feeRate int64, utxos []*UTXO) (*PsbtTransaction, error) {
+	txn, err := NewTransaction(wallet.Chainnet)
+	if err != nil {
+		return nil, err
+	}
+	if err = txn.AddOutput(receiver, amount); err != nil {
+		return nil, err
+	}
+	// The key path witness is a single schnorr signature, same as the normal taproot address.
+	if err = fundTransaction(txn, wallet.address, utxos, feeRate); err != nil {
+		return nil, err
+	}
+	psbtTxn, err := txn.ToPsbtTransaction()
+	if err != nil {
+		return nil, err
+	}
+	var participants []byte
+	for _, key := range wallet.pubkeys {
+		participants = append(participants, key.SerializeCompressed()...)
+	}
+	for i := range psbtTxn.Packet.Inputs {
+		in := &psbtTxn.Packet.Inputs[i]
+		in.TaprootInternalKey = schnorr.SerializePubKey(wallet.aggKey.PreTweakedKey)
+		in.Unknowns = append(in.Unknowns, &psbt.Unknown{
+			Key:   wallet.musig2FieldKey(psbtInMusig2ParticipantPubkeys, nil),
+			Value: participants,
+		})
+	}
+	return psbtTxn, nil
+}
+
+// musig2FieldKey
+// @return `<type> [<participant pubkey>] <aggregate pubkey>`
+func (w *Musig2Wallet) musig2FieldKey(keyType byte, participant *btcec.PublicKey) []byte {
+	key := []byte{keyType}
+	if participant != nil {
+		key = append(key, participant.SerializeCompressed()...)
+	}
+	return append(key, w.aggKey.PreTweakedKey.SerializeCompressed()...)
+}
+
+// isWalletInput
+// @return true if the input spends the wallet with the key path
+func (w *Musig2Wallet) isWalletInput(in *psbt.PInput) bool {
+	return in.WitnessUtxo != nil && in.FinalScriptWitness == nil &&
+		bytes.Equal(in.TaprootInternalKey, schnorr.SerializePubKey(w.aggKey.PreTweakedKey))
+}
+
+// musig2Field
+// @return the value of the field, nil if not found.
+func musig2Field(in *psbt.PInput, key []byte) []byte {
+	for _, u := range in.Unknowns {
+		if bytes.Equal(u.Key, key) {
+			return u.Value
+		}
+	}
+	return nil
+}
+
+// pubNonces
+// @return the public nonces of all participants, error if anyone is missing.
+func (w *Musig2Wallet) pubNonces(in *psbt.PInput) ([][musig2.PubNonceSize]byte, error) {
+	nonces := make([][musig2.PubNonceSize]byte, len(w.pubkeys))
+	for i, key := range w.pubkeys {
+		value := musig2Field(in, w.musig2FieldKey(psbtInMusig2PubNonce, key))
+		if len(value) != musig2.PubNonceSize {
+			return nil, errors.New("the public nonce is missing: " + hexutil.HexEncodeToString(key.SerializeCompressed()))
+		}
+		copy(nonces[i][:], value)
+	}
+	return nonces, nil
+}
+
+// keySpendSigHash
+// @return the taproot key path signature hash of the input with SIGHASH_DEFAULT
+func keySpendSigHash(packet *psbt.Packet, idx int) ([32]byte, error) {
+	var msg [32]byte
+	fetcher, err := psbtPrevOutFetcher(packet)
+	if err != nil {
+		return msg, err
+	}
+	sigHashes := txscript.NewTxSigHashes(packet.UnsignedTx, fetcher)
+	hash, err := txscript.CalcTaprootSignatureHash(sigHashes, txscript.SigHashDefault, packet.UnsignedTx, idx, fetcher)
+	if err != nil {
+		return msg, err
+	}
+	copy(msg[:], hash)
+	return msg, nil
+}
+
+// FinalizePsbt
+// Aggregate the partial signatures of the inputs that spend the musig2 wallet, the other inputs must have been finalized.
+// @param psbtHex the combined psbt in hex or base64
+func (w *Musig2Wallet) FinalizePsbt(psbtHex string) (signedTxn *SignedPsbtTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	packet, err := DecodePsbtTxToPacket(psbtHex)
+	if err != nil {
+		return
+	}
+	for i := range packet.Inputs {
+		in := &packet.Inputs[i]
+		if !w.isWalletInput(in) {
+			continue
+		}
+		msg, err := keySpendSigHash(packet, i)
+		if err != nil {
+			return nil, err
+		}
+		nonces, err := w.pubNonces(in)
+		if err != nil {
+			return nil, err
+		}
+		aggNonce, err := musig2.AggregateNonces(nonces)
+		if err != nil {
+			return nil, err
+		}
+		sigs := make([]*musig2.PartialSignature, len(w.pubkeys))
+		for j, key := range w.pubkeys {
+			value := musig2Field(in, w.musig2FieldKey(psbtInMusig2PartialSig, key))
+			if value == nil {
+				return nil, errors.New("input " + strconv.Itoa(i) + " has not enough signatures")
+			}
+			sigs[j] = &musig2.PartialSignature{}
+			if err = sigs[j].Decode(bytes.NewReader(value)); err != nil {
+				return nil, err
+			}
+			if !sigs[j].Verify(nonces[j], aggNonce, w.pubkeys, key, msg, musig2.WithSortedKeys(), musig2.WithBip86SignTweak()) {
+				return nil, errors.New("invalid partial signature: " + hexutil.HexEncodeToString(key.SerializeCompressed()))
+			}
+		}
+		nonce, err := musig2FinalNonce(aggNonce, w.aggKey.FinalKey, msg)
+		if err != nil {
+			return nil, err
+		}
+		sig := musig2.CombineSigs(nonce, sigs, musig2.WithBip86TweakedCombine(msg, w.pubkeys, true))
+		if !sig.Verify(msg[:], w.aggKey.FinalKey) {
+			return nil, errors.New("invalid aggregated signature")
+		}
+		in.TaprootKeySpendSig = sig.Serialize()
+	}
+	if err = EnsurePsbtFinalize(packet); err != nil {
+		return
+	}
+	return &SignedPsbtTransaction{*packet}, nil
+}
+
+// musig2FinalNonce
+// R = R1 + b*R2, b = hash_noncecoef(aggnonce || xonly(Q) || msg)
+func musig2FinalNonce(aggNonce [musig2.PubNonceSize]byte, finalKey *btcec.PublicKey, msg [32]byte) (*btcec.PublicKey, error) {
+	var buf bytes.Buffer
+	buf.Write(aggNonce[:])
+	buf.Write(schnorr.SerializePubKey(finalKey))
+	buf.Write(msg[:])
+	hash := chainhash.TaggedHash(musig2.NonceBlindTag, buf.Bytes())
+	var b btcec.ModNScalar
+	b.SetByteSlice(hash[:])
+
+	r1, err := btcec.ParseJacobian(aggNonce[:btcec.PubKeyBytesLenCompressed])
+	if err != nil {
+		return nil, err
+	}
+	r2, err := btcec.ParseJacobian(aggNonce[btcec.PubKeyBytesLenCompressed:])
+	if err != nil {
+		return nil, err
+	}
+	var r btcec.JacobianPoint
+	btcec.ScalarMultNonConst(&b, &r2, &r2)
+	btcec.AddNonConst(&r1, &r2, &r)
+	if r.Z.IsZero() { // the point at infinity
+		btcec.Generator().AsJacobian(&r)
+	}
+	r.ToAffine()
+	return btcec.NewPublicKey(&r.X, &r.Y), nil
+}
+
+// Musig2Signer holds the secret nonces of a participant between the two rounds,
+// a signer should only be used for one psbt, the secret nonces will be cleared after signed.
+type Musig2Signer struct {
+	wallet  *Musig2Wallet
+	account *Account
+
+	txHash    chainhash.Hash
+	secNonces map[int][musig2.SecNonceSize]byte
+}
+
+func (w *Musig2Wallet) NewSigner(account *Account) (*Musig2Signer, error) {
+	if !containsPubkey(w.pubkeys, account.privateKey.PubKey()) {
+		return nil, ErrNotMultisigSigner
+	}
+	return &Musig2Signer{wallet: w, account: account}, nil
+}
+
+// AddNonces
+// Generate the nonces of the inputs that spend the musig2 wallet, and add the public nonces to the psbt.
+// @param psbtHex the psbt in hex or base64
+// @return the psbt hex with the public nonces
+func (s *Musig2Signer) AddNonces(psbtHex string) (res *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if s.secNonces != nil {
+		return nil, errors.New("the nonces have been generated")
+	}
+	packet, err := DecodePsbtTxToPacket(psbtHex)
+	if err != nil {
+		return
+	}
+	pubkey := s.account.privateKey.PubKey()
+	secNonces := make(map[int][musig2.SecNonceSize]byte)
+	for i := range packet.Inputs {
+		in := &packet.Inputs[i]
+		if !s.wallet.isWalletInput(in) {
+			continue
+		}
+		msg, err := keySpendSigHash(packet, i)
+		if err != nil {
+			return nil, err
+		}
+		nonces, err := musig2.GenNonces(
+			musig2.WithPublicKey(pubkey),
+			musig2.WithNonceSecretKeyAux(s.account.privateKey),
+			musig2.WithNonceCombinedKeyAux(s.wallet.aggKey.FinalKey),
+			musig2.WithNonceMessageAux(msg),
+		)
+		if err != nil {
+			return nil, err
+		}
+		secNonces[i] = nonces.SecNonce
+		in.Unknowns = append(in.Unknowns, &psbt.Unknown{
+			Key:   s.wallet.musig2FieldKey(psbtInMusig2PubNonce, pubkey),
+			Value: nonces.PubNonce[:],
+		})
+	}
+	if len(secNonces) == 0 {
+		return nil, errors.New("no input spends the musig2 wallet")
+	}
+	s.txHash = packet.UnsignedTx.TxHash()
+	s.secNonces = secNonces
+	return encodePsbtHex(packet)
+}
+
+// PartialSign
+// Add the partial signatures to the psbt, the public nonces of all participants are required.
+// @param psbtHex the combined psbt in hex or base64
+// @return the psbt hex with the partial signatures
+func (s *Musig2Signer) PartialSign(psbtHex string) (res *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if s.secNonces == nil {
+		return nil, errors.New("the nonces have not been generated or have been used")
+	}
+	packet, err := DecodePsbtTxToPacket(psbtHex)
+	if err != nil {
+		return
+	}
+	if packet.UnsignedTx.TxHash() != s.txHash {
+		return nil, errors.New("the psbt is different from the one that nonces were generated for")
+	}
+	pubkey := s.account.privateKey.PubKey()
+	for i, secNonce := range s.secNonces {
+		in := &packet.Inputs[i]
+		msg, err := keySpendSigHash(packet, i)
+		if err != nil {
+			return nil, err
+		}
+		nonces, err := s.wallet.pubNonces(in)
+		if err != nil {
+			return nil, err
+		}
+		aggNonce, err := musig2.AggregateNonces(nonces)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := musig2.Sign(secNonce, s.account.privateKey, aggNonce, s.wallet.pubkeys, msg,
+			musig2.WithSortedKeys(), musig2.WithBip86SignTweak())
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err = sig.Encode(&buf); err != nil {
+			return nil, err
+		}
+		in.Unknowns = append(in.Unknowns, &psbt.Unknown{
+			Key:   s.wallet.musig2FieldKey(psbtInMusig2PartialSig, pubkey),
+			Value: buf.Bytes(),
+		})
+	}
+	// The secret nonces must never be reused
+	s.secNonces = nil
+	return encodePsbtHex(packet)
+}
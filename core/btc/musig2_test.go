@@ -0,0 +1,58 @@
+package btc
+
+import (
+	"testing"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMusig2Wallet(t *testing.T) {
+	signers := newTestSigners(t)
+	receiver := "tb1pqtguh4mt0206qr7t3pze5zf4st4v3xtvqfhgv7q7j6ymnv7gtutqy4nrud"
+
+	wallet, err := NewMusig2Wallet(testPubkeys(signers...), ChainSignet)
+	require.NoError(t, err)
+	reversed, err := NewMusig2Wallet(testPubkeys(signers[2], signers[1], signers[0]), ChainSignet)
+	require.NoError(t, err)
+	require.Equal(t, wallet.Address(), reversed.Address())
+
+	txn, err := buildMusig2Transfer(wallet, receiver, 150000, 10, newTestUtxos(100000, 100000))
+	require.NoError(t, err)
+	require.Len(t, txn.Packet.UnsignedTx.TxIn, 2)
+	unsigned, err := encodePsbtHex(&txn.Packet)
+	require.NoError(t, err)
+
+	// round 1: exchange the public nonces
+	musigSigners := make([]*Musig2Signer, len(signers))
+	nonces := base.NewStringArray()
+	for i, account := range signers {
+		musigSigners[i], err = wallet.NewSigner(account)
+		require.NoError(t, err)
+		withNonce, err := musigSigners[i].AddNonces(unsigned.Value)
+		require.NoError(t, err)
+		nonces.Append(withNonce.Value)
+	}
+	combined, err := CombinePsbts(nonces)
+	require.NoError(t, err)
+
+	// round 2: the partial signatures
+	partials := base.NewStringArray()
+	for _, signer := range musigSigners {
+		signed, err := signer.PartialSign(combined.Value)
+		require.NoError(t, err)
+		partials.Append(signed.Value)
+	}
+	// the secret nonces can not be reused
+	_, err = musigSigners[0].PartialSign(combined.Value)
+	require.Error(t, err)
+
+	_, err = wallet.FinalizePsbt(partials.AnyArray[0])
+	require.Error(t, err)
+
+	combined, err = CombinePsbts(partials)
+	require.NoError(t, err)
+	signedTxn, err := wallet.FinalizePsbt(combined.Value)
+	require.NoError(t, err)
+	verifyPsbtTransaction(t, signedTxn)
+}
@@ -0,0 +1,143 @@
+package ordinal
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// TapscriptCommit is a taproot output that commits to a single tapscript leaf,
+// the commit transaction sends to it's address, and the reveal transaction spends it with the script path.
+//
+// The internal key is the owner's public key, so the output can also be spent with the key path if the reveal failed.
+type TapscriptCommit struct {
+	InternalKey  *btcec.PublicKey
+	Script       []byte
+	Leaf         txscript.TapLeaf
+	ControlBlock []byte
+	PkScript     []byte
+	Address      string
+}
+
+func NewTapscriptCommit(internalKey *btcec.PublicKey, script []byte, net *chaincfg.Params) (*TapscriptCommit, error) {
+	leaf := txscript.NewBaseTapLeaf(script)
+	tree := txscript.AssembleTaprootScriptTree(leaf)
+	controlBlock := tree.LeafMerkleProofs[0].ToControlBlock(internalKey)
+	controlBlockBytes, err := controlBlock.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	rootHash := tree.RootNode.TapHash()
+	outputKey := txscript.ComputeTaprootOutputKey(internalKey, rootHash[:])
+	address, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), net)
+	if err != nil {
+		return nil, err
+	}
+	pkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return nil, err
+	}
+	return &TapscriptCommit{
+		InternalKey:  internalKey,
+		Script:       script,
+		Leaf:         leaf,
+		ControlBlock: controlBlockBytes,
+		PkScript:     pkScript,
+		Address:      address.EncodeAddress(),
+	}, nil
+}
+
+// DummyWitness
+// The placeholder of the script path witness: [signature, script, control block], used to estimate the transaction size.
+func (c *TapscriptCommit) DummyWitness() wire.TxWitness {
+	return wire.TxWitness{make([]byte, schnorr.SignatureSize), c.Script, c.ControlBlock}
+}
+
+// SignInput sign the input that spend the commit output with the script path.
+func (c *TapscriptCommit) SignInput(tx *wire.MsgTx, idx int, prevOutFetcher txscript.PrevOutputFetcher, privKey *btcec.PrivateKey) error {
+	prevOut := prevOutFetcher.FetchPrevOutput(tx.TxIn[idx].PreviousOutPoint)
+	if prevOut == nil {
+		return errors.New("the commit output is not found")
+	}
+	sigHashes := txscript.NewTxSigHashes(tx, prevOutFetcher)
+	sig, err := txscript.RawTxInTapscriptSignature(tx, sigHashes, idx, prevOut.Value, prevOut.PkScript, c.Leaf, txscript.SigHashDefault, privKey)
+	if err != nil {
+		return err
+	}
+	tx.TxIn[idx].Witness = wire.TxWitness{sig, c.Script, c.ControlBlock}
+	return nil
+}
+
+// InscriptionReveal is the reveal transaction of an inscription.
+// The extra inputs and outputs (e.g. the parent inscriptions) come first like ord does, so the sats of them are
+// transferred to the extra outputs one by one, then the input at `CommitIndex` spends the commit output,
+// and the output at `CommitIndex` receives the inscription.
+type InscriptionReveal struct {
+	Commit *TapscriptCommit
+	Tx     *wire.MsgTx
+	// The index of the input that spends the commit output, it's also the index of the inscription output.
+	CommitIndex int
+	// The network fee of the reveal transaction, the commit output's value should be `postage + fee`.
+	Fee int64
+}
+
+// NewInscriptionReveal
+// @param pubkey the owner's public key that can sign the reveal transaction.
+// @param receiverPkScript the output script of the inscription's receiver.
+// @param postage the value of the inscription's output.
+// @param extraInputs the additional inputs before the commit input, e.g. the parent inscriptions, the witness or signature
+// script of them can be filled with placeholder to estimate the size, the default is the taproot key path signature.
+// @param extraOutputs the additional outputs before the inscription output, e.g. return the parent inscriptions,
+// the count and values of them should match the extra inputs, otherwise the sats of the extra inputs will be misplaced.
+func NewInscriptionReveal(inscription *Inscription, pubkey *btcec.PublicKey, receiverPkScript []byte,
+	postage, feeRate int64, net *chaincfg.Params, extraInputs []*wire.TxIn, extraOutputs []*wire.TxOut) (*InscriptionReveal, error) {
+	script, err := inscription.RevealScript(pubkey)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := NewTapscriptCommit(pubkey, script, net)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(extraInputs) != len(extraOutputs) {
+		return nil, errors.New("the extra inputs and outputs should be paired")
+	}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	for _, in := range extraInputs {
+		if in.Witness == nil && in.SignatureScript == nil {
+			in.Witness = wire.TxWitness{make([]byte, schnorr.SignatureSize)}
+		}
+		tx.AddTxIn(in)
+	}
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0), nil, commit.DummyWitness()))
+	for _, out := range extraOutputs {
+		tx.AddTxOut(out)
+	}
+	tx.AddTxOut(wire.NewTxOut(postage, receiverPkScript))
+	fee := feeRate * mempool.GetTxVirtualSize(btcutil.NewTx(tx))
+	for _, in := range tx.TxIn {
+		in.Witness = nil
+		in.SignatureScript = nil
+	}
+	return &InscriptionReveal{
+		Commit:      commit,
+		Tx:          tx,
+		CommitIndex: len(extraInputs),
+		Fee:         fee,
+	}, nil
+}
+
+// SetCommitOutPoint
+// The reveal transaction spends the commit output, it should be set after the commit transaction is built.
+func (r *InscriptionReveal) SetCommitOutPoint(hash chainhash.Hash, index uint32) {
+	r.Tx.TxIn[r.CommitIndex].PreviousOutPoint = wire.OutPoint{Hash: hash, Index: index}
+}
@@ -0,0 +1,186 @@
+package ordinal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// The envelope field tags
+// https://docs.ordinals.com/inscriptions.html
+const (
+	TagBody            byte = 0
+	TagContentType     byte = 1
+	TagPointer         byte = 2
+	TagParent          byte = 3
+	TagMetadata        byte = 5
+	TagMetaprotocol    byte = 7
+	TagContentEncoding byte = 9
+	TagDelegate        byte = 11
+	TagRune            byte = 13
+)
+
+const protocolId = "ord"
+
+var (
+	ErrIllegalInscriptionId = errors.New("illegal inscription id")
+)
+
+type Inscription struct {
+	ContentType     string
+	Body            []byte
+	Parents         []string // the inscription ids of the parents, like `{txid}i{index}`
+	Metadata        []byte   // the CBOR encoded metadata
+	Metaprotocol    string
+	ContentEncoding string
+	Pointer         *uint64
+}
+
+func NewInscription(contentType string, body []byte) *Inscription {
+	return &Inscription{
+		ContentType: contentType,
+		Body:        body,
+	}
+}
+
+// SetJsonMetadata encode the json metadata to CBOR
+func (i *Inscription) SetJsonMetadata(metadata string) error {
+	var obj any
+	if err := json.Unmarshal([]byte(metadata), &obj); err != nil {
+		return err
+	}
+	data, err := cbor.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	i.Metadata = data
+	return nil
+}
+
+// Envelope
+// OP_FALSE OP_IF "ord" [tag value]... OP_0 [body chunk]... OP_ENDIF
+func (i *Inscription) Envelope() ([]byte, error) {
+	script := []byte{txscript.OP_FALSE, txscript.OP_IF}
+	script = appendPushData(script, []byte(protocolId))
+
+	appendField := func(tag byte, value []byte) {
+		script = appendPushData(script, []byte{tag})
+		script = appendPushData(script, value)
+	}
+	if i.ContentType != "" {
+		appendField(TagContentType, []byte(i.ContentType))
+	}
+	if i.Pointer != nil {
+		appendField(TagPointer, trimTrailingZero(binary.LittleEndian.AppendUint64(nil, *i.Pointer)))
+	}
+	for _, parent := range i.Parents {
+		id, err := EncodeInscriptionId(parent)
+		if err != nil {
+			return nil, err
+		}
+		appendField(TagParent, id)
+	}
+	for _, chunk := range chunks(i.Metadata) {
+		appendField(TagMetadata, chunk)
+	}
+	if i.Metaprotocol != "" {
+		appendField(TagMetaprotocol, []byte(i.Metaprotocol))
+	}
+	if i.ContentEncoding != "" {
+		appendField(TagContentEncoding, []byte(i.ContentEncoding))
+	}
+	if i.Body != nil {
+		script = append(script, txscript.OP_0)
+		for _, chunk := range chunks(i.Body) {
+			script = appendPushData(script, chunk)
+		}
+	}
+	return append(script, txscript.OP_ENDIF), nil
+}
+
+// RevealScript
+// <x-only pubkey> OP_CHECKSIG <envelope>
+func (i *Inscription) RevealScript(pubkey *btcec.PublicKey) ([]byte, error) {
+	envelope, err := i.Envelope()
+	if err != nil {
+		return nil, err
+	}
+	script := appendPushData(nil, schnorr.SerializePubKey(pubkey))
+	script = append(script, txscript.OP_CHECKSIG)
+	return append(script, envelope...), nil
+}
+
+// EncodeInscriptionId
+// The txid in little-endian bytes, followed by the index in little-endian bytes without trailing zero.
+// @param id like `{txid}i{index}`
+func EncodeInscriptionId(id string) ([]byte, error) {
+	parts := strings.Split(id, "i")
+	if len(parts) != 2 {
+		return nil, ErrIllegalInscriptionId
+	}
+	hash, err := chainhash.NewHashFromStr(parts[0])
+	if err != nil {
+		return nil, ErrIllegalInscriptionId
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, ErrIllegalInscriptionId
+	}
+	indexBytes := trimTrailingZero(binary.LittleEndian.AppendUint32(nil, uint32(index)))
+	return append(hash.CloneBytes(), indexBytes...), nil
+}
+
+func DecodeInscriptionId(data []byte) (string, error) {
+	if len(data) < chainhash.HashSize || len(data) > chainhash.HashSize+4 {
+		return "", ErrIllegalInscriptionId
+	}
+	hash, err := chainhash.NewHash(data[:chainhash.HashSize])
+	if err != nil {
+		return "", err
+	}
+	indexBytes := make([]byte, 4)
+	copy(indexBytes, data[chainhash.HashSize:])
+	index := binary.LittleEndian.Uint32(indexBytes)
+	return hash.String() + "i" + strconv.FormatUint(uint64(index), 10), nil
+}
+
+func trimTrailingZero(data []byte) []byte {
+	end := len(data)
+	for end > 0 && data[end-1] == 0 {
+		end--
+	}
+	return data[:end]
+}
+
+func chunks(data []byte) [][]byte {
+	var res [][]byte
+	for len(data) > 0 {
+		size := min(len(data), txscript.MaxScriptElementSize)
+		res = append(res, data[:size])
+		data = data[size:]
+	}
+	return res
+}
+
+// appendPushData always push the data with the OP_DATA_X or OP_PUSHDATA_X,
+// the txscript.ScriptBuilder will convert the small data to the OP_1 ~ OP_16 which is not allowed in the envelope.
+func appendPushData(script []byte, data []byte) []byte {
+	size := len(data)
+	switch {
+	case size <= txscript.OP_DATA_75:
+		script = append(script, byte(size))
+	case size <= 0xff:
+		script = append(script, txscript.OP_PUSHDATA1, byte(size))
+	default:
+		script = append(script, txscript.OP_PUSHDATA2, byte(size), byte(size>>8))
+	}
+	return append(script, data...)
+}
@@ -0,0 +1,86 @@
+package ordinal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInscription_RevealScriptRoundTrip(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	parent := "6fb976ab49dcec017f1e201e84395983204ae1a7c2abf7ced0a85d692e442799i1"
+	pointer := uint64(546)
+	inscription := NewInscription("text/plain;charset=utf-8", bytes.Repeat([]byte("a"), 1200))
+	inscription.Parents = []string{parent}
+	inscription.Metaprotocol = "wallet-sdk"
+	inscription.ContentEncoding = "br"
+	inscription.Pointer = &pointer
+	require.NoError(t, inscription.SetJsonMetadata(`{"name":"test","id":1}`))
+
+	script, err := inscription.RevealScript(privKey.PubKey())
+	require.NoError(t, err)
+
+	ord, err := DecodeOrdFromWitness(script)
+	require.NoError(t, err)
+	require.Equal(t, inscription.ContentType, ord.ContentType)
+	require.Equal(t, inscription.Body, ord.Content)
+	require.Equal(t, []string{parent}, ord.Parents)
+	require.Equal(t, inscription.Metaprotocol, ord.Metaprotocol)
+	require.Equal(t, inscription.ContentEncoding, ord.ContentEncoding)
+	require.Equal(t, pointer, *ord.Pointer)
+
+	var metadata map[string]any
+	require.NoError(t, cbor.Unmarshal(ord.Metadata, &metadata))
+	require.Equal(t, "test", metadata["name"])
+}
+
+func TestInscriptionId(t *testing.T) {
+	for _, id := range []string{
+		"6fb976ab49dcec017f1e201e84395983204ae1a7c2abf7ced0a85d692e442799i0",
+		"6fb976ab49dcec017f1e201e84395983204ae1a7c2abf7ced0a85d692e442799i255",
+		"6fb976ab49dcec017f1e201e84395983204ae1a7c2abf7ced0a85d692e442799i65536",
+	} {
+		data, err := EncodeInscriptionId(id)
+		require.NoError(t, err)
+		decoded, err := DecodeInscriptionId(data)
+		require.NoError(t, err)
+		require.Equal(t, id, decoded)
+	}
+	_, err := EncodeInscriptionId("6fb976ab49dcec017f1e201e84395983204ae1a7c2abf7ced0a85d692e442799")
+	require.ErrorIs(t, err, ErrIllegalInscriptionId)
+}
+
+func TestNewInscriptionReveal(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	inscription := NewInscription("text/plain", []byte("hello"))
+	reveal, err := NewInscriptionReveal(inscription, privKey.PubKey(), make([]byte, 34), 546, 2, &chaincfg.SigNetParams, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(reveal.Tx.TxIn))
+	require.Nil(t, reveal.Tx.TxIn[0].Witness)
+	require.Greater(t, reveal.Fee, int64(0))
+
+	// the parent is placed before the commit input and the inscription output
+	parentIn := wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil)
+	parentOut := wire.NewTxOut(546, make([]byte, 34))
+	reveal, err = NewInscriptionReveal(inscription, privKey.PubKey(), make([]byte, 34), 546, 50, &chaincfg.SigNetParams,
+		[]*wire.TxIn{parentIn}, []*wire.TxOut{parentOut})
+	require.NoError(t, err)
+	require.Equal(t, 1, reveal.CommitIndex)
+	require.Equal(t, parentIn, reveal.Tx.TxIn[0])
+	require.Equal(t, parentOut, reveal.Tx.TxOut[0])
+	reveal.SetCommitOutPoint(chainhash.Hash{2}, 0)
+	require.Equal(t, chainhash.Hash{2}, reveal.Tx.TxIn[1].PreviousOutPoint.Hash)
+
+	_, err = NewInscriptionReveal(inscription, privKey.PubKey(), make([]byte, 34), 546, 2, &chaincfg.SigNetParams,
+		[]*wire.TxIn{parentIn}, nil)
+	require.Error(t, err)
+}
@@ -2,7 +2,9 @@ package ordinal
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
+
 	"github.com/btcsuite/btcd/txscript"
 )
 
@@ -10,6 +12,12 @@ type Ord struct {
 	Type        string
 	ContentType string
 	Content     []byte
+
+	Parents         []string
+	Metadata        []byte
+	Metaprotocol    string
+	ContentEncoding string
+	Pointer         *uint64
 }
 
 func DecodeOrdFromWitness(witness []byte) (*Ord, error) {
@@ -29,34 +37,75 @@ func DecodeOrdFromWitness(witness []byte) (*Ord, error) {
 		if !internalTokenizer.Next() {
 			continue
 		}
-		if !bytes.Equal([]byte("ord"), internalTokenizer.Data()) {
+		if !bytes.Equal([]byte(protocolId), internalTokenizer.Data()) {
 			continue
 		}
-		if !internalTokenizer.Next() {
-			continue
+		if ord := decodeEnvelopeFields(&internalTokenizer); ord != nil {
+			return ord, nil
 		}
-		if !bytes.Equal([]byte{1}, internalTokenizer.Data()) {
-			continue
+	}
+	return nil, errors.New("not found ord")
+}
+
+// decodeEnvelopeFields
+// Decode the fields after the protocol id until OP_ENDIF, return nil if the envelope is invalid.
+func decodeEnvelopeFields(tokenizer *txscript.ScriptTokenizer) *Ord {
+	fields := make(map[byte][][]byte)
+	var body []byte
+	hasBody := false
+	for tokenizer.Next() {
+		opcode := tokenizer.Opcode()
+		if opcode == txscript.OP_ENDIF {
+			return newOrdWithFields(fields, body)
 		}
-		if !internalTokenizer.Next() {
+		if hasBody {
+			if opcode > txscript.OP_PUSHDATA4 {
+				return nil
+			}
+			body = append(body, tokenizer.Data()...)
 			continue
 		}
-		contentType := string(internalTokenizer.Data())
-		if !internalTokenizer.Next() {
+		if opcode == txscript.OP_0 {
+			hasBody = true
+			body = []byte{}
 			continue
 		}
-		if internalTokenizer.Opcode() != txscript.OP_0 {
-			continue
+		tag := tokenizer.Data()
+		if len(tag) != 1 || !tokenizer.Next() || tokenizer.Opcode() > txscript.OP_PUSHDATA4 {
+			return nil
 		}
-		if !internalTokenizer.Next() {
-			continue
+		fields[tag[0]] = append(fields[tag[0]], tokenizer.Data())
+	}
+	return nil
+}
+
+func newOrdWithFields(fields map[byte][][]byte, body []byte) *Ord {
+	ord := &Ord{
+		Type:    protocolId,
+		Content: body,
+	}
+	first := func(tag byte) []byte {
+		if values := fields[tag]; len(values) > 0 {
+			return values[0]
 		}
-		content := internalTokenizer.Data()
-		return &Ord{
-			Type:        "ord",
-			ContentType: contentType,
-			Content:     content,
-		}, nil
+		return nil
 	}
-	return nil, errors.New("not found ord")
+	ord.ContentType = string(first(TagContentType))
+	ord.Metaprotocol = string(first(TagMetaprotocol))
+	ord.ContentEncoding = string(first(TagContentEncoding))
+	for _, chunk := range fields[TagMetadata] {
+		ord.Metadata = append(ord.Metadata, chunk...)
+	}
+	for _, parent := range fields[TagParent] {
+		if id, err := DecodeInscriptionId(parent); err == nil {
+			ord.Parents = append(ord.Parents, id)
+		}
+	}
+	if pointer := first(TagPointer); pointer != nil && len(pointer) <= 8 {
+		data := make([]byte, 8)
+		copy(data, pointer)
+		value := binary.LittleEndian.Uint64(data)
+		ord.Pointer = &value
+	}
+	return ord
 }
@@ -1,24 +1,104 @@
 package runes
 
 import (
+	"errors"
 	"math/big"
+	"math/bits"
 	"strings"
 )
 
+var (
+	ErrIllegalRuneName = errors.New("illegal rune name")
+)
+
 type Rune struct {
 	big.Int //uint128
 }
 
+// NewRuneFromString
+// @param name the rune name only contains letters A-Z, e.g. "UNCOMMONGOODS"
+func NewRuneFromString(name string) (*Rune, error) {
+	if name == "" {
+		return nil, ErrIllegalRuneName
+	}
+	x := big.NewInt(0)
+	for i, c := range name {
+		if c < 'A' || c > 'Z' {
+			return nil, ErrIllegalRuneName
+		}
+		if i > 0 {
+			x.Add(x, big.NewInt(1))
+		}
+		x.Mul(x, big.NewInt(26))
+		x.Add(x, big.NewInt(int64(c-'A')))
+		if x.BitLen() > 128 {
+			return nil, ErrIllegalRuneName
+		}
+	}
+	return &Rune{*x}, nil
+}
+
+// NewSpacedRuneFromString
+// @param name the rune name with spacers, e.g. "UNCOMMON•GOODS", the spacer can be '•' or '.'
+// @return the rune and the spacers bit field
+func NewSpacedRuneFromString(name string) (*Rune, uint32, error) {
+	letters := strings.Builder{}
+	count := 0
+	spacers := uint32(0)
+	for _, c := range name {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			letters.WriteRune(c)
+			count++
+		case c == '.' || c == '•':
+			if count == 0 {
+				return nil, 0, ErrIllegalRuneName
+			}
+			flag := uint32(1) << (count - 1)
+			if spacers&flag != 0 {
+				return nil, 0, ErrIllegalRuneName
+			}
+			spacers |= flag
+		default:
+			return nil, 0, ErrIllegalRuneName
+		}
+	}
+	if spacers != 0 && 32-bits.LeadingZeros32(spacers) >= count {
+		// trailing spacer
+		return nil, 0, ErrIllegalRuneName
+	}
+	r, err := NewRuneFromString(letters.String())
+	if err != nil {
+		return nil, 0, err
+	}
+	return r, spacers, nil
+}
+
 func (r *Rune) String() string {
 	n := new(big.Int).Set(&r.Int)
 	if n.Cmp(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))) == 0 {
 		return "BCGDENLQRQWDSLRUGSNLBTMFIJAV"
 	}
-	n = n.And(n, big.NewInt(1))
-	symbol := strings.Builder{}
+	n = n.Add(n, big.NewInt(1))
+	var symbol []byte
 	for n.Cmp(big.NewInt(0)) > 0 {
-		symbol.WriteByte("ABCDEFGHIJKLMNOPQRSTUVWXYZ"[new(big.Int).Mod(n.Sub(n, big.NewInt(1)), big.NewInt(26)).Int64()])
-		n = n.Div(n.Sub(n, big.NewInt(1)), big.NewInt(26))
+		n = n.Sub(n, big.NewInt(1))
+		symbol = append(symbol, "ABCDEFGHIJKLMNOPQRSTUVWXYZ"[new(big.Int).Mod(n, big.NewInt(26)).Int64()])
+		n = n.Div(n, big.NewInt(26))
+	}
+	for i, j := 0, len(symbol)-1; i < j; i, j = i+1, j-1 {
+		symbol[i], symbol[j] = symbol[j], symbol[i]
+	}
+	return string(symbol)
+}
+
+// Commitment
+// The little-endian bytes of the rune without trailing zero, which should be pushed in the tapscript of the etching input.
+func (r *Rune) Commitment() []byte {
+	be := r.Int.Bytes()
+	le := make([]byte, len(be))
+	for i, b := range be {
+		le[len(be)-1-i] = b
 	}
-	return symbol.String()
+	return le
 }
@@ -23,7 +23,7 @@ func (r *RuneId) Next(block big.Int, tx big.Int) *RuneId {
 	}
 	newBlock := r.Block + block.Uint64()
 	var newTx uint32
-	if newBlock == 0 {
+	if block.Sign() == 0 {
 		newTx = r.Tx + uint32(tx.Uint64())
 	} else {
 		newTx = uint32(tx.Uint64())
@@ -50,3 +50,22 @@ func NewRuneIdFromStr(runeIdStr string) (*RuneId, error) {
 	}
 	return &RuneId{block, uint32(tx)}, nil
 }
+
+// Delta
+// The delta encoding of edict rune ids, the next must be greater than or equal to the current.
+func (r *RuneId) Delta(next RuneId) (block uint64, tx uint32, err error) {
+	if next.Block < r.Block || (next.Block == r.Block && next.Tx < r.Tx) {
+		return 0, 0, errors.New("rune id must be in ascending order")
+	}
+	block = next.Block - r.Block
+	if block == 0 {
+		tx = next.Tx - r.Tx
+	} else {
+		tx = next.Tx
+	}
+	return block, tx, nil
+}
+
+func (r RuneId) less(other RuneId) bool {
+	return r.Block < other.Block || (r.Block == other.Block && r.Tx < other.Tx)
+}
@@ -270,3 +270,121 @@ func payload(transaction *wire.MsgTx) (Payload, error) {
 	}
 	return nil, ErrNotFoundPayload
 }
+
+// Encipher encode the runestone to the OP_RETURN output script.
+func (r Runestone) Encipher() ([]byte, error) {
+	var payload []byte
+	encodeTag := func(tag runestone.Tag, values ...*big.Int) {
+		for _, value := range values {
+			payload = append(payload, Encode(tag.ToBigInt())...)
+			payload = append(payload, Encode(value)...)
+		}
+	}
+	uint64Ptr := func(v *uint64) *big.Int {
+		if v == nil {
+			return nil
+		}
+		return new(big.Int).SetUint64(*v)
+	}
+	encodeOption := func(tag runestone.Tag, value *big.Int) {
+		if value != nil {
+			encodeTag(tag, value)
+		}
+	}
+
+	if etching := r.Etching; etching != nil {
+		flags := runestone.Etching.Set(big.NewInt(0))
+		if etching.Terms != nil {
+			flags = runestone.Terms.Set(flags)
+		}
+		if etching.Turbo {
+			flags = runestone.Turbo.Set(flags)
+		}
+		encodeTag(runestone.Flags, flags)
+		if etching.Rune != nil {
+			encodeTag(runestone.Rune, &etching.Rune.Int)
+		}
+		if etching.Divisibility != nil {
+			if *etching.Divisibility > MaxDivisibility {
+				return nil, errors.New("divisibility too large")
+			}
+			encodeTag(runestone.Divisibility, big.NewInt(int64(*etching.Divisibility)))
+		}
+		if etching.Spacers != nil {
+			if *etching.Spacers > MaxSpacers {
+				return nil, errors.New("spacers too large")
+			}
+			encodeTag(runestone.Spacers, big.NewInt(int64(*etching.Spacers)))
+		}
+		if etching.Symbol != nil {
+			encodeTag(runestone.Symbol, big.NewInt(int64(*etching.Symbol)))
+		}
+		encodeOption(runestone.Premine, etching.Premine)
+		if terms := etching.Terms; terms != nil {
+			encodeOption(runestone.Amount, terms.Amount)
+			encodeOption(runestone.Cap, terms.Cap)
+			encodeOption(runestone.HeightStart, uint64Ptr(terms.Height[0]))
+			encodeOption(runestone.HeightEnd, uint64Ptr(terms.Height[1]))
+			encodeOption(runestone.OffsetStart, uint64Ptr(terms.Offset[0]))
+			encodeOption(runestone.OffsetEnd, uint64Ptr(terms.Offset[1]))
+		}
+		if etching.Supply() == nil {
+			return nil, errors.New("supply overflow")
+		}
+	}
+
+	if r.Mint != nil {
+		encodeTag(runestone.Mint, new(big.Int).SetUint64(r.Mint.Block), big.NewInt(int64(r.Mint.Tx)))
+	}
+	if r.Pointer != nil {
+		encodeTag(runestone.Pointer, big.NewInt(int64(*r.Pointer)))
+	}
+
+	if len(r.Edicts) > 0 {
+		payload = append(payload, Encode(runestone.Body.ToBigInt())...)
+		edicts := slices.Clone(r.Edicts)
+		slices.SortStableFunc(edicts, func(a, b Edict) int {
+			switch {
+			case a.Id.less(b.Id):
+				return -1
+			case b.Id.less(a.Id):
+				return 1
+			}
+			return 0
+		})
+		previous := RuneId{}
+		for _, edict := range edicts {
+			block, tx, err := previous.Delta(edict.Id)
+			if err != nil {
+				return nil, err
+			}
+			payload = append(payload, Encode(new(big.Int).SetUint64(block))...)
+			payload = append(payload, Encode(big.NewInt(int64(tx)))...)
+			payload = append(payload, Encode(&edict.Amount)...)
+			payload = append(payload, Encode(big.NewInt(int64(edict.Output)))...)
+			previous = edict.Id
+		}
+	}
+
+	// The payload must be pushed as data, so the txscript.ScriptBuilder cannot be used,
+	// it will convert the small data to the OP_1 ~ OP_16.
+	script := []byte{txscript.OP_RETURN, MagicNumber}
+	for len(payload) > 0 {
+		size := min(len(payload), txscript.MaxScriptElementSize)
+		script = append(script, pushDataOpcode(size)...)
+		script = append(script, payload[:size]...)
+		payload = payload[size:]
+	}
+	return script, nil
+}
+
+func pushDataOpcode(size int) []byte {
+	switch {
+	case size <= txscript.OP_DATA_75:
+		return []byte{byte(size)}
+	case size <= 0xff:
+		return []byte{txscript.OP_PUSHDATA1, byte(size)}
+	default:
+		return []byte{txscript.OP_PUSHDATA2, byte(size), byte(size >> 8)}
+	}
+}
@@ -22,3 +22,8 @@ func (f Flag) Take(flags *big.Int) bool {
 	flags.Set(new(big.Int).AndNot(flags, mask))
 	return set
 }
+
+// Set return a new flags with the flag set.
+func (f Flag) Set(flags *big.Int) *big.Int {
+	return new(big.Int).Or(flags, f.mask())
+}
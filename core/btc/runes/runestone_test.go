@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
 	"math/big"
 	"reflect"
+	"slices"
 	"testing"
 )
 
@@ -71,3 +74,130 @@ func TestBigInt(t *testing.T) {
 	//maxU128.Add(maxU128, big.NewInt(0xFFFFFFFFFFFFFFFF))
 	t.Log(new(big.Int).Sub(big.NewInt(1).Lsh(maxU128, 128), big.NewInt(1)).String())
 }
+
+func decodeTestTx(t *testing.T, txHex string) *wire.MsgTx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	raw, err := hex.DecodeString(txHex)
+	require.NoError(t, err)
+	require.NoError(t, tx.Deserialize(bytes.NewReader(raw)))
+	return tx
+}
+
+func TestRunestone_Encipher(t *testing.T) {
+	// The scripts of the mainnet transactions should be same after decipher and encipher
+	txs := []string{
+		"02000000000101b9f7ce308b96e917f5337461069960f876bb50b641027ebe66e7bbe09c240eb40000000000fdffffff021027000000000000160014c77e5d18cbd54cbfbee1a5323cca75eba7b218b800000000000000000b6a5d0814a6e09d01148f010140e55ccace7732812d3082f8e8c48e233d378bc45bf5395e7677abd72dd81854e028affc95e77bbf96afaa5047e31d1ec6f776c653113a69ab0a1ad930fd7efd0300000000",
+	}
+	for _, txHex := range txs {
+		tx := decodeTestTx(t, txHex)
+		artifact, err := Decipher(tx)
+		require.NoError(t, err)
+		stone, ok := artifact.(Runestone)
+		require.True(t, ok)
+		script, err := stone.Encipher()
+		require.NoError(t, err)
+		require.Equal(t, hex.EncodeToString(tx.TxOut[1].PkScript), hex.EncodeToString(script))
+	}
+}
+
+func TestRunestone_EncipherRoundTrip(t *testing.T) {
+	divisibility := uint8(2)
+	symbol := '¢'
+	heightStart, offsetEnd := uint64(840000), uint64(10000)
+	rune, spacers, err := NewSpacedRuneFromString("UNCOMMON•GOODS")
+	require.NoError(t, err)
+	pointer := uint32(1)
+
+	stones := []Runestone{
+		{
+			Edicts: []Edict{
+				{Id: RuneId{840000, 3}, Amount: *big.NewInt(100), Output: 0},
+				{Id: RuneId{1, 1}, Amount: *big.NewInt(1000000), Output: 1},
+				{Id: RuneId{840000, 1}, Amount: *big.NewInt(0), Output: 2},
+			},
+			Pointer: &pointer,
+		},
+		{
+			Mint:    &RuneId{2585189, 204},
+			Pointer: &pointer,
+		},
+		{
+			Etching: &Etching{
+				Divisibility: &divisibility,
+				Premine:      big.NewInt(1000),
+				Rune:         rune,
+				Spacers:      &spacers,
+				Symbol:       &symbol,
+				Terms: &Terms{
+					Amount: big.NewInt(100),
+					Cap:    big.NewInt(1111111),
+					Height: [2]*uint64{&heightStart, nil},
+					Offset: [2]*uint64{nil, &offsetEnd},
+				},
+				Turbo: true,
+			},
+		},
+	}
+	for _, stone := range stones {
+		script, err := stone.Encipher()
+		require.NoError(t, err)
+
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxOut(wire.NewTxOut(0, script))
+		tx.AddTxOut(wire.NewTxOut(546, []byte{txscript.OP_TRUE}))
+		tx.AddTxOut(wire.NewTxOut(546, []byte{txscript.OP_TRUE}))
+		artifact, err := Decipher(tx)
+		require.NoError(t, err)
+		decoded, ok := artifact.(Runestone)
+		require.True(t, ok, "%+v", artifact)
+
+		expectEdicts := slices.Clone(stone.Edicts)
+		slices.SortStableFunc(expectEdicts, func(a, b Edict) int {
+			if a.Id.less(b.Id) {
+				return -1
+			}
+			return 1
+		})
+		require.Equal(t, len(expectEdicts), len(decoded.Edicts))
+		for i := range expectEdicts {
+			require.Equal(t, expectEdicts[i].Id, decoded.Edicts[i].Id)
+			require.Equal(t, expectEdicts[i].Output, decoded.Edicts[i].Output)
+			require.Zero(t, expectEdicts[i].Amount.Cmp(&decoded.Edicts[i].Amount))
+		}
+		require.Equal(t, stone.Mint, decoded.Mint)
+		require.Equal(t, stone.Pointer, decoded.Pointer)
+		if stone.Etching != nil {
+			require.NotNil(t, decoded.Etching)
+			require.Equal(t, "UNCOMMONGOODS", decoded.Etching.Rune.String())
+			require.Equal(t, *stone.Etching.Spacers, *decoded.Etching.Spacers)
+			require.Equal(t, *stone.Etching.Symbol, *decoded.Etching.Symbol)
+			require.Equal(t, *stone.Etching.Divisibility, *decoded.Etching.Divisibility)
+			require.Zero(t, stone.Etching.Premine.Cmp(decoded.Etching.Premine))
+			require.Zero(t, stone.Etching.Terms.Cap.Cmp(decoded.Etching.Terms.Cap))
+			require.Zero(t, stone.Etching.Terms.Amount.Cmp(decoded.Etching.Terms.Amount))
+			require.Equal(t, stone.Etching.Terms.Height, decoded.Etching.Terms.Height)
+			require.Equal(t, stone.Etching.Terms.Offset, decoded.Etching.Terms.Offset)
+			require.True(t, decoded.Etching.Turbo)
+		}
+	}
+}
+
+func TestRune_String(t *testing.T) {
+	for _, name := range []string{"A", "Z", "AA", "UNCOMMONGOODS", "BCGDENLQRQWDSLRUGSNLBTMFIJAU"} {
+		r, err := NewRuneFromString(name)
+		require.NoError(t, err)
+		require.Equal(t, name, r.String())
+	}
+	r, err := NewRuneFromString("AA")
+	require.NoError(t, err)
+	require.Equal(t, int64(26), r.Int64())
+	require.Equal(t, []byte{26}, r.Commitment())
+
+	_, spacers, err := NewSpacedRuneFromString("UNCOMMON•GOODS")
+	require.NoError(t, err)
+	require.Equal(t, uint32(128), spacers)
+	_, _, err = NewSpacedRuneFromString("UNCOMMON•")
+	require.Error(t, err)
+	_, _, err = NewSpacedRuneFromString("abc")
+	require.Error(t, err)
+}
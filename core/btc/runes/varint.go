@@ -41,3 +41,14 @@ func tryDecode(buffer []byte) (*big.Int, int, error) {
 	}
 	return nil, 0, ErrUnterminated
 }
+
+// Encode the uint128 integer to LEB128 varint
+func Encode(n *big.Int) []byte {
+	value := new(big.Int).Set(n)
+	var buffer []byte
+	for value.BitLen() > 7 {
+		buffer = append(buffer, byte(value.Uint64()&0b0111_1111)|0b1000_0000)
+		value.Rsh(value, 7)
+	}
+	return append(buffer, byte(value.Uint64()))
+}
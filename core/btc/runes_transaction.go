@@ -0,0 +1,390 @@
+package btc
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/btc/ordinal"
+	"github.com/coming-chat/wallet-SDK/core/btc/runes"
+	"github.com/coming-chat/wallet-SDK/util/hexutil"
+)
+
+const (
+	// The value of the output that receives runes.
+	RunesPostage = 546
+
+	// The etching input must be spent at least 6 blocks after the commit transaction confirmed.
+	runesCommitConfirmations = 6
+)
+
+// BuildRunesTransferPsbt
+// The runes will be transferred to the first output, and the remaining runes will return to the sender.
+// @param runeId like `840000:3`
+// @param amount the amount of rune without decimal
+// @param feeRate sat/vB, the suggest average fee rate will be used if it <= 0
+func (c *Chain) BuildRunesTransferPsbt(sender, receiver, runeId, amount string, feeRate int64) (txn *PsbtTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	id, err := runes.NewRuneIdFromStr(runeId)
+	if err != nil {
+		return
+	}
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok || value.Sign() <= 0 {
+		return nil, errors.New("invalid runes amount")
+	}
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	runesUtxos, err := c.fetchRunesUtxos(sender, runeId)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchSpendableUtxos(sender, nil)
+	if err != nil {
+		return
+	}
+	transaction, err := buildRunesTransfer(c.Chainnet, sender, receiver, *id, value, feeRate, runesUtxos, utxos)
+	if err != nil {
+		return
+	}
+	return transaction.ToPsbtTransaction()
+}
+
+// BuildRunesMintPsbt
+// @param runeId like `840000:3`
+// @param feeRate sat/vB, the suggest average fee rate will be used if it <= 0
+func (c *Chain) BuildRunesMintPsbt(sender, receiver, runeId string, feeRate int64) (txn *PsbtTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	id, err := runes.NewRuneIdFromStr(runeId)
+	if err != nil {
+		return
+	}
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchSpendableUtxos(sender, nil)
+	if err != nil {
+		return
+	}
+	transaction, err := buildRunesMint(c.Chainnet, sender, receiver, *id, feeRate, utxos)
+	if err != nil {
+		return
+	}
+	return transaction.ToPsbtTransaction()
+}
+
+// BuildRunesEtchTransaction
+// The etching needs two transactions, the commit transaction sends the btc to a taproot address committed to the rune name,
+// and the reveal transaction can be published after the commit transaction has 6 confirmations.
+// The premine runes will be sent to the sender.
+// @param senderPubkey the public key of the sender account, which is used to sign the reveal transaction.
+// @param feeRate sat/vB, the suggest average fee rate will be used if it <= 0
+func (c *Chain) BuildRunesEtchTransaction(sender, senderPubkey string, params *RunesEtchParams, feeRate int64) (txn *RunesEtchTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	pubData, err := hexutil.HexDecodeString(senderPubkey)
+	if err != nil {
+		return
+	}
+	pubkey, err := btcec.ParsePubKey(pubData)
+	if err != nil {
+		return
+	}
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchSpendableUtxos(sender, nil)
+	if err != nil {
+		return
+	}
+	return buildRunesEtch(c.Chainnet, sender, pubkey, params, feeRate, utxos)
+}
+
+func buildRunesTransfer(chainnet string, sender, receiver string, runeId runes.RuneId, amount *big.Int, feeRate int64,
+	runesUtxos []*RunesUTXO, utxos []*UTXO) (*Transaction, error) {
+	txn, err := NewTransaction(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	senderAddr, err := btcutil.DecodeAddress(sender, txn.netParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// Spend the utxos with most runes first.
+	idString := runeId.String()
+	sort.SliceStable(runesUtxos, func(i, j int) bool {
+		return runesUtxos[i].RuneAmount(idString).Cmp(runesUtxos[j].RuneAmount(idString)) > 0
+	})
+	total := big.NewInt(0)
+	needChange := false
+	for _, utxo := range runesUtxos {
+		if total.Cmp(amount) >= 0 {
+			break
+		}
+		runeAmount := utxo.RuneAmount(idString)
+		if runeAmount.Sign() <= 0 {
+			continue
+		}
+		if err = txn.AddInput(utxo.Txid, utxo.Vout, sender, utxo.Satoshi); err != nil {
+			return nil, err
+		}
+		total.Add(total, runeAmount)
+		for _, r := range utxo.Runes {
+			if r.RuneId != idString {
+				needChange = true // other runes held in the same utxo
+			}
+		}
+	}
+	if total.Cmp(amount) < 0 {
+		return nil, errors.New("insufficient runes balance")
+	}
+	if total.Cmp(amount) > 0 {
+		needChange = true
+	}
+
+	if err = txn.AddOutput(receiver, RunesPostage); err != nil {
+		return nil, err
+	}
+	runestone := runes.Runestone{
+		Edicts: []runes.Edict{{Id: runeId, Amount: *amount, Output: 0}},
+	}
+	if needChange {
+		// The unallocated runes will be sent to the pointer output.
+		if err = txn.AddOutput(sender, RunesPostage); err != nil {
+			return nil, err
+		}
+		pointer := uint32(1)
+		runestone.Pointer = &pointer
+	}
+	script, err := runestone.Encipher()
+	if err != nil {
+		return nil, err
+	}
+	txn.addOutputScript(script, 0)
+
+	// The utxos that hold runes cannot be used to pay the network fee.
+	payments := make([]*UTXO, 0, len(utxos))
+	for _, utxo := range utxos {
+		if indexOfRunesUtxo(runesUtxos, utxo) < 0 {
+			payments = append(payments, utxo)
+		}
+	}
+	if err = fundTransaction(txn, senderAddr, payments, feeRate); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+func indexOfRunesUtxo(runesUtxos []*RunesUTXO, utxo *UTXO) int {
+	for i, r := range runesUtxos {
+		if r.Txid == utxo.Txid && r.Vout == utxo.Vout {
+			return i
+		}
+	}
+	return -1
+}
+
+func buildRunesMint(chainnet string, sender, receiver string, runeId runes.RuneId, feeRate int64, utxos []*UTXO) (*Transaction, error) {
+	txn, err := NewTransaction(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	senderAddr, err := btcutil.DecodeAddress(sender, txn.netParams)
+	if err != nil {
+		return nil, err
+	}
+	if err = txn.AddOutput(receiver, RunesPostage); err != nil {
+		return nil, err
+	}
+	pointer := uint32(0)
+	script, err := runes.Runestone{Mint: &runeId, Pointer: &pointer}.Encipher()
+	if err != nil {
+		return nil, err
+	}
+	txn.addOutputScript(script, 0)
+	if err = fundTransaction(txn, senderAddr, utxos, feeRate); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+func (p *RunesEtchParams) toEtching() (*runes.Etching, error) {
+	etching := &runes.Etching{Turbo: p.Turbo}
+	if p.Rune != "" {
+		r, spacers, err := runes.NewSpacedRuneFromString(p.Rune)
+		if err != nil {
+			return nil, err
+		}
+		etching.Rune = r
+		if spacers != 0 {
+			etching.Spacers = &spacers
+		}
+	}
+	if p.Symbol != "" {
+		symbol, _ := utf8.DecodeRuneInString(p.Symbol)
+		etching.Symbol = &symbol
+	}
+	if p.Divisibility < 0 || p.Divisibility > int(runes.MaxDivisibility) {
+		return nil, errors.New("invalid divisibility")
+	}
+	if p.Divisibility > 0 {
+		divisibility := uint8(p.Divisibility)
+		etching.Divisibility = &divisibility
+	}
+	parseAmount := func(s string) (*big.Int, error) {
+		if s == "" {
+			return nil, nil
+		}
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok || n.Sign() < 0 {
+			return nil, errors.New("invalid number: " + s)
+		}
+		return n, nil
+	}
+	var err error
+	if etching.Premine, err = parseAmount(p.Premine); err != nil {
+		return nil, err
+	}
+	if p.Amount != "" || p.Cap != "" {
+		terms := &runes.Terms{}
+		if terms.Amount, err = parseAmount(p.Amount); err != nil {
+			return nil, err
+		}
+		if terms.Cap, err = parseAmount(p.Cap); err != nil {
+			return nil, err
+		}
+		optional := func(v int64) *uint64 {
+			if v <= 0 {
+				return nil
+			}
+			u := uint64(v)
+			return &u
+		}
+		terms.Height = [2]*uint64{optional(p.HeightStart), optional(p.HeightEnd)}
+		terms.Offset = [2]*uint64{optional(p.OffsetStart), optional(p.OffsetEnd)}
+		etching.Terms = terms
+	}
+	if etching.Supply() == nil {
+		return nil, errors.New("supply overflow")
+	}
+	return etching, nil
+}
+
+// runesCommitScript
+// <pubkey> OP_CHECKSIG OP_FALSE OP_IF <rune commitment> OP_ENDIF
+func runesCommitScript(pubkey *btcec.PublicKey, r *runes.Rune) ([]byte, error) {
+	builder := txscript.NewScriptBuilder().
+		AddData(schnorr.SerializePubKey(pubkey)).AddOp(txscript.OP_CHECKSIG)
+	if r != nil {
+		builder.AddOp(txscript.OP_FALSE).AddOp(txscript.OP_IF).
+			AddData(r.Commitment()).
+			AddOp(txscript.OP_ENDIF)
+	}
+	return builder.Script()
+}
+
+func buildRunesEtch(chainnet string, sender string, pubkey *btcec.PublicKey, params *RunesEtchParams, feeRate int64, utxos []*UTXO) (*RunesEtchTransaction, error) {
+	if params == nil {
+		return nil, errors.New("invalid etching params")
+	}
+	etching, err := params.toEtching()
+	if err != nil {
+		return nil, err
+	}
+	pointer := uint32(0)
+	runestone, err := runes.Runestone{Etching: etching, Pointer: &pointer}.Encipher()
+	if err != nil {
+		return nil, err
+	}
+
+	commitTxn, err := NewTransaction(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	senderAddr, err := btcutil.DecodeAddress(sender, commitTxn.netParams)
+	if err != nil {
+		return nil, err
+	}
+	script, err := runesCommitScript(pubkey, etching.Rune)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := ordinal.NewTapscriptCommit(pubkey, script, commitTxn.netParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// The reveal transaction, it's input will be filled after the commit transaction funded.
+	reveal := wire.NewMsgTx(2) // BIP68 relative lock time requires version 2
+	revealIn := wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0), nil, commit.DummyWitness())
+	revealIn.Sequence = runesCommitConfirmations - 1
+	reveal.AddTxIn(revealIn)
+	senderScript, err := txscript.PayToAddrScript(senderAddr)
+	if err != nil {
+		return nil, err
+	}
+	reveal.AddTxOut(wire.NewTxOut(RunesPostage, senderScript))
+	reveal.AddTxOut(wire.NewTxOut(0, runestone))
+	revealFee := feeRate * mempool.GetTxVirtualSize(btcutil.NewTx(reveal))
+	revealIn.Witness = nil
+
+	commitTxn.addOutputScript(commit.PkScript, RunesPostage+revealFee)
+	if err = fundTransaction(commitTxn, senderAddr, utxos, feeRate); err != nil {
+		return nil, err
+	}
+	commitPsbt, err := commitTxn.ToPsbtTransaction()
+	if err != nil {
+		return nil, err
+	}
+	revealIn.PreviousOutPoint = wire.OutPoint{Hash: commitTxn.msgTx.TxHash(), Index: 0}
+
+	return &RunesEtchTransaction{
+		Commit:        commitPsbt,
+		CommitFee:     commitTxn.TotalInputValue() - commitTxn.TotalOutputValue(),
+		RevealFee:     revealFee,
+		CommitAddress: commit.Address,
+
+		commitReveal: &commitReveal{
+			commitPsbt: commitPsbt,
+			commit:     commit,
+			reveal:     reveal,
+		},
+	}, nil
+}
+
+type RunesEtchTransaction struct {
+	// The commit psbt
+	Commit        *PsbtTransaction `json:"-"`
+	CommitFee     int64            `json:"commitFee"`
+	RevealFee     int64            `json:"revealFee"`
+	CommitAddress string           `json:"commitAddress"`
+
+	commitReveal *commitReveal
+}
+
+func (t *RunesEtchTransaction) SignWithAccount(account base.Account) (signedTxn *base.OptionalString, err error) {
+	return nil, base.ErrUnsupportedFunction
+}
+
+// SignedTransactionWithAccount
+// Sign the commit psbt and the reveal transaction.
+func (t *RunesEtchTransaction) SignedTransactionWithAccount(account base.Account) (signedTxn base.SignedTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+	return t.commitReveal.signWithAccount(account)
+}
@@ -0,0 +1,94 @@
+package btc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/coming-chat/wallet-SDK/core/btc/runes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRunesTransfer(t *testing.T) {
+	account, err := NewAccountWithMnemonic("antenna chaos arrive hungry distance human question history decade deal impose color", ChainSignet, AddressTypeTaproot)
+	require.NoError(t, err)
+	sender := account.Address()
+	receiver := "tb1pqtguh4mt0206qr7t3pze5zf4st4v3xtvqfhgv7q7j6ymnv7gtutqy4nrud"
+	runeId := runes.RuneId{Block: 840000, Tx: 3}
+
+	utxos := newTestUtxos(546, 546, 50000)
+	runesUtxos := []*RunesUTXO{
+		{Txid: utxos[0].Txid, Vout: 0, Satoshi: 546, Runes: []*RunesBalance{{RuneId: "840000:3", Amount: "100"}}},
+		{Txid: utxos[1].Txid, Vout: 1, Satoshi: 546, Runes: []*RunesBalance{{RuneId: "840000:3", Amount: "300"}}},
+	}
+
+	txn, err := buildRunesTransfer(ChainSignet, sender, receiver, runeId, big.NewInt(250), 3, runesUtxos, utxos)
+	require.NoError(t, err)
+	// rune input + fee input
+	require.Equal(t, 2, len(txn.msgTx.TxIn))
+	require.Equal(t, uint32(1), txn.msgTx.TxIn[0].PreviousOutPoint.Index)
+	artifact, err := runes.Decipher(txn.msgTx)
+	require.NoError(t, err)
+	stone := artifact.(runes.Runestone)
+	require.Equal(t, 1, len(stone.Edicts))
+	require.Equal(t, uint32(0), stone.Edicts[0].Output)
+	require.Equal(t, int64(250), stone.Edicts[0].Amount.Int64())
+	require.Equal(t, uint32(1), *stone.Pointer)
+
+	psbtTxn, err := txn.ToPsbtTransaction()
+	require.NoError(t, err)
+	_, err = psbtTxn.SignedTransactionWithAccount(account)
+	require.NoError(t, err)
+
+	_, err = buildRunesTransfer(ChainSignet, sender, receiver, runeId, big.NewInt(401), 3, runesUtxos, utxos)
+	require.Error(t, err)
+}
+
+func TestBuildRunesMint(t *testing.T) {
+	sender := "tb1pqtguh4mt0206qr7t3pze5zf4st4v3xtvqfhgv7q7j6ymnv7gtutqy4nrud"
+	runeId := runes.RuneId{Block: 2585189, Tx: 204}
+	txn, err := buildRunesMint(ChainSignet, sender, sender, runeId, 2, newTestUtxos(10000))
+	require.NoError(t, err)
+	artifact, err := runes.Decipher(txn.msgTx)
+	require.NoError(t, err)
+	require.Equal(t, runeId, *artifact.(runes.Runestone).Mint)
+	require.Equal(t, int64(RunesPostage), txn.msgTx.TxOut[0].Value)
+}
+
+func TestBuildRunesEtch(t *testing.T) {
+	account, err := NewAccountWithMnemonic("antenna chaos arrive hungry distance human question history decade deal impose color", ChainSignet, AddressTypeNativeSegwit)
+	require.NoError(t, err)
+	params := &RunesEtchParams{
+		Rune:         "WALLET•SDK•TEST•RUNE",
+		Symbol:       "W",
+		Divisibility: 2,
+		Premine:      "1000",
+		Amount:       "100",
+		Cap:          "1000000",
+	}
+	txn, err := buildRunesEtch(ChainSignet, account.Address(), account.privateKey.PubKey(), params, 2, newTestUtxos(20000))
+	require.NoError(t, err)
+
+	signed, err := txn.SignedTransactionWithAccount(account)
+	require.NoError(t, err)
+	signedEtch := signed.(*SignedCommitRevealTransaction)
+	commitTx, err := PsbtPacketToMsgTx(&signedEtch.Commit.Packet)
+	require.NoError(t, err)
+	reveal := signedEtch.Reveal.msgTx
+	require.Equal(t, commitTx.TxHash(), reveal.TxIn[0].PreviousOutPoint.Hash)
+
+	// verify the reveal input script
+	prevOut := commitTx.TxOut[0]
+	fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+	engine, err := txscript.NewEngine(prevOut.PkScript, reveal, 0, txscript.StandardVerifyFlags, nil,
+		txscript.NewTxSigHashes(reveal, fetcher), prevOut.Value, fetcher)
+	require.NoError(t, err)
+	require.NoError(t, engine.Execute())
+
+	artifact, err := runes.Decipher(reveal)
+	require.NoError(t, err)
+	etching := artifact.(runes.Runestone).Etching
+	require.Equal(t, "WALLETSDKTESTRUNE", etching.Rune.String())
+	require.Equal(t, int64(1000), etching.Premine.Int64())
+	require.Contains(t, string(reveal.TxIn[0].Witness[1]), string(etching.Rune.Commitment()))
+}
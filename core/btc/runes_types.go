@@ -0,0 +1,129 @@
+package btc
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/base/inter"
+	"github.com/coming-chat/wallet-SDK/pkg/httpUtil"
+)
+
+type RunesBalance struct {
+	Rune         string `json:"rune"`
+	RuneId       string `json:"runeid"`
+	SpacedRune   string `json:"spacedRune"`
+	Amount       string `json:"amount"`
+	Symbol       string `json:"symbol"`
+	Divisibility int16  `json:"divisibility"`
+}
+
+func (j *RunesBalance) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(j)
+}
+
+type RunesUTXO struct {
+	Txid     string          `json:"txid"`
+	Vout     int64           `json:"vout"`
+	Satoshi  int64           `json:"satoshi"`
+	ScriptPk string          `json:"scriptPk"`
+	Address  string          `json:"address"`
+	Height   int64           `json:"height"`
+	Runes    []*RunesBalance `json:"runes"`
+}
+
+// RuneAmount
+// @return the amount of the specified rune in this utxo, 0 if not found.
+func (u *RunesUTXO) RuneAmount(runeId string) *big.Int {
+	total := big.NewInt(0)
+	for _, r := range u.Runes {
+		if r.RuneId != runeId {
+			continue
+		}
+		if amount, ok := new(big.Int).SetString(r.Amount, 10); ok {
+			total.Add(total, amount)
+		}
+	}
+	return total
+}
+
+func (u *RunesUTXO) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(u)
+}
+
+type RunesUTXOArray struct {
+	inter.AnyArray[*RunesUTXO]
+}
+
+func NewRunesUTXOArrayWithJsonString(str string) (*RunesUTXOArray, error) {
+	var arr []*RunesUTXO
+	err := base.FromJsonString(str, &arr)
+	if err != nil {
+		return nil, err
+	}
+	return &RunesUTXOArray{arr}, nil
+}
+
+// FetchRunesUtxos
+// Query the utxos of the owner that holds the specified rune.
+// @param runeId like `840000:3`
+func (c *Chain) FetchRunesUtxos(owner, runeId string) (arr *RunesUTXOArray, err error) {
+	utxos, err := c.fetchRunesUtxos(owner, runeId)
+	if err != nil {
+		return nil, err
+	}
+	return &RunesUTXOArray{utxos}, nil
+}
+
+func (c *Chain) fetchRunesUtxos(owner, runeId string) (utxos []*RunesUTXO, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+	host, err := unisatHost(c.Chainnet)
+	if err != nil {
+		return
+	}
+
+	header := unisatRequestHeader(owner)
+	url := fmt.Sprintf("%v/v5/runes/utxos?address=%v&runeid=%v", host, owner, runeId)
+	resp, err := httpUtil.Request(http.MethodGet, url, header, nil)
+	if err != nil {
+		return
+	}
+	if err = decodeUnisatResponseV5(*resp, &utxos); err != nil {
+		return
+	}
+	return utxos, nil
+}
+
+// RunesEtchParams
+// The zero value of the optional fields means not set.
+type RunesEtchParams struct {
+	// The rune name with spacers, e.g. "UNCOMMON•GOODS"
+	Rune         string `json:"rune"`
+	Symbol       string `json:"symbol"`
+	Divisibility int    `json:"divisibility"`
+	Premine      string `json:"premine"`
+	Turbo        bool   `json:"turbo"`
+
+	// The open mint terms, will be ignored if the Amount and Cap are empty.
+	Amount      string `json:"amount"`
+	Cap         string `json:"cap"`
+	HeightStart int64  `json:"heightStart"`
+	HeightEnd   int64  `json:"heightEnd"`
+	OffsetStart int64  `json:"offsetStart"`
+	OffsetEnd   int64  `json:"offsetEnd"`
+}
+
+func NewRunesEtchParams() *RunesEtchParams {
+	return &RunesEtchParams{}
+}
+
+func NewRunesEtchParamsWithJsonString(str string) (*RunesEtchParams, error) {
+	var o RunesEtchParams
+	err := base.FromJsonString(str, &o)
+	return &o, err
+}
+
+func (j *RunesEtchParams) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(j)
+}
@@ -0,0 +1,664 @@
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/base/inter"
+	"github.com/coming-chat/wallet-SDK/util/hexutil"
+)
+
+// BIP-352 Silent Payments
+// https://github.com/bitcoin/bips/blob/master/bip-0352.mediawiki
+
+var (
+	ErrInvalidSilentPaymentAddress = errors.New("invalid silent payment address")
+	ErrNoSilentPaymentInputs       = errors.New("the transaction has no input that is eligible for the silent payment")
+	ErrSilentPaymentOutputNotFound = errors.New("the silent payment output of the input is not found")
+
+	silentPaymentInputsTag       = []byte("BIP0352/Inputs")
+	silentPaymentSharedSecretTag = []byte("BIP0352/SharedSecret")
+
+	// The NUMS point H of BIP-341, the taproot input with it as the internal key is not eligible.
+	taprootNumsKey, _ = hex.DecodeString("50929b74c1a04954b78b4b6035e97a5e078a5a0f28ec96d547bfee9ace803ac0")
+)
+
+// silentPaymentHrpOf
+// @return "sp" for mainnet, and "tsp" for the test networks.
+func silentPaymentHrpOf(chain *chaincfg.Params) string {
+	if chain.Net == chaincfg.MainNetParams.Net {
+		return "sp"
+	}
+	return "tsp"
+}
+
+// EncodeSilentPaymentAddress
+// @param scanPubkey the compressed public key for scanning, can start with 0x or not.
+// @param spendPubkey the compressed public key for spending, can start with 0x or not.
+// @return the silent payment address, e.g. "sp1q..."
+func EncodeSilentPaymentAddress(scanPubkey, spendPubkey, chainnet string) (string, error) {
+	chain, err := netParamsOf(chainnet)
+	if err != nil {
+		return "", err
+	}
+	scan, err := parseHexPubkey(scanPubkey)
+	if err != nil {
+		return "", err
+	}
+	spend, err := parseHexPubkey(spendPubkey)
+	if err != nil {
+		return "", err
+	}
+	return encodeSilentPaymentAddress(scan, spend, chain)
+}
+
+func parseHexPubkey(pubkey string) (*btcec.PublicKey, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(pubkey, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	return btcec.ParsePubKey(data)
+}
+
+func encodeSilentPaymentAddress(scan, spend *btcec.PublicKey, chain *chaincfg.Params) (string, error) {
+	keys := append(scan.SerializeCompressed(), spend.SerializeCompressed()...)
+	data, err := bech32.ConvertBits(keys, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	// version 0
+	return bech32.EncodeM(silentPaymentHrpOf(chain), append([]byte{0}, data...))
+}
+
+// IsValidSilentPaymentAddress
+// @return true if the address is the silent payment address of the chainnet, e.g. "sp1q..."
+func IsValidSilentPaymentAddress(address, chainnet string) bool {
+	chain, err := netParamsOf(chainnet)
+	if err != nil {
+		return false
+	}
+	_, _, err = decodeSilentPaymentAddress(address, chain)
+	return err == nil
+}
+
+// decodeSilentPaymentAddress
+// @return the scan public key and the spend public key of the address
+func decodeSilentPaymentAddress(address string, chain *chaincfg.Params) (scan, spend *btcec.PublicKey, err error) {
+	// the address is longer than the bech32 limit (90)
+	hrp, data, err := bech32.DecodeNoLimit(address)
+	if err != nil || hrp != silentPaymentHrpOf(chain) || len(data) == 0 {
+		return nil, nil, ErrInvalidSilentPaymentAddress
+	}
+	// check the checksum is bech32m
+	if encoded, err := bech32.EncodeM(hrp, data); err != nil || encoded != strings.ToLower(address) {
+		return nil, nil, ErrInvalidSilentPaymentAddress
+	}
+	version := data[0]
+	keys, err := bech32.ConvertBits(data[1:], 5, 8, false)
+	switch {
+	case err != nil || version == 31:
+		return nil, nil, ErrInvalidSilentPaymentAddress
+	case version == 0 && len(keys) != 66:
+		return nil, nil, ErrInvalidSilentPaymentAddress
+	case len(keys) < 66:
+		return nil, nil, ErrInvalidSilentPaymentAddress
+	}
+	// the future versions are backward compatible, only the first 66 bytes are used.
+	if scan, err = btcec.ParsePubKey(keys[:33]); err != nil {
+		return nil, nil, ErrInvalidSilentPaymentAddress
+	}
+	if spend, err = btcec.ParsePubKey(keys[33:66]); err != nil {
+		return nil, nil, ErrInvalidSilentPaymentAddress
+	}
+	return scan, spend, nil
+}
+
+// MARK - Sender
+
+type silentPaymentRecipient struct {
+	scan  *btcec.PublicKey
+	spend *btcec.PublicKey
+}
+
+// silentPaymentInputKey
+// @return the private key that the input contributes to the shared secret, the taproot key is negated if its y is odd.
+func silentPaymentInputKey(privKey *btcec.PrivateKey, pkScript []byte) (*btcec.PrivateKey, error) {
+	pubkey := privKey.PubKey().SerializeCompressed()
+	switch {
+	case txscript.IsPayToTaproot(pkScript):
+		for _, key := range []*btcec.PrivateKey{privKey, txscript.TweakTaprootPrivKey(*privKey, nil)} {
+			if bytes.Equal(schnorr.SerializePubKey(key.PubKey()), pkScript[2:]) {
+				if key.PubKey().SerializeCompressed()[0] == 0x03 {
+					var negated btcec.ModNScalar
+					negated.NegateVal(&key.Key)
+					return &btcec.PrivateKey{Key: negated}, nil
+				}
+				return key, nil
+			}
+		}
+	case txscript.IsPayToWitnessPubKeyHash(pkScript), txscript.IsPayToPubKeyHash(pkScript):
+		pkh := btcutil.Hash160(pubkey)
+		if bytes.Contains(pkScript, pkh) {
+			return privKey, nil
+		}
+	case txscript.IsPayToScriptHash(pkScript):
+		redeemScript, err := PayToWitnessPubKeyHashScript(btcutil.Hash160(pubkey))
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(pkScript[2:22], btcutil.Hash160(redeemScript)) {
+			return privKey, nil
+		}
+	}
+	return nil, errors.New("the input is not eligible for the silent payment or not spent by the key")
+}
+
+// silentPaymentInputHash
+// @return hash_BIP0352/Inputs(outpoint_L || A) as a scalar
+func silentPaymentInputHash(outPoints []wire.OutPoint, sumKey *btcec.PublicKey) (*btcec.ModNScalar, error) {
+	var smallest []byte
+	for _, point := range outPoints {
+		data := make([]byte, 36)
+		copy(data, point.Hash[:])
+		binary.LittleEndian.PutUint32(data[32:], point.Index)
+		if smallest == nil || bytes.Compare(data, smallest) < 0 {
+			smallest = data
+		}
+	}
+	hash := chainhash.TaggedHash(silentPaymentInputsTag, smallest, sumKey.SerializeCompressed())
+	var scalar btcec.ModNScalar
+	if overflow := scalar.SetByteSlice(hash[:]); overflow || scalar.IsZero() {
+		return nil, errors.New("invalid silent payment input hash")
+	}
+	return &scalar, nil
+}
+
+// silentPaymentOutputKey
+// @return B_spend + hash_BIP0352/SharedSecret(ecdh_shared_secret || k)·G and the tweak.
+func silentPaymentOutputKey(sharedSecret, spend *btcec.PublicKey, k uint32) (*btcec.PublicKey, *btcec.ModNScalar, error) {
+	var index [4]byte
+	binary.BigEndian.PutUint32(index[:], k)
+	hash := chainhash.TaggedHash(silentPaymentSharedSecretTag, sharedSecret.SerializeCompressed(), index[:])
+	var tweak btcec.ModNScalar
+	if overflow := tweak.SetByteSlice(hash[:]); overflow || tweak.IsZero() {
+		return nil, nil, errors.New("invalid silent payment tweak")
+	}
+	var tweakPoint, spendPoint, result btcec.JacobianPoint
+	btcec.ScalarBaseMultNonConst(&tweak, &tweakPoint)
+	spend.AsJacobian(&spendPoint)
+	btcec.AddNonConst(&spendPoint, &tweakPoint, &result)
+	result.ToAffine()
+	return btcec.NewPublicKey(&result.X, &result.Y), &tweak, nil
+}
+
+// silentPaymentOutputKeys
+// Derive the taproot output keys of the recipients.
+// @param inputKeys the keys of all the eligible inputs, returned by `silentPaymentInputKey`
+// @param outPoints the outpoints of all the inputs of the transaction
+// @return the output keys in the order of the recipients
+func silentPaymentOutputKeys(inputKeys []*btcec.PrivateKey, outPoints []wire.OutPoint, recipients []*silentPaymentRecipient) ([]*btcec.PublicKey, error) {
+	if len(inputKeys) == 0 || len(outPoints) == 0 {
+		return nil, ErrNoSilentPaymentInputs
+	}
+	var sum btcec.ModNScalar
+	for _, key := range inputKeys {
+		sum.Add(&key.Key)
+	}
+	if sum.IsZero() {
+		return nil, ErrNoSilentPaymentInputs
+	}
+	sumKey := &btcec.PrivateKey{Key: sum}
+	inputHash, err := silentPaymentInputHash(outPoints, sumKey.PubKey())
+	if err != nil {
+		return nil, err
+	}
+	var tweakedSum btcec.ModNScalar
+	tweakedSum.Mul2(inputHash, &sum)
+
+	res := make([]*btcec.PublicKey, len(recipients))
+	// the outputs of the same scan key use the increasing k
+	counts := make(map[string]uint32)
+	for i, recipient := range recipients {
+		var scanPoint, secretPoint btcec.JacobianPoint
+		recipient.scan.AsJacobian(&scanPoint)
+		btcec.ScalarMultNonConst(&tweakedSum, &scanPoint, &secretPoint)
+		secretPoint.ToAffine()
+		sharedSecret := btcec.NewPublicKey(&secretPoint.X, &secretPoint.Y)
+
+		scanKey := string(recipient.scan.SerializeCompressed())
+		res[i], _, err = silentPaymentOutputKey(sharedSecret, recipient.spend, counts[scanKey])
+		if err != nil {
+			return nil, err
+		}
+		counts[scanKey]++
+	}
+	return res, nil
+}
+
+// BuildSilentPaymentTransferWithFeeRate
+// Build a transfer transaction that pays to the silent payment address,
+// the output key is derived from the private key of the selected inputs, so the account is required.
+// The utxos that hold inscriptions or runes will not be spent.
+// @param receiver the silent payment address, e.g. "sp1q..."
+// @param amount the satoshi amount to transfer
+// @param feeRate sat/vB, the suggest average fee rate will be used if it <= 0
+func (c *Chain) BuildSilentPaymentTransferWithFeeRate(account *Account, receiver, amount string, feeRate int64) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil || value <= 0 {
+		return nil, errors.New("invalid transfer amount")
+	}
+	options := NewUTXOSpendOptions(feeRate)
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchSpendableUtxos(account.address, options)
+	if err != nil {
+		return
+	}
+	return buildSilentPaymentTransferWithUtxos(account, receiver, value, feeRate, utxos)
+}
+
+func buildSilentPaymentTransferWithUtxos(account *Account, receiver string, amount int64, feeRate int64, utxos []*UTXO) (*Transaction, error) {
+	scan, spend, err := decodeSilentPaymentAddress(receiver, account.chain)
+	if err != nil {
+		return nil, err
+	}
+	txn := &Transaction{
+		netParams:      account.chain,
+		msgTx:          wire.NewMsgTx(wire.TxVersion),
+		prevOutFetcher: txscript.NewMultiPrevOutFetcher(nil),
+	}
+	senderAddr, err := btcutil.DecodeAddress(account.address, account.chain)
+	if err != nil {
+		return nil, err
+	}
+	// the placeholder taproot output, the output key is unknown until the inputs are selected.
+	placeholder := make([]byte, 34)
+	placeholder[0], placeholder[1] = txscript.OP_1, txscript.OP_DATA_32
+	txn.addOutputScript(placeholder, amount)
+	if amount < mempool.GetDustThreshold(txn.msgTx.TxOut[0]) {
+		return nil, ErrDustAmount
+	}
+	if err = fundTransaction(txn, senderAddr, utxos, feeRate); err != nil {
+		return nil, err
+	}
+
+	inputKeys := make([]*btcec.PrivateKey, len(txn.msgTx.TxIn))
+	outPoints := make([]wire.OutPoint, len(txn.msgTx.TxIn))
+	for i, in := range txn.msgTx.TxIn {
+		prevOut := txn.prevOutFetcher.FetchPrevOutput(in.PreviousOutPoint)
+		if inputKeys[i], err = silentPaymentInputKey(account.privateKey, prevOut.PkScript); err != nil {
+			return nil, err
+		}
+		outPoints[i] = in.PreviousOutPoint
+	}
+	keys, err := silentPaymentOutputKeys(inputKeys, outPoints, []*silentPaymentRecipient{{scan: scan, spend: spend}})
+	if err != nil {
+		return nil, err
+	}
+	copy(placeholder[2:], schnorr.SerializePubKey(keys[0]))
+	return txn, nil
+}
+
+// MARK - Receiver
+
+// SilentPaymentOutput is the output received by the silent payment account.
+type SilentPaymentOutput struct {
+	Txid  string `json:"txid"`
+	Vout  int64  `json:"vout"`
+	Value int64  `json:"value"`
+	// The taproot address of the output
+	Address string `json:"address"`
+	// The tweak that added to the spend private key to spend the output, hex string without 0x.
+	Tweak string `json:"tweak"`
+}
+
+type SilentPaymentOutputArray struct {
+	inter.AnyArray[*SilentPaymentOutput]
+}
+
+// SilentPaymentAccount holds the scan key and the spend key of the silent payment.
+// The labels are not supported now.
+type SilentPaymentAccount struct {
+	scanKey  *btcec.PrivateKey
+	spendKey *btcec.PrivateKey
+	chain    *chaincfg.Params
+	address  string
+}
+
+// NewSilentPaymentAccountWithMnemonic
+// The scan key and the spend key are derived with the BIP-352 path m/352'/coin_type'/0'/1'/0 and m/352'/coin_type'/0'/0'/0
+func NewSilentPaymentAccountWithMnemonic(mnemonic, chainnet string) (*SilentPaymentAccount, error) {
+	chain, err := netParamsOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	coinType := "1'"
+	if chain.Net == chaincfg.MainNetParams.Net {
+		coinType = "0'"
+	}
+	scanKey, err := Derivation(mnemonic, "m/352'/"+coinType+"/0'/1'/0")
+	if err != nil {
+		return nil, err
+	}
+	spendKey, err := Derivation(mnemonic, "m/352'/"+coinType+"/0'/0'/0")
+	if err != nil {
+		return nil, err
+	}
+	return newSilentPaymentAccount(scanKey, spendKey, chain)
+}
+
+// SilentPaymentAccountWithPrivateKey
+// @param scanKey the hex private key for scanning
+// @param spendKey the hex private key for spending
+func SilentPaymentAccountWithPrivateKey(scanKey, spendKey, chainnet string) (*SilentPaymentAccount, error) {
+	chain, err := netParamsOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	scan, err := hex.DecodeString(strings.TrimPrefix(scanKey, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	spend, err := hex.DecodeString(strings.TrimPrefix(spendKey, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	scanPriv, _ := btcec.PrivKeyFromBytes(scan)
+	spendPriv, _ := btcec.PrivKeyFromBytes(spend)
+	return newSilentPaymentAccount(scanPriv, spendPriv, chain)
+}
+
+func newSilentPaymentAccount(scanKey, spendKey *btcec.PrivateKey, chain *chaincfg.Params) (*SilentPaymentAccount, error) {
+	address, err := encodeSilentPaymentAddress(scanKey.PubKey(), spendKey.PubKey(), chain)
+	if err != nil {
+		return nil, err
+	}
+	return &SilentPaymentAccount{
+		scanKey:  scanKey,
+		spendKey: spendKey,
+		chain:    chain,
+		address:  address,
+	}, nil
+}
+
+// Address
+// @return the silent payment address, e.g. "sp1q..."
+func (a *SilentPaymentAccount) Address() string {
+	return a.address
+}
+
+// @return the scan public key string that will start with 0x.
+func (a *SilentPaymentAccount) ScanPublicKeyHex() string {
+	return hexutil.HexEncodeToString(a.scanKey.PubKey().SerializeCompressed())
+}
+
+// @return the spend public key string that will start with 0x.
+func (a *SilentPaymentAccount) SpendPublicKeyHex() string {
+	return hexutil.HexEncodeToString(a.spendKey.PubKey().SerializeCompressed())
+}
+
+// ScanTransaction
+// Find the outputs of the transaction that belong to the account.
+// @param txHex the raw transaction
+// @param prevOutScripts the hex output scripts spent by the inputs, in the order of the inputs.
+func (a *SilentPaymentAccount) ScanTransaction(txHex string, prevOutScripts *base.StringArray) (outputs *SilentPaymentOutputArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	tx, err := DecodeTx(txHex)
+	if err != nil {
+		return
+	}
+	if prevOutScripts == nil || prevOutScripts.Count() != len(tx.TxIn) {
+		return nil, errors.New("the count of the previous output scripts does not match the inputs")
+	}
+	scripts := make([][]byte, len(tx.TxIn))
+	for i, script := range prevOutScripts.AnyArray {
+		if scripts[i], err = hex.DecodeString(strings.TrimPrefix(script, "0x")); err != nil {
+			return
+		}
+	}
+	return a.scanTransaction(tx, scripts)
+}
+
+// ScanSilentPaymentTransaction
+// Fetch the transaction and its previous outputs, and find the outputs that belong to the account.
+func (c *Chain) ScanSilentPaymentTransaction(account *SilentPaymentAccount, txid string) (outputs *SilentPaymentOutputArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := esploraClientOf(c.Chainnet)
+	if err != nil {
+		return
+	}
+	tx, err := client.FetchRawTransaction(strings.TrimPrefix(txid, "0x"))
+	if err != nil {
+		return
+	}
+	scripts := make([][]byte, len(tx.TxIn))
+	if blockchain.IsCoinBaseTx(tx) {
+		return &SilentPaymentOutputArray{}, nil
+	}
+	for i, in := range tx.TxIn {
+		prevTx, err := client.FetchRawTransaction(in.PreviousOutPoint.Hash.String())
+		if err != nil {
+			return nil, err
+		}
+		if int(in.PreviousOutPoint.Index) >= len(prevTx.TxOut) {
+			return nil, errors.New("invalid previous output index")
+		}
+		scripts[i] = prevTx.TxOut[in.PreviousOutPoint.Index].PkScript
+	}
+	return account.scanTransaction(tx, scripts)
+}
+
+func (a *SilentPaymentAccount) scanTransaction(tx *wire.MsgTx, prevOutScripts [][]byte) (*SilentPaymentOutputArray, error) {
+	res := &SilentPaymentOutputArray{}
+	var sumPoint btcec.JacobianPoint
+	outPoints := make([]wire.OutPoint, len(tx.TxIn))
+	eligible := 0
+	for i, in := range tx.TxIn {
+		outPoints[i] = in.PreviousOutPoint
+		script := prevOutScripts[i]
+		if version, _, err := txscript.ExtractWitnessProgramInfo(script); err == nil && version > 1 {
+			// the transaction that spends the unknown segwit version is skipped
+			return res, nil
+		}
+		pubkey := silentPaymentInputPubkey(in, script)
+		if pubkey == nil {
+			continue
+		}
+		var point btcec.JacobianPoint
+		pubkey.AsJacobian(&point)
+		btcec.AddNonConst(&sumPoint, &point, &sumPoint)
+		eligible++
+	}
+	if eligible == 0 || (sumPoint.X.IsZero() && sumPoint.Y.IsZero()) || sumPoint.Z.IsZero() {
+		return res, nil
+	}
+	sumPoint.ToAffine()
+	sumKey := btcec.NewPublicKey(&sumPoint.X, &sumPoint.Y)
+	inputHash, err := silentPaymentInputHash(outPoints, sumKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var tweakedScan btcec.ModNScalar
+	tweakedScan.Mul2(inputHash, &a.scanKey.Key)
+	var secretPoint btcec.JacobianPoint
+	sumKey.AsJacobian(&sumPoint)
+	btcec.ScalarMultNonConst(&tweakedScan, &sumPoint, &secretPoint)
+	secretPoint.ToAffine()
+	sharedSecret := btcec.NewPublicKey(&secretPoint.X, &secretPoint.Y)
+
+	txid := tx.TxHash().String()
+	found := make(map[int]bool)
+	for k := uint32(0); ; k++ {
+		outputKey, tweak, err := silentPaymentOutputKey(sharedSecret, a.spendKey.PubKey(), k)
+		if err != nil {
+			return nil, err
+		}
+		xOnly := schnorr.SerializePubKey(outputKey)
+		vout := -1
+		for i, out := range tx.TxOut {
+			if !found[i] && txscript.IsPayToTaproot(out.PkScript) && bytes.Equal(out.PkScript[2:], xOnly) {
+				vout = i
+				break
+			}
+		}
+		if vout < 0 {
+			break
+		}
+		found[vout] = true
+		address, err := btcutil.NewAddressTaproot(xOnly, a.chain)
+		if err != nil {
+			return nil, err
+		}
+		tweakBytes := tweak.Bytes()
+		res.Append(&SilentPaymentOutput{
+			Txid:    txid,
+			Vout:    int64(vout),
+			Value:   tx.TxOut[vout].Value,
+			Address: address.EncodeAddress(),
+			Tweak:   hex.EncodeToString(tweakBytes[:]),
+		})
+	}
+	return res, nil
+}
+
+// silentPaymentInputPubkey
+// @return the public key of the eligible input, or nil if the input is not eligible.
+func silentPaymentInputPubkey(in *wire.TxIn, pkScript []byte) *btcec.PublicKey {
+	witness := in.Witness
+	switch {
+	case txscript.IsPayToTaproot(pkScript):
+		if len(witness) > 1 && len(witness[len(witness)-1]) > 0 && witness[len(witness)-1][0] == txscript.TaprootAnnexTag {
+			witness = witness[:len(witness)-1] // remove the annex
+		}
+		if len(witness) > 1 {
+			// the script path spend with the NUMS internal key is not eligible
+			controlBlock := witness[len(witness)-1]
+			if len(controlBlock) >= 33 && bytes.Equal(controlBlock[1:33], taprootNumsKey) {
+				return nil
+			}
+		}
+		pubkey, err := schnorr.ParsePubKey(pkScript[2:])
+		if err != nil {
+			return nil
+		}
+		return pubkey
+	case txscript.IsPayToWitnessPubKeyHash(pkScript):
+		if len(witness) == 2 && len(witness[1]) == btcec.PubKeyBytesLenCompressed {
+			return parseCompressedPubkey(witness[1], pkScript[2:])
+		}
+	case txscript.IsPayToScriptHash(pkScript):
+		// only the P2SH-P2WPKH is eligible
+		pushes, err := txscript.PushedData(in.SignatureScript)
+		if err != nil || len(pushes) != 1 || !txscript.IsPayToWitnessPubKeyHash(pushes[0]) {
+			return nil
+		}
+		if len(witness) == 2 && len(witness[1]) == btcec.PubKeyBytesLenCompressed {
+			return parseCompressedPubkey(witness[1], pushes[0][2:])
+		}
+	case txscript.IsPayToPubKeyHash(pkScript):
+		pushes, err := txscript.PushedData(in.SignatureScript)
+		if err != nil {
+			return nil
+		}
+		for i := len(pushes) - 1; i >= 0; i-- {
+			if len(pushes[i]) == btcec.PubKeyBytesLenCompressed {
+				if pubkey := parseCompressedPubkey(pushes[i], pkScript[3:23]); pubkey != nil {
+					return pubkey
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseCompressedPubkey
+// @return the public key if it's compressed and matches the hash
+func parseCompressedPubkey(data, pkh []byte) *btcec.PublicKey {
+	if len(data) != btcec.PubKeyBytesLenCompressed || !bytes.Equal(btcutil.Hash160(data), pkh) {
+		return nil
+	}
+	pubkey, err := btcec.ParsePubKey(data)
+	if err != nil {
+		return nil
+	}
+	return pubkey
+}
+
+// SignTransaction
+// Sign the inputs that spend the silent payment outputs of the account.
+// @param outputs the outputs found by `ScanTransaction`, every input of the transaction must be one of them.
+func (a *SilentPaymentAccount) SignTransaction(txn *Transaction, outputs *SilentPaymentOutputArray) (signedTxn *SignedTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if len(txn.msgTx.TxIn) == 0 || len(txn.msgTx.TxOut) == 0 {
+		return nil, errors.New("invalid inputs or outputs")
+	}
+	tx := txn.msgTx.Copy()
+	sigHashes := txscript.NewTxSigHashes(tx, txn.prevOutFetcher)
+	for i, in := range tx.TxIn {
+		privKey, err := a.outputPrivateKey(in.PreviousOutPoint, outputs)
+		if err != nil {
+			return nil, err
+		}
+		prevOut := txn.prevOutFetcher.FetchPrevOutput(in.PreviousOutPoint)
+		if !bytes.Equal(prevOut.PkScript[2:], schnorr.SerializePubKey(privKey.PubKey())) {
+			return nil, errors.New("the silent payment tweak does not match the input")
+		}
+		hash, err := txscript.CalcTaprootSignatureHash(sigHashes, txscript.SigHashDefault, tx, i, txn.prevOutFetcher)
+		if err != nil {
+			return nil, err
+		}
+		// the output key is not tweaked by the BIP-86, so the key is used directly
+		signature, err := schnorr.Sign(privKey, hash)
+		if err != nil {
+			return nil, err
+		}
+		in.Witness = wire.TxWitness{signature.Serialize()}
+	}
+	return &SignedTransaction{msgTx: tx}, nil
+}
+
+// outputPrivateKey
+// @return b_spend + tweak of the output
+func (a *SilentPaymentAccount) outputPrivateKey(point wire.OutPoint, outputs *SilentPaymentOutputArray) (*btcec.PrivateKey, error) {
+	if outputs != nil {
+		for _, output := range outputs.AnyArray {
+			if output.Txid != point.Hash.String() || output.Vout != int64(point.Index) {
+				continue
+			}
+			tweakBytes, err := hex.DecodeString(output.Tweak)
+			if err != nil {
+				return nil, err
+			}
+			var key btcec.ModNScalar
+			if overflow := key.SetByteSlice(tweakBytes); overflow {
+				return nil, errors.New("invalid silent payment tweak")
+			}
+			key.Add(&a.spendKey.Key)
+			return &btcec.PrivateKey{Key: key}, nil
+		}
+	}
+	return nil, ErrSilentPaymentOutputNotFound
+}
@@ -0,0 +1,180 @@
+package btc
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/stretchr/testify/require"
+)
+
+const silentPaymentMnemonic = "antenna chaos arrive hungry distance human question history decade deal impose color"
+
+// The "Simple send: two inputs" case of the BIP-352 test vectors
+var silentPaymentVector = struct {
+	inputKeys []string
+	txids     []string
+	scanKey   string
+	spendKey  string
+	address   string
+	output    string
+}{
+	inputKeys: []string{
+		"eadc78165ff1f8ea94ad7cfdc54990738a4c53f6e0507b42154201b8e5dff3b1",
+		"93f5ed907ad5b2bdbbdcb5d9116ebc0a4e1f92f910d5260237fa45a9408aad16",
+	},
+	txids: []string{
+		"f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16",
+		"a1075db55d416d3ca199f55b6084e2115b9345e16c5cf302fc80e9d5fbf5d48d",
+	},
+	scanKey:  "0f694e068028a717f8af6b9411f9a133dd3565258714cc226594b34db90c1f2c",
+	spendKey: "9d6ad855ce3417ef84e836892e5a56392bfba05fa5d97ccea30e266f540e08b3",
+	address:  "sp1qqgste7k9hx0qftg6qmwlkqtwuy6cycyavzmzj85c6qdfhjdpdjtdgqjuexzk6murw56suy3e0rd2cgqvycxttddwsvgxe2usfpxumr70xc9pkqwv",
+	output:   "3e9fce73d4e77a4809908e3c3a2e54ee147b9312dc5044a193d1fc85de46e3c1",
+}
+
+func TestSilentPaymentAddress(t *testing.T) {
+	account, err := SilentPaymentAccountWithPrivateKey(silentPaymentVector.scanKey, silentPaymentVector.spendKey, ChainMainnet)
+	require.NoError(t, err)
+	require.Equal(t, silentPaymentVector.address, account.Address())
+	address, err := EncodeSilentPaymentAddress(account.ScanPublicKeyHex(), account.SpendPublicKeyHex(), ChainMainnet)
+	require.NoError(t, err)
+	require.Equal(t, silentPaymentVector.address, address)
+
+	require.True(t, IsValidSilentPaymentAddress(address, ChainMainnet))
+	require.False(t, IsValidSilentPaymentAddress(address, ChainSignet))
+	require.False(t, IsValidSilentPaymentAddress(address[:len(address)-1]+"q", ChainMainnet))
+	require.False(t, IsValidSilentPaymentAddress("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", ChainMainnet))
+
+	testnetAccount, err := NewSilentPaymentAccountWithMnemonic(silentPaymentMnemonic, ChainSignet)
+	require.NoError(t, err)
+	require.Regexp(t, "^tsp1q", testnetAccount.Address())
+	require.True(t, IsValidSilentPaymentAddress(testnetAccount.Address(), ChainRegtest))
+}
+
+func TestSilentPayment_Vector(t *testing.T) {
+	inputKeys := make([]*btcec.PrivateKey, 0)
+	outPoints := make([]wire.OutPoint, 0)
+	tx := wire.NewMsgTx(wire.TxVersion)
+	prevOutScripts := base.NewStringArray()
+	for i, key := range silentPaymentVector.inputKeys {
+		data, err := hex.DecodeString(key)
+		require.NoError(t, err)
+		privKey, pubKey := btcec.PrivKeyFromBytes(data)
+		inputKeys = append(inputKeys, privKey)
+		point, err := outPoint(silentPaymentVector.txids[i], 0)
+		require.NoError(t, err)
+		outPoints = append(outPoints, *point)
+
+		// the P2PKH inputs, the signature is not checked by the scanning
+		sigScript, err := txscript.NewScriptBuilder().AddData(make([]byte, 71)).AddData(pubKey.SerializeCompressed()).Script()
+		require.NoError(t, err)
+		tx.AddTxIn(wire.NewTxIn(point, sigScript, nil))
+		pkScript, err := PayToPubKeyHashScript(btcutil.Hash160(pubKey.SerializeCompressed()))
+		require.NoError(t, err)
+		prevOutScripts.Append(hex.EncodeToString(pkScript))
+	}
+
+	account, err := SilentPaymentAccountWithPrivateKey(silentPaymentVector.scanKey, silentPaymentVector.spendKey, ChainMainnet)
+	require.NoError(t, err)
+	scan, spend, err := decodeSilentPaymentAddress(account.Address(), account.chain)
+	require.NoError(t, err)
+	keys, err := silentPaymentOutputKeys(inputKeys, outPoints, []*silentPaymentRecipient{{scan: scan, spend: spend}})
+	require.NoError(t, err)
+	require.Equal(t, silentPaymentVector.output, hex.EncodeToString(schnorr.SerializePubKey(keys[0])))
+
+	outputScript := append([]byte{txscript.OP_1, txscript.OP_DATA_32}, schnorr.SerializePubKey(keys[0])...)
+	tx.AddTxOut(wire.NewTxOut(10000, outputScript))
+	txHex, err := (&SignedTransaction{msgTx: tx}).HexString()
+	require.NoError(t, err)
+	outputs, err := account.ScanTransaction(txHex.Value, prevOutScripts)
+	require.NoError(t, err)
+	require.Equal(t, 1, outputs.Count())
+	require.Equal(t, int64(0), outputs.ValueAt(0).Vout)
+
+	// the tweaked spend key matches the output key
+	privKey, err := account.outputPrivateKey(tx.TxIn[0].PreviousOutPoint, outputs)
+	require.Error(t, err)
+	privKey, err = account.outputPrivateKey(wire.OutPoint{Hash: tx.TxHash(), Index: 0}, outputs)
+	require.NoError(t, err)
+	require.Equal(t, silentPaymentVector.output, hex.EncodeToString(schnorr.SerializePubKey(privKey.PubKey())))
+}
+
+func TestSilentPayment_SendAndSpend(t *testing.T) {
+	receiver, err := NewSilentPaymentAccountWithMnemonic(silentPaymentMnemonic, ChainSignet)
+	require.NoError(t, err)
+	for _, sender := range newTestSigners(t) {
+		txn, err := buildSilentPaymentTransferWithUtxos(sender, receiver.Address(), 30000, 2, newTestUtxos(20000, 20000))
+		require.NoError(t, err)
+		signed := signTestTransaction(t, txn, sender)
+
+		prevOutScripts := base.NewStringArray()
+		for _, in := range signed.TxIn {
+			prevOut := txn.prevOutFetcher.FetchPrevOutput(in.PreviousOutPoint)
+			prevOutScripts.Append(hex.EncodeToString(prevOut.PkScript))
+		}
+		signedHex, err := (&SignedTransaction{msgTx: signed}).HexString()
+		require.NoError(t, err)
+		outputs, err := receiver.ScanTransaction(signedHex.Value, prevOutScripts)
+		require.NoError(t, err)
+		require.Equal(t, 1, outputs.Count())
+		output := outputs.ValueAt(0)
+		require.Equal(t, int64(30000), output.Value)
+		require.Equal(t, signed.TxHash().String(), output.Txid)
+
+		// the other account can't find the output
+		other, err := SilentPaymentAccountWithPrivateKey(silentPaymentVector.scanKey, silentPaymentVector.spendKey, ChainSignet)
+		require.NoError(t, err)
+		otherOutputs, err := other.ScanTransaction(signedHex.Value, prevOutScripts)
+		require.NoError(t, err)
+		require.Equal(t, 0, otherOutputs.Count())
+
+		// spend the received output
+		spendTxn, err := NewTransaction(ChainSignet)
+		require.NoError(t, err)
+		require.NoError(t, spendTxn.AddInput(output.Txid, output.Vout, output.Address, output.Value))
+		require.NoError(t, spendTxn.AddOutput(sender.address, 29000))
+		spent, err := receiver.SignTransaction(spendTxn, outputs)
+		require.NoError(t, err)
+		verifyTransaction(t, spent.msgTx, spendTxn.prevOutFetcher)
+
+		_, err = receiver.SignTransaction(spendTxn, &SilentPaymentOutputArray{})
+		require.Equal(t, ErrSilentPaymentOutputNotFound, err)
+	}
+}
+
+func TestSilentPayment_ScanSkipped(t *testing.T) {
+	receiver, err := NewSilentPaymentAccountWithMnemonic(silentPaymentMnemonic, ChainSignet)
+	require.NoError(t, err)
+	sender := newTestSigners(t)[1]
+	txn, err := buildSilentPaymentTransferWithUtxos(sender, receiver.Address(), 30000, 2, newTestUtxos(50000))
+	require.NoError(t, err)
+	signed := signTestTransaction(t, txn, sender)
+	signedHex, err := (&SignedTransaction{msgTx: signed}).HexString()
+	require.NoError(t, err)
+
+	// the transaction spends the segwit v2 output is not scanned
+	hash := chainhash.DoubleHashH([]byte("v2"))
+	signed.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&hash, 0), nil, nil))
+	withV2, err := (&SignedTransaction{msgTx: signed}).HexString()
+	require.NoError(t, err)
+	pkScript := txn.prevOutFetcher.FetchPrevOutput(signed.TxIn[0].PreviousOutPoint).PkScript
+	v2Script := append([]byte{txscript.OP_2, txscript.OP_DATA_32}, hash[:]...)
+	outputs, err := receiver.ScanTransaction(withV2.Value, base.NewStringArrayWithItem(hex.EncodeToString(pkScript)))
+	require.Error(t, err)
+	scripts := base.NewStringArrayWithItem(hex.EncodeToString(pkScript))
+	scripts.Append(hex.EncodeToString(v2Script))
+	outputs, err = receiver.ScanTransaction(withV2.Value, scripts)
+	require.NoError(t, err)
+	require.Equal(t, 0, outputs.Count())
+
+	outputs, err = receiver.ScanTransaction(signedHex.Value, base.NewStringArrayWithItem(hex.EncodeToString(pkScript)))
+	require.NoError(t, err)
+	require.Equal(t, 1, outputs.Count())
+}
@@ -0,0 +1,321 @@
+package btc
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+// The max distance between the checkpoint and the verified block, it's about one day,
+// the checkpoint should be updated if the blocks are farther.
+// All the headers between them are fetched at the first verification, the esplora returns 10 headers per request.
+const SpvMaxHeaderDistance int64 = 144
+
+var (
+	ErrSpvCheckpointMismatch = errors.New("the header does not match the spv checkpoint")
+	ErrSpvCheckpointTooFar   = errors.New("the block is too far from the spv checkpoint")
+	ErrSpvBrokenHeaderChain  = errors.New("the headers are not linked to the spv checkpoint")
+	ErrSpvInvalidProofOfWork = errors.New("the header has invalid proof of work")
+	ErrSpvInvalidMerkleProof = errors.New("the merkle proof does not match the block header")
+	ErrSpvAmbiguousTx        = errors.New("the 64 bytes transaction can't be verified by the merkle proof")
+)
+
+// SpvVerification is the evidence that the transaction is included in the block,
+// the header of the block is linked to the pinned checkpoint.
+type SpvVerification struct {
+	Txid             string `json:"txid"`
+	BlockHash        string `json:"blockHash"`
+	BlockHeight      int64  `json:"blockHeight"`
+	BlockTime        int64  `json:"blockTime"`
+	CheckpointHeight int64  `json:"checkpointHeight"`
+}
+
+func (v *SpvVerification) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(v)
+}
+
+// spvVerifier caches the verified headers, they're a contiguous range that contains the checkpoint.
+type spvVerifier struct {
+	params           *chaincfg.Params
+	checkpointHeight int64
+	checkpointHash   chainhash.Hash
+
+	mu      sync.Mutex
+	headers map[int64]*wire.BlockHeader
+	low     int64
+	high    int64
+}
+
+// EnableSpvVerification
+// The confirmed status of the transaction is reported only after the merkle proof is verified,
+// and the header of the block is linked to the checkpoint with valid proof of work.
+// @param checkpointHeight the height of the trusted block, it should be near to the verified blocks, see `SpvMaxHeaderDistance`.
+// @param checkpointHash the hash of the trusted block
+func (c *Chain) EnableSpvVerification(checkpointHeight int64, checkpointHash string) error {
+	params, err := netParamsOf(c.Chainnet)
+	if err != nil {
+		return err
+	}
+	hash, err := chainhash.NewHashFromStr(strings.TrimPrefix(checkpointHash, "0x"))
+	if err != nil {
+		return err
+	}
+	if checkpointHeight < 0 {
+		return errors.New("invalid checkpoint height")
+	}
+	c.spv = &spvVerifier{
+		params:           params,
+		checkpointHeight: checkpointHeight,
+		checkpointHash:   *hash,
+		headers:          make(map[int64]*wire.BlockHeader),
+	}
+	return nil
+}
+
+// DisableSpvVerification
+// The transaction status is reported as the esplora host says.
+func (c *Chain) DisableSpvVerification() {
+	c.spv = nil
+}
+
+func (c *Chain) IsSpvVerificationEnabled() bool {
+	return c.spv != nil
+}
+
+// VerifyTransactionInclusion
+// Verify the transaction is included in the block that is linked to the checkpoint,
+// the spv verification must be enabled.
+func (c *Chain) VerifyTransactionInclusion(txid string) (res *SpvVerification, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if c.spv == nil {
+		return nil, errors.New("the spv verification is not enabled")
+	}
+	client, err := esploraClientOf(c.Chainnet)
+	if err != nil {
+		return
+	}
+	return c.spv.verifyInclusion(client, strings.TrimPrefix(txid, "0x"))
+}
+
+func (c *Chain) fetchTransactionDetailSpv(txid string) (detail *base.TransactionDetail, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := esploraClientOf(c.Chainnet)
+	if err != nil {
+		return
+	}
+	txid = strings.TrimPrefix(txid, "0x")
+	status, err := client.fetchStatus(txid)
+	if err != nil {
+		return
+	}
+	detail = &base.TransactionDetail{
+		HashString: txid,
+		Status:     base.TransactionStatusPending,
+	}
+	if !status.Confirmed {
+		return detail, nil
+	}
+	res, err := c.spv.verifyInclusion(client, txid)
+	if err != nil {
+		return nil, err
+	}
+	detail.Status = base.TransactionStatusSuccess
+	detail.FinishTimestamp = res.BlockTime
+	return detail, nil
+}
+
+func (v *spvVerifier) verifyInclusion(client *EsploraClient, txid string) (*SpvVerification, error) {
+	txHash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return nil, err
+	}
+	// the 64 bytes transaction is ambiguous with the inner node of the merkle tree (CVE-2017-12842),
+	// the host can present the inner node as the txid with a shorter merkle proof.
+	tx, err := client.FetchRawTransaction(txid)
+	if err != nil {
+		return nil, err
+	}
+	if tx.TxHash() != *txHash {
+		return nil, ErrSpvInvalidMerkleProof
+	}
+	if tx.SerializeSizeStripped() == 64 {
+		return nil, ErrSpvAmbiguousTx
+	}
+	proof, err := client.FetchMerkleProof(txid)
+	if err != nil {
+		return nil, err
+	}
+	header, err := v.headerAt(client, proof.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+	root, err := merkleRootOfProof(txHash, proof)
+	if err != nil {
+		return nil, err
+	}
+	if !root.IsEqual(&header.MerkleRoot) {
+		return nil, ErrSpvInvalidMerkleProof
+	}
+	return &SpvVerification{
+		Txid:             txHash.String(),
+		BlockHash:        header.BlockHash().String(),
+		BlockHeight:      proof.BlockHeight,
+		BlockTime:        header.Timestamp.Unix(),
+		CheckpointHeight: v.checkpointHeight,
+	}, nil
+}
+
+// merkleRootOfProof
+// The siblings are hashed from the bottom up, the bits of the position tell which side the node is on.
+func merkleRootOfProof(txHash *chainhash.Hash, proof *MerkleProof) (*chainhash.Hash, error) {
+	if proof.Pos < 0 || len(proof.Merkle) > 32 {
+		return nil, ErrSpvInvalidMerkleProof
+	}
+	current := *txHash
+	pos := proof.Pos
+	for _, item := range proof.Merkle {
+		sibling, err := chainhash.NewHashFromStr(item)
+		if err != nil {
+			return nil, ErrSpvInvalidMerkleProof
+		}
+		if pos&1 == 0 {
+			current = blockchain.HashMerkleBranches(&current, sibling)
+		} else {
+			current = blockchain.HashMerkleBranches(sibling, &current)
+		}
+		pos >>= 1
+	}
+	if pos != 0 {
+		return nil, ErrSpvInvalidMerkleProof
+	}
+	return &current, nil
+}
+
+// headerAt
+// @return the header at the height that is linked to the checkpoint, the missing headers are fetched and verified.
+func (v *spvVerifier) headerAt(client *EsploraClient, height int64) (*wire.BlockHeader, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if header, ok := v.headers[height]; ok {
+		return header, nil
+	}
+	if height < 0 || max(height-v.checkpointHeight, v.checkpointHeight-height) > SpvMaxHeaderDistance {
+		return nil, ErrSpvCheckpointTooFar
+	}
+
+	var from, to int64
+	switch {
+	case len(v.headers) == 0:
+		from, to = min(height, v.checkpointHeight), max(height, v.checkpointHeight)
+	case height > v.high:
+		from, to = v.high+1, height
+	default:
+		from, to = height, v.low-1
+	}
+	fetched, err := fetchHeaderRange(client, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(v.headers) == 0 {
+		checkpoint := fetched[v.checkpointHeight]
+		hash := checkpoint.BlockHash()
+		if !hash.IsEqual(&v.checkpointHash) {
+			return nil, ErrSpvCheckpointMismatch
+		}
+		v.headers[v.checkpointHeight] = checkpoint
+		v.low, v.high = v.checkpointHeight, v.checkpointHeight
+	}
+	// the headers are cached only after they're linked to the verified headers.
+	for v.high < height {
+		header, parent := fetched[v.high+1], v.headers[v.high]
+		if err := v.checkHeader(header, parent, v.high+1); err != nil {
+			return nil, err
+		}
+		v.high++
+		v.headers[v.high] = header
+	}
+	for v.low > height {
+		header, parent := v.headers[v.low], fetched[v.low-1]
+		if err := v.checkHeader(header, parent, v.low); err != nil {
+			return nil, err
+		}
+		v.low--
+		v.headers[v.low] = parent
+	}
+	return v.headers[height], nil
+}
+
+// checkHeader
+// Check the header is linked to the parent, and both of them have valid proof of work.
+func (v *spvVerifier) checkHeader(header, parent *wire.BlockHeader, height int64) error {
+	parentHash := parent.BlockHash()
+	if !header.PrevBlock.IsEqual(&parentHash) {
+		return ErrSpvBrokenHeaderChain
+	}
+	for _, h := range []*wire.BlockHeader{header, parent} {
+		target := blockchain.CompactToBig(h.Bits)
+		if target.Sign() <= 0 || target.Cmp(v.params.PowLimit) > 0 {
+			return ErrSpvInvalidProofOfWork
+		}
+		hash := h.BlockHash()
+		if blockchain.HashToBig(&hash).Cmp(target) > 0 {
+			return ErrSpvInvalidProofOfWork
+		}
+	}
+
+	params := v.params
+	blocksPerRetarget := int64(params.TargetTimespan / params.TargetTimePerBlock)
+	switch {
+	case params.PoWNoRetargeting:
+		if header.Bits != parent.Bits {
+			return ErrSpvInvalidProofOfWork
+		}
+	case params.ReduceMinDifficulty:
+		// the minimum difficulty blocks are allowed, only the pow limit is checked.
+	case height%blocksPerRetarget != 0:
+		if header.Bits != parent.Bits {
+			return ErrSpvInvalidProofOfWork
+		}
+	default:
+		// the difficulty can't be adjusted more than the factor at the retarget height.
+		target := blockchain.CompactToBig(header.Bits)
+		parentTarget := blockchain.CompactToBig(parent.Bits)
+		factor := big.NewInt(params.RetargetAdjustmentFactor)
+		if target.Cmp(new(big.Int).Mul(parentTarget, factor)) > 0 ||
+			target.Cmp(new(big.Int).Div(parentTarget, factor)) < 0 {
+			return ErrSpvInvalidProofOfWork
+		}
+	}
+	return nil
+}
+
+// fetchHeaderRange
+// @return the headers in the range [from, to], they're fetched from the top down.
+func fetchHeaderRange(client *EsploraClient, from, to int64) (map[int64]*wire.BlockHeader, error) {
+	headers := make(map[int64]*wire.BlockHeader, to-from+1)
+	for start := to; start >= from; {
+		batch, err := client.FetchBlockHeaders(start)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			return nil, ErrSpvBrokenHeaderChain
+		}
+		for i, header := range batch {
+			headers[start-int64(i)] = header
+		}
+		start -= int64(len(batch))
+	}
+	return headers, nil
+}
@@ -0,0 +1,272 @@
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/stretchr/testify/require"
+)
+
+// spvTestChain is the fake esplora server of the regtest blocks from height 100 to 110,
+// the block 105 contains the transactions a, b and c.
+type spvTestChain struct {
+	headers map[int64]*wire.BlockHeader
+	txids   []chainhash.Hash
+	txs     map[string]*wire.MsgTx
+	proofs  map[string]*MerkleProof
+}
+
+func newSpvTestTx(seed byte) *wire.MsgTx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{seed}, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, make([]byte, 22)))
+	return tx
+}
+
+func mineTestHeader(header *wire.BlockHeader, valid bool) {
+	target := blockchain.CompactToBig(header.Bits)
+	for header.Nonce = 0; ; header.Nonce++ {
+		hash := header.BlockHash()
+		if (blockchain.HashToBig(&hash).Cmp(target) <= 0) == valid {
+			return
+		}
+	}
+}
+
+func newSpvTestChain() *spvTestChain {
+	return newSpvTestChainWithTxs(newSpvTestTx(1), newSpvTestTx(2), newSpvTestTx(3))
+}
+
+func newSpvTestChainWithTxs(txA, txB, txC *wire.MsgTx) *spvTestChain {
+	a, b, c := txA.TxHash(), txB.TxHash(), txC.TxHash()
+	ab := blockchain.HashMerkleBranches(&a, &b)
+	cc := blockchain.HashMerkleBranches(&c, &c)
+	root := blockchain.HashMerkleBranches(&ab, &cc)
+
+	chain := &spvTestChain{
+		headers: make(map[int64]*wire.BlockHeader),
+		txids:   []chainhash.Hash{a, b, c},
+		txs:     map[string]*wire.MsgTx{a.String(): txA, b.String(): txB, c.String(): txC},
+		proofs: map[string]*MerkleProof{
+			a.String(): {BlockHeight: 105, Merkle: []string{b.String(), cc.String()}, Pos: 0},
+			b.String(): {BlockHeight: 105, Merkle: []string{a.String(), cc.String()}, Pos: 1},
+			c.String(): {BlockHeight: 105, Merkle: []string{c.String(), ab.String()}, Pos: 2},
+		},
+	}
+	prevBlock := chainhash.Hash{}
+	for height := int64(100); height <= 110; height++ {
+		header := &wire.BlockHeader{
+			Version:    0x20000000,
+			PrevBlock:  prevBlock,
+			MerkleRoot: chainhash.DoubleHashH([]byte(strconv.FormatInt(height, 10))),
+			Timestamp:  time.Unix(1700000000+height*600, 0),
+			Bits:       chaincfg.RegressionNetParams.PowLimitBits,
+		}
+		if height == 105 {
+			header.MerkleRoot = root
+		}
+		mineTestHeader(header, true)
+		chain.headers[height] = header
+		prevBlock = header.BlockHash()
+	}
+	return chain
+}
+
+func (c *spvTestChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	paths := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(paths) == 3 && paths[0] == "tx" && paths[2] == "status":
+		if proof, ok := c.proofs[paths[1]]; ok {
+			json.NewEncoder(w).Encode(UTXOStatus{Confirmed: true, BlockHeight: proof.BlockHeight})
+		} else {
+			json.NewEncoder(w).Encode(UTXOStatus{Confirmed: false})
+		}
+	case len(paths) == 3 && paths[0] == "tx" && paths[2] == "hex":
+		tx, ok := c.txs[paths[1]]
+		if !ok {
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+			return
+		}
+		var buf bytes.Buffer
+		tx.Serialize(&buf)
+		w.Write([]byte(hex.EncodeToString(buf.Bytes())))
+	case len(paths) == 3 && paths[0] == "tx" && paths[2] == "merkle-proof":
+		proof, ok := c.proofs[paths[1]]
+		if !ok {
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(proof)
+	case len(paths) == 2 && paths[0] == "blocks":
+		start, _ := strconv.ParseInt(paths[1], 10, 64)
+		blocks := make([]*esploraBlock, 0)
+		for height := start; height > start-10 && c.headers[height] != nil; height-- {
+			header := c.headers[height]
+			blocks = append(blocks, &esploraBlock{
+				Id:                header.BlockHash().String(),
+				Height:            height,
+				Version:           header.Version,
+				Timestamp:         header.Timestamp.Unix(),
+				Bits:              header.Bits,
+				Nonce:             header.Nonce,
+				MerkleRoot:        header.MerkleRoot.String(),
+				PreviousBlockHash: header.PrevBlock.String(),
+			})
+		}
+		json.NewEncoder(w).Encode(blocks)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newSpvTestClient(t *testing.T, testChain *spvTestChain) *Chain {
+	server := httptest.NewServer(testChain)
+	t.Cleanup(server.Close)
+	require.NoError(t, SetBackend(ChainRegtest, NewBackend(server.URL, "", "", "", "")))
+	t.Cleanup(func() { SetBackend(ChainRegtest, nil) })
+	chain, err := NewChainWithChainnet(ChainRegtest)
+	require.NoError(t, err)
+	return chain
+}
+
+func TestSpv_VerifyInclusion(t *testing.T) {
+	testChain := newSpvTestChain()
+	chain := newSpvTestClient(t, testChain)
+
+	_, err := chain.VerifyTransactionInclusion(testChain.txids[0].String())
+	require.Error(t, err)
+
+	// the checkpoint below and above the block
+	for _, checkpoint := range []int64{100, 110} {
+		require.NoError(t, chain.EnableSpvVerification(checkpoint, testChain.headers[checkpoint].BlockHash().String()))
+		require.True(t, chain.IsSpvVerificationEnabled())
+		for _, txid := range testChain.txids {
+			res, err := chain.VerifyTransactionInclusion(txid.String())
+			require.NoError(t, err)
+			require.Equal(t, int64(105), res.BlockHeight)
+			require.Equal(t, testChain.headers[105].BlockHash().String(), res.BlockHash)
+			require.Equal(t, checkpoint, res.CheckpointHeight)
+
+			detail, err := chain.FetchTransactionDetail(txid.String())
+			require.NoError(t, err)
+			require.Equal(t, base.TransactionStatusSuccess, detail.Status)
+			require.Equal(t, testChain.headers[105].Timestamp.Unix(), detail.FinishTimestamp)
+		}
+	}
+
+	// the unconfirmed transaction is not verified
+	pending := chainhash.DoubleHashH([]byte("pending")).String()
+	detail, err := chain.FetchTransactionDetail(pending)
+	require.NoError(t, err)
+	require.Equal(t, base.TransactionStatusPending, detail.Status)
+
+	statuses := chain.BatchFetchTransactionStatus(testChain.txids[0].String() + "," + pending)
+	require.Equal(t, strconv.Itoa(base.TransactionStatusSuccess)+","+strconv.Itoa(base.TransactionStatusPending), statuses)
+
+	chain.DisableSpvVerification()
+	require.False(t, chain.IsSpvVerificationEnabled())
+}
+
+func TestSpv_InvalidProof(t *testing.T) {
+	testChain := newSpvTestChain()
+	chain := newSpvTestClient(t, testChain)
+	txid := testChain.txids[1].String()
+	checkpointHash := testChain.headers[100].BlockHash().String()
+
+	require.NoError(t, chain.EnableSpvVerification(100, testChain.headers[101].BlockHash().String()))
+	_, err := chain.VerifyTransactionInclusion(txid)
+	require.Equal(t, ErrSpvCheckpointMismatch, err)
+
+	require.NoError(t, chain.EnableSpvVerification(106+SpvMaxHeaderDistance, checkpointHash))
+	_, err = chain.VerifyTransactionInclusion(txid)
+	require.Equal(t, ErrSpvCheckpointTooFar, err)
+
+	// the merkle proof of the wrong position
+	require.NoError(t, chain.EnableSpvVerification(100, checkpointHash))
+	testChain.proofs[txid].Pos = 0
+	_, err = chain.VerifyTransactionInclusion(txid)
+	require.Equal(t, ErrSpvInvalidMerkleProof, err)
+	require.Equal(t, base.TransactionStatusNone, chain.FetchTransactionStatus(txid))
+	testChain.proofs[txid].Pos = 4
+	_, err = chain.VerifyTransactionInclusion(txid)
+	require.Equal(t, ErrSpvInvalidMerkleProof, err)
+	testChain.proofs[txid].Pos = 1
+
+	// the header without enough work
+	mineTestHeader(testChain.headers[103], false)
+	require.NoError(t, chain.EnableSpvVerification(100, checkpointHash))
+	_, err = chain.VerifyTransactionInclusion(txid)
+	require.Equal(t, ErrSpvInvalidProofOfWork, err)
+	require.NoError(t, chain.EnableSpvVerification(103, testChain.headers[103].BlockHash().String()))
+	_, err = chain.VerifyTransactionInclusion(txid)
+	require.Equal(t, ErrSpvBrokenHeaderChain, err)
+
+	// the header that is not linked to the checkpoint
+	testChain = newSpvTestChain()
+	chain = newSpvTestClient(t, testChain)
+	testChain.headers[104].PrevBlock = chainhash.Hash{}
+	mineTestHeader(testChain.headers[104], true)
+	require.NoError(t, chain.EnableSpvVerification(100, testChain.headers[100].BlockHash().String()))
+	_, err = chain.VerifyTransactionInclusion(txid)
+	require.Equal(t, ErrSpvBrokenHeaderChain, err)
+
+	require.Error(t, chain.EnableSpvVerification(100, "invalid hash"))
+
+	// the 64 bytes transaction is rejected even if the merkle proof is valid
+	txC := wire.NewMsgTx(wire.TxVersion)
+	txC.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{3}, 0), nil, nil))
+	txC.AddTxOut(wire.NewTxOut(1000, make([]byte, 4)))
+	require.Equal(t, 64, txC.SerializeSizeStripped())
+	testChain = newSpvTestChainWithTxs(newSpvTestTx(1), newSpvTestTx(2), txC)
+	chain = newSpvTestClient(t, testChain)
+	require.NoError(t, chain.EnableSpvVerification(100, testChain.headers[100].BlockHash().String()))
+	_, err = chain.VerifyTransactionInclusion(testChain.txids[0].String())
+	require.NoError(t, err)
+	_, err = chain.VerifyTransactionInclusion(testChain.txids[2].String())
+	require.Equal(t, ErrSpvAmbiguousTx, err)
+
+	// the raw transaction doesn't match the txid
+	testChain.txs[testChain.txids[0].String()] = newSpvTestTx(4)
+	_, err = chain.VerifyTransactionInclusion(testChain.txids[0].String())
+	require.Equal(t, ErrSpvInvalidMerkleProof, err)
+}
+
+func TestSpv_RetargetDifficulty(t *testing.T) {
+	verifier := &spvVerifier{params: &chaincfg.MainNetParams}
+	parent := &wire.BlockHeader{Bits: 0x207fffff}
+	header := &wire.BlockHeader{Bits: 0x207fffff}
+
+	// the mainnet pow limit is exceeded
+	mineTestHeader(parent, true)
+	header.PrevBlock = parent.BlockHash()
+	mineTestHeader(header, true)
+	require.Equal(t, ErrSpvInvalidProofOfWork, verifier.checkHeader(header, parent, 2016))
+
+	verifier.params = &chaincfg.SimNetParams
+	require.NoError(t, verifier.checkHeader(header, parent, 2015))
+	params := *verifier.params
+	params.ReduceMinDifficulty = false
+	verifier.params = &params
+	require.NoError(t, verifier.checkHeader(header, parent, 2016))
+
+	// the difficulty can be changed only at the retarget height, and at most 4 times.
+	header.Bits = 0x203fffff
+	mineTestHeader(header, true)
+	require.Equal(t, ErrSpvInvalidProofOfWork, verifier.checkHeader(header, parent, 2015))
+	require.NoError(t, verifier.checkHeader(header, parent, 2016))
+	header.Bits = 0x200fffff
+	mineTestHeader(header, true)
+	require.Equal(t, ErrSpvInvalidProofOfWork, verifier.checkHeader(header, parent, 2016))
+}
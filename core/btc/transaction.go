@@ -2,8 +2,11 @@ package btc
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"regexp"
+	"strconv"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
@@ -41,32 +44,46 @@ func EnsurePsbtFinalize(packet *psbt.Packet) error {
 }
 
 func DecodePsbtTxToPacket(encode string) (*psbt.Packet, error) {
-	hexReg := regexp.MustCompile("^[a-fA-F0-9]+$")
-	b64Reg := regexp.MustCompile("^(?:[A-Za-z0-9+/]{4})*(?:[A-Za-z0-9+/]{2}==|[A-Za-z0-9+/]{3}=)?$")
-	var (
-		encodeRaw []byte
-		isB64     bool
-		err       error
-	)
-	switch {
-	case hexReg.MatchString(encode):
-		encodeRaw, err = hex.DecodeString(encode)
-		if err != nil {
+	encodeRaw, err := decodePsbtRawBytes(encode)
+	if err != nil {
+		return nil, err
+	}
+	// The psbt v2 is converted to v0, so the packet can be processed as usual.
+	version, err := psbtVersionOf(encodeRaw)
+	if err != nil {
+		return nil, err
+	}
+	switch version {
+	case 0:
+	case 2:
+		if encodeRaw, err = convertPsbtV2ToV0(encodeRaw); err != nil {
 			return nil, err
 		}
-	case b64Reg.MatchString(encode):
-		encodeRaw = []byte(encode)
-		isB64 = true
 	default:
-		return nil, ErrPsbtEncode
+		return nil, errors.New("unsupported psbt version " + strconv.FormatUint(uint64(version), 10))
 	}
-	packet, err := psbt.NewFromRawBytes(bytes.NewReader(encodeRaw), isB64)
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(encodeRaw), false)
 	if err != nil {
 		return nil, err
 	}
 	return packet, nil
 }
 
+// decodePsbtRawBytes
+// @param encode the psbt in hex or base64
+func decodePsbtRawBytes(encode string) ([]byte, error) {
+	hexReg := regexp.MustCompile("^[a-fA-F0-9]+$")
+	b64Reg := regexp.MustCompile("^(?:[A-Za-z0-9+/]{4})*(?:[A-Za-z0-9+/]{2}==|[A-Za-z0-9+/]{3}=)?$")
+	switch {
+	case hexReg.MatchString(encode):
+		return hex.DecodeString(encode)
+	case b64Reg.MatchString(encode):
+		return base64.StdEncoding.DecodeString(encode)
+	default:
+		return nil, ErrPsbtEncode
+	}
+}
+
 // SignPSBTTx just support segwit v0 & v1(Taproot)
 func SignPSBTTx(tx *psbt.Packet, account *Account) error {
 	updater, err := psbt.NewUpdater(tx)
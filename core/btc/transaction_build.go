@@ -107,6 +107,24 @@ func (t *Transaction) AddOutput(address string, value int64) error {
 	return nil
 }
 
+// addOutputScript add an output with the raw pkScript.
+func (t *Transaction) addOutputScript(pkScript []byte, value int64) {
+	t.msgTx.TxOut = append(t.msgTx.TxOut, wire.NewTxOut(value, pkScript))
+}
+
+func (t *Transaction) containsInput(txId string, index int64) bool {
+	point, err := outPoint(txId, uint32(index))
+	if err != nil {
+		return false
+	}
+	for _, in := range t.msgTx.TxIn {
+		if in.PreviousOutPoint == *point {
+			return true
+		}
+	}
+	return false
+}
+
 // Add op_return to the outputs.
 func (t *Transaction) AddOpReturn(opReturn string) error {
 	data := []byte(opReturn)
@@ -0,0 +1,317 @@
+package btc
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+const (
+	// The sequence that signals the opt-in replace-by-fee (BIP125)
+	rbfSequence = wire.MaxTxInSequenceNum - 2
+
+	// The incremental relay fee rate of the replacement, sat/vB
+	incrementalRelayFeeRate = 1
+)
+
+var (
+	ErrNoBumpableOutput        = errors.New("the transaction has no change output belongs to the sender")
+	ErrChangeOutputNotLast     = errors.New("the change output must be the last output except OP_RETURN")
+	ErrChangeOutputHoldsAssets = errors.New("the change output holds inscriptions or runes")
+	ErrTransactionConfirmed    = errors.New("the transaction has been confirmed")
+)
+
+// BuildRbfTransaction
+// Replace the pending transaction with a higher fee rate, all the outputs are kept in place and in order,
+// the extra fee is only taken from the change output, so the runestone's edicts and pointer still target
+// the same outputs. If the change is not enough, more confirmed utxos of the sender will be spent,
+// the protected utxos that hold inscriptions or runes are never spent.
+// @param txidOrHex the txid of the pending transaction, or it's signed hex.
+// @param sender the address that signed the pending transaction and receives the change.
+// @param changeVout the index of the change output, -1 means the last output that is not OP_RETURN.
+// The change output must be the last one except OP_RETURN, otherwise the sats of the following outputs will be moved.
+// @param feeRate sat/vB, the suggest average fee rate will be used if it <= 0,
+// it will be raised to satisfy the replacement rules.
+func (c *Chain) BuildRbfTransaction(txidOrHex, sender string, changeVout, feeRate int64) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	original, prevOuts, err := c.fetchPendingTransactionWithPrevOuts(txidOrHex)
+	if err != nil {
+		return
+	}
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchSpendableUtxos(sender, nil)
+	if err != nil {
+		return
+	}
+	return buildRbfTransaction(c.Chainnet, original, prevOuts, sender, changeVout, feeRate, utxos)
+}
+
+// BuildCpfpTransaction
+// Build a child transaction that spends the pending transaction's change output,
+// and pays enough fee to make the fee rate of the parent and child reach the feeRate.
+// The other outputs of the parent are never spent, e.g. the postage of the inscriptions or runes.
+// @param txidOrHex the txid of the pending transaction, or it's signed hex.
+// @param sender the owner of the change output, the child transaction's output returns to it.
+// @param changeVout the index of the change output, -1 means the last output that is not OP_RETURN.
+// @param feeRate sat/vB of the package, the suggest average fee rate will be used if it <= 0
+func (c *Chain) BuildCpfpTransaction(txidOrHex, sender string, changeVout, feeRate int64) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	parent, prevOuts, err := c.fetchPendingTransactionWithPrevOuts(txidOrHex)
+	if err != nil {
+		return
+	}
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchSpendableUtxos(sender, nil)
+	if err != nil {
+		return
+	}
+	return buildCpfpTransaction(c.Chainnet, parent, prevOuts, sender, changeVout, feeRate, utxos)
+}
+
+// fetchPendingTransactionWithPrevOuts
+// @return the transaction and the previous outputs of it's inputs,
+// or `ErrTransactionConfirmed` if the transaction has been confirmed, it can't be replaced or accelerated anymore.
+func (c *Chain) fetchPendingTransactionWithPrevOuts(txidOrHex string) (*wire.MsgTx, map[wire.OutPoint]*wire.TxOut, error) {
+	var tx *wire.MsgTx
+	var err error
+	if len(txidOrHex) == chainhash.MaxHashStringSize {
+		tx, err = fetchRawTransaction(txidOrHex, c.Chainnet)
+	} else {
+		tx, err = DecodeTx(txidOrHex)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	status, err := fetchUtxoStatus(tx.TxHash().String(), c.Chainnet)
+	if err != nil {
+		return nil, nil, err
+	}
+	if status.Confirmed {
+		return nil, nil, ErrTransactionConfirmed
+	}
+
+	txids := make([]any, 0, len(tx.TxIn))
+	for _, in := range tx.TxIn {
+		txid := in.PreviousOutPoint.Hash.String()
+		if !slices.Contains(txids, any(txid)) {
+			txids = append(txids, txid)
+		}
+	}
+	prevTxs, err := base.MapListConcurrent(txids, 10, func(i interface{}) (interface{}, error) {
+		return fetchRawTransaction(i.(string), c.Chainnet)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	prevTxMap := make(map[chainhash.Hash]*wire.MsgTx, len(prevTxs))
+	for _, prevTx := range prevTxs {
+		msgTx := prevTx.(*wire.MsgTx)
+		prevTxMap[msgTx.TxHash()] = msgTx
+	}
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(tx.TxIn))
+	for _, in := range tx.TxIn {
+		point := in.PreviousOutPoint
+		prevTx, ok := prevTxMap[point.Hash]
+		if !ok || int(point.Index) >= len(prevTx.TxOut) {
+			return nil, nil, errors.New("the previous output is not found: " + point.String())
+		}
+		prevOuts[point] = prevTx.TxOut[point.Index]
+	}
+	return tx, prevOuts, nil
+}
+
+func buildRbfTransaction(chainnet string, original *wire.MsgTx, prevOuts map[wire.OutPoint]*wire.TxOut,
+	sender string, changeVout, feeRate int64, utxos []*UTXO) (*Transaction, error) {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	senderAddr, err := btcutil.DecodeAddress(sender, net)
+	if err != nil {
+		return nil, err
+	}
+	senderScript, err := txscript.PayToAddrScript(senderAddr)
+	if err != nil {
+		return nil, err
+	}
+	change, err := changeOutputOf(original, senderScript, changeVout)
+	if err != nil {
+		return nil, err
+	}
+	// Reducing the change moves the sats of the following outputs.
+	for _, out := range original.TxOut[change+1:] {
+		if !txscript.IsNullData(out.PkScript) {
+			return nil, ErrChangeOutputNotLast
+		}
+	}
+	originalFee, err := transactionFee(original, prevOuts)
+	if err != nil {
+		return nil, err
+	}
+	originalSize := mempool.GetTxVirtualSize(btcutil.NewTx(original))
+	// The replacement can only spend the confirmed utxos (BIP125 rule 2),
+	// the descendants of the original transaction are unconfirmed, so they are excluded too.
+	pool := confirmedUtxosLargestFirst(utxos, original.TxHash().String())
+
+	txn, err := NewTransaction(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	for _, in := range original.TxIn {
+		txn.msgTx.AddTxIn(wire.NewTxIn(&in.PreviousOutPoint, nil, nil))
+		txn.prevOutFetcher.AddPrevOut(in.PreviousOutPoint, prevOuts[in.PreviousOutPoint])
+	}
+	for _, out := range original.TxOut {
+		txn.addOutputScript(out.PkScript, out.Value)
+	}
+	otherOutputs := txn.TotalOutputValue() - original.TxOut[change].Value
+
+	// The replacement must pay a higher fee rate, and the extra fee must pay for it's own relay.
+	feeRate = max(feeRate, ceilDiv(originalFee, originalSize)+incrementalRelayFeeRate)
+	for {
+		size := EstimateTxSize(txn.msgTx, senderAddr)
+		fee := max(feeRate*size, originalFee+size*incrementalRelayFeeRate)
+		value := txn.TotalInputValue() - otherOutputs - fee
+		if value >= mempool.GetDustThreshold(txn.msgTx.TxOut[change]) {
+			txn.SetOutputValue(value, change)
+			break
+		}
+		if len(pool) == 0 {
+			return nil, ErrInsufficientBalance
+		}
+		if err = txn.AddInput(pool[0].Txid, pool[0].Vout, sender, pool[0].Value); err != nil {
+			return nil, err
+		}
+		pool = pool[1:]
+	}
+	for _, in := range txn.msgTx.TxIn {
+		in.Sequence = rbfSequence
+	}
+	return txn, nil
+}
+
+func buildCpfpTransaction(chainnet string, parent *wire.MsgTx, prevOuts map[wire.OutPoint]*wire.TxOut,
+	sender string, changeVout, feeRate int64, utxos []*UTXO) (*Transaction, error) {
+	txn, err := NewTransaction(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	senderAddr, err := btcutil.DecodeAddress(sender, txn.netParams)
+	if err != nil {
+		return nil, err
+	}
+	senderScript, err := txscript.PayToAddrScript(senderAddr)
+	if err != nil {
+		return nil, err
+	}
+	change, err := changeOutputOf(parent, senderScript, changeVout)
+	if err != nil {
+		return nil, err
+	}
+	parentFee, err := transactionFee(parent, prevOuts)
+	if err != nil {
+		return nil, err
+	}
+	parentSize := mempool.GetTxVirtualSize(btcutil.NewTx(parent))
+
+	parentHash := parent.TxHash()
+	point := wire.NewOutPoint(&parentHash, uint32(change))
+	txn.msgTx.AddTxIn(wire.NewTxIn(point, nil, nil))
+	txn.prevOutFetcher.AddPrevOut(*point, parent.TxOut[change])
+	txn.addOutputScript(senderScript, 0)
+
+	// Spend more confirmed utxos if the change can't pay the fee, the largest first,
+	// the unconfirmed utxos have their own ancestors that lower the package fee rate.
+	pool := confirmedUtxosLargestFirst(utxos, parentHash.String())
+	for {
+		childSize := EstimateTxSize(txn.msgTx, senderAddr)
+		fee := max(feeRate*(parentSize+childSize)-parentFee, childSize*incrementalRelayFeeRate)
+		value := txn.TotalInputValue() - fee
+		if value >= mempool.GetDustThreshold(txn.msgTx.TxOut[0]) {
+			txn.SetOutputValue(value, 0)
+			break
+		}
+		if len(pool) == 0 {
+			return nil, ErrInsufficientBalance
+		}
+		if err = txn.AddInput(pool[0].Txid, pool[0].Vout, sender, pool[0].Value); err != nil {
+			return nil, err
+		}
+		pool = pool[1:]
+	}
+	for _, in := range txn.msgTx.TxIn {
+		in.Sequence = rbfSequence
+	}
+	return txn, nil
+}
+
+// changeOutputOf
+// @param changeVout the index of the change output, -1 means the last output that is not OP_RETURN.
+// @return the index of the change output, it must belong to the sender and not receive the inscriptions or runes.
+func changeOutputOf(tx *wire.MsgTx, senderScript []byte, changeVout int64) (int, error) {
+	if changeVout < 0 {
+		for vout := len(tx.TxOut) - 1; vout >= 0; vout-- {
+			if !txscript.IsNullData(tx.TxOut[vout].PkScript) {
+				changeVout = int64(vout)
+				break
+			}
+		}
+	}
+	if changeVout < 0 || changeVout >= int64(len(tx.TxOut)) || !bytes.Equal(tx.TxOut[changeVout].PkScript, senderScript) {
+		return -1, ErrNoBumpableOutput
+	}
+	assets := newTxOutputAssets(tx)
+	if len(assets.inscriptions[int(changeVout)]) > 0 || len(assets.runes[int(changeVout)]) > 0 {
+		return -1, ErrChangeOutputHoldsAssets
+	}
+	return int(changeVout), nil
+}
+
+// confirmedUtxosLargestFirst
+// @return the confirmed utxos that are not created by the excluded transaction, sorted by value descending.
+func confirmedUtxosLargestFirst(utxos []*UTXO, excludedTxid string) []*UTXO {
+	pool := make([]*UTXO, 0, len(utxos))
+	for _, utxo := range utxos {
+		if utxo.Confirmed() && utxo.Txid != excludedTxid {
+			pool = append(pool, utxo)
+		}
+	}
+	slices.SortFunc(pool, func(a, b *UTXO) int { return int(b.Value - a.Value) })
+	return pool
+}
+
+// transactionFee
+// @return the total value of the inputs minus the total value of the outputs.
+func transactionFee(tx *wire.MsgTx, prevOuts map[wire.OutPoint]*wire.TxOut) (int64, error) {
+	fee := int64(0)
+	for _, in := range tx.TxIn {
+		prevOut, ok := prevOuts[in.PreviousOutPoint]
+		if !ok {
+			return 0, errors.New("the previous output is not found: " + in.PreviousOutPoint.String())
+		}
+		fee += prevOut.Value
+	}
+	for _, out := range tx.TxOut {
+		fee -= out.Value
+	}
+	return fee, nil
+}
+
+func ceilDiv(a, b int64) int64 {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,191 @@
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/btc/runes"
+	"github.com/stretchr/testify/require"
+)
+
+func verifyTransaction(t *testing.T, tx *wire.MsgTx, fetcher txscript.PrevOutputFetcher) {
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+	for i, in := range tx.TxIn {
+		prevOut := fetcher.FetchPrevOutput(in.PreviousOutPoint)
+		engine, err := txscript.NewEngine(prevOut.PkScript, tx, i, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, fetcher)
+		require.NoError(t, err)
+		require.NoError(t, engine.Execute())
+	}
+}
+
+func signTestTransaction(t *testing.T, txn *Transaction, account *Account) *wire.MsgTx {
+	signed, err := txn.SignedTransactionWithAccount(account)
+	require.NoError(t, err)
+	msgTx := signed.(*SignedTransaction).msgTx
+	verifyTransaction(t, msgTx, txn.prevOutFetcher)
+	return msgTx
+}
+
+func TestBuildRbfAndCpfpTransaction(t *testing.T) {
+	mnemonic := "antenna chaos arrive hungry distance human question history decade deal impose color"
+	receiver := "tb1pqtguh4mt0206qr7t3pze5zf4st4v3xtvqfhgv7q7j6ymnv7gtutqy4nrud"
+
+	for _, addrType := range []AddressType{AddressTypeComingTaproot, AddressTypeNativeSegwit,
+		AddressTypeNestedSegwit, AddressTypeTaproot, AddressTypeLegacy} {
+		account, err := NewAccountWithMnemonic(mnemonic, ChainSignet, addrType)
+		require.NoError(t, err)
+		desc := AddressTypeDescription(addrType)
+		utxos := newTestUtxos(400000, 100000)
+		for _, utxo := range utxos {
+			utxo.Status = &UTXOStatus{Confirmed: true}
+		}
+
+		txn, err := buildTransferWithUtxos(ChainSignet, account.Address(), receiver, 40000, 2, utxos[1:])
+		require.NoError(t, err)
+		original := signTestTransaction(t, txn, account)
+		prevOuts := map[wire.OutPoint]*wire.TxOut{}
+		for _, in := range original.TxIn {
+			prevOuts[in.PreviousOutPoint] = txn.prevOutFetcher.FetchPrevOutput(in.PreviousOutPoint)
+		}
+		originalFee := txn.TotalInputValue() - txn.TotalOutputValue()
+
+		// RBF
+		rbf, err := buildRbfTransaction(ChainSignet, original, prevOuts, account.Address(), -1, 10, utxos[:1])
+		require.NoError(t, err)
+		require.Equal(t, original.TxOut[0], rbf.msgTx.TxOut[0], desc)
+		require.Equal(t, 2, len(rbf.msgTx.TxOut), desc)
+		require.Equal(t, original.TxOut[1].PkScript, rbf.msgTx.TxOut[1].PkScript, desc)
+		require.Equal(t, 1, len(rbf.msgTx.TxIn), desc)
+		require.Equal(t, original.TxIn[0].PreviousOutPoint, rbf.msgTx.TxIn[0].PreviousOutPoint, desc)
+		require.Equal(t, uint32(rbfSequence), rbf.msgTx.TxIn[0].Sequence)
+		replacement := signTestTransaction(t, rbf, account)
+		fee := rbf.TotalInputValue() - rbf.TotalOutputValue()
+		vsize := mempool.GetTxVirtualSize(btcutil.NewTx(replacement))
+		require.GreaterOrEqual(t, fee, 10*vsize, desc)
+		require.GreaterOrEqual(t, fee, originalFee+vsize, desc)
+
+		// The fee rate is raised if it's not higher than the original
+		rbf, err = buildRbfTransaction(ChainSignet, original, prevOuts, account.Address(), -1, 1, nil)
+		require.NoError(t, err)
+		require.Greater(t, rbf.TotalInputValue()-rbf.TotalOutputValue(), originalFee, desc)
+
+		// The change can't pay the fee, more utxos are spent
+		rbf, err = buildRbfTransaction(ChainSignet, original, prevOuts, account.Address(), 1, 800, utxos[:1])
+		require.NoError(t, err)
+		require.Equal(t, 2, len(rbf.msgTx.TxIn), desc)
+		require.Equal(t, original.TxOut[0], rbf.msgTx.TxOut[0], desc)
+		signTestTransaction(t, rbf, account)
+
+		// The unconfirmed utxos can't be spent by the replacement
+		unconfirmed := newTestUtxos(400000)
+		_, err = buildRbfTransaction(ChainSignet, original, prevOuts, account.Address(), -1, 800, unconfirmed)
+		require.ErrorIs(t, err, ErrInsufficientBalance, desc)
+
+		// CPFP
+		cpfp, err := buildCpfpTransaction(ChainSignet, original, prevOuts, account.Address(), -1, 20, nil)
+		require.NoError(t, err)
+		require.Equal(t, original.TxHash(), cpfp.msgTx.TxIn[0].PreviousOutPoint.Hash, desc)
+		require.Equal(t, uint32(1), cpfp.msgTx.TxIn[0].PreviousOutPoint.Index, desc)
+		child := signTestTransaction(t, cpfp, account)
+		childFee := cpfp.TotalInputValue() - cpfp.TotalOutputValue()
+		packageSize := mempool.GetTxVirtualSize(btcutil.NewTx(original)) + mempool.GetTxVirtualSize(btcutil.NewTx(child))
+		require.GreaterOrEqual(t, originalFee+childFee, 20*packageSize, desc)
+	}
+}
+
+func TestBuildCpfpTransaction_NoOutput(t *testing.T) {
+	account, err := NewAccountWithMnemonic("antenna chaos arrive hungry distance human question history decade deal impose color", ChainSignet, AddressTypeTaproot)
+	require.NoError(t, err)
+	txn, err := buildTransferAllWithUtxos(ChainSignet, account.Address(), "tb1pqtguh4mt0206qr7t3pze5zf4st4v3xtvqfhgv7q7j6ymnv7gtutqy4nrud", 2, newTestUtxos(10000))
+	require.NoError(t, err)
+	prevOuts := map[wire.OutPoint]*wire.TxOut{}
+	for _, in := range txn.msgTx.TxIn {
+		prevOuts[in.PreviousOutPoint] = txn.prevOutFetcher.FetchPrevOutput(in.PreviousOutPoint)
+	}
+	_, err = buildCpfpTransaction(ChainSignet, txn.msgTx, prevOuts, account.Address(), -1, 10, nil)
+	require.ErrorIs(t, err, ErrNoBumpableOutput)
+	_, err = buildRbfTransaction(ChainSignet, txn.msgTx, prevOuts, account.Address(), -1, 10, nil)
+	require.ErrorIs(t, err, ErrNoBumpableOutput)
+}
+
+func TestChain_BuildRbfAndCpfpTransaction_Confirmed(t *testing.T) {
+	defer SetBackend(ChainRegtest, nil)
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(5000, make([]byte, 22)))
+	var buf bytes.Buffer
+	require.NoError(t, tx.Serialize(&buf))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tx/"+tx.TxHash().String()+"/status" {
+			_, _ = w.Write([]byte(`{"confirmed":true,"block_height":100,"block_time":1700000000}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	require.NoError(t, SetBackend(ChainRegtest, NewBackend(server.URL, "", "", "", "")))
+
+	chain, err := NewChainWithChainnet(ChainRegtest)
+	require.NoError(t, err)
+	_, err = chain.BuildRbfTransaction(hex.EncodeToString(buf.Bytes()), "", -1, 10)
+	require.EqualError(t, err, ErrTransactionConfirmed.Error())
+	_, err = chain.BuildCpfpTransaction(hex.EncodeToString(buf.Bytes()), "", -1, 10)
+	require.EqualError(t, err, ErrTransactionConfirmed.Error())
+}
+
+func TestBuildRbfAndCpfpTransaction_Runes(t *testing.T) {
+	account, err := NewAccountWithMnemonic("antenna chaos arrive hungry distance human question history decade deal impose color", ChainSignet, AddressTypeTaproot)
+	require.NoError(t, err)
+	receiver := "tb1pqtguh4mt0206qr7t3pze5zf4st4v3xtvqfhgv7q7j6ymnv7gtutqy4nrud"
+	utxos := newTestUtxos(546, 50000)
+	runesUtxos := []*RunesUTXO{
+		{Txid: utxos[0].Txid, Vout: 0, Satoshi: 546, Runes: []*RunesBalance{{RuneId: "840000:3", Amount: "300"}}},
+	}
+	// outputs: the receiver's runes, the sender's runes change (pointer), OP_RETURN, the change
+	txn, err := buildRunesTransfer(ChainSignet, account.Address(), receiver, runes.RuneId{Block: 840000, Tx: 3},
+		big.NewInt(100), 2, runesUtxos, utxos[1:])
+	require.NoError(t, err)
+	require.Equal(t, 4, len(txn.msgTx.TxOut))
+	parent := signTestTransaction(t, txn, account)
+	prevOuts := map[wire.OutPoint]*wire.TxOut{}
+	for _, in := range parent.TxIn {
+		prevOuts[in.PreviousOutPoint] = txn.prevOutFetcher.FetchPrevOutput(in.PreviousOutPoint)
+	}
+
+	// RBF keeps all the outputs in place, so the runestone still targets the same outputs
+	rbf, err := buildRbfTransaction(ChainSignet, parent, prevOuts, account.Address(), -1, 20, nil)
+	require.NoError(t, err)
+	require.Equal(t, len(parent.TxIn), len(rbf.msgTx.TxIn))
+	require.Equal(t, len(parent.TxOut), len(rbf.msgTx.TxOut))
+	for vout := 0; vout < 3; vout++ {
+		require.Equal(t, parent.TxOut[vout], rbf.msgTx.TxOut[vout])
+	}
+	require.Less(t, rbf.msgTx.TxOut[3].Value, parent.TxOut[3].Value)
+	artifact, err := runes.Decipher(rbf.msgTx)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), *artifact.(runes.Runestone).Pointer)
+	signTestTransaction(t, rbf, account)
+
+	// CPFP only spends the change, the runes postage is never spent
+	cpfp, err := buildCpfpTransaction(ChainSignet, parent, prevOuts, account.Address(), -1, 20, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(cpfp.msgTx.TxIn))
+	require.Equal(t, wire.OutPoint{Hash: parent.TxHash(), Index: 3}, cpfp.msgTx.TxIn[0].PreviousOutPoint)
+	signTestTransaction(t, cpfp, account)
+
+	// The outputs that receive runes can't be the change
+	_, err = buildCpfpTransaction(ChainSignet, parent, prevOuts, account.Address(), 1, 20, nil)
+	require.ErrorIs(t, err, ErrChangeOutputHoldsAssets)
+	_, err = buildRbfTransaction(ChainSignet, parent, prevOuts, account.Address(), 1, 20, nil)
+	require.ErrorIs(t, err, ErrChangeOutputHoldsAssets)
+}
@@ -10,8 +10,16 @@ import (
 
 // Note: The input parsing of bitcoin is very complex and the network cost is relatively high,
 // So only the status and timestamp can be queried.
+// The status is queried by the esplora if the rpc credentials are not configured.
 func fetchTransactionDetail(hashString, chainnet string) (*base.TransactionDetail, error) {
 	client, err := rpcClientOf(chainnet)
+	if err == ErrRpcCredentialsNotConfigured {
+		esplora, err := esploraClientOf(chainnet)
+		if err != nil {
+			return nil, err
+		}
+		return esplora.FetchTransactionStatus(hashString)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -3,8 +3,12 @@ package btc
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
 
 	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/coming-chat/wallet-SDK/core/base"
 )
 
@@ -20,6 +24,24 @@ func NewPsbtTransaction(psbtString string) (*PsbtTransaction, error) {
 	return &PsbtTransaction{*packet}, nil
 }
 
+// ToPsbtTransaction
+// Convert the unsigned transaction to psbt, the prev outputs will be filled as the witness utxo,
+// so the psbt only can be signed if all the inputs are segwit v0 or v1(Taproot).
+func (t *Transaction) ToPsbtTransaction() (*PsbtTransaction, error) {
+	packet, err := psbt.NewFromUnsignedTx(t.msgTx.Copy())
+	if err != nil {
+		return nil, err
+	}
+	for i, in := range packet.UnsignedTx.TxIn {
+		prevOut := t.prevOutFetcher.FetchPrevOutput(in.PreviousOutPoint)
+		if prevOut == nil {
+			return nil, fmt.Errorf("input %d has no UTXO information", i)
+		}
+		packet.Inputs[i].WitnessUtxo = wire.NewTxOut(prevOut.Value, prevOut.PkScript)
+	}
+	return &PsbtTransaction{*packet}, nil
+}
+
 func (t *PsbtTransaction) SignWithAccount(account base.Account) (signedTxn *base.OptionalString, err error) {
 	return nil, base.ErrUnsupportedFunction
 }
@@ -63,3 +85,113 @@ func (t *SignedPsbtTransaction) PsbtHexString() (*base.OptionalString, error) {
 func (t *SignedPsbtTransaction) PublishWithChain(c *Chain) (hashs *base.OptionalString, err error) {
 	return c.SendSignedTransaction(t)
 }
+
+// CombinePsbts
+// Merge the signatures and the other fields of the psbts that have the same unsigned transaction (BIP174 combiner).
+// @param psbts the psbts in hex or base64
+// @return the combined psbt hex
+func CombinePsbts(psbts *base.StringArray) (combined *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if psbts == nil || psbts.Count() == 0 {
+		return nil, ErrPsbtEncode
+	}
+	packets := make([]*psbt.Packet, 0, psbts.Count())
+	for _, str := range psbts.AnyArray {
+		packet, err := DecodePsbtTxToPacket(str)
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, packet)
+	}
+	packet, err := combinePsbtPackets(packets)
+	if err != nil {
+		return
+	}
+	return encodePsbtHex(packet)
+}
+
+func combinePsbtPackets(packets []*psbt.Packet) (*psbt.Packet, error) {
+	res := packets[0]
+	txHash := res.UnsignedTx.TxHash()
+	for _, packet := range packets[1:] {
+		if packet.UnsignedTx.TxHash() != txHash {
+			return nil, errors.New("can not combine the psbts of different transactions")
+		}
+		for i := range res.Inputs {
+			combinePsbtInput(&res.Inputs[i], &packet.Inputs[i])
+		}
+		for i := range res.Outputs {
+			res.Outputs[i].Bip32Derivation = appendUniqueFunc(res.Outputs[i].Bip32Derivation, packet.Outputs[i].Bip32Derivation,
+				func(a, b *psbt.Bip32Derivation) bool { return bytes.Equal(a.PubKey, b.PubKey) })
+			res.Outputs[i].Unknowns = appendUniqueFunc(res.Outputs[i].Unknowns, packet.Outputs[i].Unknowns,
+				func(a, b *psbt.Unknown) bool { return bytes.Equal(a.Key, b.Key) })
+		}
+		res.Unknowns = appendUniqueFunc(res.Unknowns, packet.Unknowns,
+			func(a, b *psbt.Unknown) bool { return bytes.Equal(a.Key, b.Key) })
+	}
+	return res, nil
+}
+
+func combinePsbtInput(dst, src *psbt.PInput) {
+	if dst.FinalScriptSig == nil && dst.FinalScriptWitness == nil {
+		dst.FinalScriptSig = src.FinalScriptSig
+		dst.FinalScriptWitness = src.FinalScriptWitness
+	}
+	if dst.NonWitnessUtxo == nil {
+		dst.NonWitnessUtxo = src.NonWitnessUtxo
+	}
+	if dst.WitnessUtxo == nil {
+		dst.WitnessUtxo = src.WitnessUtxo
+	}
+	if dst.RedeemScript == nil {
+		dst.RedeemScript = src.RedeemScript
+	}
+	if dst.WitnessScript == nil {
+		dst.WitnessScript = src.WitnessScript
+	}
+	if dst.SighashType == 0 {
+		dst.SighashType = src.SighashType
+	}
+	if dst.TaprootKeySpendSig == nil {
+		dst.TaprootKeySpendSig = src.TaprootKeySpendSig
+	}
+	if dst.TaprootInternalKey == nil {
+		dst.TaprootInternalKey = src.TaprootInternalKey
+	}
+	if dst.TaprootMerkleRoot == nil {
+		dst.TaprootMerkleRoot = src.TaprootMerkleRoot
+	}
+	dst.PartialSigs = appendUniqueFunc(dst.PartialSigs, src.PartialSigs,
+		func(a, b *psbt.PartialSig) bool { return bytes.Equal(a.PubKey, b.PubKey) })
+	dst.Bip32Derivation = appendUniqueFunc(dst.Bip32Derivation, src.Bip32Derivation,
+		func(a, b *psbt.Bip32Derivation) bool { return bytes.Equal(a.PubKey, b.PubKey) })
+	dst.TaprootScriptSpendSig = appendUniqueFunc(dst.TaprootScriptSpendSig, src.TaprootScriptSpendSig,
+		func(a, b *psbt.TaprootScriptSpendSig) bool {
+			return bytes.Equal(a.XOnlyPubKey, b.XOnlyPubKey) && bytes.Equal(a.LeafHash, b.LeafHash)
+		})
+	dst.TaprootLeafScript = appendUniqueFunc(dst.TaprootLeafScript, src.TaprootLeafScript,
+		func(a, b *psbt.TaprootTapLeafScript) bool { return bytes.Equal(a.ControlBlock, b.ControlBlock) })
+	dst.TaprootBip32Derivation = appendUniqueFunc(dst.TaprootBip32Derivation, src.TaprootBip32Derivation,
+		func(a, b *psbt.TaprootBip32Derivation) bool { return bytes.Equal(a.XOnlyPubKey, b.XOnlyPubKey) })
+	dst.Unknowns = appendUniqueFunc(dst.Unknowns, src.Unknowns,
+		func(a, b *psbt.Unknown) bool { return bytes.Equal(a.Key, b.Key) })
+}
+
+// appendUniqueFunc append the elements of src which are not in dst.
+func appendUniqueFunc[T any](dst, src []T, equal func(a, b T) bool) []T {
+	for _, s := range src {
+		if !slices.ContainsFunc(dst, func(d T) bool { return equal(d, s) }) {
+			dst = append(dst, s)
+		}
+	}
+	return dst
+}
+
+func encodePsbtHex(packet *psbt.Packet) (*base.OptionalString, error) {
+	var buff bytes.Buffer
+	if err := packet.Serialize(&buff); err != nil {
+		return nil, err
+	}
+	return &base.OptionalString{Value: hex.EncodeToString(buff.Bytes())}, nil
+}
@@ -0,0 +1,517 @@
+package btc
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/base/inter"
+	"github.com/coming-chat/wallet-SDK/util/hexutil"
+)
+
+const (
+	PsbtInputStatusUnsigned  = "unsigned"
+	PsbtInputStatusPartial   = "partial"
+	PsbtInputStatusSigned    = "signed"
+	PsbtInputStatusFinalized = "finalized"
+
+	// The fee rate (sat/vB) that is considered as a mistake, same as the default maxfeerate of bitcoind.
+	absurdFeeRate = 10000
+)
+
+type PsbtInputAnalysis struct {
+	Index int    `json:"index"`
+	Txid  string `json:"txid"`
+	Vout  int64  `json:"vout"`
+	// The value and address are empty if the input has no utxo information.
+	Value      int64  `json:"value"`
+	Address    string `json:"address"`
+	ScriptType string `json:"scriptType"`
+
+	// The status is one of unsigned, partial, signed (enough signatures but not finalized) and finalized.
+	Status string `json:"status"`
+	// The count of signatures required
+	Threshold int `json:"threshold"`
+	// The public keys that can sign the input, it's empty if the keys are unknown.
+	// The taproot key is x-only.
+	RequiredKeys *base.StringArray `json:"requiredKeys"`
+	SignedKeys   *base.StringArray `json:"signedKeys"`
+
+	SighashType int               `json:"sighashType"`
+	SighashName string            `json:"sighashName"`
+	IsMine      bool              `json:"isMine"`
+	Warnings    *base.StringArray `json:"warnings"`
+}
+
+type PsbtInputAnalysisArray struct {
+	inter.AnyArray[*PsbtInputAnalysis]
+}
+
+type PsbtOutputAnalysis struct {
+	Index      int    `json:"index"`
+	Value      int64  `json:"value"`
+	Address    string `json:"address"`
+	ScriptType string `json:"scriptType"`
+	IsMine     bool   `json:"isMine"`
+}
+
+type PsbtOutputAnalysisArray struct {
+	inter.AnyArray[*PsbtOutputAnalysis]
+}
+
+type PsbtAnalysis struct {
+	// The version of the psbt encoding, 0 or 2
+	Version int    `json:"version"`
+	Txid    string `json:"txid"`
+
+	Inputs  *PsbtInputAnalysisArray  `json:"inputs"`
+	Outputs *PsbtOutputAnalysisArray `json:"outputs"`
+
+	// The total value of the inputs and the fee are -1 if any input has no utxo information.
+	TotalInput  int64 `json:"totalInput"`
+	TotalOutput int64 `json:"totalOutput"`
+	Fee         int64 `json:"fee"`
+	// The virtual size after all inputs are signed, it's estimated if the psbt is not finalized.
+	VSize   int64   `json:"vsize"`
+	FeeRate float64 `json:"feeRate"`
+
+	// All inputs have enough signatures
+	IsComplete bool `json:"isComplete"`
+	// The balance change of the account, negative if the account spends.
+	NetAmount int64             `json:"netAmount"`
+	Warnings  *base.StringArray `json:"warnings"`
+}
+
+func (a *PsbtAnalysis) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(a)
+}
+
+// AnalyzePsbt
+// Analyze the signing status, sighash types and fee of the psbt.
+// @param psbtHex the psbt v0 or v2 in hex or base64
+func AnalyzePsbt(psbtHex, chainnet string) (analysis *PsbtAnalysis, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return
+	}
+	return analyzePsbt(psbtHex, net, nil)
+}
+
+// AnalyzePsbt
+// Same as the `AnalyzePsbt`, and the inputs and outputs owned by the account are marked.
+// @param psbtHex the psbt v0 or v2 in hex or base64
+func (a *Account) AnalyzePsbt(psbtHex string) (analysis *PsbtAnalysis, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+	return analyzePsbt(psbtHex, a.chain, a)
+}
+
+func analyzePsbt(psbtHex string, net *chaincfg.Params, account *Account) (*PsbtAnalysis, error) {
+	raw, err := decodePsbtRawBytes(psbtHex)
+	if err != nil {
+		return nil, err
+	}
+	version, err := psbtVersionOf(raw)
+	if err != nil {
+		return nil, err
+	}
+	packet, err := DecodePsbtTxToPacket(psbtHex)
+	if err != nil {
+		return nil, err
+	}
+	owner := newPsbtOwner(account)
+
+	res := &PsbtAnalysis{
+		Version:    int(version),
+		Txid:       packet.UnsignedTx.TxHash().String(),
+		Inputs:     &PsbtInputAnalysisArray{},
+		Outputs:    &PsbtOutputAnalysisArray{},
+		IsComplete: true,
+		Warnings:   base.NewStringArray(),
+	}
+	for i := range packet.Inputs {
+		input := analyzePsbtInput(packet, i, net, owner)
+		res.Inputs.Append(input)
+		if input.Status != PsbtInputStatusSigned && input.Status != PsbtInputStatusFinalized {
+			res.IsComplete = false
+		}
+		if res.TotalInput >= 0 && input.ScriptType != "" {
+			res.TotalInput += input.Value
+		} else {
+			res.TotalInput = -1
+		}
+		if input.IsMine {
+			res.NetAmount -= input.Value
+		}
+	}
+	for i, out := range packet.UnsignedTx.TxOut {
+		class, address := scriptClassAndAddress(out.PkScript, net)
+		output := &PsbtOutputAnalysis{
+			Index:      i,
+			Value:      out.Value,
+			Address:    address,
+			ScriptType: class.String(),
+			IsMine:     owner.ownsOutput(out.PkScript, &packet.Outputs[i]),
+		}
+		res.Outputs.Append(output)
+		res.TotalOutput += out.Value
+		if output.IsMine {
+			res.NetAmount += out.Value
+		}
+	}
+
+	res.VSize = estimatePsbtVSize(packet)
+	if res.TotalInput < 0 {
+		res.Fee = -1
+		res.Warnings.Append("the fee is unknown because some inputs have no utxo information")
+	} else {
+		res.Fee = res.TotalInput - res.TotalOutput
+		res.FeeRate = float64(res.Fee) / float64(res.VSize)
+		if res.Fee < 0 {
+			res.Warnings.Append("the total output value exceeds the total input value")
+		} else if res.FeeRate > absurdFeeRate {
+			res.Warnings.Append(fmt.Sprintf("the fee rate %.2f sat/vB is abnormally high", res.FeeRate))
+		}
+	}
+	return res, nil
+}
+
+func analyzePsbtInput(packet *psbt.Packet, idx int, net *chaincfg.Params, owner *psbtOwner) *PsbtInputAnalysis {
+	in := &packet.Inputs[idx]
+	point := packet.UnsignedTx.TxIn[idx].PreviousOutPoint
+	res := &PsbtInputAnalysis{
+		Index:        idx,
+		Txid:         point.Hash.String(),
+		Vout:         int64(point.Index),
+		Threshold:    1,
+		RequiredKeys: base.NewStringArray(),
+		SignedKeys:   base.NewStringArray(),
+		Warnings:     base.NewStringArray(),
+	}
+
+	prevOut := psbtInputPrevOut(packet, idx)
+	if in.NonWitnessUtxo != nil && in.NonWitnessUtxo.TxHash() != point.Hash {
+		res.Warnings.Append("the non-witness utxo does not match the previous output")
+	}
+	if prevOut == nil {
+		res.Warnings.Append("the input has no utxo information")
+		res.Status = PsbtInputStatusUnsigned
+		if in.FinalScriptSig != nil || in.FinalScriptWitness != nil {
+			res.Status = PsbtInputStatusFinalized
+		}
+		return res
+	}
+	class, address := scriptClassAndAddress(prevOut.PkScript, net)
+	res.Value = prevOut.Value
+	res.Address = address
+	res.ScriptType = class.String()
+
+	sigHashes := res.collectSigningKeys(in, prevOut.PkScript, net, owner)
+	res.IsMine = owner.ownsInput(prevOut.PkScript, res.RequiredKeys)
+	switch {
+	case in.FinalScriptSig != nil || in.FinalScriptWitness != nil:
+		res.Status = PsbtInputStatusFinalized
+	case res.SignedKeys.Count() >= res.Threshold:
+		res.Status = PsbtInputStatusSigned
+	case res.SignedKeys.Count() > 0:
+		res.Status = PsbtInputStatusPartial
+	default:
+		res.Status = PsbtInputStatusUnsigned
+	}
+
+	// The sighash type of the psbt field takes precedence over the signatures'.
+	sigHashType := txscript.SigHashAll
+	if txscript.IsPayToTaproot(prevOut.PkScript) {
+		sigHashType = txscript.SigHashDefault
+	}
+	if in.SighashType != 0 {
+		sigHashType = in.SighashType
+	} else if len(sigHashes) > 0 {
+		sigHashType = sigHashes[0]
+	}
+	for _, t := range sigHashes {
+		if t != sigHashType {
+			res.Warnings.Append(fmt.Sprintf("the signature's sighash type %s is different from %s", sighashName(t), sighashName(sigHashType)))
+		}
+	}
+	res.SighashType = int(sigHashType)
+	res.SighashName = sighashName(sigHashType)
+	for _, warning := range sighashWarnings(sigHashType, idx, len(packet.UnsignedTx.TxOut)) {
+		res.Warnings.Append(warning)
+	}
+	return res
+}
+
+// collectSigningKeys
+// Fill the threshold, required keys and signed keys according to the script.
+// @return the sighash types of the signatures
+func (r *PsbtInputAnalysis) collectSigningKeys(in *psbt.PInput, pkScript []byte, net *chaincfg.Params, owner *psbtOwner) []txscript.SigHashType {
+	var sigHashes []txscript.SigHashType
+	if txscript.IsPayToTaproot(pkScript) {
+		outputKey := hexutil.HexEncodeToString(pkScript[2:])
+		r.RequiredKeys.Append(outputKey)
+		if in.TaprootKeySpendSig != nil {
+			r.SignedKeys.Append(outputKey)
+			sigHashes = append(sigHashes, schnorrSigHashType(in.TaprootKeySpendSig))
+		}
+		for _, sig := range in.TaprootScriptSpendSig {
+			key := hexutil.HexEncodeToString(sig.XOnlyPubKey)
+			if !r.SignedKeys.Contains(key) {
+				r.SignedKeys.Append(key)
+			}
+			sigHashes = append(sigHashes, sig.SigHash)
+		}
+		return sigHashes
+	}
+
+	for _, sig := range in.PartialSigs {
+		r.SignedKeys.Append(hexutil.HexEncodeToString(sig.PubKey))
+		if len(sig.Signature) > 0 {
+			sigHashes = append(sigHashes, txscript.SigHashType(sig.Signature[len(sig.Signature)-1]))
+		}
+	}
+	script := pkScript
+	if txscript.IsPayToScriptHash(script) && in.RedeemScript != nil {
+		script = in.RedeemScript
+	}
+	if txscript.IsPayToWitnessScriptHash(script) && in.WitnessScript != nil {
+		script = in.WitnessScript
+	}
+	if txscript.GetScriptClass(script) == txscript.MultiSigTy {
+		_, addresses, threshold, err := txscript.ExtractPkScriptAddrs(script, net)
+		if err == nil {
+			r.Threshold = threshold
+			for _, addr := range addresses {
+				if pubkey, ok := addr.(*btcutil.AddressPubKey); ok {
+					r.RequiredKeys.Append(hexutil.HexEncodeToString(pubkey.ScriptAddress()))
+				}
+			}
+		}
+		return sigHashes
+	}
+
+	// The single key script, the key is found from the signatures, the derivations and the account.
+	keys := make([][]byte, 0, len(in.PartialSigs)+len(in.Bip32Derivation)+1)
+	for _, sig := range in.PartialSigs {
+		keys = append(keys, sig.PubKey)
+	}
+	for _, derivation := range in.Bip32Derivation {
+		keys = append(keys, derivation.PubKey)
+	}
+	if owner != nil {
+		keys = append(keys, owner.pubkey)
+	}
+	for _, key := range keys {
+		if pubkeyHashMatches(script, key) && !r.RequiredKeys.Contains(hexutil.HexEncodeToString(key)) {
+			r.RequiredKeys.Append(hexutil.HexEncodeToString(key))
+		}
+	}
+	return sigHashes
+}
+
+// pubkeyHashMatches
+// @return true if the script is P2PKH or P2WPKH of the public key.
+func pubkeyHashMatches(script, pubkey []byte) bool {
+	hash := btcutil.Hash160(pubkey)
+	switch {
+	case txscript.IsPayToPubKeyHash(script):
+		return bytes.Equal(script[3:23], hash)
+	case txscript.IsPayToWitnessPubKeyHash(script):
+		return bytes.Equal(script[2:], hash)
+	}
+	return false
+}
+
+func schnorrSigHashType(sig []byte) txscript.SigHashType {
+	if len(sig) == schnorr.SignatureSize+1 {
+		return txscript.SigHashType(sig[schnorr.SignatureSize])
+	}
+	return txscript.SigHashDefault
+}
+
+func sighashName(t txscript.SigHashType) string {
+	var name string
+	switch t & ^txscript.SigHashAnyOneCanPay {
+	case txscript.SigHashDefault:
+		name = "DEFAULT"
+	case txscript.SigHashAll:
+		name = "ALL"
+	case txscript.SigHashNone:
+		name = "NONE"
+	case txscript.SigHashSingle:
+		name = "SINGLE"
+	default:
+		name = fmt.Sprintf("UNKNOWN(%d)", t)
+	}
+	if t&txscript.SigHashAnyOneCanPay != 0 {
+		name += "|ANYONECANPAY"
+	}
+	return name
+}
+
+// sighashWarnings
+// @return the risks of the sighash type, empty if it commits to all inputs and outputs.
+func sighashWarnings(t txscript.SigHashType, idx, outputCount int) []string {
+	var warnings []string
+	switch t & ^txscript.SigHashAnyOneCanPay {
+	case txscript.SigHashDefault, txscript.SigHashAll:
+	case txscript.SigHashNone:
+		warnings = append(warnings, "SIGHASH_NONE: the outputs are not signed, anyone can redirect the funds")
+	case txscript.SigHashSingle:
+		if idx >= outputCount {
+			warnings = append(warnings, "SIGHASH_SINGLE: there is no output at the same index, the signature may be reused to spend the input")
+		} else {
+			warnings = append(warnings, "SIGHASH_SINGLE: only the output at the same index is signed, the other outputs can be changed")
+		}
+	default:
+		warnings = append(warnings, "the sighash type is unknown")
+	}
+	if t&txscript.SigHashAnyOneCanPay != 0 {
+		warnings = append(warnings, "SIGHASH_ANYONECANPAY: the other inputs are not signed, the inputs can be added or removed")
+	}
+	return warnings
+}
+
+func psbtInputPrevOut(packet *psbt.Packet, idx int) *wire.TxOut {
+	in := &packet.Inputs[idx]
+	if in.WitnessUtxo != nil {
+		return in.WitnessUtxo
+	}
+	if in.NonWitnessUtxo != nil {
+		index := packet.UnsignedTx.TxIn[idx].PreviousOutPoint.Index
+		if int(index) < len(in.NonWitnessUtxo.TxOut) {
+			return in.NonWitnessUtxo.TxOut[index]
+		}
+	}
+	return nil
+}
+
+func scriptClassAndAddress(pkScript []byte, net *chaincfg.Params) (txscript.ScriptClass, string) {
+	class, addresses, _, err := txscript.ExtractPkScriptAddrs(pkScript, net)
+	if err != nil || len(addresses) != 1 || class == txscript.MultiSigTy {
+		return class, ""
+	}
+	return class, addresses[0].EncodeAddress()
+}
+
+// estimatePsbtVSize
+// The unsigned inputs are filled with the placeholder signatures according to the script.
+func estimatePsbtVSize(packet *psbt.Packet) int64 {
+	tx := packet.UnsignedTx.Copy()
+	for i, txIn := range tx.TxIn {
+		in := &packet.Inputs[i]
+		if in.FinalScriptSig != nil || in.FinalScriptWitness != nil {
+			txIn.SignatureScript = in.FinalScriptSig
+			if in.FinalScriptWitness != nil {
+				witness, err := readTxWitness(bytes.NewReader(in.FinalScriptWitness))
+				if err == nil {
+					txIn.Witness = witness
+				}
+			}
+			continue
+		}
+		var pkScript []byte
+		if prevOut := psbtInputPrevOut(packet, i); prevOut != nil {
+			pkScript = prevOut.PkScript
+		}
+		txIn.SignatureScript, txIn.Witness = placeholderSignature(in, pkScript)
+	}
+	return mempool.GetTxVirtualSize(btcutil.NewTx(tx))
+}
+
+func placeholderSignature(in *psbt.PInput, pkScript []byte) ([]byte, wire.TxWitness) {
+	script := pkScript
+	var sigScript []byte
+	if txscript.IsPayToScriptHash(script) && in.RedeemScript != nil {
+		script = in.RedeemScript
+		sigScript = make([]byte, len(in.RedeemScript)+3)
+	}
+	if txscript.IsPayToWitnessScriptHash(script) && in.WitnessScript != nil {
+		script = in.WitnessScript
+		if class := txscript.GetScriptClass(script); class == txscript.MultiSigTy {
+			_, _, threshold, _ := txscript.ExtractPkScriptAddrs(script, &chaincfg.MainNetParams)
+			witness := wire.TxWitness{{}}
+			for i := 0; i < threshold; i++ {
+				witness = append(witness, make([]byte, 73))
+			}
+			return sigScript, append(witness, script)
+		}
+	}
+	if txscript.GetScriptClass(script) == txscript.MultiSigTy && sigScript != nil {
+		_, _, threshold, _ := txscript.ExtractPkScriptAddrs(script, &chaincfg.MainNetParams)
+		return make([]byte, 1+74*threshold+len(sigScript)), nil
+	}
+	switch {
+	case txscript.IsPayToTaproot(script):
+		return nil, wire.TxWitness{make([]byte, 64)}
+	case txscript.IsPayToPubKeyHash(script):
+		return make([]byte, 106), nil
+	case txscript.IsPayToWitnessPubKeyHash(script):
+		return sigScript, wire.TxWitness{make([]byte, 72), make([]byte, 33)}
+	}
+	// unknown script, the average: NestedSegwit
+	return make([]byte, 23), wire.TxWitness{make([]byte, 108)}
+}
+
+// psbtOwner checks the inputs and outputs belong to the account.
+type psbtOwner struct {
+	pkScript []byte
+	pubkey   []byte
+	keys     []string
+}
+
+func newPsbtOwner(account *Account) *psbtOwner {
+	if account == nil {
+		return nil
+	}
+	pkScript, _ := addressToPkScript(account.address, account.chain)
+	pubkey := account.privateKey.PubKey()
+	return &psbtOwner{
+		pkScript: pkScript,
+		pubkey:   pubkey.SerializeCompressed(),
+		keys: []string{
+			hexutil.HexEncodeToString(pubkey.SerializeCompressed()),
+			hexutil.HexEncodeToString(schnorr.SerializePubKey(pubkey)),
+			hexutil.HexEncodeToString(schnorr.SerializePubKey(txscript.ComputeTaprootKeyNoScript(pubkey))),
+		},
+	}
+}
+
+func (o *psbtOwner) ownsInput(pkScript []byte, requiredKeys *base.StringArray) bool {
+	if o == nil {
+		return false
+	}
+	if bytes.Equal(pkScript, o.pkScript) {
+		return true
+	}
+	for _, key := range requiredKeys.AnyArray {
+		if slices.Contains(o.keys, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *psbtOwner) ownsOutput(pkScript []byte, out *psbt.POutput) bool {
+	if o == nil {
+		return false
+	}
+	if bytes.Equal(pkScript, o.pkScript) {
+		return true
+	}
+	for _, derivation := range out.Bip32Derivation {
+		if slices.Contains(o.keys, hexutil.HexEncodeToString(derivation.PubKey)) {
+			return true
+		}
+	}
+	return false
+}
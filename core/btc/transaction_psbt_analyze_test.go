@@ -0,0 +1,212 @@
+package btc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAccountPsbt(t *testing.T, account *Account, value int64, receiver string, amount int64) *psbt.Packet {
+	pkScript, err := addressToPkScript(account.address, account.chain)
+	require.NoError(t, err)
+	receiverScript, err := addressToPkScript(receiver, account.chain)
+	require.NoError(t, err)
+	hash := chainhash.DoubleHashH([]byte(account.address))
+	packet, err := psbt.New(
+		[]*wire.OutPoint{wire.NewOutPoint(&hash, 1)},
+		[]*wire.TxOut{wire.NewTxOut(amount, receiverScript), wire.NewTxOut(value-amount-1000, pkScript)},
+		2, 0, []uint32{wire.MaxTxInSequenceNum - 2},
+	)
+	require.NoError(t, err)
+	packet.Inputs[0].WitnessUtxo = wire.NewTxOut(value, pkScript)
+	return packet
+}
+
+func TestPsbtV2_RoundTrip(t *testing.T) {
+	signers := newTestSigners(t)
+	wallet, err := NewMultisigWallet(2, testPubkeys(signers...), ChainSignet)
+	require.NoError(t, err)
+	txn, err := buildMultisigTransfer(wallet, wallet.Address(), 50000, 2, newTestUtxos(100000))
+	require.NoError(t, err)
+	v0, err := encodePsbtHex(&txn.Packet)
+	require.NoError(t, err)
+
+	v2, err := ConvertPsbtToV2(v0.Value)
+	require.NoError(t, err)
+	raw, err := decodePsbtRawBytes(v2.Value)
+	require.NoError(t, err)
+	version, err := psbtVersionOf(raw)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), version)
+
+	back, err := ConvertPsbtToV0(v2.Value)
+	require.NoError(t, err)
+	require.Equal(t, v0.Value, back.Value)
+
+	// the v2 psbt can be signed and combined as the v0
+	signed0, err := wallet.SignPsbt(v2.Value, signers[0])
+	require.NoError(t, err)
+	signed1, err := wallet.SignPsbt(v2.Value, signers[1])
+	require.NoError(t, err)
+	signed1V2, err := ConvertPsbtToV2(signed1.Value)
+	require.NoError(t, err)
+	combined, err := CombinePsbts(&base.StringArray{AnyArray: []string{signed0.Value, signed1V2.Value}})
+	require.NoError(t, err)
+	signedTxn, err := wallet.FinalizePsbt(combined.Value)
+	require.NoError(t, err)
+	verifyPsbtTransaction(t, signedTxn)
+}
+
+func TestPsbtV2_Locktime(t *testing.T) {
+	signers := newTestSigners(t)
+	packet := newTestAccountPsbt(t, signers[1], 100000, signers[0].address, 50000)
+	raw, err := serializePsbtV2(packet)
+	require.NoError(t, err)
+
+	// the PSBT_IN_REQUIRED_HEIGHT_LOCKTIME overrides the fallback locktime
+	r := bytes.NewReader(raw[len(psbtMagic):])
+	global, err := readPsbtMap(r)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 0}, global.get(psbtGlobalFallbackLocktime))
+	input, err := readPsbtMap(r)
+	require.NoError(t, err)
+	input = append(input, &psbtKeyValue{key: []byte{psbtInRequiredHeight}, value: []byte{0x40, 0x0d, 0x03, 0x00}})
+	var buf bytes.Buffer
+	buf.Write(psbtMagic)
+	require.NoError(t, writePsbtMap(&buf, global))
+	require.NoError(t, writePsbtMap(&buf, input))
+	rest := raw[len(raw)-r.Len():]
+	withHeight := append(buf.Bytes(), rest...)
+
+	v0, err := convertPsbtV2ToV0(withHeight)
+	require.NoError(t, err)
+	decoded, err := psbt.NewFromRawBytes(bytes.NewReader(v0), false)
+	require.NoError(t, err)
+	require.Equal(t, uint32(200000), decoded.UnsignedTx.LockTime)
+	require.Equal(t, wire.MaxTxInSequenceNum-2, decoded.UnsignedTx.TxIn[0].Sequence)
+}
+
+func TestAnalyzePsbt_Multisig(t *testing.T) {
+	signers := newTestSigners(t)
+	wallet, err := NewMultisigWallet(2, testPubkeys(signers...), ChainSignet)
+	require.NoError(t, err)
+	txn, err := buildMultisigTransfer(wallet, signers[0].address, 150000, 10, newTestUtxos(100000, 100000))
+	require.NoError(t, err)
+	unsigned, err := encodePsbtHex(&txn.Packet)
+	require.NoError(t, err)
+
+	analysis, err := AnalyzePsbt(unsigned.Value, ChainSignet)
+	require.NoError(t, err)
+	require.False(t, analysis.IsComplete)
+	require.Equal(t, int64(200000), analysis.TotalInput)
+	require.Equal(t, analysis.TotalInput-analysis.TotalOutput, analysis.Fee)
+	require.InDelta(t, 10, analysis.FeeRate, 0.5)
+	require.Equal(t, 0, analysis.Warnings.Count())
+	for _, input := range analysis.Inputs.AnyArray {
+		require.Equal(t, PsbtInputStatusUnsigned, input.Status)
+		require.Equal(t, 2, input.Threshold)
+		require.Equal(t, 3, input.RequiredKeys.Count())
+		require.Equal(t, "ALL", input.SighashName)
+		require.Equal(t, 0, input.Warnings.Count())
+	}
+	require.Equal(t, signers[0].address, analysis.Outputs.ValueAt(0).Address)
+	require.Equal(t, wallet.Address(), analysis.Outputs.ValueAt(1).Address)
+
+	signed0, err := wallet.SignPsbt(unsigned.Value, signers[0])
+	require.NoError(t, err)
+	analysis, err = signers[2].AnalyzePsbt(signed0.Value)
+	require.NoError(t, err)
+	input := analysis.Inputs.ValueAt(0)
+	require.Equal(t, PsbtInputStatusPartial, input.Status)
+	require.Equal(t, testPubkeys(signers[0]).AnyArray, input.SignedKeys.AnyArray)
+	require.True(t, input.IsMine)
+	require.False(t, analysis.Outputs.ValueAt(0).IsMine)
+	require.Equal(t, int64(-200000), analysis.NetAmount)
+
+	signed2, err := wallet.SignPsbt(signed0.Value, signers[2])
+	require.NoError(t, err)
+	analysis, err = AnalyzePsbt(signed2.Value, ChainSignet)
+	require.NoError(t, err)
+	require.True(t, analysis.IsComplete)
+	require.Equal(t, PsbtInputStatusSigned, analysis.Inputs.ValueAt(1).Status)
+
+	finalized, err := wallet.FinalizePsbt(signed2.Value)
+	require.NoError(t, err)
+	finalizedHex, err := encodePsbtHex(&finalized.Packet)
+	require.NoError(t, err)
+	estimated := analysis.VSize
+	analysis, err = AnalyzePsbt(finalizedHex.Value, ChainSignet)
+	require.NoError(t, err)
+	require.Equal(t, PsbtInputStatusFinalized, analysis.Inputs.ValueAt(0).Status)
+	require.InDelta(t, analysis.VSize, estimated, 2)
+}
+
+func TestAnalyzePsbt_Account(t *testing.T) {
+	signers := newTestSigners(t)
+	for _, account := range signers[:2] {
+		packet := newTestAccountPsbt(t, account, 100000, signers[2].address, 50000)
+		psbtHex, err := encodePsbtHex(packet)
+		require.NoError(t, err)
+
+		analysis, err := account.AnalyzePsbt(psbtHex.Value)
+		require.NoError(t, err)
+		require.True(t, analysis.Inputs.ValueAt(0).IsMine)
+		require.False(t, analysis.Outputs.ValueAt(0).IsMine)
+		require.True(t, analysis.Outputs.ValueAt(1).IsMine)
+		require.Equal(t, int64(-51000), analysis.NetAmount)
+		require.Equal(t, int64(1000), analysis.Fee)
+		require.Equal(t, 1, analysis.Inputs.ValueAt(0).RequiredKeys.Count())
+
+		signed, err := account.SignPsbt(psbtHex.Value)
+		require.NoError(t, err)
+		signedHex, err := encodePsbtHex(&signed.Packet)
+		require.NoError(t, err)
+		analysis, err = AnalyzePsbt(signedHex.Value, ChainSignet)
+		require.NoError(t, err)
+		require.Equal(t, PsbtInputStatusFinalized, analysis.Inputs.ValueAt(0).Status)
+		require.True(t, analysis.IsComplete)
+	}
+
+	other, err := signers[2].AnalyzePsbt(mustEncodePsbt(t, newTestAccountPsbt(t, signers[1], 100000, signers[0].address, 50000)))
+	require.NoError(t, err)
+	require.False(t, other.Inputs.ValueAt(0).IsMine)
+	require.Equal(t, int64(0), other.NetAmount)
+}
+
+func TestAnalyzePsbt_Warnings(t *testing.T) {
+	signers := newTestSigners(t)
+	packet := newTestAccountPsbt(t, signers[1], 100000, signers[0].address, 50000)
+	packet.Inputs[0].SighashType = txscript.SigHashNone | txscript.SigHashAnyOneCanPay
+	analysis, err := AnalyzePsbt(mustEncodePsbt(t, packet), ChainSignet)
+	require.NoError(t, err)
+	input := analysis.Inputs.ValueAt(0)
+	require.Equal(t, "NONE|ANYONECANPAY", input.SighashName)
+	require.Equal(t, 2, input.Warnings.Count())
+
+	packet.Inputs[0].SighashType = txscript.SigHashSingle
+	packet.UnsignedTx.TxOut = packet.UnsignedTx.TxOut[:0]
+	packet.Outputs = packet.Outputs[:0]
+	packet.Inputs[0].WitnessUtxo.Value = 10000000
+	analysis, err = AnalyzePsbt(mustEncodePsbt(t, packet), ChainSignet)
+	require.NoError(t, err)
+	require.Contains(t, analysis.Inputs.ValueAt(0).Warnings.ValueAt(0), "no output at the same index")
+	require.Contains(t, analysis.Warnings.ValueAt(0), "abnormally high")
+
+	packet.Inputs[0].WitnessUtxo = nil
+	analysis, err = AnalyzePsbt(mustEncodePsbt(t, packet), ChainSignet)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), analysis.Fee)
+	require.Equal(t, 1, analysis.Warnings.Count())
+}
+
+func mustEncodePsbt(t *testing.T, packet *psbt.Packet) string {
+	res, err := encodePsbtHex(packet)
+	require.NoError(t, err)
+	return res.Value
+}
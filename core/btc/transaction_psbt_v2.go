@@ -0,0 +1,336 @@
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+// The psbt fields (BIP174 and BIP370) that are converted between the psbt v0 and v2
+const (
+	psbtGlobalUnsignedTx       byte = 0x00
+	psbtGlobalTxVersion        byte = 0x02
+	psbtGlobalFallbackLocktime byte = 0x03
+	psbtGlobalInputCount       byte = 0x04
+	psbtGlobalOutputCount      byte = 0x05
+	psbtGlobalTxModifiable     byte = 0x06
+	psbtGlobalVersion          byte = 0xfb
+
+	psbtInPreviousTxid     byte = 0x0e
+	psbtInOutputIndex      byte = 0x0f
+	psbtInSequence         byte = 0x10
+	psbtInRequiredTimeLock byte = 0x11
+	psbtInRequiredHeight   byte = 0x12
+
+	psbtOutAmount byte = 0x03
+	psbtOutScript byte = 0x04
+)
+
+var psbtMagic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// ConvertPsbtToV2
+// @param psbtHex the psbt v0 or v2 in hex or base64
+// @return the psbt v2 (BIP370) hex
+func ConvertPsbtToV2(psbtHex string) (res *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	packet, err := DecodePsbtTxToPacket(psbtHex)
+	if err != nil {
+		return
+	}
+	raw, err := serializePsbtV2(packet)
+	if err != nil {
+		return
+	}
+	return base.NewOptionalString(hex.EncodeToString(raw)), nil
+}
+
+// ConvertPsbtToV0
+// @param psbtHex the psbt v0 or v2 in hex or base64
+// @return the psbt v0 (BIP174) hex
+func ConvertPsbtToV0(psbtHex string) (res *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	packet, err := DecodePsbtTxToPacket(psbtHex)
+	if err != nil {
+		return
+	}
+	return encodePsbtHex(packet)
+}
+
+// PsbtV2HexString
+// @return the psbt v2 (BIP370) hex
+func (t *PsbtTransaction) PsbtV2HexString() (*base.OptionalString, error) {
+	raw, err := serializePsbtV2(&t.Packet)
+	if err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(hex.EncodeToString(raw)), nil
+}
+
+type psbtKeyValue struct {
+	key   []byte
+	value []byte
+}
+
+// psbtMap is the key-value pairs of a psbt map, the order is kept.
+type psbtMap []*psbtKeyValue
+
+func (m psbtMap) get(keyType byte) []byte {
+	for _, kv := range m {
+		if len(kv.key) == 1 && kv.key[0] == keyType {
+			return kv.value
+		}
+	}
+	return nil
+}
+
+// without
+// @return the map without the fields of the key types
+func (m psbtMap) without(keyTypes ...byte) psbtMap {
+	res := make(psbtMap, 0, len(m))
+	for _, kv := range m {
+		if !bytes.Contains(keyTypes, kv.key[:1]) {
+			res = append(res, kv)
+		}
+	}
+	return res
+}
+
+func readPsbtMap(r *bytes.Reader) (psbtMap, error) {
+	var m psbtMap
+	for {
+		key, err := wire.ReadVarBytes(r, 0, psbt.MaxPsbtKeyLength, "psbt key")
+		if err != nil {
+			return nil, err
+		}
+		if len(key) == 0 {
+			return m, nil
+		}
+		value, err := wire.ReadVarBytes(r, 0, psbt.MaxPsbtValueLength, "psbt value")
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, &psbtKeyValue{key: key, value: value})
+	}
+}
+
+func writePsbtMap(w io.Writer, m psbtMap) error {
+	for _, kv := range m {
+		if err := wire.WriteVarBytes(w, 0, kv.key); err != nil {
+			return err
+		}
+		if err := wire.WriteVarBytes(w, 0, kv.value); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write([]byte{0x00})
+	return err
+}
+
+// psbtVersionOf
+// @return the PSBT_GLOBAL_VERSION of the raw psbt, 0 if the field is not found.
+func psbtVersionOf(raw []byte) (uint32, error) {
+	if !bytes.HasPrefix(raw, psbtMagic) {
+		return 0, psbt.ErrInvalidMagicBytes
+	}
+	global, err := readPsbtMap(bytes.NewReader(raw[len(psbtMagic):]))
+	if err != nil {
+		return 0, err
+	}
+	version := global.get(psbtGlobalVersion)
+	if version == nil {
+		return 0, nil
+	}
+	if len(version) != 4 {
+		return 0, errors.New("invalid psbt version")
+	}
+	return binary.LittleEndian.Uint32(version), nil
+}
+
+// convertPsbtV2ToV0
+// The unsigned transaction is built with the v2 fields, and then the v2 fields are removed.
+func convertPsbtV2ToV0(raw []byte) ([]byte, error) {
+	r := bytes.NewReader(raw[len(psbtMagic):])
+	global, err := readPsbtMap(r)
+	if err != nil {
+		return nil, err
+	}
+	inputCount, err := psbtCompactSize(global.get(psbtGlobalInputCount))
+	if err != nil {
+		return nil, err
+	}
+	outputCount, err := psbtCompactSize(global.get(psbtGlobalOutputCount))
+	if err != nil {
+		return nil, err
+	}
+	if inputCount > uint64(r.Len()) || outputCount > uint64(r.Len()) {
+		return nil, errors.New("invalid psbt v2 inputs or outputs count")
+	}
+	txVersion := global.get(psbtGlobalTxVersion)
+	if len(txVersion) != 4 {
+		return nil, errors.New("psbt v2 has no transaction version")
+	}
+	tx := wire.NewMsgTx(int32(binary.LittleEndian.Uint32(txVersion)))
+	if fallback := global.get(psbtGlobalFallbackLocktime); len(fallback) == 4 {
+		tx.LockTime = binary.LittleEndian.Uint32(fallback)
+	}
+
+	inputs := make([]psbtMap, inputCount)
+	var timeLocks, heightLocks []uint32
+	allowTime, allowHeight := true, true
+	for i := range inputs {
+		if inputs[i], err = readPsbtMap(r); err != nil {
+			return nil, err
+		}
+		txid := inputs[i].get(psbtInPreviousTxid)
+		index := inputs[i].get(psbtInOutputIndex)
+		if len(txid) != chainhash.HashSize || len(index) != 4 {
+			return nil, errors.New("psbt v2 input has no previous output")
+		}
+		hash, _ := chainhash.NewHash(txid)
+		in := wire.NewTxIn(wire.NewOutPoint(hash, binary.LittleEndian.Uint32(index)), nil, nil)
+		if sequence := inputs[i].get(psbtInSequence); len(sequence) == 4 {
+			in.Sequence = binary.LittleEndian.Uint32(sequence)
+		}
+		tx.AddTxIn(in)
+
+		timeLock := inputs[i].get(psbtInRequiredTimeLock)
+		heightLock := inputs[i].get(psbtInRequiredHeight)
+		if len(timeLock) == 4 {
+			timeLocks = append(timeLocks, binary.LittleEndian.Uint32(timeLock))
+		}
+		if len(heightLock) == 4 {
+			heightLocks = append(heightLocks, binary.LittleEndian.Uint32(heightLock))
+		}
+		allowTime = allowTime && (timeLock != nil || heightLock == nil)
+		allowHeight = allowHeight && (heightLock != nil || timeLock == nil)
+	}
+	// The locktime is determined by the inputs' required locktime, the height is preferred (BIP370).
+	switch {
+	case len(heightLocks) > 0 && allowHeight:
+		tx.LockTime = slices.Max(heightLocks)
+	case len(timeLocks) > 0 && allowTime:
+		tx.LockTime = slices.Max(timeLocks)
+	case len(heightLocks) > 0 || len(timeLocks) > 0:
+		return nil, errors.New("the inputs' required locktime are conflicting")
+	}
+
+	outputs := make([]psbtMap, outputCount)
+	for i := range outputs {
+		if outputs[i], err = readPsbtMap(r); err != nil {
+			return nil, err
+		}
+		amount := outputs[i].get(psbtOutAmount)
+		script := outputs[i].get(psbtOutScript)
+		if len(amount) != 8 || script == nil {
+			return nil, errors.New("psbt v2 output has no amount or script")
+		}
+		tx.AddTxOut(wire.NewTxOut(int64(binary.LittleEndian.Uint64(amount)), script))
+	}
+
+	var txBuf bytes.Buffer
+	if err = tx.SerializeNoWitness(&txBuf); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Write(psbtMagic)
+	global = append(psbtMap{{key: []byte{psbtGlobalUnsignedTx}, value: txBuf.Bytes()}},
+		global.without(psbtGlobalTxVersion, psbtGlobalFallbackLocktime, psbtGlobalInputCount,
+			psbtGlobalOutputCount, psbtGlobalTxModifiable, psbtGlobalVersion)...)
+	if err = writePsbtMap(&buf, global); err != nil {
+		return nil, err
+	}
+	for _, in := range inputs {
+		in = in.without(psbtInPreviousTxid, psbtInOutputIndex, psbtInSequence, psbtInRequiredTimeLock, psbtInRequiredHeight)
+		if err = writePsbtMap(&buf, in); err != nil {
+			return nil, err
+		}
+	}
+	for _, out := range outputs {
+		if err = writePsbtMap(&buf, out.without(psbtOutAmount, psbtOutScript)); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// serializePsbtV2
+// The unsigned transaction is split into the v2 fields.
+func serializePsbtV2(packet *psbt.Packet) ([]byte, error) {
+	var v0 bytes.Buffer
+	if err := packet.Serialize(&v0); err != nil {
+		return nil, err
+	}
+	r := bytes.NewReader(v0.Bytes()[len(psbtMagic):])
+	global, err := readPsbtMap(r)
+	if err != nil {
+		return nil, err
+	}
+	tx := packet.UnsignedTx
+
+	var buf bytes.Buffer
+	buf.Write(psbtMagic)
+	v2Global := psbtMap{
+		{key: []byte{psbtGlobalTxVersion}, value: binary.LittleEndian.AppendUint32(nil, uint32(tx.Version))},
+		{key: []byte{psbtGlobalFallbackLocktime}, value: binary.LittleEndian.AppendUint32(nil, tx.LockTime)},
+		{key: []byte{psbtGlobalInputCount}, value: psbtCompactSizeBytes(uint64(len(tx.TxIn)))},
+		{key: []byte{psbtGlobalOutputCount}, value: psbtCompactSizeBytes(uint64(len(tx.TxOut)))},
+	}
+	v2Global = append(v2Global, global.without(psbtGlobalUnsignedTx, psbtGlobalVersion)...)
+	v2Global = append(v2Global, &psbtKeyValue{key: []byte{psbtGlobalVersion}, value: binary.LittleEndian.AppendUint32(nil, 2)})
+	if err = writePsbtMap(&buf, v2Global); err != nil {
+		return nil, err
+	}
+	for _, in := range tx.TxIn {
+		m, err := readPsbtMap(r)
+		if err != nil {
+			return nil, err
+		}
+		m = append(m,
+			&psbtKeyValue{key: []byte{psbtInPreviousTxid}, value: in.PreviousOutPoint.Hash[:]},
+			&psbtKeyValue{key: []byte{psbtInOutputIndex}, value: binary.LittleEndian.AppendUint32(nil, in.PreviousOutPoint.Index)},
+		)
+		if in.Sequence != wire.MaxTxInSequenceNum {
+			m = append(m, &psbtKeyValue{key: []byte{psbtInSequence}, value: binary.LittleEndian.AppendUint32(nil, in.Sequence)})
+		}
+		if err = writePsbtMap(&buf, m); err != nil {
+			return nil, err
+		}
+	}
+	for _, out := range tx.TxOut {
+		m, err := readPsbtMap(r)
+		if err != nil {
+			return nil, err
+		}
+		m = append(m,
+			&psbtKeyValue{key: []byte{psbtOutAmount}, value: binary.LittleEndian.AppendUint64(nil, uint64(out.Value))},
+			&psbtKeyValue{key: []byte{psbtOutScript}, value: out.PkScript},
+		)
+		if err = writePsbtMap(&buf, m); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func psbtCompactSize(value []byte) (uint64, error) {
+	if value == nil {
+		return 0, errors.New("psbt v2 has no inputs or outputs count")
+	}
+	return wire.ReadVarInt(bytes.NewReader(value), 0)
+}
+
+func psbtCompactSizeBytes(n uint64) []byte {
+	var buf bytes.Buffer
+	_ = wire.WriteVarInt(&buf, 0, n)
+	return buf.Bytes()
+}
@@ -56,50 +56,58 @@ func Sign(tx *wire.MsgTx, privKey *btcec.PrivateKey, prevOutFetcher txscript.Pre
 	if len(tx.TxIn) == 0 || len(tx.TxOut) == 0 {
 		return errors.New("invalid inputs or outputs")
 	}
-	for i, in := range tx.TxIn {
-		prevOut := prevOutFetcher.FetchPrevOutput(in.PreviousOutPoint)
-		txSigHashes := txscript.NewTxSigHashes(tx, prevOutFetcher)
-		if isComing {
-			witness, err := coming_TaprootWitnessSignature(tx, txSigHashes, i, prevOut.Value, prevOut.PkScript, txscript.SigHashDefault, privKey)
-			if err != nil {
-				return err
-			}
-			in.Witness = witness
-		} else if txscript.IsPayToTaproot(prevOut.PkScript) {
-			witness, err := txscript.TaprootWitnessSignature(tx, txSigHashes, i, prevOut.Value, prevOut.PkScript, txscript.SigHashDefault, privKey)
-			if err != nil {
-				return err
-			}
-			in.Witness = witness
-		} else if txscript.IsPayToPubKeyHash(prevOut.PkScript) {
-			sigScript, err := txscript.SignatureScript(tx, i, prevOut.PkScript, txscript.SigHashAll, privKey, true)
-			if err != nil {
-				return err
-			}
-			in.SignatureScript = sigScript
-		} else {
-			pubKeyBytes := privKey.PubKey().SerializeCompressed()
-			script, err := PayToPubKeyHashScript(btcutil.Hash160(pubKeyBytes))
-			if err != nil {
-				return err
-			}
-			amount := prevOut.Value
-			witness, err := txscript.WitnessSignature(tx, txSigHashes, i, amount, script, txscript.SigHashAll, privKey, true)
+	for i := range tx.TxIn {
+		if err := signInput(tx, i, privKey, prevOutFetcher, isComing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signInput sign the input at the index with the key path.
+func signInput(tx *wire.MsgTx, i int, privKey *btcec.PrivateKey, prevOutFetcher txscript.PrevOutputFetcher, isComing bool) error {
+	in := tx.TxIn[i]
+	prevOut := prevOutFetcher.FetchPrevOutput(in.PreviousOutPoint)
+	txSigHashes := txscript.NewTxSigHashes(tx, prevOutFetcher)
+	if isComing {
+		witness, err := coming_TaprootWitnessSignature(tx, txSigHashes, i, prevOut.Value, prevOut.PkScript, txscript.SigHashDefault, privKey)
+		if err != nil {
+			return err
+		}
+		in.Witness = witness
+	} else if txscript.IsPayToTaproot(prevOut.PkScript) {
+		witness, err := txscript.TaprootWitnessSignature(tx, txSigHashes, i, prevOut.Value, prevOut.PkScript, txscript.SigHashDefault, privKey)
+		if err != nil {
+			return err
+		}
+		in.Witness = witness
+	} else if txscript.IsPayToPubKeyHash(prevOut.PkScript) {
+		sigScript, err := txscript.SignatureScript(tx, i, prevOut.PkScript, txscript.SigHashAll, privKey, true)
+		if err != nil {
+			return err
+		}
+		in.SignatureScript = sigScript
+	} else {
+		pubKeyBytes := privKey.PubKey().SerializeCompressed()
+		script, err := PayToPubKeyHashScript(btcutil.Hash160(pubKeyBytes))
+		if err != nil {
+			return err
+		}
+		amount := prevOut.Value
+		witness, err := txscript.WitnessSignature(tx, txSigHashes, i, amount, script, txscript.SigHashAll, privKey, true)
+		if err != nil {
+			return err
+		}
+		in.Witness = witness
+
+		if txscript.IsPayToScriptHash(prevOut.PkScript) {
+			redeemScript, err := PayToWitnessPubKeyHashScript(btcutil.Hash160(pubKeyBytes))
 			if err != nil {
 				return err
 			}
-			in.Witness = witness
-
-			if txscript.IsPayToScriptHash(prevOut.PkScript) {
-				redeemScript, err := PayToWitnessPubKeyHashScript(btcutil.Hash160(pubKeyBytes))
-				if err != nil {
-					return err
-				}
-				in.SignatureScript = append([]byte{byte(len(redeemScript))}, redeemScript...)
-			}
+			in.SignatureScript = append([]byte{byte(len(redeemScript))}, redeemScript...)
 		}
 	}
-
 	return nil
 }
 
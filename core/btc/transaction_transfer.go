@@ -0,0 +1,219 @@
+package btc
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+// BuildTransferWithFeeRate
+// Build a transfer transaction, the utxos of the sender will be selected automatically,
+// and the change will return to the sender.
+// The utxos that hold inscriptions or runes will not be spent.
+// @param amount the satoshi amount to transfer
+// @param feeRate sat/vB, the suggest average fee rate will be used if it <= 0
+func (c *Chain) BuildTransferWithFeeRate(sender, receiver, amount string, feeRate int64) (txn *Transaction, err error) {
+	return c.BuildTransferWithOptions(sender, receiver, amount, NewUTXOSpendOptions(feeRate))
+}
+
+// BuildTransferWithOptions
+// @param options specify the fee rate, and whether the utxos that hold inscriptions or runes can be spent.
+func (c *Chain) BuildTransferWithOptions(sender, receiver, amount string, options *UTXOSpendOptions) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil || value <= 0 {
+		return nil, errors.New("invalid transfer amount")
+	}
+	if options == nil {
+		options = &UTXOSpendOptions{}
+	}
+	feeRate, err := c.feeRateOrSuggest(options.FeeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchSpendableUtxos(sender, options)
+	if err != nil {
+		return
+	}
+	return buildTransferWithUtxos(c.Chainnet, sender, receiver, value, feeRate, utxos)
+}
+
+// BuildTransferAllWithFeeRate
+// Build a transaction that spend all the utxos of the sender to the receiver,
+// except the utxos that hold inscriptions or runes.
+// @param feeRate sat/vB, the suggest average fee rate will be used if it <= 0
+func (c *Chain) BuildTransferAllWithFeeRate(sender, receiver string, feeRate int64) (txn *Transaction, err error) {
+	return c.BuildTransferAllWithOptions(sender, receiver, NewUTXOSpendOptions(feeRate))
+}
+
+// BuildTransferAllWithOptions
+// @param options specify the fee rate, and whether the utxos that hold inscriptions or runes can be spent.
+func (c *Chain) BuildTransferAllWithOptions(sender, receiver string, options *UTXOSpendOptions) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if options == nil {
+		options = &UTXOSpendOptions{}
+	}
+	feeRate, err := c.feeRateOrSuggest(options.FeeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchSpendableUtxos(sender, options)
+	if err != nil {
+		return
+	}
+	return buildTransferAllWithUtxos(c.Chainnet, sender, receiver, feeRate, utxos)
+}
+
+func (c *Chain) feeRateOrSuggest(feeRate int64) (int64, error) {
+	if feeRate > 0 {
+		return feeRate, nil
+	}
+	rates, err := c.SuggestFeeRate()
+	if err != nil {
+		return 0, err
+	}
+	return base.Max(rates.Average, 1), nil
+}
+
+func buildTransferWithUtxos(chainnet string, sender, receiver string, amount int64, feeRate int64, utxos []*UTXO) (*Transaction, error) {
+	txn, err := NewTransaction(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	senderAddr, err := btcutil.DecodeAddress(sender, txn.netParams)
+	if err != nil {
+		return nil, err
+	}
+	if err = txn.AddOutput(receiver, amount); err != nil {
+		return nil, err
+	}
+	if amount < mempool.GetDustThreshold(txn.msgTx.TxOut[0]) {
+		return nil, ErrDustAmount
+	}
+	if err = fundTransaction(txn, senderAddr, utxos, feeRate); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// txSizeEstimator estimate the virtual size of the transaction after all inputs are signed.
+type txSizeEstimator func(tx *wire.MsgTx) int64
+
+// fundTransaction select the sender's utxos to pay the outputs and the network fee of the transaction,
+// and the change will return to the sender.
+// The existing inputs of the transaction will be kept, and they will not be selected again.
+func fundTransaction(txn *Transaction, sender btcutil.Address, utxos []*UTXO, feeRate int64) error {
+	return fundTransactionWithEstimator(txn, sender, utxos, feeRate, func(tx *wire.MsgTx) int64 {
+		return EstimateTxSize(tx, sender)
+	})
+}
+
+// fundTransactionWithEstimator is same as `fundTransaction`,
+// it's used for the sender whose inputs can't be estimated by `EstimateTxSize`, e.g. multisig.
+func fundTransactionWithEstimator(txn *Transaction, sender btcutil.Address, utxos []*UTXO, feeRate int64, estimate txSizeEstimator) error {
+	pool := make([]*UTXO, 0, len(utxos))
+	for _, utxo := range utxos {
+		if !txn.containsInput(utxo.Txid, utxo.Vout) {
+			pool = append(pool, utxo)
+		}
+	}
+	changeScript, err := txscript.PayToAddrScript(sender)
+	if err != nil {
+		return err
+	}
+	selection, err := selectCoinsForTx(txn.msgTx, txn.TotalInputValue(), changeScript, pool, feeRate, estimate)
+	if err != nil {
+		return err
+	}
+	senderAddress := sender.EncodeAddress()
+	for _, utxo := range selection.utxos {
+		if err = txn.AddInput(utxo.Txid, utxo.Vout, senderAddress, utxo.Value); err != nil {
+			return err
+		}
+	}
+	if selection.change > 0 {
+		if err = txn.AddOutput(senderAddress, selection.change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectCoinsForTx choose the utxos to pay the outputs and the network fee of the transaction.
+// @param inputValue the total value of the existing inputs
+// @param changeScript the script of the change output
+func selectCoinsForTx(tx *wire.MsgTx, inputValue int64, changeScript []byte, utxos []*UTXO, feeRate int64, estimate txSizeEstimator) (*coinSelectionResult, error) {
+	params := estimateCoinSelectionParams(tx, changeScript, feeRate, estimate)
+	target := -inputValue
+	for _, out := range tx.TxOut {
+		target += out.Value
+	}
+	if len(tx.TxIn) > 0 && target+feeRate*params.baseSize <= 0 {
+		// The existing inputs are enough to pay
+		return finishCoinSelection(nil, target, params)
+	}
+	return selectCoins(utxos, target, params)
+}
+
+func buildTransferAllWithUtxos(chainnet string, sender, receiver string, feeRate int64, utxos []*UTXO) (*Transaction, error) {
+	txn, err := NewTransaction(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	senderAddr, err := btcutil.DecodeAddress(sender, txn.netParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(utxos) == 0 {
+		return nil, ErrInsufficientBalance
+	}
+	for _, utxo := range utxos {
+		if err = txn.AddInput(utxo.Txid, utxo.Vout, sender, utxo.Value); err != nil {
+			return nil, err
+		}
+	}
+	// Use the total value as a placeholder, because the size of output is not related to it's value.
+	total := txn.TotalInputValue()
+	if err = txn.AddOutput(receiver, total); err != nil {
+		return nil, err
+	}
+	fee := feeRate * EstimateTxSize(txn.msgTx, senderAddr)
+	value := total - fee
+	if value < mempool.GetDustThreshold(txn.msgTx.TxOut[0]) {
+		return nil, ErrInsufficientBalance
+	}
+	txn.SetOutputValue(value, 0)
+	return txn, nil
+}
+
+// estimateCoinSelectionParams calculate the size of every part of the transaction by the estimator
+// @param tx the transaction that has not been funded.
+func estimateCoinSelectionParams(tx *wire.MsgTx, changeScript []byte, feeRate int64, estimate txSizeEstimator) *coinSelectionParams {
+	changeOut := wire.NewTxOut(0, changeScript)
+
+	temp := tx.Copy()
+	temp.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0), nil, nil))
+	oneInputSize := estimate(temp)
+	temp.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 1), nil, nil))
+	twoInputSize := estimate(temp)
+	temp.AddTxOut(changeOut)
+	withChangeSize := estimate(temp)
+
+	// The base size contains the existing inputs.
+	inputSize := twoInputSize - oneInputSize
+	return &coinSelectionParams{
+		feeRate:          feeRate,
+		baseSize:         oneInputSize - inputSize,
+		inputSize:        inputSize,
+		changeOutputSize: withChangeSize - twoInputSize,
+		dustThreshold:    mempool.GetDustThreshold(changeOut),
+	}
+}
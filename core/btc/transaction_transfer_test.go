@@ -0,0 +1,50 @@
+package btc
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTransferWithUtxos(t *testing.T) {
+	mnemonic := "antenna chaos arrive hungry distance human question history decade deal impose color"
+	receiver := "tb1pqtguh4mt0206qr7t3pze5zf4st4v3xtvqfhgv7q7j6ymnv7gtutqy4nrud"
+	feeRate := int64(5)
+
+	for _, addrType := range []AddressType{AddressTypeComingTaproot, AddressTypeNativeSegwit,
+		AddressTypeNestedSegwit, AddressTypeTaproot, AddressTypeLegacy} {
+		account, err := NewAccountWithMnemonic(mnemonic, ChainSignet, addrType)
+		require.NoError(t, err)
+		utxos := newTestUtxos(600, 2000, 15000, 30000, 100000)
+
+		txn, err := buildTransferWithUtxos(ChainSignet, account.Address(), receiver, 40000, feeRate, utxos)
+		require.NoError(t, err)
+		require.Equal(t, txn.msgTx.TxOut[0].Value, int64(40000))
+		fee := txn.TotalInputValue() - txn.TotalOutputValue()
+
+		signedTxn, err := txn.SignedTransactionWithAccount(account)
+		require.NoError(t, err)
+		msgTx := signedTxn.(*SignedTransaction).msgTx
+		vsize := mempool.GetTxVirtualSize(btcutil.NewTx(msgTx))
+		require.GreaterOrEqual(t, fee, feeRate*vsize, AddressTypeDescription(addrType))
+
+		txnAll, err := buildTransferAllWithUtxos(ChainSignet, account.Address(), receiver, feeRate, utxos)
+		require.NoError(t, err)
+		require.Equal(t, len(txnAll.msgTx.TxIn), len(utxos))
+		require.Equal(t, len(txnAll.msgTx.TxOut), 1)
+		signedTxn, err = txnAll.SignedTransactionWithAccount(account)
+		require.NoError(t, err)
+		msgTx = signedTxn.(*SignedTransaction).msgTx
+		vsize = mempool.GetTxVirtualSize(btcutil.NewTx(msgTx))
+		fee = txnAll.TotalInputValue() - txnAll.TotalOutputValue()
+		require.GreaterOrEqual(t, fee, feeRate*vsize, AddressTypeDescription(addrType))
+	}
+
+	sender := "tb1pqtguh4mt0206qr7t3pze5zf4st4v3xtvqfhgv7q7j6ymnv7gtutqy4nrud"
+	_, err := buildTransferWithUtxos(ChainSignet, sender, receiver, 100, feeRate, newTestUtxos(100000))
+	require.ErrorIs(t, err, ErrDustAmount)
+	_, err = buildTransferWithUtxos(ChainSignet, sender, receiver, 100000, feeRate, newTestUtxos(100000))
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+}
@@ -0,0 +1,107 @@
+package btc
+
+import (
+	"strconv"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/base/inter"
+)
+
+type UTXOStatus struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight int64  `json:"block_height,omitempty"`
+	BlockHash   string `json:"block_hash,omitempty"`
+	BlockTime   int64  `json:"block_time,omitempty"`
+}
+
+// UTXO is the unspent output returned by the esplora api `/address/:address/utxo`
+type UTXO struct {
+	Txid   string      `json:"txid"`
+	Vout   int64       `json:"vout"`
+	Value  int64       `json:"value"`
+	Status *UTXOStatus `json:"status,omitempty"`
+}
+
+func (u *UTXO) Confirmed() bool {
+	return u.Status != nil && u.Status.Confirmed
+}
+
+// Outpoint
+// @return `txid:vout`
+func (u *UTXO) Outpoint() string {
+	return u.Txid + ":" + strconv.FormatInt(u.Vout, 10)
+}
+
+func (u *UTXO) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(u)
+}
+
+type UTXOArray struct {
+	inter.AnyArray[*UTXO]
+}
+
+func NewUTXOArray() *UTXOArray {
+	return &UTXOArray{}
+}
+
+func (a *UTXOArray) TotalValue() int64 {
+	total := int64(0)
+	for _, u := range a.AnyArray {
+		total += u.Value
+	}
+	return total
+}
+
+func NewUTXOArrayWithJsonString(str string) (*UTXOArray, error) {
+	var arr []*UTXO
+	err := base.FromJsonString(str, &arr)
+	if err != nil {
+		return nil, err
+	}
+	return &UTXOArray{arr}, nil
+}
+
+// FetchUtxos
+// Query all the unspent outputs (include the mempool's) of the address.
+func (c *Chain) FetchUtxos(address string) (*UTXOArray, error) {
+	utxos, err := fetchUtxos(address, c.Chainnet)
+	if err != nil {
+		return nil, err
+	}
+	return &UTXOArray{utxos}, nil
+}
+
+func fetchUtxos(address, chainnet string) (utxos []*UTXO, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := esploraClientOf(chainnet)
+	if err != nil {
+		return
+	}
+	return client.FetchUtxos(address)
+}
+
+// fetchRawTransaction
+// Query the transaction with the esplora api `/tx/:txid/hex`
+func fetchRawTransaction(txid, chainnet string) (tx *wire.MsgTx, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := esploraClientOf(chainnet)
+	if err != nil {
+		return
+	}
+	return client.FetchRawTransaction(txid)
+}
+
+// fetchUtxoStatus
+// Query the status of the transaction with the esplora api `/tx/:txid/status`
+func fetchUtxoStatus(txid, chainnet string) (status *UTXOStatus, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := esploraClientOf(chainnet)
+	if err != nil {
+		return
+	}
+	return client.fetchStatus(txid)
+}
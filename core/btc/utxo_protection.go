@@ -0,0 +1,468 @@
+package btc
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/base/inter"
+	"github.com/coming-chat/wallet-SDK/core/btc/ordinal"
+	"github.com/coming-chat/wallet-SDK/core/btc/runes"
+	"github.com/coming-chat/wallet-SDK/pkg/httpUtil"
+)
+
+const (
+	UTXOProtectReasonInscription = "inscription"
+	UTXOProtectReasonRunes       = "runes"
+
+	// The rune id of the etching is unknown until the transaction is confirmed.
+	runesEtchingPlaceholder = "etching"
+	runesUnknownPlaceholder = "unknown"
+
+	inscriptionsPageSize = 100
+	runesPageSize        = 100
+)
+
+// ProtectedUTXO is the utxo that holds inscriptions or runes,
+// spending it as a normal input will transfer or burn the assets.
+type ProtectedUTXO struct {
+	Utxo *UTXO `json:"utxo"`
+	// The reasons joined with comma, e.g. `inscription,runes`
+	Reason string `json:"reason"`
+	// The inscription ids joined with comma
+	InscriptionIds string `json:"inscriptionIds"`
+	// The rune ids joined with comma. Without the indexer, it's `etching` if the rune is etched in the transaction,
+	// or `unknown` if the runes come from the inputs.
+	RuneIds string `json:"runeIds"`
+}
+
+func (p *ProtectedUTXO) HasInscription() bool {
+	return p.InscriptionIds != ""
+}
+
+func (p *ProtectedUTXO) HasRunes() bool {
+	return p.RuneIds != ""
+}
+
+func (p *ProtectedUTXO) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(p)
+}
+
+type ProtectedUTXOArray struct {
+	inter.AnyArray[*ProtectedUTXO]
+}
+
+func NewProtectedUTXOArrayWithJsonString(str string) (*ProtectedUTXOArray, error) {
+	var arr []*ProtectedUTXO
+	err := base.FromJsonString(str, &arr)
+	if err != nil {
+		return nil, err
+	}
+	return &ProtectedUTXOArray{arr}, nil
+}
+
+// UTXOSpendOptions
+// The protected utxos are skipped by default, the caller can explicitly allow to spend them.
+type UTXOSpendOptions struct {
+	// sat/vB, the suggest average fee rate will be used if it <= 0
+	FeeRate          int64 `json:"feeRate"`
+	AllowInscription bool  `json:"allowInscription"`
+	AllowRunes       bool  `json:"allowRunes"`
+}
+
+func NewUTXOSpendOptions(feeRate int64) *UTXOSpendOptions {
+	return &UTXOSpendOptions{FeeRate: feeRate}
+}
+
+func (o *UTXOSpendOptions) canSpend(p *ProtectedUTXO) bool {
+	return (o.AllowInscription || !p.HasInscription()) && (o.AllowRunes || !p.HasRunes())
+}
+
+// FetchProtectedUtxos
+// Query the utxos of the owner that hold inscriptions or runes.
+func (c *Chain) FetchProtectedUtxos(owner string) (arr *ProtectedUTXOArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	utxos, err := fetchUtxos(owner, c.Chainnet)
+	if err != nil {
+		return
+	}
+	protected, err := c.protectedUtxos(owner, utxos)
+	if err != nil {
+		return
+	}
+	return &ProtectedUTXOArray{protected}, nil
+}
+
+// fetchSpendableUtxos
+// Query the utxos of the owner, the protected utxos are removed unless the options allow them.
+func (c *Chain) fetchSpendableUtxos(owner string, options *UTXOSpendOptions) ([]*UTXO, error) {
+	utxos, err := fetchUtxos(owner, c.Chainnet)
+	if err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &UTXOSpendOptions{}
+	}
+	if options.AllowInscription && options.AllowRunes {
+		return utxos, nil
+	}
+	protected, err := c.protectedUtxos(owner, utxos)
+	if err != nil {
+		return nil, err
+	}
+	return filterSpendableUtxos(utxos, protected, options), nil
+}
+
+// protectedUtxos
+// The assets of the utxos are queried from the indexer, the transactions that created the utxos are not needed.
+// The error of the indexer is returned, the utxos will not be spent while the indexer is unavailable.
+// If the chain has no indexer (e.g. regtest), the assets are decoded from the creating transactions,
+// which can't find the assets that are transferred without envelope or runestone.
+func (c *Chain) protectedUtxos(owner string, utxos []*UTXO) ([]*ProtectedUTXO, error) {
+	if len(utxos) == 0 {
+		return []*ProtectedUTXO{}, nil
+	}
+	if _, err := unisatHost(c.Chainnet); err == nil {
+		indexed, err := c.fetchIndexedAssets(owner)
+		if err != nil {
+			return nil, err
+		}
+		return classifyUtxos(utxos, nil, indexed), nil
+	}
+
+	txids := make([]any, 0, len(utxos))
+	for _, utxo := range utxos {
+		if !slices.Contains(txids, any(utxo.Txid)) {
+			txids = append(txids, utxo.Txid)
+		}
+	}
+	txList, err := base.MapListConcurrent(txids, 10, func(i interface{}) (interface{}, error) {
+		return fetchRawTransaction(i.(string), c.Chainnet)
+	})
+	if err != nil {
+		return nil, err
+	}
+	txs := make(map[string]*wire.MsgTx, len(txList))
+	for _, tx := range txList {
+		msgTx := tx.(*wire.MsgTx)
+		txs[msgTx.TxHash().String()] = msgTx
+	}
+	return classifyUtxos(utxos, txs, nil), nil
+}
+
+// indexedAssets is the inscriptions and runes held by the outputs, keyed by the output `txid:vout`.
+type indexedAssets struct {
+	inscriptions map[string][]string
+	runes        map[string][]string
+}
+
+// fetchIndexedAssets
+// Query all the inscriptions and the runes balance of each utxo of the owner from the indexer.
+func (c *Chain) fetchIndexedAssets(owner string) (*indexedAssets, error) {
+	inscriptions, err := c.fetchInscriptionOutputs(owner)
+	if err != nil {
+		return nil, err
+	}
+	runeOutputs, err := c.fetchRunesOutputs(owner)
+	if err != nil {
+		return nil, err
+	}
+	return &indexedAssets{inscriptions: inscriptions, runes: runeOutputs}, nil
+}
+
+// fetchInscriptionOutputs
+// Query all the inscriptions of the owner from the indexer.
+// @return the map of output `txid:vout` to the inscription ids.
+func (c *Chain) fetchInscriptionOutputs(owner string) (outputs map[string][]string, err error) {
+	host, err := unisatHost(c.Chainnet)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs = make(map[string][]string)
+	header := unisatRequestHeader(owner)
+	for cursor := 0; ; cursor += inscriptionsPageSize {
+		url := fmt.Sprintf("%v/v5/ordinals/inscriptions?address=%v&cursor=%v&size=%v", host, owner, cursor, inscriptionsPageSize)
+		resp, err := httpUtil.Request(http.MethodGet, url, header, nil)
+		if err != nil {
+			return nil, err
+		}
+		var rawPage rawBrc20InscriptionPage
+		if err = decodeUnisatResponseV5(*resp, &rawPage); err != nil {
+			return nil, err
+		}
+		for _, inscription := range rawPage.List {
+			outputs[inscription.Output] = append(outputs[inscription.Output], inscription.InscriptionId)
+		}
+		if len(rawPage.List) < inscriptionsPageSize || cursor+inscriptionsPageSize >= rawPage.Total {
+			return outputs, nil
+		}
+	}
+}
+
+// fetchRunesOutputs
+// Query the runes balance of the owner, then the utxos that hold each rune from the indexer.
+// @return the map of output `txid:vout` to the rune ids.
+func (c *Chain) fetchRunesOutputs(owner string) (outputs map[string][]string, err error) {
+	host, err := unisatHost(c.Chainnet)
+	if err != nil {
+		return nil, err
+	}
+
+	runeIds := make([]any, 0)
+	header := unisatRequestHeader(owner)
+	for cursor := 0; ; cursor += runesPageSize {
+		url := fmt.Sprintf("%v/v5/runes/list?address=%v&cursor=%v&size=%v", host, owner, cursor, runesPageSize)
+		resp, err := httpUtil.Request(http.MethodGet, url, header, nil)
+		if err != nil {
+			return nil, err
+		}
+		var rawPage struct {
+			List  []*RunesBalance `json:"list"`
+			Total int             `json:"total"`
+		}
+		if err = decodeUnisatResponseV5(*resp, &rawPage); err != nil {
+			return nil, err
+		}
+		for _, balance := range rawPage.List {
+			runeIds = append(runeIds, balance.RuneId)
+		}
+		if len(rawPage.List) < runesPageSize || cursor+runesPageSize >= rawPage.Total {
+			break
+		}
+	}
+
+	utxoList, err := base.MapListConcurrent(runeIds, 5, func(i interface{}) (interface{}, error) {
+		return c.fetchRunesUtxos(owner, i.(string))
+	})
+	if err != nil {
+		return nil, err
+	}
+	outputs = make(map[string][]string)
+	for _, utxos := range utxoList {
+		for _, utxo := range utxos.([]*RunesUTXO) {
+			outpoint := fmt.Sprintf("%v:%v", utxo.Txid, utxo.Vout)
+			for _, balance := range utxo.Runes {
+				if !slices.Contains(outputs[outpoint], balance.RuneId) {
+					outputs[outpoint] = append(outputs[outpoint], balance.RuneId)
+				}
+			}
+		}
+	}
+	return outputs, nil
+}
+
+// classifyUtxos
+// The inscriptions and runes of the utxo come from:
+//   - the indexer's inscription outputs and runes balance of the utxo;
+//   - the inscriptions revealed in the transaction that created the utxo;
+//   - the runestone of the transaction that created the utxo.
+//
+// The assets transferred without envelope or runestone can only be found by the indexer.
+// @param txs the transactions that created the utxos, keyed by txid, nil if the indexer is used.
+// @param indexed the assets from the indexer, nil if the indexer is unavailable.
+func classifyUtxos(utxos []*UTXO, txs map[string]*wire.MsgTx, indexed *indexedAssets) []*ProtectedUTXO {
+	if indexed == nil {
+		indexed = &indexedAssets{}
+	}
+	assets := make(map[string]*txOutputAssets)
+	protected := make([]*ProtectedUTXO, 0)
+	for _, utxo := range utxos {
+		inscriptions := slices.Clone(indexed.inscriptions[utxo.Outpoint()])
+		runeIds := slices.Clone(indexed.runes[utxo.Outpoint()])
+		if tx, ok := txs[utxo.Txid]; ok {
+			txAssets, ok := assets[utxo.Txid]
+			if !ok {
+				txAssets = newTxOutputAssets(tx)
+				assets[utxo.Txid] = txAssets
+			}
+			for _, id := range txAssets.inscriptions[int(utxo.Vout)] {
+				if !slices.Contains(inscriptions, id) {
+					inscriptions = append(inscriptions, id)
+				}
+			}
+			for _, id := range txAssets.runes[int(utxo.Vout)] {
+				if !slices.Contains(runeIds, id) {
+					runeIds = append(runeIds, id)
+				}
+			}
+		}
+		if len(inscriptions) == 0 && len(runeIds) == 0 {
+			continue
+		}
+		var reasons []string
+		if len(inscriptions) > 0 {
+			reasons = append(reasons, UTXOProtectReasonInscription)
+		}
+		if len(runeIds) > 0 {
+			reasons = append(reasons, UTXOProtectReasonRunes)
+		}
+		protected = append(protected, &ProtectedUTXO{
+			Utxo:           utxo,
+			Reason:         strings.Join(reasons, ","),
+			InscriptionIds: strings.Join(inscriptions, ","),
+			RuneIds:        strings.Join(runeIds, ","),
+		})
+	}
+	return protected
+}
+
+func filterSpendableUtxos(utxos []*UTXO, protected []*ProtectedUTXO, options *UTXOSpendOptions) []*UTXO {
+	skipped := make(map[string]bool)
+	for _, p := range protected {
+		if !options.canSpend(p) {
+			skipped[p.Utxo.Outpoint()] = true
+		}
+	}
+	res := make([]*UTXO, 0, len(utxos))
+	for _, utxo := range utxos {
+		if !skipped[utxo.Outpoint()] {
+			res = append(res, utxo)
+		}
+	}
+	return res
+}
+
+// txOutputAssets is the inscriptions and runes that may be received by the outputs of a transaction.
+type txOutputAssets struct {
+	inscriptions map[int][]string
+	runes        map[int][]string
+}
+
+func newTxOutputAssets(tx *wire.MsgTx) *txOutputAssets {
+	a := &txOutputAssets{
+		inscriptions: make(map[int][]string),
+		runes:        make(map[int][]string),
+	}
+	a.decodeInscriptions(tx)
+	a.decipherRunes(tx)
+	return a
+}
+
+// decodeInscriptions
+// The inscription is bound to the first sat of it's input, or the sat at the pointer's offset of the outputs.
+// The value of the inputs is unknown, so all outputs are protected if the location can't be determined.
+func (a *txOutputAssets) decodeInscriptions(tx *wire.MsgTx) {
+	txid := tx.TxHash().String()
+	index := 0
+	for i, in := range tx.TxIn {
+		script := tapscriptOfWitness(in.Witness)
+		if script == nil {
+			continue
+		}
+		ord, err := ordinal.DecodeOrdFromWitness(script)
+		if err != nil {
+			continue
+		}
+		id := txid + "i" + strconv.Itoa(index)
+		index++
+
+		vout := -1
+		if ord.Pointer != nil {
+			vout = outputOfSatOffset(tx, *ord.Pointer)
+		} else if i == 0 {
+			vout = 0
+		}
+		if vout >= 0 {
+			a.inscriptions[vout] = append(a.inscriptions[vout], id)
+			continue
+		}
+		for vout, out := range tx.TxOut {
+			if !txscript.IsNullData(out.PkScript) {
+				a.inscriptions[vout] = append(a.inscriptions[vout], id)
+			}
+		}
+	}
+}
+
+// decipherRunes
+// The edicts' outputs, and the pointer or the first non OP_RETURN output that receives the unallocated runes.
+// All runes are burned if it's a cenotaph.
+func (a *txOutputAssets) decipherRunes(tx *wire.MsgTx) {
+	artifact, err := runes.Decipher(tx)
+	if err != nil {
+		return
+	}
+	stone, ok := artifact.(runes.Runestone)
+	if !ok {
+		return
+	}
+	add := func(vout int, runeId string) {
+		if vout < 0 || vout >= len(tx.TxOut) || txscript.IsNullData(tx.TxOut[vout].PkScript) {
+			return
+		}
+		if !slices.Contains(a.runes[vout], runeId) {
+			a.runes[vout] = append(a.runes[vout], runeId)
+		}
+	}
+
+	var ids []string
+	for _, edict := range stone.Edicts {
+		id := edict.Id.String()
+		ids = append(ids, id)
+		if int(edict.Output) == len(tx.TxOut) {
+			// split to all non OP_RETURN outputs
+			for vout := range tx.TxOut {
+				add(vout, id)
+			}
+		} else {
+			add(int(edict.Output), id)
+		}
+	}
+	if stone.Mint != nil {
+		ids = append(ids, stone.Mint.String())
+	}
+	if stone.Etching != nil {
+		ids = append(ids, runesEtchingPlaceholder)
+	}
+
+	defaultOutput := -1
+	if stone.Pointer != nil {
+		defaultOutput = int(*stone.Pointer)
+	} else {
+		for vout, out := range tx.TxOut {
+			if !txscript.IsNullData(out.PkScript) {
+				defaultOutput = vout
+				break
+			}
+		}
+	}
+	if len(ids) == 0 {
+		// The runestone only moves the runes of the inputs, which are unknown here.
+		ids = append(ids, runesUnknownPlaceholder)
+	}
+	for _, id := range ids {
+		add(defaultOutput, id)
+	}
+}
+
+// tapscriptOfWitness
+// @return the tapscript of the script path spending, nil if it's not.
+func tapscriptOfWitness(witness wire.TxWitness) []byte {
+	if len(witness) > 0 && len(witness[len(witness)-1]) > 0 && witness[len(witness)-1][0] == txscript.TaprootAnnexTag {
+		witness = witness[:len(witness)-1]
+	}
+	if len(witness) < 2 {
+		return nil
+	}
+	return witness[len(witness)-2]
+}
+
+// outputOfSatOffset
+// @return the index of the output that contains the sat at the offset, -1 if it's out of the outputs.
+func outputOfSatOffset(tx *wire.MsgTx, offset uint64) int {
+	total := uint64(0)
+	for vout, out := range tx.TxOut {
+		total += uint64(out.Value)
+		if offset < total {
+			return vout
+		}
+	}
+	return -1
+}
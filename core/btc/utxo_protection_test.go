@@ -0,0 +1,163 @@
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/btc/ordinal"
+	"github.com/coming-chat/wallet-SDK/core/btc/runes"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTx(t *testing.T, witness wire.TxWitness, values ...int64) *wire.MsgTx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, witness))
+	for _, value := range values {
+		tx.AddTxOut(wire.NewTxOut(value, make([]byte, 34)))
+	}
+	return tx
+}
+
+func utxosOfTx(tx *wire.MsgTx) []*UTXO {
+	utxos := make([]*UTXO, 0, len(tx.TxOut))
+	for vout, out := range tx.TxOut {
+		utxos = append(utxos, &UTXO{Txid: tx.TxHash().String(), Vout: int64(vout), Value: out.Value})
+	}
+	return utxos
+}
+
+func TestClassifyUtxos(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	script, err := ordinal.NewInscription("text/plain", []byte("hello")).RevealScript(privKey.PubKey())
+	require.NoError(t, err)
+	revealTx := newTestTx(t, wire.TxWitness{make([]byte, 64), script, make([]byte, 33)}, 546, 10000)
+
+	pointer := uint32(2)
+	runestone, err := runes.Runestone{
+		Edicts:  []runes.Edict{{Id: runes.RuneId{Block: 840000, Tx: 3}, Amount: *big.NewInt(100), Output: 1}},
+		Pointer: &pointer,
+	}.Encipher()
+	require.NoError(t, err)
+	runesTx := newTestTx(t, wire.TxWitness{make([]byte, 64)}, 10000, 546, 546)
+	runesTx.AddTxOut(wire.NewTxOut(0, runestone))
+
+	normalTx := newTestTx(t, wire.TxWitness{make([]byte, 64)}, 20000, 546)
+	txs := map[string]*wire.MsgTx{
+		revealTx.TxHash().String(): revealTx,
+		runesTx.TxHash().String():  runesTx,
+		normalTx.TxHash().String(): normalTx,
+	}
+	utxos := append(append(utxosOfTx(revealTx), utxosOfTx(runesTx)[:3]...), utxosOfTx(normalTx)...)
+	indexedId := normalTx.TxHash().String() + "i0"
+	indexed := &indexedAssets{inscriptions: map[string][]string{utxos[6].Outpoint(): {indexedId}}}
+
+	protected := classifyUtxos(utxos, txs, indexed)
+	require.Equal(t, 4, len(protected))
+
+	require.Equal(t, utxos[0], protected[0].Utxo)
+	require.Equal(t, UTXOProtectReasonInscription, protected[0].Reason)
+	require.Equal(t, revealTx.TxHash().String()+"i0", protected[0].InscriptionIds)
+
+	require.Equal(t, utxos[3], protected[1].Utxo)
+	require.Equal(t, UTXOProtectReasonRunes, protected[1].Reason)
+	require.Equal(t, "840000:3", protected[1].RuneIds)
+	require.Equal(t, utxos[4], protected[2].Utxo)
+	require.True(t, protected[2].HasRunes())
+
+	require.Equal(t, utxos[6], protected[3].Utxo)
+	require.Equal(t, indexedId, protected[3].InscriptionIds)
+
+	spendable := filterSpendableUtxos(utxos, protected, &UTXOSpendOptions{})
+	require.Equal(t, []*UTXO{utxos[1], utxos[2], utxos[5]}, spendable)
+	spendable = filterSpendableUtxos(utxos, protected, &UTXOSpendOptions{AllowRunes: true})
+	require.Equal(t, []*UTXO{utxos[1], utxos[2], utxos[3], utxos[4], utxos[5]}, spendable)
+	spendable = filterSpendableUtxos(utxos, protected, &UTXOSpendOptions{AllowInscription: true, AllowRunes: true})
+	require.Equal(t, utxos, spendable)
+}
+
+func TestClassifyUtxos_Cenotaph(t *testing.T) {
+	runestone, err := runes.Runestone{
+		Edicts: []runes.Edict{{Id: runes.RuneId{Block: 840000, Tx: 3}, Amount: *big.NewInt(100), Output: 0}},
+	}.Encipher()
+	require.NoError(t, err)
+	tx := newTestTx(t, wire.TxWitness{make([]byte, 64)}, 546)
+	// The unrecognized even tag makes it a cenotaph
+	tx.AddTxOut(wire.NewTxOut(0, append(runestone, 0x01, 0x7e)))
+	artifact, err := runes.Decipher(tx)
+	require.NoError(t, err)
+	require.IsType(t, runes.Cenotaph{}, artifact)
+
+	txs := map[string]*wire.MsgTx{tx.TxHash().String(): tx}
+	require.Empty(t, classifyUtxos(utxosOfTx(tx), txs, nil))
+}
+
+func TestChain_ProtectedUtxos(t *testing.T) {
+	defer SetBackend(ChainRegtest, nil)
+	owner := "bcrt1q6rhpng9evdsfnn833a4f4vej0asu6dk5srld6x"
+	// the runes are moved to the utxo by the previous transfer without edict, only the indexer knows it
+	plainTx := newTestTx(t, wire.TxWitness{make([]byte, 64)}, 546, 546, 20000)
+	utxos := utxosOfTx(plainTx)
+	txid := plainTx.TxHash().String()
+
+	available, rawTxFetched := true, false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data any
+		switch {
+		case r.URL.Path == "/v5/ordinals/inscriptions":
+			data = map[string]any{"list": []map[string]any{{"inscriptionId": txid + "i0", "output": txid + ":0"}}, "total": 1}
+		case r.URL.Path == "/v5/runes/list":
+			data = map[string]any{"list": []*RunesBalance{{RuneId: "840000:3"}}, "total": 1}
+		case r.URL.Path == "/v5/runes/utxos" && r.URL.Query().Get("runeid") == "840000:3":
+			data = []*RunesUTXO{{Txid: txid, Vout: 1, Runes: []*RunesBalance{{RuneId: "840000:3", Amount: "100"}}}}
+		case r.URL.Path == "/tx/"+txid+"/hex":
+			rawTxFetched = true
+			var buf bytes.Buffer
+			require.NoError(t, plainTx.Serialize(&buf))
+			_, _ = w.Write([]byte(hex.EncodeToString(buf.Bytes())))
+			return
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"code": 0, "msg": "ok", "data": data})
+	}))
+	defer server.Close()
+	require.NoError(t, SetBackend(ChainRegtest, NewBackend(server.URL, "", "", "", server.URL)))
+	chain, err := NewChainWithChainnet(ChainRegtest)
+	require.NoError(t, err)
+
+	protected, err := chain.protectedUtxos(owner, utxos)
+	require.NoError(t, err)
+	require.False(t, rawTxFetched)
+	require.Equal(t, 2, len(protected))
+	require.Equal(t, txid+"i0", protected[0].InscriptionIds)
+	require.Equal(t, utxos[1], protected[1].Utxo)
+	require.Equal(t, "840000:3", protected[1].RuneIds)
+
+	// the utxos are not spendable if the indexer is unavailable
+	available = false
+	_, err = chain.protectedUtxos(owner, utxos)
+	require.Error(t, err)
+	require.False(t, rawTxFetched)
+
+	// fallback to decode the creating transactions if the chain has no indexer
+	require.NoError(t, SetBackend(ChainRegtest, NewBackend(server.URL, "", "", "", "")))
+	protected, err = chain.protectedUtxos(owner, utxos)
+	require.NoError(t, err)
+	require.True(t, rawTxFetched)
+	require.Empty(t, protected)
+}
@@ -0,0 +1,142 @@
+package btc
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// UtxoTransaction is the unsigned transaction that all the inputs are spent from the sender,
+// and the change returns to the sender.
+// It's the shared builder of the bitcoin forks (e.g. Litecoin, Bitcoin Cash) whose addresses can't be decoded by this package,
+// so the sender and the receivers are specified by the scripts, and the chains sign it with their own rules.
+type UtxoTransaction struct {
+	MsgTx        *wire.MsgTx
+	SenderScript []byte
+	PrevOuts     *txscript.MultiPrevOutFetcher
+	estimate     txSizeEstimator
+}
+
+// NewUtxoTransaction
+// @param estimate estimate the size of the transaction after all inputs are signed, the fee is calculated by it.
+func NewUtxoTransaction(senderScript []byte, estimate func(tx *wire.MsgTx) int64) *UtxoTransaction {
+	return &UtxoTransaction{
+		MsgTx:        wire.NewMsgTx(wire.TxVersion),
+		SenderScript: senderScript,
+		PrevOuts:     txscript.NewMultiPrevOutFetcher(nil),
+		estimate:     estimate,
+	}
+}
+
+// AddUtxo
+// Spend the sender's utxo.
+func (t *UtxoTransaction) AddUtxo(utxo *UTXO) error {
+	if utxo.Value <= 0 {
+		return errors.New("invalid utxo value")
+	}
+	outPoint, err := outPoint(utxo.Txid, uint32(utxo.Vout))
+	if err != nil {
+		return err
+	}
+	t.MsgTx.AddTxIn(wire.NewTxIn(outPoint, nil, nil))
+	t.PrevOuts.AddPrevOut(*outPoint, wire.NewTxOut(utxo.Value, t.SenderScript))
+	return nil
+}
+
+// AddOutput
+// @return `ErrDustAmount` if the value is less than the dust threshold of the script.
+func (t *UtxoTransaction) AddOutput(pkScript []byte, value int64) error {
+	out := wire.NewTxOut(value, pkScript)
+	if value < mempool.GetDustThreshold(out) {
+		return ErrDustAmount
+	}
+	t.MsgTx.AddTxOut(out)
+	return nil
+}
+
+// Fund
+// Select the sender's utxos to pay the outputs and the network fee of the transaction,
+// and the change will return to the sender.
+// The existing inputs of the transaction will be kept, and they will not be selected again.
+// @param feeRate the fee per (virtual) byte
+func (t *UtxoTransaction) Fund(utxos []*UTXO, feeRate int64) error {
+	pool := make([]*UTXO, 0, len(utxos))
+	for _, utxo := range utxos {
+		if !t.containsUtxo(utxo) {
+			pool = append(pool, utxo)
+		}
+	}
+	selection, err := selectCoinsForTx(t.MsgTx, t.InputValue(), t.SenderScript, pool, feeRate, t.estimate)
+	if err != nil {
+		return err
+	}
+	for _, utxo := range selection.utxos {
+		if err = t.AddUtxo(utxo); err != nil {
+			return err
+		}
+	}
+	if selection.change > 0 {
+		t.MsgTx.AddTxOut(wire.NewTxOut(selection.change, t.SenderScript))
+	}
+	return nil
+}
+
+// SpendAll
+// Spend all the utxos to the receiver, the network fee is deducted from the receiver's output.
+// @param feeRate the fee per (virtual) byte
+func (t *UtxoTransaction) SpendAll(utxos []*UTXO, receiverScript []byte, feeRate int64) error {
+	if len(utxos) == 0 {
+		return ErrInsufficientBalance
+	}
+	for _, utxo := range utxos {
+		if err := t.AddUtxo(utxo); err != nil {
+			return err
+		}
+	}
+	out := wire.NewTxOut(0, receiverScript)
+	t.MsgTx.AddTxOut(out)
+	out.Value = t.InputValue() - feeRate*t.EstimateSize()
+	if out.Value < mempool.GetDustThreshold(out) {
+		return ErrInsufficientBalance
+	}
+	return nil
+}
+
+func (t *UtxoTransaction) containsUtxo(utxo *UTXO) bool {
+	point, err := outPoint(utxo.Txid, uint32(utxo.Vout))
+	if err != nil {
+		return false
+	}
+	for _, in := range t.MsgTx.TxIn {
+		if in.PreviousOutPoint == *point {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *UtxoTransaction) InputValue() int64 {
+	total := int64(0)
+	for _, in := range t.MsgTx.TxIn {
+		total += t.PrevOuts.FetchPrevOutput(in.PreviousOutPoint).Value
+	}
+	return total
+}
+
+// Fee
+// @return the network fee of the transaction
+func (t *UtxoTransaction) Fee() int64 {
+	fee := t.InputValue()
+	for _, out := range t.MsgTx.TxOut {
+		fee -= out.Value
+	}
+	return fee
+}
+
+// EstimateSize
+// @return the size of the transaction after all inputs are signed
+func (t *UtxoTransaction) EstimateSize() int64 {
+	return t.estimate(t.MsgTx)
+}
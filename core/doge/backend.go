@@ -0,0 +1,80 @@
+package doge
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+var (
+	ErrBackendNotConfigured = errors.New("the backend service of the Doge chainnet is not configured")
+)
+
+// Backend is the services that a chainnet depends on.
+// The empty field means the default service of the chainnet is used.
+type Backend struct {
+	// The blockcypher compatible api url, e.g. "https://api.blockcypher.com/v1/doge/main"
+	RestUrl string `json:"restUrl"`
+}
+
+func NewBackend(restUrl string) *Backend {
+	return &Backend{RestUrl: restUrl}
+}
+
+func (b *Backend) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(b)
+}
+
+var defaultBackends = map[string]Backend{
+	ChainMainnet: {RestUrl: "https://api.blockcypher.com/v1/doge/main"},
+}
+
+var customBackends = make(map[string]Backend)
+var backendLock sync.RWMutex
+
+// SetBackend
+// Configure the services of the chainnet, the empty fields of the backend use the default services.
+// @param backend the custom backend, nil to restore the default services.
+func SetBackend(chainnet string, backend *Backend) error {
+	if !isValidChain(chainnet) {
+		return ErrUnsupportedChain
+	}
+	backendLock.Lock()
+	defer backendLock.Unlock()
+	if backend == nil {
+		delete(customBackends, chainnet)
+	} else {
+		customBackends[chainnet] = *backend
+	}
+	return nil
+}
+
+// BackendOf
+// @return the services that the chainnet is using, the custom fields override the default services.
+func BackendOf(chainnet string) (*Backend, error) {
+	if !isValidChain(chainnet) {
+		return nil, ErrUnsupportedChain
+	}
+	backend := defaultBackends[chainnet]
+
+	backendLock.RLock()
+	custom, ok := customBackends[chainnet]
+	backendLock.RUnlock()
+	if ok && custom.RestUrl != "" {
+		backend.RestUrl = custom.RestUrl
+	}
+	return &backend, nil
+}
+
+func restUrlOf(chainnet string) (string, error) {
+	backend, err := BackendOf(chainnet)
+	if err != nil {
+		return "", err
+	}
+	if backend.RestUrl == "" {
+		return "", ErrBackendNotConfigured
+	}
+	return strings.TrimSuffix(backend.RestUrl, "/"), nil
+}
@@ -53,7 +53,21 @@ func (c *Chain) SendRawTransaction(signedTx string) (string, error) {
 }
 
 func (c *Chain) SendSignedTransaction(signedTxn base.SignedTransaction) (hash *base.OptionalString, err error) {
-	return nil, base.ErrUnsupportedFunction
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	txn, ok := signedTxn.(*SignedTransaction)
+	if !ok {
+		return nil, base.ErrInvalidTransactionType
+	}
+	txHex, err := txn.HexString()
+	if err != nil {
+		return
+	}
+	hashString, err := c.SendRawTransaction(txHex.Value)
+	if err != nil {
+		return
+	}
+	return base.NewOptionalString(hashString), nil
 }
 
 // Fetch transaction details through transaction hash
@@ -84,7 +98,11 @@ func (c *Chain) BatchFetchTransactionStatus(hashListString string) string {
 }
 
 func (c *Chain) EstimateTransactionFee(transaction base.Transaction) (fee *base.OptionalString, err error) {
-	return nil, base.ErrUnsupportedFunction
+	txn, ok := transaction.(*TransferTransaction)
+	if !ok {
+		return nil, base.ErrInvalidTransactionType
+	}
+	return base.NewOptionalString(strconv.FormatInt(txn.Fee(), 10)), nil
 }
 func (c *Chain) EstimateTransactionFeeUsePublicKey(transaction base.Transaction, pubkey string) (fee *base.OptionalString, err error) {
 	return c.EstimateTransactionFee(transaction)
@@ -1,9 +1,13 @@
 package doge
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/coming-chat/wallet-SDK/core/testcase"
+	"github.com/stretchr/testify/require"
 )
 
 type chainInfo struct {
@@ -136,3 +140,29 @@ func TestChain_SendRawTransaction(t *testing.T) {
 	}
 	t.Log(hash)
 }
+
+func TestChain_CustomBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.True(t, strings.HasSuffix(r.URL.Path, "/balance"))
+		_, _ = w.Write([]byte(`{"balance": 123456}`))
+	}))
+	defer server.Close()
+
+	account, err := NewAccountWithMnemonic(accountCase.mnemonic, ChainRegtest)
+	require.NoError(t, err)
+	require.True(t, IsValidAddress(account.Address(), ChainRegtest))
+	require.False(t, IsValidAddress(account.Address(), ChainMainnet))
+
+	chain, err := NewChainWithChainnet(ChainRegtest)
+	require.NoError(t, err)
+	_, err = chain.BalanceOfAddress(account.Address())
+	require.Error(t, err)
+
+	require.NoError(t, SetBackend(ChainRegtest, NewBackend(server.URL+"/")))
+	defer SetBackend(ChainRegtest, nil)
+	balance, err := chain.BalanceOfAddress(account.Address())
+	require.NoError(t, err)
+	require.Equal(t, "123456", balance.Total)
+
+	require.Error(t, SetBackend("unknown", NewBackend(server.URL)))
+}
@@ -9,6 +9,7 @@ import (
 const (
 	ChainTestnet = "testnet"
 	ChainMainnet = "mainnet"
+	ChainRegtest = "regtest"
 )
 
 var (
@@ -41,11 +42,24 @@ var (
 
 		Bech32HRPSegwit: "doget",
 	}
+	regtestCfg = chaincfg.Params{
+		Name: "regtest",
+		Net:  0xdab5bffa,
+
+		PubKeyHashAddrID: 111,
+		ScriptHashAddrID: 196,
+		PrivateKeyID:     239,
+
+		HDPrivateKeyID: [4]byte{0x04, 0x35, 0x83, 0x94},
+		HDPublicKeyID:  [4]byte{0x04, 0x35, 0x87, 0xcf},
+
+		Bech32HRPSegwit: "dcrt",
+	}
 )
 
 func isValidChain(chainnet string) bool {
 	switch chainnet {
-	case ChainTestnet, ChainMainnet:
+	case ChainTestnet, ChainMainnet, ChainRegtest:
 		return true
 	default:
 		return false
@@ -58,24 +72,15 @@ func netParamsOf(chainnet string) (*chaincfg.Params, error) {
 		return &testnetCfg, nil
 	case ChainMainnet:
 		return &mainnetCfg, nil
+	case ChainRegtest:
+		return &regtestCfg, nil
 	}
 	return nil, ErrUnsupportedChain
 }
 
-func restUrlOf(chainnet string) (string, error) {
-	switch chainnet {
-	case ChainMainnet:
-		return "https://api.blockcypher.com/v1/doge/main", nil
-	case ChainTestnet:
-		return "", ErrUnsupportedChain
-	default:
-		return "", ErrUnsupportedChain
-	}
-}
-
 func nameOf(chainnet string) (string, error) {
 	switch chainnet {
-	case ChainTestnet:
+	case ChainTestnet, ChainRegtest:
 		return "Doge", nil
 	case ChainMainnet:
 		return "Doget", nil
@@ -20,12 +20,15 @@ func (c *Chain) TokenInfo() (*base.TokenInfo, error) {
 	}, nil
 }
 
+// BuildTransfer
+// The utxos will be selected automatically, and the network fee is calculated with the suggest average fee rate.
+// @param amount the koinu amount to transfer
 func (t *Chain) BuildTransfer(sender, receiver, amount string) (txn base.Transaction, err error) {
-	return nil, base.ErrUnsupportedFunction
+	return t.BuildTransferWithFeeRate(sender, receiver, amount, 0)
 }
 func (t *Chain) CanTransferAll() bool {
-	return false
+	return true
 }
 func (t *Chain) BuildTransferAll(sender, receiver string) (txn base.Transaction, err error) {
-	return nil, base.ErrUnsupportedFunction
+	return t.BuildTransferAllWithFeeRate(sender, receiver, 0)
 }
@@ -0,0 +1,316 @@
+package doge
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/util/hexutil"
+)
+
+const (
+	// The recommended minimum fee rate 0.01 DOGE/kB (koinu/byte) of Dogecoin Core.
+	MinFeeRate int64 = 1000
+	// The soft dust limit 0.01 DOGE (koinu) of Dogecoin Core, the outputs below it need the extra fee and may not be relayed.
+	DustLimit int64 = 1000000
+
+	// The size of the P2PKH input signed by the uncompressed public key, which is larger than the compressed one.
+	p2pkhInputSize  int64 = 32 + 4 + 1 + (1 + 73 + 1 + 65) + 4
+	p2pkhOutputSize int64 = 8 + 1 + 25
+	// version + input count + output count + locktime
+	txOverheadSize int64 = 4 + 1 + 1 + 4
+
+	// The count of utxos to fetch, it's the maximum limit of the blockcypher api.
+	transferUtxoLimit = 2000
+)
+
+var (
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrDustAmount          = errors.New("transfer amount is too small (dust)")
+	ErrNotSenderAccount    = errors.New("the account is not the sender of the transaction")
+)
+
+// TransferTransaction is the unsigned P2PKH transaction that transfers DOGE.
+type TransferTransaction struct {
+	netParams *chaincfg.Params
+	msgTx     *wire.MsgTx
+	// The sender's script, all the inputs are spent from the sender.
+	senderScript []byte
+	inputValue   int64
+}
+
+// BuildTransferWithFeeRate
+// Build a P2PKH transfer transaction, the utxos of the sender will be selected automatically,
+// and the change will return to the sender.
+// @param amount the koinu amount to transfer
+// @param feeRate koinu/byte, the suggest average fee rate will be used if it <= 0, and it's at least `MinFeeRate`.
+func (c *Chain) BuildTransferWithFeeRate(sender, receiver, amount string, feeRate int64) (txn *TransferTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil || value <= 0 {
+		return nil, errors.New("invalid transfer amount")
+	}
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := fetchUtxos(sender, c.Chainnet, transferUtxoLimit)
+	if err != nil {
+		return
+	}
+	return buildTransferWithUtxos(c.Chainnet, sender, receiver, value, feeRate, utxos)
+}
+
+// BuildTransferAllWithFeeRate
+// Build a transaction that spend all the utxos of the sender to the receiver.
+// @param feeRate koinu/byte, the suggest average fee rate will be used if it <= 0, and it's at least `MinFeeRate`.
+func (c *Chain) BuildTransferAllWithFeeRate(sender, receiver string, feeRate int64) (txn *TransferTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := fetchUtxos(sender, c.Chainnet, transferUtxoLimit)
+	if err != nil {
+		return
+	}
+	return buildTransferAllWithUtxos(c.Chainnet, sender, receiver, feeRate, utxos)
+}
+
+func (c *Chain) feeRateOrSuggest(feeRate int64) (int64, error) {
+	if feeRate <= 0 {
+		rates, err := c.SuggestFeeRate()
+		if err != nil {
+			return 0, err
+		}
+		feeRate = rates.Average
+	}
+	return base.Max(feeRate, MinFeeRate), nil
+}
+
+// NewTransferTransactionWithUtxos
+// Build the transfer transaction with the specified utxos, e.g. the utxos fetched by `Chain.FetchUtxos`.
+// @param amount the koinu amount to transfer
+// @param feeRate koinu/byte, it's at least `MinFeeRate`.
+func NewTransferTransactionWithUtxos(chainnet, sender, receiver, amount string, feeRate int64, utxos *UTXOList) (txn *TransferTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil || value <= 0 {
+		return nil, errors.New("invalid transfer amount")
+	}
+	return buildTransferWithUtxos(chainnet, sender, receiver, value, base.Max(feeRate, MinFeeRate), utxos)
+}
+
+func newTransferTransaction(chainnet, sender string) (*TransferTransaction, error) {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	senderScript, err := p2pkhScriptOf(sender, net)
+	if err != nil {
+		return nil, err
+	}
+	return &TransferTransaction{
+		netParams:    net,
+		msgTx:        wire.NewMsgTx(wire.TxVersion),
+		senderScript: senderScript,
+	}, nil
+}
+
+func buildTransferWithUtxos(chainnet, sender, receiver string, amount, feeRate int64, utxos *UTXOList) (*TransferTransaction, error) {
+	txn, err := newTransferTransaction(chainnet, sender)
+	if err != nil {
+		return nil, err
+	}
+	if amount < DustLimit {
+		return nil, ErrDustAmount
+	}
+	if err = txn.addOutput(receiver, amount); err != nil {
+		return nil, err
+	}
+
+	// Select the largest utxos first to reduce the inputs count.
+	candidates, err := sortedUtxos(utxos)
+	if err != nil {
+		return nil, err
+	}
+	for _, utxo := range candidates {
+		if err = txn.addInput(utxo); err != nil {
+			return nil, err
+		}
+		fee := feeRate * estimateTxSize(len(txn.msgTx.TxIn), 1)
+		if txn.inputValue < amount+fee {
+			continue
+		}
+		feeWithChange := feeRate * estimateTxSize(len(txn.msgTx.TxIn), 2)
+		if change := txn.inputValue - amount - feeWithChange; change >= DustLimit {
+			txn.msgTx.AddTxOut(wire.NewTxOut(change, txn.senderScript))
+		}
+		// else the change is too small, it's paid as the fee.
+		return txn, nil
+	}
+	return nil, ErrInsufficientBalance
+}
+
+func buildTransferAllWithUtxos(chainnet, sender, receiver string, feeRate int64, utxos *UTXOList) (*TransferTransaction, error) {
+	txn, err := newTransferTransaction(chainnet, sender)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := sortedUtxos(utxos)
+	if err != nil {
+		return nil, err
+	}
+	for _, utxo := range candidates {
+		if err = txn.addInput(utxo); err != nil {
+			return nil, err
+		}
+	}
+	value := txn.inputValue - feeRate*estimateTxSize(len(txn.msgTx.TxIn), 1)
+	if len(candidates) == 0 || value < DustLimit {
+		return nil, ErrInsufficientBalance
+	}
+	if err = txn.addOutput(receiver, value); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+func sortedUtxos(utxos *UTXOList) ([]*UTXO, error) {
+	if utxos == nil {
+		return nil, ErrInsufficientBalance
+	}
+	res := make([]*UTXO, 0, len(utxos.Utxos))
+	for _, utxo := range utxos.Utxos {
+		if utxo.Value == nil || !utxo.Value.IsInt64() || utxo.Value.Sign() <= 0 {
+			return nil, errors.New("invalid utxo value")
+		}
+		res = append(res, utxo)
+	}
+	slices.SortStableFunc(res, func(a, b *UTXO) int {
+		return b.Value.Cmp(a.Value)
+	})
+	return res, nil
+}
+
+// estimateTxSize
+// @return the size of the P2PKH transaction after all inputs are signed.
+func estimateTxSize(inputCount, outputCount int) int64 {
+	return txOverheadSize + int64(inputCount)*p2pkhInputSize + int64(outputCount)*p2pkhOutputSize
+}
+
+func p2pkhScriptOf(address string, net *chaincfg.Params) ([]byte, error) {
+	addr, err := btcutil.DecodeAddress(address, net)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := addr.(*btcutil.AddressPubKeyHash); !ok {
+		return nil, errors.New("only the P2PKH address is supported")
+	}
+	return txscript.PayToAddrScript(addr)
+}
+
+func (t *TransferTransaction) addInput(utxo *UTXO) error {
+	hash, err := chainhash.NewHashFromStr(utxo.Txid)
+	if err != nil {
+		return err
+	}
+	t.msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, uint32(utxo.Index)), nil, nil))
+	t.inputValue += utxo.Value.Int64()
+	return nil
+}
+
+func (t *TransferTransaction) addOutput(address string, value int64) error {
+	addr, err := btcutil.DecodeAddress(address, t.netParams)
+	if err != nil {
+		return err
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return err
+	}
+	t.msgTx.AddTxOut(wire.NewTxOut(value, script))
+	return nil
+}
+
+// Fee
+// @return the network fee (koinu) of the transaction
+func (t *TransferTransaction) Fee() int64 {
+	fee := t.inputValue
+	for _, out := range t.msgTx.TxOut {
+		fee -= out.Value
+	}
+	return fee
+}
+
+// EstimateSize
+// @return the size of the transaction after all inputs are signed
+func (t *TransferTransaction) EstimateSize() int64 {
+	return estimateTxSize(len(t.msgTx.TxIn), len(t.msgTx.TxOut))
+}
+
+// MARK - Implement the protocol Transaction
+
+func (t *TransferTransaction) SignWithAccount(account base.Account) (signedTxn *base.OptionalString, err error) {
+	txn, err := t.SignedTransactionWithAccount(account)
+	if err != nil {
+		return nil, err
+	}
+	return txn.HexString()
+}
+
+func (t *TransferTransaction) SignedTransactionWithAccount(account base.Account) (signedTxn base.SignedTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	dogeAcc := AsDogecoinAccount(account)
+	if dogeAcc == nil {
+		return nil, base.ErrInvalidAccountType
+	}
+	accountScript, err := p2pkhScriptOf(dogeAcc.address, t.netParams)
+	if err != nil {
+		return
+	}
+	if !bytes.Equal(accountScript, t.senderScript) {
+		return nil, ErrNotSenderAccount
+	}
+
+	tx := t.msgTx.Copy()
+	privateKey, _ := btcec.PrivKeyFromBytes(dogeAcc.privateKey)
+	compress := len(dogeAcc.publicKey) == btcec.PubKeyBytesLenCompressed
+	for i, in := range tx.TxIn {
+		in.SignatureScript, err = txscript.SignatureScript(tx, i, t.senderScript, txscript.SigHashAll, privateKey, compress)
+		if err != nil {
+			return
+		}
+	}
+	return &SignedTransaction{msgTx: tx}, nil
+}
+
+type SignedTransaction struct {
+	msgTx *wire.MsgTx
+}
+
+func (t *SignedTransaction) HexString() (res *base.OptionalString, err error) {
+	var buf bytes.Buffer
+	if err := t.msgTx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(hexutil.HexEncodeToString(buf.Bytes())), nil
+}
+
+// TxHash
+// @return the hash of the signed transaction
+func (t *SignedTransaction) TxHash() string {
+	return t.msgTx.TxHash().String()
+}
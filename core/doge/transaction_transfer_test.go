@@ -0,0 +1,99 @@
+package doge
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUtxoList(values ...int64) *UTXOList {
+	list := &UTXOList{}
+	for i, value := range values {
+		hash := chainhash.DoubleHashH([]byte(strconv.Itoa(i)))
+		list.Utxos = append(list.Utxos, &UTXO{Txid: hash.String(), Index: i, Value: big.NewInt(value)})
+	}
+	return list
+}
+
+func verifySignedTransaction(t *testing.T, signed *SignedTransaction, pkScript []byte, values map[wire.OutPoint]int64) {
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for point, value := range values {
+		fetcher.AddPrevOut(point, wire.NewTxOut(value, pkScript))
+	}
+	tx := signed.msgTx
+	for i, in := range tx.TxIn {
+		prevOut := fetcher.FetchPrevOutput(in.PreviousOutPoint)
+		require.NotNil(t, prevOut)
+		engine, err := txscript.NewEngine(prevOut.PkScript, tx, i, txscript.StandardVerifyFlags, nil, nil, prevOut.Value, fetcher)
+		require.NoError(t, err)
+		require.NoError(t, engine.Execute())
+	}
+}
+
+func TestTransferTransaction(t *testing.T) {
+	account, err := NewAccountWithMnemonic(accountCase.mnemonic, ChainMainnet)
+	require.NoError(t, err)
+	require.Equal(t, accountCase.addrMainnet, account.Address())
+	receiver := "DBx1XSBxpSUnEK79nA8VtrKh2qr2LupZ6G"
+	utxos := newTestUtxoList(300000000, 500000000, 120000000)
+
+	txn, err := NewTransferTransactionWithUtxos(ChainMainnet, account.Address(), receiver, "700000000", 100, utxos)
+	require.NoError(t, err)
+	require.Len(t, txn.msgTx.TxIn, 2)
+	require.Len(t, txn.msgTx.TxOut, 2)
+	require.Equal(t, int64(700000000), txn.msgTx.TxOut[0].Value)
+	// the fee rate is raised to the minimum
+	require.Equal(t, MinFeeRate*txn.EstimateSize(), txn.Fee())
+
+	signedTxn, err := txn.SignedTransactionWithAccount(account)
+	require.NoError(t, err)
+	signed := signedTxn.(*SignedTransaction)
+	require.LessOrEqual(t, int64(signed.msgTx.SerializeSize()), txn.EstimateSize())
+
+	values := map[wire.OutPoint]int64{}
+	for i, in := range txn.msgTx.TxIn {
+		values[in.PreviousOutPoint] = []int64{500000000, 300000000}[i]
+	}
+	verifySignedTransaction(t, signed, txn.senderScript, values)
+
+	chain, err := NewChainWithChainnet(ChainMainnet)
+	require.NoError(t, err)
+	fee, err := chain.EstimateTransactionFee(txn)
+	require.NoError(t, err)
+	require.Equal(t, strconv.FormatInt(txn.Fee(), 10), fee.Value)
+
+	other, err := AccountWithPrivateKey("0x0101010101010101010101010101010101010101010101010101010101010101", ChainMainnet)
+	require.NoError(t, err)
+	_, err = txn.SignedTransactionWithAccount(other)
+	require.EqualError(t, err, ErrNotSenderAccount.Error())
+}
+
+func TestTransferTransaction_Limits(t *testing.T) {
+	account, err := NewAccountWithMnemonic(accountCase.mnemonic, ChainMainnet)
+	require.NoError(t, err)
+	receiver := "DBx1XSBxpSUnEK79nA8VtrKh2qr2LupZ6G"
+
+	_, err = NewTransferTransactionWithUtxos(ChainMainnet, account.Address(), receiver, "999999", MinFeeRate, newTestUtxoList(300000000))
+	require.EqualError(t, err, ErrDustAmount.Error())
+	_, err = NewTransferTransactionWithUtxos(ChainMainnet, account.Address(), receiver, "300000000", MinFeeRate, newTestUtxoList(300000000))
+	require.EqualError(t, err, ErrInsufficientBalance.Error())
+
+	// the change below the dust limit is paid as the fee
+	txn, err := NewTransferTransactionWithUtxos(ChainMainnet, account.Address(), receiver, "299000000", MinFeeRate, newTestUtxoList(300000000))
+	require.NoError(t, err)
+	require.Len(t, txn.msgTx.TxOut, 1)
+	require.Equal(t, int64(1000000), txn.Fee())
+
+	all, err := buildTransferAllWithUtxos(ChainMainnet, account.Address(), receiver, MinFeeRate, newTestUtxoList(300000000, 200000000))
+	require.NoError(t, err)
+	require.Len(t, all.msgTx.TxIn, 2)
+	require.Len(t, all.msgTx.TxOut, 1)
+	require.Equal(t, MinFeeRate*all.EstimateSize(), all.Fee())
+	_, err = buildTransferAllWithUtxos(ChainMainnet, account.Address(), receiver, MinFeeRate, newTestUtxoList(1000000))
+	require.Equal(t, ErrInsufficientBalance, err)
+}
@@ -31,3 +31,13 @@ func AbiCoderEncode(abiTypes []string, args ...any) ([]byte, error) {
 	}
 	return coder.Pack(args...)
 }
+
+// AbiCoderDecode
+// usage like js ethers.AbiCoder.decode
+func AbiCoderDecode(abiTypes []string, data []byte) ([]any, error) {
+	coder, err := AbiCoder(abiTypes)
+	if err != nil {
+		return nil, err
+	}
+	return coder.Unpack(data)
+}
@@ -0,0 +1,190 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+var ErrInvalidAbiSignature = errors.New("invalid abi signature")
+
+// ParseFunctionSignature
+// Parse the human-readable function signature like ethers, the parameter names are optional.
+// e.g. `function transfer(address to, uint256 amount)`, `aggregate3((address,bool,bytes)[])`
+func ParseFunctionSignature(signature string) (*abi.Method, error) {
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), "function ")
+	name, args, err := parseAbiSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	method := abi.NewMethod(name, name, abi.Function, "nonpayable", false, false, args, nil)
+	return &method, nil
+}
+
+// ParseEventSignature
+// Parse the human-readable event signature like ethers, e.g. `event Transfer(address indexed from, address indexed to, uint256 value)`
+func ParseEventSignature(signature string) (*abi.Event, error) {
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), "event ")
+	name, args, err := parseAbiSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	event := abi.NewEvent(name, name, false, args)
+	return &event, nil
+}
+
+func parseAbiSignature(signature string) (string, abi.Arguments, error) {
+	open := strings.Index(signature, "(")
+	if open <= 0 || !strings.HasSuffix(signature, ")") {
+		return "", nil, ErrInvalidAbiSignature
+	}
+	name := strings.TrimSpace(signature[:open])
+	params, err := parseAbiParams(signature[open+1 : len(signature)-1])
+	if err != nil {
+		return "", nil, err
+	}
+	args := make(abi.Arguments, 0, len(params))
+	for _, param := range params {
+		typ, err := abi.NewType(param.Type, "", param.Components)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, abi.Argument{Name: param.Name, Type: typ, Indexed: param.Indexed})
+	}
+	return name, args, nil
+}
+
+// parseAbiParams
+// @param params the comma separated parameters, e.g. `address to, (uint256 id, bytes data)[] items`
+func parseAbiParams(params string) ([]abi.ArgumentMarshaling, error) {
+	params = strings.TrimSpace(params)
+	if params == "" {
+		return nil, nil
+	}
+	items, err := splitAbiParams(params)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]abi.ArgumentMarshaling, 0, len(items))
+	for i, item := range items {
+		param, err := parseAbiParam(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		if param.Name == "" && strings.HasPrefix(param.Type, "tuple") {
+			// the tuple fields must be named to build the go struct
+			param.Name = fmt.Sprintf("arg%d", i)
+		}
+		res = append(res, param)
+	}
+	return res, nil
+}
+
+func parseAbiParam(param string) (abi.ArgumentMarshaling, error) {
+	res := abi.ArgumentMarshaling{}
+	param = strings.TrimPrefix(param, "tuple")
+	var rest string
+	if strings.HasPrefix(param, "(") {
+		end := matchingParen(param)
+		if end < 0 {
+			return res, ErrInvalidAbiSignature
+		}
+		components, err := parseAbiParams(param[1:end])
+		if err != nil {
+			return res, err
+		}
+		for i := range components {
+			if components[i].Name == "" {
+				components[i].Name = fmt.Sprintf("field%d", i)
+			}
+		}
+		res.Components = components
+		// the array suffix of the tuple, e.g. `(address,bytes)[]`
+		suffix := param[end+1:]
+		if space := strings.IndexAny(suffix, " \t"); space >= 0 {
+			suffix, rest = suffix[:space], suffix[space:]
+		}
+		res.Type = "tuple" + suffix
+	} else {
+		fields := strings.Fields(param)
+		if len(fields) == 0 {
+			return res, ErrInvalidAbiSignature
+		}
+		res.Type = normalizeAbiType(fields[0])
+		rest = strings.Join(fields[1:], " ")
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) > 0 && fields[0] == "indexed" {
+		res.Indexed = true
+		fields = fields[1:]
+	}
+	switch len(fields) {
+	case 0:
+	case 1:
+		res.Name = fields[0]
+	default:
+		return res, ErrInvalidAbiSignature
+	}
+	return res, nil
+}
+
+// normalizeAbiType
+// @return the canonical type, e.g. `uint256[]` of `uint[]`
+func normalizeAbiType(typ string) string {
+	index := strings.Index(typ, "[")
+	base, suffix := typ, ""
+	if index >= 0 {
+		base, suffix = typ[:index], typ[index:]
+	}
+	switch base {
+	case "uint", "int":
+		base += "256"
+	}
+	return base + suffix
+}
+
+func splitAbiParams(params string) ([]string, error) {
+	items := make([]string, 0)
+	depth, start := 0, 0
+	for i, c := range params {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, ErrInvalidAbiSignature
+			}
+		case ',':
+			if depth == 0 {
+				items = append(items, params[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, ErrInvalidAbiSignature
+	}
+	return append(items, params[start:]), nil
+}
+
+// matchingParen
+// @return the index of the paren that closes the first character
+func matchingParen(s string) int {
+	depth := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,85 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// AccessListResult is the result of the `eth_createAccessList`.
+type AccessListResult struct {
+	// The json of the access list, it can be set to the `Transaction.AccessList` directly.
+	AccessList string
+	// The gas used by the transaction with the access list.
+	GasUsed string
+}
+
+func (r *AccessListResult) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(r)
+}
+
+// CreateAccessList
+// Create the access list of the transaction through the `eth_createAccessList`.
+// @param from the sender of the transaction
+func (c *Chain) CreateAccessList(from string, transaction *Transaction) (result *AccessListResult, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if !common.IsHexAddress(from) {
+		return nil, base.ErrInvalidAddress
+	}
+	arg, err := transaction.callArg(from)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), chain.timeout)
+	defer cancel()
+	var res struct {
+		AccessList types.AccessList `json:"accessList"`
+		GasUsed    hexutil.Uint64   `json:"gasUsed"`
+		Error      string           `json:"error"`
+	}
+	err = chain.RemoteRpcClient.Client().CallContext(ctx, &res, "eth_createAccessList", arg, "pending")
+	if err != nil {
+		return nil, base.MapAnyToBasicError(err)
+	}
+	if res.Error != "" {
+		return nil, errors.New(res.Error)
+	}
+	result = &AccessListResult{GasUsed: strconv.FormatUint(uint64(res.GasUsed), 10)}
+	if len(res.AccessList) > 0 {
+		data, err := json.Marshal(res.AccessList)
+		if err != nil {
+			return nil, err
+		}
+		result.AccessList = string(data)
+	}
+	return result, nil
+}
+
+// FillAccessList
+// Create the access list and set it to the transaction, the legacy transaction is changed to the EIP-2930 transaction.
+// @param from the sender of the transaction
+func (c *Chain) FillAccessList(from string, transaction *Transaction) (err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	result, err := c.CreateAccessList(from, transaction)
+	if err != nil {
+		return err
+	}
+	transaction.AccessList = result.AccessList
+	if transaction.Type == TransactionTypeLegacy && result.AccessList != "" {
+		transaction.Type = TransactionTypeAccessList
+	}
+	return nil
+}
@@ -70,8 +70,8 @@ type BKSNFT struct {
 	// AnimationUrl   string `json:"animation_url"`
 	// ExternalAppUrl string `json:"external_app_url"`
 	// IsUnique       string `json:"is_unique"`
-	// Value          string `json:"value"`
 	Id        string          `json:"id"`
+	Value     string          `json:"value"`
 	ImageUrl  string          `json:"image_url"`
 	Metadata  *BKSNFTMetadata `json:"metadata"`
 	Owner     string          `json:"owner"`
@@ -84,6 +84,7 @@ func (n *BKSNFT) ToBaseNFT() *base.NFT {
 		Id:       n.Id,
 		Image:    n.ImageUrl,
 		Standard: n.TokenType,
+		Amount:   n.Value,
 	}
 	if n.Metadata != nil {
 		nft.Name = n.Metadata.Name
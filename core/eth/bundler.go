@@ -0,0 +1,183 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// The placeholder signature to estimate the gas of the ECDSA signed user operation,
+// it passes the signature recovery, but it's not the signature of the owner.
+const UserOperationDummySignature = "0xfffffffffffffffffffffffffffffff0000000000000000000000000000000007aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa1c"
+
+// Bundler is the client of the ERC-4337 bundler json rpc.
+type Bundler struct {
+	RpcUrl string
+}
+
+func NewBundler(rpcUrl string) *Bundler {
+	return &Bundler{RpcUrl: rpcUrl}
+}
+
+type UserOperationGasEstimate struct {
+	CallGasLimit         string
+	VerificationGasLimit string
+	PreVerificationGas   string
+	// v0.7 only
+	PaymasterVerificationGasLimit string
+	PaymasterPostOpGasLimit       string
+}
+
+func (e *UserOperationGasEstimate) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(e)
+}
+
+type UserOperationReceipt struct {
+	UserOpHash    string
+	EntryPoint    string
+	Sender        string
+	Nonce         string
+	Paymaster     string
+	ActualGasCost string
+	ActualGasUsed string
+	Success       bool
+	// The revert reason of the execution if it's not success.
+	Reason string
+
+	TransactionHash string
+	BlockHash       string
+	BlockNumber     string
+}
+
+func (r *UserOperationReceipt) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(r)
+}
+
+// SupportedEntryPoints
+// @return the entry point addresses that the bundler supports
+func (b *Bundler) SupportedEntryPoints() (addresses *base.StringArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	var res []string
+	if err = b.call(&res, "eth_supportedEntryPoints"); err != nil {
+		return nil, err
+	}
+	return &base.StringArray{AnyArray: res}, nil
+}
+
+// EstimateUserOperationGas
+// If the user operation has no signature, the `UserOperationDummySignature` is used to estimate.
+func (b *Bundler) EstimateUserOperationGas(op *UserOperation) (estimate *UserOperationGasEstimate, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	estimateOp := *op
+	if estimateOp.Signature == "" || estimateOp.Signature == "0x" {
+		estimateOp.Signature = UserOperationDummySignature
+	}
+	param, err := estimateOp.MarshalRpc()
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		CallGasLimit                  *hexutil.Big `json:"callGasLimit"`
+		VerificationGasLimit          *hexutil.Big `json:"verificationGasLimit"`
+		PreVerificationGas            *hexutil.Big `json:"preVerificationGas"`
+		PaymasterVerificationGasLimit *hexutil.Big `json:"paymasterVerificationGasLimit"`
+		PaymasterPostOpGasLimit       *hexutil.Big `json:"paymasterPostOpGasLimit"`
+	}
+	if err = b.call(&res, "eth_estimateUserOperationGas", json.RawMessage(param), op.EntryPoint); err != nil {
+		return nil, err
+	}
+	return &UserOperationGasEstimate{
+		CallGasLimit:                  hexBigString(res.CallGasLimit),
+		VerificationGasLimit:          hexBigString(res.VerificationGasLimit),
+		PreVerificationGas:            hexBigString(res.PreVerificationGas),
+		PaymasterVerificationGasLimit: hexBigString(res.PaymasterVerificationGasLimit),
+		PaymasterPostOpGasLimit:       hexBigString(res.PaymasterPostOpGasLimit),
+	}, nil
+}
+
+// SendUserOperation
+// @return the userOpHash
+func (b *Bundler) SendUserOperation(op *UserOperation) (hash *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	param, err := op.MarshalRpc()
+	if err != nil {
+		return nil, err
+	}
+	var res string
+	if err = b.call(&res, "eth_sendUserOperation", json.RawMessage(param), op.EntryPoint); err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(res), nil
+}
+
+// GetUserOperationReceipt
+// @return nil if the user operation is not included yet.
+func (b *Bundler) GetUserOperationReceipt(userOpHash string) (receipt *UserOperationReceipt, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	var res *struct {
+		UserOpHash    string       `json:"userOpHash"`
+		EntryPoint    string       `json:"entryPoint"`
+		Sender        string       `json:"sender"`
+		Nonce         *hexutil.Big `json:"nonce"`
+		Paymaster     string       `json:"paymaster"`
+		ActualGasCost *hexutil.Big `json:"actualGasCost"`
+		ActualGasUsed *hexutil.Big `json:"actualGasUsed"`
+		Success       bool         `json:"success"`
+		Reason        string       `json:"reason"`
+		Receipt       struct {
+			TransactionHash string       `json:"transactionHash"`
+			BlockHash       string       `json:"blockHash"`
+			BlockNumber     *hexutil.Big `json:"blockNumber"`
+		} `json:"receipt"`
+	}
+	if err = b.call(&res, "eth_getUserOperationReceipt", userOpHash); err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, nil
+	}
+	return &UserOperationReceipt{
+		UserOpHash:      res.UserOpHash,
+		EntryPoint:      res.EntryPoint,
+		Sender:          res.Sender,
+		Nonce:           hexBigString(res.Nonce),
+		Paymaster:       res.Paymaster,
+		ActualGasCost:   hexBigString(res.ActualGasCost),
+		ActualGasUsed:   hexBigString(res.ActualGasUsed),
+		Success:         res.Success,
+		Reason:          res.Reason,
+		TransactionHash: res.Receipt.TransactionHash,
+		BlockHash:       res.Receipt.BlockHash,
+		BlockNumber:     hexBigString(res.Receipt.BlockNumber),
+	}, nil
+}
+
+func (b *Bundler) call(result interface{}, method string, args ...interface{}) error {
+	client, err := GetConnection(b.RpcUrl)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), client.timeout)
+	defer cancel()
+	err = client.RemoteRpcClient.Client().CallContext(ctx, result, method, args...)
+	if err != nil {
+		return base.MapAnyToBasicError(err)
+	}
+	return nil
+}
+
+// hexBigString
+// @return the decimal string of the quantity, empty if it's nil.
+func hexBigString(n *hexutil.Big) string {
+	if n == nil {
+		return ""
+	}
+	return (*big.Int)(n).String()
+}
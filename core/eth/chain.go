@@ -20,6 +20,8 @@ type IChain interface {
 
 type Chain struct {
 	RpcUrl string
+
+	nonceManager *NonceManager
 }
 
 func NewChainWithRpc(rpcUrl string) *Chain {
@@ -28,6 +30,15 @@ func NewChainWithRpc(rpcUrl string) *Chain {
 	}
 }
 
+// SetNonceManager
+// The transactions without nonce that are built or signed by the chain will reserve the nonces from the manager,
+// and the reservations are committed or released when the transactions are sent by `SendRawTransaction`.
+// The reservations of the transactions that are never sent are kept until the manager is reset.
+// @param manager nil to read the pending nonce from the rpc every time
+func (c *Chain) SetNonceManager(manager *NonceManager) {
+	c.nonceManager = manager
+}
+
 // MARK - Implement the protocol Chain
 
 func (c *Chain) MainToken() base.Token {
@@ -81,7 +92,11 @@ func (c *Chain) SendRawTransaction(signedTx string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return chain.SendRawTransaction(signedTx)
+	hash, err := chain.SendRawTransaction(signedTx)
+	if c.nonceManager != nil {
+		c.nonceManager.settle(signedTx, err)
+	}
+	return hash, err
 }
 
 func (c *Chain) SendSignedTransaction(signedTxn base.SignedTransaction) (hash *base.OptionalString, err error) {
@@ -157,6 +172,9 @@ func (c *Chain) buildTransfer(privateKey *ecdsa.PrivateKey, transaction *Transac
 	}
 
 	if transaction.Nonce == "" || transaction.Nonce == "0" {
+		if c.nonceManager != nil {
+			return c.signTransactionWithManagedNonce(privateKey, transaction)
+		}
 		address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
 		nonce, err := chain.Nonce(address)
 		if err != nil {
@@ -166,12 +184,18 @@ func (c *Chain) buildTransfer(privateKey *ecdsa.PrivateKey, transaction *Transac
 		transaction.Nonce = nonce
 	}
 
-	rawTx, err := transaction.GetRawTx()
+	return c.signTransactionWithNonce(privateKey, transaction)
+}
+
+// signTransactionWithNonce
+// Sign the transaction with it's own nonce, the nonce 0 is not replaced by the pending nonce.
+func (c *Chain) signTransactionWithNonce(privateKey *ecdsa.PrivateKey, transaction *Transaction) (*base.OptionalString, error) {
+	chain, err := GetConnection(c.RpcUrl)
 	if err != nil {
 		return nil, err
 	}
 
-	txResult, err := chain.buildTxWithTransaction(rawTx, privateKey)
+	txResult, err := chain.signTransaction(transaction, privateKey)
 	if err != nil {
 		return nil, err
 	}
@@ -200,21 +224,15 @@ func (c *Chain) SubmitTransactionData(account base.Account, to string, data []by
 	}
 	msg.SetGasLimit(gasLimit.Value)
 	tx := msg.TransferToTransaction()
-	chain, err := GetConnection(c.RpcUrl)
+	privateKeyData, err := account.PrivateKey()
 	if err != nil {
 		return "", err
 	}
-	nonce, err := chain.Nonce(account.Address())
-	if err != nil {
-		nonce = "0"
-		err = nil
-	}
-	tx.Nonce = nonce
-	privateKeyHex, err := account.PrivateKeyHex()
+	privateKeyECDSA, err := crypto.ToECDSA(privateKeyData)
 	if err != nil {
 		return "", err
 	}
-	signedTx, err := c.SignTransaction(privateKeyHex, tx)
+	signedTx, err := c.buildTransfer(privateKeyECDSA, tx)
 	if err != nil {
 		return "", err
 	}
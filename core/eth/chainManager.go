@@ -14,7 +14,9 @@ func GetConnection(rpcUrl string) (*EthChain, error) {
 
 // @param timeout time unit millisecond, zero instead use default.
 func getConnectionWithTimeout(rpcUrl string, timeout int64) (*EthChain, error) {
+	lock.RLock()
 	chain, ok := chainConnections[rpcUrl]
+	lock.RUnlock()
 	if ok {
 		return chain, nil
 	}
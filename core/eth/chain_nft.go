@@ -12,17 +12,32 @@ func (c *Chain) TransferNFT(sender, receiver string, nft *base.NFT) (*Transactio
 }
 
 // TransferNFTParams
-// - param nftStandard: only support erc-721 now, else throw error unsupported nft type.
+// - param nftStandard: only support erc-721 and erc-1155 now, else throw error unsupported nft type.
+// The erc-1155 nft will transfer quantity 1, use `TransferErc1155` to transfer the chosen quantity.
 func (c *Chain) TransferNFTParams(sender, receiver, nftId, nftContractAddress, nftStandard string) (txn *Transaction, err error) {
 	defer base.CatchPanicAndMapToBasicError(&err)
 
-	if strings.ToLower(nftStandard) != "erc-721" {
-		return nil, errors.New("unsupported nft type")
-	}
-	data, err := EncodeErc721TransferFrom(sender, receiver, nftId)
+	data, err := encodeNftTransfer(sender, receiver, nftId, nftStandard)
 	if err != nil {
 		return nil, err
 	}
+	return c.buildContractCallTransaction(sender, nftContractAddress, data)
+}
+
+func encodeNftTransfer(sender, receiver, nftId, nftStandard string) ([]byte, error) {
+	switch strings.ToLower(nftStandard) {
+	case NftStandardErc721:
+		return EncodeErc721TransferFrom(sender, receiver, nftId)
+	case NftStandardErc1155:
+		return EncodeErc1155SafeTransferFrom(sender, receiver, nftId, "1")
+	default:
+		return nil, errors.New("unsupported nft type")
+	}
+}
+
+// buildContractCallTransaction
+// Build the transaction that calls the contract with the suggested gas price and the estimated gas limit.
+func (c *Chain) buildContractCallTransaction(sender, contractAddress string, data []byte) (*Transaction, error) {
 	gasPrice, err := c.SuggestGasPrice()
 	if err != nil {
 		return nil, err
@@ -30,7 +45,7 @@ func (c *Chain) TransferNFTParams(sender, receiver, nftId, nftContractAddress, n
 
 	msg := NewCallMsg()
 	msg.SetFrom(sender)
-	msg.SetTo(nftContractAddress)
+	msg.SetTo(contractAddress)
 	msg.SetValue("0")
 	msg.SetGasPrice(gasPrice.Value)
 	msg.SetData(data)
@@ -3,6 +3,7 @@ package eth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"strconv"
 	"strings"
@@ -61,10 +62,24 @@ func (c *Chain) BatchFetchErc20TokenBalance(contractListString, address string)
 // @return 余额数组，顺序与传入的 contractList 是保持一致的
 // @throw 如果任意一个代币请求余额出错时，会抛出错误
 func (c *Chain) BatchErc20TokenBalance(contractList []string, address string) ([]string, error) {
-	return base.MapListConcurrentStringToString(contractList, func(s string) (string, error) {
-		b, err := c.Erc20Token(s).BalanceOfAddress(address)
-		return b.Total, err
-	})
+	multicall := c.NewMulticall()
+	for _, contract := range contractList {
+		if _, err := multicall.AddErc20Balance(contract, address); err != nil {
+			return nil, err
+		}
+	}
+	results, err := multicall.Execute()
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]string, len(contractList))
+	for i, result := range results.AnyArray {
+		if !result.Success {
+			return nil, fmt.Errorf("fetch the balance of %v failed: %v", contractList[i], result.Error)
+		}
+		balances[i] = result.Value
+	}
+	return balances, nil
 }
 
 // call eth_call method
@@ -136,13 +151,11 @@ func (c *Chain) SignWithPrivateKeyData(privateKeyData []byte, transaction *Trans
 	if err != nil {
 		return
 	}
-
-	rawTx, err := transaction.GetRawTx()
-	if err != nil {
-		return
+	if c.nonceManager != nil && (transaction.Nonce == "" || transaction.Nonce == "0") {
+		return c.signTransactionWithManagedNonce(privateKeyECDSA, transaction)
 	}
 
-	output, err := client.buildTxWithTransaction(rawTx, privateKeyECDSA)
+	output, err := client.signTransaction(transaction, privateKeyECDSA)
 	if err != nil {
 		return
 	}
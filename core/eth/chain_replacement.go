@@ -0,0 +1,181 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// The nodes reject the replacement transaction unless both of the fee caps are bumped at least 10%.
+const ReplacementFeeBumpPercent = 10
+
+var (
+	ErrTransactionAlreadyMined    = errors.New("the transaction has already been mined, it cannot be replaced")
+	ErrTransactionNotFound        = errors.New("the transaction is not found, it may have been dropped")
+	ErrReplacementTypeUnsupported = errors.New("the transaction type cannot be replaced")
+)
+
+// ReplacementTransaction is the transaction that replaces the pending transaction at the same nonce.
+type ReplacementTransaction struct {
+	*Transaction
+
+	From         string
+	OriginalHash string
+	// The fee caps of the original transaction, the MaxPriorityFeePerGas is empty for the legacy transaction.
+	OriginalGasPrice             string
+	OriginalMaxPriorityFeePerGas string
+}
+
+func (r *ReplacementTransaction) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(r)
+}
+
+// BuildSpeedUpTransaction
+// Rebuild the pending transaction at the same nonce with the bumped fees.
+// @throw ErrTransactionAlreadyMined if the nonce of the transaction has been used.
+func (c *Chain) BuildSpeedUpTransaction(txHash string) (replacement *ReplacementTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	replacement, tx, err := c.loadReplaceableTransaction(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if tx.To() == nil {
+		return nil, ErrReplacementTypeUnsupported
+	}
+	replacement.To = tx.To().String()
+	replacement.Value = tx.Value().String()
+	replacement.GasLimit = strconv.FormatUint(tx.Gas(), 10)
+	replacement.Data = HexType.HexEncodeToString(tx.Data())
+	if len(tx.AccessList()) > 0 {
+		accessList, err := json.Marshal(tx.AccessList())
+		if err != nil {
+			return nil, err
+		}
+		replacement.AccessList = string(accessList)
+	}
+	return replacement, nil
+}
+
+// BuildCancelTransaction
+// Build the zero-value self-transfer at the same nonce with the bumped fees, the original transaction will be dropped if it's mined.
+// @throw ErrTransactionAlreadyMined if the nonce of the transaction has been used.
+func (c *Chain) BuildCancelTransaction(txHash string) (replacement *ReplacementTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	replacement, original, err := c.loadReplaceableTransaction(txHash)
+	if err != nil {
+		return nil, err
+	}
+	replacement.To = replacement.From
+	replacement.Value = "0"
+	replacement.Data = ""
+	// the transfer costs more than 21000 gas on some L2s (e.g. Arbitrum),
+	// the original gas limit is used if the estimation fails.
+	msg := NewCallMsg()
+	msg.SetFrom(replacement.From)
+	msg.SetTo(replacement.To)
+	msg.SetValue("0")
+	if gas, err := c.EstimateGasLimit(msg); err == nil {
+		replacement.GasLimit = gas.Value
+	} else {
+		replacement.GasLimit = strconv.FormatUint(original.Gas(), 10)
+	}
+	return replacement, nil
+}
+
+// SignReplacementTransaction
+// The replacement should be signed by this method, the nonce of the replacement is kept even it's 0.
+func (c *Chain) SignReplacementTransaction(account *Account, replacement *ReplacementTransaction) (signedTx *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if !common.IsHexAddress(replacement.From) || common.HexToAddress(replacement.From) != common.HexToAddress(account.Address()) {
+		return nil, errors.New("the account is not the sender of the transaction")
+	}
+	return c.signTransactionWithNonce(account.privateKeyECDSA, replacement.Transaction)
+}
+
+// loadReplaceableTransaction
+// @return the replacement with the same nonce and the bumped fees, and the original transaction.
+func (c *Chain) loadReplaceableTransaction(txHash string) (*ReplacementTransaction, *types.Transaction, error) {
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), chain.timeout)
+	defer cancel()
+	tx, isPending, err := chain.RemoteRpcClient.TransactionByHash(ctx, common.HexToHash(txHash))
+	if err == ethereum.NotFound {
+		return nil, nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isPending {
+		return nil, nil, ErrTransactionAlreadyMined
+	}
+	sender, err := decodeSigner(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the other transaction with the same nonce may have been mined
+	minedNonce, err := chain.RemoteRpcClient.NonceAt(ctx, sender, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if minedNonce > tx.Nonce() {
+		return nil, nil, ErrTransactionAlreadyMined
+	}
+
+	replacement := &ReplacementTransaction{
+		Transaction:      &Transaction{Nonce: strconv.FormatUint(tx.Nonce(), 10)},
+		From:             sender.String(),
+		OriginalHash:     tx.Hash().String(),
+		OriginalGasPrice: tx.GasFeeCap().String(),
+	}
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		gasPrice := bumpReplacementFee(tx.GasPrice())
+		if suggest, err := chain.RemoteRpcClient.SuggestGasPrice(ctx); err == nil {
+			gasPrice = base.MaxBigInt(gasPrice, suggest)
+		}
+		replacement.GasPrice = gasPrice.String()
+	case types.DynamicFeeTxType:
+		replacement.OriginalMaxPriorityFeePerGas = tx.GasTipCap().String()
+		tipCap := bumpReplacementFee(tx.GasTipCap())
+		feeCap := bumpReplacementFee(tx.GasFeeCap())
+		if suggest, err := c.SuggestGasPriceEIP1559(); err == nil {
+			suggestTip, _ := big.NewInt(0).SetString(suggest.MaxPriorityFee, 10)
+			suggestFee, _ := big.NewInt(0).SetString(suggest.MaxFee, 10)
+			if suggestTip != nil && suggestFee != nil {
+				tipCap = base.MaxBigInt(tipCap, suggestTip)
+				feeCap = base.MaxBigInt(feeCap, suggestFee)
+			}
+		}
+		// the tip can't be more than the fee cap
+		feeCap = base.MaxBigInt(feeCap, tipCap)
+		replacement.GasPrice = feeCap.String()
+		replacement.MaxPriorityFeePerGas = tipCap.String()
+	default:
+		return nil, nil, ErrReplacementTypeUnsupported
+	}
+	return replacement, tx, nil
+}
+
+// bumpReplacementFee
+// @return the fee that is increased by the `ReplacementFeeBumpPercent` and rounded up, at least 1 wei more than the fee.
+func bumpReplacementFee(fee *big.Int) *big.Int {
+	bumped := big.NewInt(0).Mul(fee, big.NewInt(100+ReplacementFeeBumpPercent))
+	bumped.Add(bumped, big.NewInt(99))
+	bumped.Div(bumped, big.NewInt(100))
+	minimum := big.NewInt(0).Add(fee, big.NewInt(1))
+	return base.MaxBigInt(bumped, minimum)
+}
@@ -0,0 +1,124 @@
+package eth
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBumpReplacementFee(t *testing.T) {
+	require.Equal(t, "110", bumpReplacementFee(big.NewInt(100)).String())
+	require.Equal(t, "1100000002", bumpReplacementFee(big.NewInt(1000000001)).String())
+	require.Equal(t, "13", bumpReplacementFee(big.NewInt(11)).String())
+	require.Equal(t, "1", bumpReplacementFee(big.NewInt(0)).String())
+}
+
+func TestChain_ReplaceTransaction(t *testing.T) {
+	account := cowAccount(t)
+	receiver := common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	legacyTx, err := types.SignNewTx(account.privateKeyECDSA, signer, &types.LegacyTx{
+		Nonce: 0, To: &receiver, Value: big.NewInt(1000), Gas: 21000, GasPrice: big.NewInt(1e9),
+	})
+	require.NoError(t, err)
+	dynamicTx, err := types.SignNewTx(account.privateKeyECDSA, signer, &types.DynamicFeeTx{
+		ChainID: big.NewInt(1), Nonce: 3, To: &receiver, Gas: 60000, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(3e9), Data: []byte{1, 2, 3},
+	})
+	require.NoError(t, err)
+
+	minedNonce, estimatedGas := "0x0", ""
+	mined := map[common.Hash]bool{}
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		switch method {
+		case "eth_getTransactionByHash":
+			var hash common.Hash
+			require.NoError(t, json.Unmarshal(params[0], &hash))
+			for _, tx := range []*types.Transaction{legacyTx, dynamicTx} {
+				if tx.Hash() != hash {
+					continue
+				}
+				data, err := tx.MarshalJSON()
+				require.NoError(t, err)
+				var res map[string]any
+				require.NoError(t, json.Unmarshal(data, &res))
+				res["blockNumber"] = nil
+				if mined[hash] {
+					res["blockNumber"] = "0x10"
+					res["blockHash"] = common.Hash{1}.String()
+				}
+				return res
+			}
+			return nil
+		case "eth_getTransactionCount":
+			return minedNonce
+		case "eth_estimateGas":
+			if estimatedGas == "" {
+				return errStubReverted{}
+			}
+			return estimatedGas
+		}
+		// the fee suggestions are unavailable
+		return errStubReverted{}
+	})
+	defer server.Close()
+	chain := NewChainWithRpc(server.URL)
+
+	speedUp, err := chain.BuildSpeedUpTransaction(legacyTx.Hash().String())
+	require.NoError(t, err)
+	require.Equal(t, "0", speedUp.Nonce)
+	require.Equal(t, "1100000000", speedUp.GasPrice)
+	require.Equal(t, "", speedUp.MaxPriorityFeePerGas)
+	require.Equal(t, "1000", speedUp.Value)
+	require.Equal(t, account.Address(), speedUp.From)
+
+	// the nonce 0 is kept
+	signed, err := chain.SignReplacementTransaction(account, speedUp)
+	require.NoError(t, err)
+	signedTx, err := NewTransactionFromHex(signed.Value[2:])
+	require.NoError(t, err)
+	require.Equal(t, "0", signedTx.Nonce)
+
+	cancel, err := chain.BuildCancelTransaction(dynamicTx.Hash().String())
+	require.NoError(t, err)
+	require.Equal(t, "3", cancel.Nonce)
+	require.Equal(t, account.Address(), cancel.To)
+	require.Equal(t, "0", cancel.Value)
+	require.Equal(t, "", cancel.Data)
+	require.Equal(t, "3300000000", cancel.GasPrice)
+	require.Equal(t, "1100000000", cancel.MaxPriorityFeePerGas)
+	require.Equal(t, "1000000000", cancel.OriginalMaxPriorityFeePerGas)
+	// the original gas limit is used if the estimation fails
+	require.Equal(t, "60000", cancel.GasLimit)
+	signed, err = chain.SignReplacementTransaction(account, cancel)
+	require.NoError(t, err)
+	signedTx, err = NewTransactionFromHex(signed.Value[2:])
+	require.NoError(t, err)
+	require.Equal(t, "3", signedTx.Nonce)
+	require.Equal(t, "1100000000", signedTx.MaxPriorityFeePerGas)
+
+	// the estimated gas of the self-transfer on the L2
+	estimatedGas = "0x186a0"
+	estimated, err := chain.BuildCancelTransaction(dynamicTx.Hash().String())
+	require.NoError(t, err)
+	require.Equal(t, "100000", estimated.GasLimit)
+
+	other, err := NewAccountWithMnemonic("test test test test test test test test test test test junk")
+	require.NoError(t, err)
+	_, err = chain.SignReplacementTransaction(other, cancel)
+	require.Error(t, err)
+
+	// the other transaction with the nonce 3 is mined
+	minedNonce = "0x4"
+	_, err = chain.BuildSpeedUpTransaction(dynamicTx.Hash().String())
+	require.Equal(t, ErrTransactionAlreadyMined, err)
+	minedNonce = "0x0"
+	mined[dynamicTx.Hash()] = true
+	_, err = chain.BuildCancelTransaction(dynamicTx.Hash().String())
+	require.Equal(t, ErrTransactionAlreadyMined, err)
+	_, err = chain.BuildCancelTransaction(common.Hash{2}.String())
+	require.Equal(t, ErrTransactionNotFound, err)
+}
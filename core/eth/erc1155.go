@@ -0,0 +1,150 @@
+package eth
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	NftStandardErc721  = "erc-721"
+	NftStandardErc1155 = "erc-1155"
+)
+
+const Erc1155Abi = `[{"inputs":[{"internalType":"address","name":"account","type":"address"},{"internalType":"uint256","name":"id","type":"uint256"}],"name":"balanceOf","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address[]","name":"accounts","type":"address[]"},{"internalType":"uint256[]","name":"ids","type":"uint256[]"}],"name":"balanceOfBatch","outputs":[{"internalType":"uint256[]","name":"","type":"uint256[]"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"from","type":"address"},{"internalType":"address","name":"to","type":"address"},{"internalType":"uint256","name":"id","type":"uint256"},{"internalType":"uint256","name":"amount","type":"uint256"},{"internalType":"bytes","name":"data","type":"bytes"}],"name":"safeTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"from","type":"address"},{"internalType":"address","name":"to","type":"address"},{"internalType":"uint256[]","name":"ids","type":"uint256[]"},{"internalType":"uint256[]","name":"amounts","type":"uint256[]"},{"internalType":"bytes","name":"data","type":"bytes"}],"name":"safeBatchTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+
+// Encode erc1155 safeTransferFrom data
+// @param amount the quantity of the token to transfer
+func EncodeErc1155SafeTransferFrom(sender, receiver, nftId, amount string) ([]byte, error) {
+	if !common.IsHexAddress(sender) || !common.IsHexAddress(receiver) {
+		return nil, base.ErrInvalidAddress
+	}
+	id, ok := big.NewInt(0).SetString(nftId, 10)
+	if !ok {
+		return nil, errors.New("invalid nft id")
+	}
+	amountInt, ok := big.NewInt(0).SetString(amount, 10)
+	if !ok || amountInt.Sign() <= 0 {
+		return nil, base.ErrInvalidAmount
+	}
+	return EncodeContractData(Erc1155Abi, "safeTransferFrom",
+		common.HexToAddress(sender),
+		common.HexToAddress(receiver),
+		id,
+		amountInt,
+		[]byte{})
+}
+
+// Encode erc1155 safeBatchTransferFrom data
+// @param nftIds the token ids, the count should be same as the amounts.
+// @param amounts the quantities of the tokens to transfer
+func EncodeErc1155SafeBatchTransferFrom(sender, receiver string, nftIds, amounts *base.StringArray) ([]byte, error) {
+	if !common.IsHexAddress(sender) || !common.IsHexAddress(receiver) {
+		return nil, base.ErrInvalidAddress
+	}
+	if nftIds.Count() == 0 || nftIds.Count() != amounts.Count() {
+		return nil, errors.New("the count of the nft ids and amounts should be same")
+	}
+	ids := make([]*big.Int, 0, nftIds.Count())
+	amountInts := make([]*big.Int, 0, amounts.Count())
+	for i := range nftIds.AnyArray {
+		id, ok := big.NewInt(0).SetString(nftIds.ValueAt(i), 10)
+		if !ok {
+			return nil, errors.New("invalid nft id")
+		}
+		amount, ok := big.NewInt(0).SetString(amounts.ValueAt(i), 10)
+		if !ok || amount.Sign() <= 0 {
+			return nil, base.ErrInvalidAmount
+		}
+		ids = append(ids, id)
+		amountInts = append(amountInts, amount)
+	}
+	return EncodeContractData(Erc1155Abi, "safeBatchTransferFrom",
+		common.HexToAddress(sender),
+		common.HexToAddress(receiver),
+		ids,
+		amountInts,
+		[]byte{})
+}
+
+// TransferErc1155
+// Build the transaction that transfers the chosen quantity of the ERC-1155 token.
+func (c *Chain) TransferErc1155(sender, receiver, nftId, nftContractAddress, amount string) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	data, err := EncodeErc1155SafeTransferFrom(sender, receiver, nftId, amount)
+	if err != nil {
+		return nil, err
+	}
+	return c.buildContractCallTransaction(sender, nftContractAddress, data)
+}
+
+// TransferErc1155Batch
+// Build the transaction that transfers multiple ERC-1155 tokens of the same contract.
+func (c *Chain) TransferErc1155Batch(sender, receiver, nftContractAddress string, nftIds, amounts *base.StringArray) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	data, err := EncodeErc1155SafeBatchTransferFrom(sender, receiver, nftIds, amounts)
+	if err != nil {
+		return nil, err
+	}
+	return c.buildContractCallTransaction(sender, nftContractAddress, data)
+}
+
+// Erc1155BalanceOf
+// @return the amount of the token that the owner holds
+func (c *Chain) Erc1155BalanceOf(nftContractAddress, owner, nftId string) (balance *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	id, ok := big.NewInt(0).SetString(nftId, 10)
+	if !ok {
+		return nil, errors.New("invalid nft id")
+	}
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	res := big.NewInt(0)
+	err = chain.CallContractConstant(&res, nftContractAddress, Erc1155Abi, "balanceOf", nil, common.HexToAddress(owner), id)
+	if err != nil {
+		return
+	}
+	return base.NewOptionalString(res.String()), nil
+}
+
+// Erc1155BalanceOfBatch
+// @param owners the owner of each token, the count should be same as the nftIds.
+// @return the amounts in the same order of the nftIds
+func (c *Chain) Erc1155BalanceOfBatch(nftContractAddress string, owners, nftIds *base.StringArray) (balances *base.StringArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if owners.Count() != nftIds.Count() {
+		return nil, errors.New("the count of the owners and nft ids should be same")
+	}
+	accounts := make([]common.Address, 0, owners.Count())
+	ids := make([]*big.Int, 0, nftIds.Count())
+	for i := range owners.AnyArray {
+		accounts = append(accounts, common.HexToAddress(owners.ValueAt(i)))
+		id, ok := big.NewInt(0).SetString(nftIds.ValueAt(i), 10)
+		if !ok {
+			return nil, errors.New("invalid nft id")
+		}
+		ids = append(ids, id)
+	}
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	var res []*big.Int
+	err = chain.CallContractConstant(&res, nftContractAddress, Erc1155Abi, "balanceOfBatch", nil, accounts, ids)
+	if err != nil {
+		return
+	}
+	balances = base.NewStringArray()
+	for _, amount := range res {
+		balances.Append(amount.String())
+	}
+	return balances, nil
+}
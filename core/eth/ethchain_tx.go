@@ -68,7 +68,25 @@ func (e *EthChain) buildTx(
 }
 
 func (e *EthChain) buildTxWithTransaction(transaction *types.Transaction, privateKeyCDSA *ecdsa.PrivateKey) (*BuildTxResult, error) {
-	signedTx, err := types.SignTx(transaction, types.LatestSignerForChainID(e.chainId), privateKeyCDSA)
+	return e.buildTxWithSigner(transaction, types.LatestSignerForChainID(e.chainId), privateKeyCDSA)
+}
+
+// signTransaction
+// Sign the transaction with it's type and chain id, e.g. the legacy transaction with chain id "0" is signed without the EIP-155.
+func (e *EthChain) signTransaction(transaction *Transaction, privateKeyCDSA *ecdsa.PrivateKey) (*BuildTxResult, error) {
+	rawTx, err := transaction.GetRawTx()
+	if err != nil {
+		return nil, err
+	}
+	signer, err := transaction.signer(e.chainId)
+	if err != nil {
+		return nil, err
+	}
+	return e.buildTxWithSigner(rawTx, signer, privateKeyCDSA)
+}
+
+func (e *EthChain) buildTxWithSigner(transaction *types.Transaction, signer types.Signer, privateKeyCDSA *ecdsa.PrivateKey) (*BuildTxResult, error) {
+	signedTx, err := types.SignTx(transaction, signer, privateKeyCDSA)
 	if err != nil {
 		return nil, err
 	}
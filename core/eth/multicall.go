@@ -0,0 +1,373 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/base/inter"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// The Multicall3 contract, it's deployed at the same address on the most chains, see https://www.multicall3.com
+const Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+const Multicall3Abi = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"},{"inputs":[{"internalType":"address","name":"addr","type":"address"}],"name":"getEthBalance","outputs":[{"internalType":"uint256","name":"balance","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+// The max count of the calls in one `aggregate3`, the more calls are split into multiple requests.
+const multicallBatchSize = 200
+
+var (
+	multicall3Parsed = mustParseAbi(Multicall3Abi)
+	erc20Parsed      = mustParseAbi(Erc20AbiStr)
+)
+
+type MulticallResult struct {
+	Success bool
+	// The hex string of the return data
+	ReturnData string
+	// The decoded value of the builtin calls, the integer is the decimal string.
+	Value string
+	// The reason if the call failed
+	Error string
+}
+
+func (r *MulticallResult) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(r)
+}
+
+type MulticallResultArray struct {
+	inter.AnyArray[*MulticallResult]
+}
+
+type multicallCall struct {
+	target   common.Address
+	callData []byte
+	// the native balance is fetched by the `eth_getBalance` if the Multicall3 is not deployed.
+	nativeBalanceOf *common.Address
+	decode          func(data []byte) (string, error)
+}
+
+// Multicall packs many contract reads into one `aggregate3` call of the Multicall3,
+// each call is decoded individually and it's failure doesn't affect the others.
+// If the Multicall3 is not deployed on the chain, the calls are sent by the json rpc batch.
+type Multicall struct {
+	chain *Chain
+	calls []*multicallCall
+
+	// The Multicall3 contract address, default is `Multicall3Address`
+	ContractAddress string
+}
+
+func (c *Chain) NewMulticall() *Multicall {
+	return &Multicall{chain: c, ContractAddress: Multicall3Address}
+}
+
+func (m *Multicall) Count() int {
+	return len(m.calls)
+}
+
+// AddCall
+// Add the raw call, the `Value` of the result is empty.
+// @return the index of the result
+func (m *Multicall) AddCall(target string, callData []byte) (int, error) {
+	return m.addCall(target, callData, nil)
+}
+
+// AddErc20Balance
+// @return the index of the result
+func (m *Multicall) AddErc20Balance(token, owner string) (int, error) {
+	return m.addErc20Call(token, "balanceOf", common.HexToAddress(owner))
+}
+
+// AddErc20Allowance
+// @return the index of the result
+func (m *Multicall) AddErc20Allowance(token, owner, spender string) (int, error) {
+	return m.addErc20Call(token, "allowance", common.HexToAddress(owner), common.HexToAddress(spender))
+}
+
+// AddErc20Decimals
+// @return the index of the result
+func (m *Multicall) AddErc20Decimals(token string) (int, error) {
+	return m.addErc20Call(token, "decimals")
+}
+
+// AddErc20Symbol
+// @return the index of the result
+func (m *Multicall) AddErc20Symbol(token string) (int, error) {
+	return m.addErc20Call(token, "symbol")
+}
+
+// AddErc20Name
+// @return the index of the result
+func (m *Multicall) AddErc20Name(token string) (int, error) {
+	return m.addErc20Call(token, "name")
+}
+
+// AddNativeBalance
+// @return the index of the result
+func (m *Multicall) AddNativeBalance(owner string) (int, error) {
+	if !common.IsHexAddress(owner) {
+		return -1, base.ErrInvalidAddress
+	}
+	method := multicall3Parsed.Methods["getEthBalance"]
+	ownerAddress := common.HexToAddress(owner)
+	callData, err := multicall3Parsed.Pack(method.Name, ownerAddress)
+	if err != nil {
+		return -1, err
+	}
+	index, err := m.addCall(m.ContractAddress, callData, method.Outputs)
+	if err != nil {
+		return -1, err
+	}
+	m.calls[index].nativeBalanceOf = &ownerAddress
+	return index, nil
+}
+
+func (m *Multicall) addErc20Call(token, method string, params ...interface{}) (int, error) {
+	callData, err := erc20Parsed.Pack(method, params...)
+	if err != nil {
+		return -1, err
+	}
+	return m.addCall(token, callData, erc20Parsed.Methods[method].Outputs)
+}
+
+func (m *Multicall) addCall(target string, callData []byte, outputs abi.Arguments) (int, error) {
+	if !common.IsHexAddress(target) {
+		return -1, base.ErrInvalidAddress
+	}
+	call := &multicallCall{target: common.HexToAddress(target), callData: callData}
+	if outputs != nil {
+		call.decode = func(data []byte) (string, error) {
+			return decodeMulticallValue(outputs, data)
+		}
+	}
+	m.calls = append(m.calls, call)
+	return len(m.calls) - 1, nil
+}
+
+// Execute
+// @return the results in the same order of the calls
+func (m *Multicall) Execute() (results *MulticallResultArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	chain, err := GetConnection(m.chain.RpcUrl)
+	if err != nil {
+		return
+	}
+	results = &MulticallResultArray{AnyArray: make([]*MulticallResult, 0, len(m.calls))}
+	for start := 0; start < len(m.calls); start += multicallBatchSize {
+		end := min(start+multicallBatchSize, len(m.calls))
+		batch, err := m.aggregate3(chain, m.calls[start:end])
+		if err == errMulticallNotDeployed {
+			batch, err = m.batchCall(chain, m.calls[start:end])
+		}
+		if err != nil {
+			return nil, err
+		}
+		results.AnyArray = append(results.AnyArray, batch...)
+	}
+	return results, nil
+}
+
+var errMulticallNotDeployed = errors.New("the multicall contract is not deployed")
+
+func (m *Multicall) aggregate3(chain *EthChain, calls []*multicallCall) ([]*MulticallResult, error) {
+	type call3 struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	params := make([]call3, len(calls))
+	for i, call := range calls {
+		params[i] = call3{Target: call.target, AllowFailure: true, CallData: call.callData}
+	}
+	data, err := multicall3Parsed.Pack("aggregate3", params)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), chain.timeout)
+	defer cancel()
+	contract := common.HexToAddress(m.ContractAddress)
+	output, err := chain.RemoteRpcClient.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(output) == 0 {
+		// the call to the address without code returns nothing
+		return nil, errMulticallNotDeployed
+	}
+	unpacked, err := multicall3Parsed.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, err
+	}
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	returnData := *abi.ConvertType(unpacked[0], new([]result)).(*[]result)
+	if len(returnData) != len(calls) {
+		return nil, errors.New("the count of the multicall results mismatch")
+	}
+
+	results := make([]*MulticallResult, len(calls))
+	for i, call := range calls {
+		if returnData[i].Success {
+			results[i] = newMulticallResult(call, returnData[i].ReturnData)
+		} else {
+			results[i] = &MulticallResult{
+				ReturnData: HexType.HexEncodeToString(returnData[i].ReturnData),
+				Error:      "execution reverted",
+			}
+		}
+	}
+	return results, nil
+}
+
+// batchCall
+// Send each call by the json rpc batch, it's used if the Multicall3 is not deployed.
+func (m *Multicall) batchCall(chain *EthChain, calls []*multicallCall) ([]*MulticallResult, error) {
+	elems := make([]rpc.BatchElem, len(calls))
+	for i, call := range calls {
+		if call.nativeBalanceOf != nil {
+			elems[i] = rpc.BatchElem{
+				Method: "eth_getBalance",
+				Args:   []interface{}{*call.nativeBalanceOf, "latest"},
+				Result: new(hexutil.Big),
+			}
+		} else {
+			elems[i] = rpc.BatchElem{
+				Method: "eth_call",
+				Args: []interface{}{map[string]interface{}{
+					"to":   call.target,
+					"data": hexutil.Bytes(call.callData),
+				}, "latest"},
+				Result: new(hexutil.Bytes),
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), chain.timeout)
+	defer cancel()
+	if err := chain.RemoteRpcClient.Client().BatchCallContext(ctx, elems); err != nil {
+		return nil, base.MapAnyToBasicError(err)
+	}
+
+	results := make([]*MulticallResult, len(calls))
+	for i, call := range calls {
+		if elems[i].Error != nil {
+			results[i] = &MulticallResult{Error: elems[i].Error.Error()}
+			continue
+		}
+		switch res := elems[i].Result.(type) {
+		case *hexutil.Big:
+			results[i] = &MulticallResult{
+				Success:    true,
+				ReturnData: HexType.HexEncodeToString(common.BigToHash(res.ToInt()).Bytes()),
+				Value:      res.ToInt().String(),
+			}
+		case *hexutil.Bytes:
+			results[i] = newMulticallResult(call, *res)
+		}
+	}
+	return results, nil
+}
+
+func newMulticallResult(call *multicallCall, returnData []byte) *MulticallResult {
+	result := &MulticallResult{
+		Success:    true,
+		ReturnData: HexType.HexEncodeToString(returnData),
+	}
+	if call.decode != nil {
+		value, err := call.decode(returnData)
+		if err != nil {
+			// the call of the address without code is success with the empty data
+			result.Success = false
+			result.Error = err.Error()
+		} else {
+			result.Value = value
+		}
+	}
+	return result
+}
+
+// decodeMulticallValue
+// @return the decimal string of the integer or the string, the bytes32 string like the MKR symbol is supported.
+func decodeMulticallValue(outputs abi.Arguments, data []byte) (string, error) {
+	values, err := outputs.Unpack(data)
+	if err != nil {
+		if len(outputs) == 1 && outputs[0].Type.T == abi.StringTy && len(data) == 32 {
+			return string(bytes.TrimRight(data, "\x00")), nil
+		}
+		return "", err
+	}
+	if len(values) != 1 {
+		return "", errors.New("unsupported multicall output")
+	}
+	switch v := values[0].(type) {
+	case *big.Int:
+		return v.String(), nil
+	case uint8:
+		return fmt.Sprint(v), nil
+	case string:
+		return v, nil
+	case bool:
+		return fmt.Sprint(v), nil
+	case common.Address:
+		return v.Hex(), nil
+	default:
+		return "", errors.New("unsupported multicall output")
+	}
+}
+
+// BatchErc20TokenInfo
+// Fetch the name, symbol and decimals of the tokens in one multicall.
+// @return the token infos in the same order of the contracts, the info is nil if any field of the token failed.
+func (c *Chain) BatchErc20TokenInfo(contractList []string) ([]*Erc20TokenInfo, error) {
+	multicall := c.NewMulticall()
+	for _, contract := range contractList {
+		for _, add := range []func(string) (int, error){multicall.AddErc20Name, multicall.AddErc20Symbol, multicall.AddErc20Decimals} {
+			if _, err := add(contract); err != nil {
+				return nil, err
+			}
+		}
+	}
+	results, err := multicall.Execute()
+	if err != nil {
+		return nil, err
+	}
+	chainId, err := c.connectedChainId()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]*Erc20TokenInfo, len(contractList))
+	for i, contract := range contractList {
+		name, symbol, decimals := results.AnyArray[i*3], results.AnyArray[i*3+1], results.AnyArray[i*3+2]
+		if !name.Success || !symbol.Success || !decimals.Success {
+			continue
+		}
+		var decimal int16
+		if _, err := fmt.Sscan(decimals.Value, &decimal); err != nil {
+			continue
+		}
+		infos[i] = &Erc20TokenInfo{
+			TokenInfo: &base.TokenInfo{
+				Name:    name.Value,
+				Symbol:  symbol.Value,
+				Decimal: decimal,
+			},
+			ContractAddress: contract,
+			ChainId:         big.NewInt(chainId).String(),
+		}
+	}
+	return infos, nil
+}
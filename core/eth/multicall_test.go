@@ -0,0 +1,185 @@
+package eth
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	stubTokenUsdt = "0x1111111111111111111111111111111111111111"
+	stubTokenMkr  = "0x2222222222222222222222222222222222222222"
+	// the address without code
+	stubTokenNone = "0x3333333333333333333333333333333333333333"
+	stubOwner     = "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"
+)
+
+// stubTokenCall
+// @return the return data of the token call, false if the call reverted.
+func stubTokenCall(t *testing.T, target common.Address, data []byte) ([]byte, bool) {
+	encode := func(typ string, value any) []byte {
+		res, err := AbiCoderEncode([]string{typ}, value)
+		require.NoError(t, err)
+		return res
+	}
+	selector := HexType.HexEncodeToString(data[:4])
+	switch {
+	case target == common.HexToAddress(Multicall3Address) && selector == stubSelector("getEthBalance(address)"):
+		return encode("uint256", big.NewInt(1e18)), true
+	case target == common.HexToAddress(stubTokenUsdt):
+		switch selector {
+		case stubSelector("balanceOf(address)"):
+			return encode("uint256", big.NewInt(100)), true
+		case stubSelector("allowance(address,address)"):
+			return nil, false
+		case stubSelector("decimals()"):
+			return encode("uint8", uint8(6)), true
+		case stubSelector("symbol()"):
+			return encode("string", "USDT"), true
+		case stubSelector("name()"):
+			return encode("string", "Tether USD"), true
+		}
+	case target == common.HexToAddress(stubTokenMkr):
+		switch selector {
+		case stubSelector("balanceOf(address)"):
+			return encode("uint256", big.NewInt(200)), true
+		case stubSelector("decimals()"):
+			return encode("uint8", uint8(18)), true
+		case stubSelector("symbol()"):
+			return common.RightPadBytes([]byte("MKR"), 32), true
+		case stubSelector("name()"):
+			return common.RightPadBytes([]byte("Maker"), 32), true
+		}
+	case target == common.HexToAddress(stubTokenNone):
+		return []byte{}, true
+	}
+	return nil, false
+}
+
+// newStubMulticallServer
+// @param deployed whether the Multicall3 is deployed, the calls are sent by the json rpc batch if it's not.
+func newStubMulticallServer(t *testing.T, deployed bool, requests *[]string) *httptest.Server {
+	return newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		*requests = append(*requests, method)
+		switch method {
+		case "eth_getBalance":
+			return "0xde0b6b3a7640000"
+		case "eth_call":
+			var msg struct {
+				To common.Address `json:"to"`
+			}
+			require.NoError(t, json.Unmarshal(params[0], &msg))
+			data, err := HexType.HexDecodeString(stubCallInput(t, params))
+			require.NoError(t, err)
+			if msg.To != common.HexToAddress(Multicall3Address) {
+				res, ok := stubTokenCall(t, msg.To, data)
+				if !ok {
+					return errStubReverted{}
+				}
+				return HexType.HexEncodeToString(res)
+			}
+			if !deployed {
+				return "0x"
+			}
+			inputs, err := multicall3Parsed.Methods["aggregate3"].Inputs.Unpack(data[4:])
+			require.NoError(t, err)
+			type call3 struct {
+				Target       common.Address
+				AllowFailure bool
+				CallData     []byte
+			}
+			calls := *abi.ConvertType(inputs[0], new([]call3)).(*[]call3)
+			type result struct {
+				Success    bool
+				ReturnData []byte
+			}
+			results := make([]result, len(calls))
+			for i, call := range calls {
+				res, ok := stubTokenCall(t, call.Target, call.CallData)
+				results[i] = result{Success: ok, ReturnData: res}
+			}
+			output, err := multicall3Parsed.Methods["aggregate3"].Outputs.Pack(results)
+			require.NoError(t, err)
+			return HexType.HexEncodeToString(output)
+		}
+		t.Fatalf("unexpected method %v", method)
+		return nil
+	})
+}
+
+func TestMulticall_Execute(t *testing.T) {
+	for _, deployed := range []bool{true, false} {
+		var requests []string
+		server := newStubMulticallServer(t, deployed, &requests)
+
+		multicall := NewChainWithRpc(server.URL).NewMulticall()
+		_, err := multicall.AddNativeBalance(stubOwner)
+		require.NoError(t, err)
+		_, err = multicall.AddErc20Balance(stubTokenUsdt, stubOwner)
+		require.NoError(t, err)
+		_, err = multicall.AddErc20Allowance(stubTokenUsdt, stubOwner, stubOwner)
+		require.NoError(t, err)
+		_, err = multicall.AddErc20Decimals(stubTokenUsdt)
+		require.NoError(t, err)
+		_, err = multicall.AddErc20Symbol(stubTokenMkr)
+		require.NoError(t, err)
+		_, err = multicall.AddErc20Name(stubTokenNone)
+		require.NoError(t, err)
+		index, err := multicall.AddCall(stubTokenUsdt, common.FromHex(stubSelector("name()")))
+		require.NoError(t, err)
+		require.Equal(t, 6, index)
+		_, err = multicall.AddCall("0x123", nil)
+		require.Error(t, err)
+
+		results, err := multicall.Execute()
+		require.NoError(t, err)
+		require.Equal(t, multicall.Count(), results.Count())
+		values := make([]string, 0)
+		for _, result := range results.AnyArray {
+			if result.Success {
+				values = append(values, result.Value)
+			} else {
+				values = append(values, "failed")
+			}
+		}
+		require.Equal(t, []string{"1000000000000000000", "100", "failed", "6", "MKR", "failed", ""}, values)
+		require.True(t, strings.HasPrefix(results.ValueAt(6).ReturnData, "0x"))
+
+		if deployed {
+			require.Equal(t, []string{"eth_call"}, requests)
+		} else {
+			// the aggregate3 and the batch
+			require.Len(t, requests, 1+multicall.Count())
+		}
+		server.Close()
+	}
+}
+
+func TestChain_BatchErc20(t *testing.T) {
+	var requests []string
+	server := newStubMulticallServer(t, true, &requests)
+	defer server.Close()
+
+	chain := NewChainWithRpc(server.URL)
+	balances, err := chain.BatchErc20TokenBalance([]string{stubTokenUsdt, stubTokenMkr}, stubOwner)
+	require.NoError(t, err)
+	require.Equal(t, []string{"100", "200"}, balances)
+	_, err = chain.BatchErc20TokenBalance([]string{stubTokenUsdt, stubTokenNone}, stubOwner)
+	require.Error(t, err)
+
+	infos, err := chain.BatchErc20TokenInfo([]string{stubTokenUsdt, stubTokenMkr, stubTokenNone})
+	require.NoError(t, err)
+	require.Equal(t, "Tether USD", infos[0].Name)
+	require.Equal(t, int16(6), infos[0].Decimal)
+	require.Equal(t, "MKR", infos[1].Symbol)
+	require.Equal(t, "1", infos[1].ChainId)
+	require.Nil(t, infos[2])
+	require.Len(t, requests, 3)
+}
@@ -3,6 +3,7 @@ package eth
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"sort"
 	"strings"
 	"time"
@@ -32,6 +33,8 @@ type RSS3Metadata struct {
 	Collection      string `json:"collection"`
 	Descr           string `json:"description"`
 	ContractAddress string `json:"contract_address"`
+	// The transferred amount of the ERC-1155 token
+	Value string `json:"value"`
 }
 
 type RSS3NoteAction struct {
@@ -68,6 +71,19 @@ func (a *RSS3NoteAction) NftIdentifierKey() string {
 	return ""
 }
 
+func (a *RSS3NoteAction) IsErc1155() bool {
+	return a.Tag == TagCollectible && strings.EqualFold(a.Metadata.Standard, NftStandardErc1155)
+}
+
+// @return the transferred amount of the ERC-1155 token, it's 1 if the value is not specified.
+func (a *RSS3NoteAction) Erc1155Amount() *big.Int {
+	amount, ok := big.NewInt(0).SetString(a.Metadata.Value, 10)
+	if !ok {
+		return big.NewInt(1)
+	}
+	return amount
+}
+
 func (a *RSS3NoteAction) RelatedScanUrl() string {
 	if len(a.RelatedUrls) == 0 {
 		return ""
@@ -165,6 +181,8 @@ func (f *RSS3Fetcher) FetchNFTs(owner string) (map[string][]*base.NFT, error) {
 	f.Owner = strings.ToLower(owner)
 
 	actions := make(map[string]*RSS3NoteAction)
+	// the ERC-1155 token isn't unique, the balance is the received amount minus the sent amount.
+	erc1155Amounts := make(map[string]*big.Int)
 	willTradeInFutureActions := []*RSS3NoteAction{}
 	for true {
 		notes, err := f.FetchNotesNext()
@@ -182,6 +200,22 @@ func (f *RSS3Fetcher) FetchNFTs(owner string) (map[string][]*base.NFT, error) {
 				if nftKey == "" {
 					continue
 				}
+				if action.IsErc1155() {
+					amount, ok := erc1155Amounts[nftKey]
+					if !ok {
+						amount = big.NewInt(0)
+						erc1155Amounts[nftKey] = amount
+					}
+					if action.To == f.Owner {
+						amount.Add(amount, action.Erc1155Amount())
+						actions[nftKey] = action
+						action.Timestamp = note.Timestamp.Unix()
+						action.HashString = note.HashString
+					} else if action.From == f.Owner {
+						amount.Sub(amount, action.Erc1155Amount())
+					}
+					continue
+				}
 				if action.To == f.Owner { // receive a nft
 					actions[nftKey] = action
 					action.Timestamp = note.Timestamp.Unix()
@@ -220,8 +254,14 @@ func (f *RSS3Fetcher) FetchNFTs(owner string) (map[string][]*base.NFT, error) {
 	}
 
 	nftGroupd := make(map[string][]*base.NFT)
-	for _, action := range actions {
+	for nftKey, action := range actions {
 		if nft := action.Nft(); nft != nil {
+			if amount, ok := erc1155Amounts[nftKey]; ok {
+				if amount.Sign() <= 0 {
+					continue
+				}
+				nft.Amount = amount.String()
+			}
 			key := nft.GroupName()
 			group, exist := nftGroupd[key]
 			if exist {
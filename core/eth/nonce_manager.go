@@ -0,0 +1,349 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// NonceManager reserves the nonces locally, so the transactions sent concurrently by the same address don't collide.
+// The reservations are tracked per (chainId, address) and reconciled with the on-chain pending and latest nonces.
+type NonceManager struct {
+	mu       sync.Mutex
+	accounts map[string]*nonceAccount
+	// the reservations of the transactions signed by the chain but not sent yet, keyed by the transaction hash
+	signed map[common.Hash]*NonceReservation
+}
+
+// nonceAccount is the local nonce state of an address on a chain.
+type nonceAccount struct {
+	// the next nonce that has never been reserved
+	next uint64
+	// the reserved nonces that are being built or sent
+	inFlight map[uint64]bool
+	// the nonces of the transactions that were accepted by the node but not mined yet
+	committed map[uint64]bool
+}
+
+func NewNonceManager() *NonceManager {
+	return &NonceManager{
+		accounts: make(map[string]*nonceAccount),
+		signed:   make(map[common.Hash]*NonceReservation),
+	}
+}
+
+// NonceReservation is a nonce reserved by the `NonceManager`.
+// It must be committed after the transaction is sent, or released if the building or sending fails.
+type NonceReservation struct {
+	ChainId string
+	Address string
+	Nonce   string
+
+	manager *NonceManager
+	key     string
+	nonce   uint64
+}
+
+// Reserve
+// Reserve the lowest nonce that is not used on-chain and not reserved locally.
+// The nonces freed by the released reservations are reassigned before the new nonces.
+func (m *NonceManager) Reserve(chain *Chain, address string) (reservation *NonceReservation, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	key, chainId, err := nonceAccountKey(chain, address)
+	if err != nil {
+		return nil, err
+	}
+	pending, latest, err := chainNonces(chain, address)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	nonce := m.account(key).reserve(pending, latest)
+	return &NonceReservation{
+		ChainId: chainId,
+		Address: address,
+		Nonce:   strconv.FormatUint(nonce, 10),
+		manager: m,
+		key:     key,
+		nonce:   nonce,
+	}, nil
+}
+
+// Gaps
+// @return the nonces that block the committed transactions from being mined, they're neither reserved nor known by the node.
+// The gaps are reassigned by the next reservations, or they can be filled by the cancel transactions.
+func (m *NonceManager) Gaps(chain *Chain, address string) (gaps *base.StringArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	key, _, err := nonceAccountKey(chain, address)
+	if err != nil {
+		return nil, err
+	}
+	pending, latest, err := chainNonces(chain, address)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	gaps = base.NewStringArray()
+	for _, nonce := range m.account(key).gaps(pending, latest) {
+		gaps.Append(strconv.FormatUint(nonce, 10))
+	}
+	return gaps, nil
+}
+
+// Reset
+// Forget all of the local reservations of the address, the next reservation starts from the on-chain pending nonce.
+func (m *NonceManager) Reset(chain *Chain, address string) (err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	key, _, err := nonceAccountKey(chain, address)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.accounts, key)
+	return nil
+}
+
+// Commit
+// Mark the nonce as used after the transaction is accepted by the node.
+func (r *NonceReservation) Commit() {
+	r.manager.mu.Lock()
+	defer r.manager.mu.Unlock()
+	r.manager.account(r.key).commit(r.nonce)
+}
+
+// Release
+// Free the nonce if the transaction failed to build or send, or the committed transaction has been dropped by the node.
+// The nonce will be reassigned to the next reservation.
+func (r *NonceReservation) Release() {
+	r.manager.mu.Lock()
+	defer r.manager.mu.Unlock()
+	r.manager.account(r.key).release(r.nonce)
+}
+
+// SendTransactionWithNonceManager
+// Sign and send the transaction with the nonce reserved by the manager, the nonce of the transaction is ignored.
+// The nonce is committed if the transaction is sent, otherwise it's released.
+// @return the hash of the transaction
+func (c *Chain) SendTransactionWithNonceManager(manager *NonceManager, account *Account, transaction *Transaction) (hash *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	reservation, err := manager.Reserve(c, account.Address())
+	if err != nil {
+		return nil, err
+	}
+	committed := false
+	defer func() {
+		if committed {
+			reservation.Commit()
+		} else {
+			reservation.Release()
+		}
+	}()
+
+	txn := *transaction
+	txn.Nonce = reservation.Nonce
+	signedTx, err := c.signTransactionWithNonce(account.privateKeyECDSA, &txn)
+	if err != nil {
+		return nil, err
+	}
+	txHash, err := c.SendRawTransaction(signedTx.Value)
+	if err != nil {
+		if !isAlreadyKnown(err) {
+			return nil, err
+		}
+		signed := new(types.Transaction)
+		if err := signed.UnmarshalBinary(common.FromHex(signedTx.Value)); err != nil {
+			return nil, err
+		}
+		txHash = signed.Hash().String()
+	}
+	committed = true
+	return base.NewOptionalString(txHash), nil
+}
+
+// signTransactionWithManagedNonce
+// Sign the transaction with the nonce reserved by the chain's manager,
+// the reservation is settled when the signed transaction is sent by `SendRawTransaction`.
+func (c *Chain) signTransactionWithManagedNonce(privateKey *ecdsa.PrivateKey, transaction *Transaction) (*base.OptionalString, error) {
+	reservation, err := c.nonceManager.Reserve(c, crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+	if err != nil {
+		return nil, err
+	}
+	transaction.Nonce = reservation.Nonce
+	signedTx, err := c.signTransactionWithNonce(privateKey, transaction)
+	if err != nil {
+		reservation.Release()
+		return nil, err
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(common.FromHex(signedTx.Value)); err != nil {
+		reservation.Release()
+		return nil, err
+	}
+
+	c.nonceManager.mu.Lock()
+	c.nonceManager.signed[signed.Hash()] = reservation
+	c.nonceManager.mu.Unlock()
+	return signedTx, nil
+}
+
+// settle
+// Commit the reservation of the signed transaction if it's accepted by the node, otherwise release it.
+// The transactions that were not signed with the reserved nonces are ignored.
+func (m *NonceManager) settle(signedTx string, sendErr error) {
+	txn := new(types.Transaction)
+	if err := txn.UnmarshalBinary(common.FromHex(signedTx)); err != nil {
+		return
+	}
+	m.mu.Lock()
+	reservation, ok := m.signed[txn.Hash()]
+	delete(m.signed, txn.Hash())
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	if sendErr == nil || isAlreadyKnown(sendErr) {
+		reservation.Commit()
+	} else {
+		reservation.Release()
+	}
+}
+
+// isAlreadyKnown
+// @return true if the node has received the same transaction before.
+func isAlreadyKnown(err error) bool {
+	return strings.Contains(err.Error(), "already known")
+}
+
+// account
+// The caller should hold the lock.
+func (m *NonceManager) account(key string) *nonceAccount {
+	account, ok := m.accounts[key]
+	if !ok {
+		account = &nonceAccount{
+			inFlight:  make(map[uint64]bool),
+			committed: make(map[uint64]bool),
+		}
+		m.accounts[key] = account
+	}
+	return account
+}
+
+// reconcile
+// Drop the committed nonces that have been mined, and skip the nonces that have been used by the other senders.
+func (a *nonceAccount) reconcile(pending, latest uint64) {
+	for nonce := range a.committed {
+		if nonce < latest {
+			delete(a.committed, nonce)
+		}
+	}
+	if pending > a.next {
+		a.next = pending
+	}
+}
+
+func (a *nonceAccount) reserve(pending, latest uint64) uint64 {
+	a.reconcile(pending, latest)
+	nonce := pending
+	for ; nonce < a.next; nonce++ {
+		if !a.inFlight[nonce] && !a.committed[nonce] {
+			break
+		}
+	}
+	if nonce == a.next {
+		a.next++
+	}
+	a.inFlight[nonce] = true
+	return nonce
+}
+
+func (a *nonceAccount) commit(nonce uint64) {
+	delete(a.inFlight, nonce)
+	a.committed[nonce] = true
+}
+
+func (a *nonceAccount) release(nonce uint64) {
+	delete(a.inFlight, nonce)
+	delete(a.committed, nonce)
+	if nonce+1 == a.next {
+		a.next--
+	}
+}
+
+// gaps
+// The nonces from the pending nonce to the highest committed nonce that are not reserved,
+// and the pending nonce itself if it has been committed, because the node has dropped it.
+func (a *nonceAccount) gaps(pending, latest uint64) []uint64 {
+	a.reconcile(pending, latest)
+	highest, ok := uint64(0), false
+	for nonce := range a.committed {
+		if nonce >= pending && nonce >= highest {
+			highest, ok = nonce, true
+		}
+	}
+	gaps := make([]uint64, 0)
+	if !ok {
+		return gaps
+	}
+	for nonce := pending; nonce <= highest; nonce++ {
+		if a.inFlight[nonce] {
+			continue
+		}
+		if !a.committed[nonce] || nonce == pending {
+			gaps = append(gaps, nonce)
+		}
+	}
+	return gaps
+}
+
+// nonceAccountKey
+// @return the key of the (chainId, address), and the chainId.
+func nonceAccountKey(chain *Chain, address string) (string, string, error) {
+	if !common.IsHexAddress(address) {
+		return "", "", base.ErrInvalidAddress
+	}
+	ethChain, err := GetConnection(chain.RpcUrl)
+	if err != nil {
+		return "", "", err
+	}
+	if ethChain.chainId == nil {
+		return "", "", errors.New("the chain id of the rpc is unknown")
+	}
+	chainId := ethChain.chainId.String()
+	return chainId + ":" + strings.ToLower(common.HexToAddress(address).Hex()), chainId, nil
+}
+
+// chainNonces
+// @return the pending and the latest nonce of the address.
+func chainNonces(chain *Chain, address string) (pending, latest uint64, err error) {
+	ethChain, err := GetConnection(chain.RpcUrl)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ethChain.timeout)
+	defer cancel()
+	owner := common.HexToAddress(address)
+	if pending, err = ethChain.RemoteRpcClient.PendingNonceAt(ctx, owner); err != nil {
+		return
+	}
+	if latest, err = ethChain.RemoteRpcClient.NonceAt(ctx, owner, nil); err != nil {
+		return
+	}
+	return pending, latest, nil
+}
@@ -0,0 +1,193 @@
+package eth
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonceAccount(t *testing.T) {
+	account := &nonceAccount{inFlight: map[uint64]bool{}, committed: map[uint64]bool{}}
+	require.Equal(t, uint64(5), account.reserve(5, 5))
+	require.Equal(t, uint64(6), account.reserve(5, 5))
+	require.Equal(t, uint64(7), account.reserve(5, 5))
+
+	// the failed nonce is reassigned
+	account.commit(5)
+	account.release(6)
+	account.commit(7)
+	require.Equal(t, []uint64{6}, account.gaps(6, 5))
+	require.Equal(t, uint64(6), account.reserve(6, 5))
+	require.Equal(t, uint64(8), account.reserve(6, 5))
+	require.Empty(t, account.gaps(6, 5))
+
+	// the last nonce is freed
+	account.release(8)
+	require.Equal(t, uint64(8), account.next)
+
+	// the committed nonce 6 is dropped by the node
+	account.commit(6)
+	require.Equal(t, []uint64{6}, account.gaps(6, 6))
+	account.release(6)
+	require.Equal(t, uint64(6), account.reserve(6, 6))
+
+	// the nonces are used by the other sender
+	require.Equal(t, uint64(10), account.reserve(10, 10))
+	require.Empty(t, account.committed)
+}
+
+func TestNonceManager_Reserve(t *testing.T) {
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		require.Equal(t, "eth_getTransactionCount", method)
+		return "0x3"
+	})
+	defer server.Close()
+	chain := NewChainWithRpc(server.URL)
+	manager := NewNonceManager()
+	address := cowAccount(t).Address()
+
+	const count = 20
+	nonces := make(chan string, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reservation, err := manager.Reserve(chain, address)
+			require.NoError(t, err)
+			require.Equal(t, "1", reservation.ChainId)
+			nonces <- reservation.Nonce
+			reservation.Commit()
+		}()
+	}
+	wg.Wait()
+	close(nonces)
+	unique := map[string]bool{}
+	for nonce := range nonces {
+		unique[nonce] = true
+	}
+	require.Len(t, unique, count)
+	require.True(t, unique["3"])
+	require.True(t, unique["22"])
+
+	gaps, err := manager.Gaps(chain, address)
+	require.NoError(t, err)
+	// the node doesn't know the committed nonce 3
+	require.Equal(t, 1, gaps.Count())
+	require.Equal(t, "3", gaps.ValueAt(0))
+
+	require.NoError(t, manager.Reset(chain, address))
+	reservation, err := manager.Reserve(chain, address)
+	require.NoError(t, err)
+	require.Equal(t, "3", reservation.Nonce)
+	_, err = manager.Reserve(chain, "0x123")
+	require.Error(t, err)
+}
+
+func TestChain_SendTransactionWithNonceManager(t *testing.T) {
+	sent := make([]string, 0)
+	rejected := false
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		switch method {
+		case "eth_getTransactionCount":
+			return "0x0"
+		case "eth_sendRawTransaction":
+			if rejected {
+				return errStubReverted{}
+			}
+			var signedTx string
+			require.NoError(t, json.Unmarshal(params[0], &signedTx))
+			txn, err := NewTransactionFromHex(signedTx[2:])
+			require.NoError(t, err)
+			sent = append(sent, txn.Nonce)
+			return common.Hash{1}.String()
+		}
+		t.Fatalf("unexpected method %v", method)
+		return nil
+	})
+	defer server.Close()
+	chain := NewChainWithRpc(server.URL)
+	manager := NewNonceManager()
+	account := cowAccount(t)
+	transaction := NewTransaction("7", "1000000000", DEFAULT_ETH_GAS_LIMIT, account.Address(), "1", "")
+
+	_, err := chain.SendTransactionWithNonceManager(manager, account, transaction)
+	require.NoError(t, err)
+	_, err = chain.SendTransactionWithNonceManager(manager, account, transaction)
+	require.NoError(t, err)
+	require.Equal(t, "7", transaction.Nonce)
+
+	// the nonce of the failed transaction is reassigned
+	rejected = true
+	_, err = chain.SendTransactionWithNonceManager(manager, account, transaction)
+	require.Error(t, err)
+	rejected = false
+	_, err = chain.SendTransactionWithNonceManager(manager, account, transaction)
+	require.NoError(t, err)
+	require.Equal(t, []string{"0", "1", "2"}, sent)
+}
+
+func TestChain_SetNonceManager(t *testing.T) {
+	sent := make([]string, 0)
+	rejected := false
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		switch method {
+		case "eth_getTransactionCount":
+			return "0x0"
+		case "eth_sendRawTransaction":
+			if rejected {
+				return errStubReverted{}
+			}
+			var signedTx string
+			require.NoError(t, json.Unmarshal(params[0], &signedTx))
+			txn, err := NewTransactionFromHex(signedTx[2:])
+			require.NoError(t, err)
+			sent = append(sent, txn.Nonce)
+			return common.Hash{1}.String()
+		}
+		t.Fatalf("unexpected method %v", method)
+		return nil
+	})
+	defer server.Close()
+	chain := NewChainWithRpc(server.URL)
+	chain.SetNonceManager(NewNonceManager())
+	account := cowAccount(t)
+	newTransfer := func() *Transaction {
+		return NewTransaction("", "1000000000", DEFAULT_ETH_GAS_LIMIT, account.Address(), "1", "")
+	}
+
+	// the transactions built before sending don't collide
+	approveTx, err := chain.BuildTransferTxWithAccount(account, newTransfer())
+	require.NoError(t, err)
+	createTx, err := chain.BuildTransferTxWithAccount(account, newTransfer())
+	require.NoError(t, err)
+
+	// the nonce of the rejected transaction is reassigned
+	rejected = true
+	_, err = chain.SendRawTransaction(createTx.Value)
+	require.Error(t, err)
+	rejected = false
+	createTx, err = chain.SignTransactionWithAccount(account, newTransfer())
+	require.NoError(t, err)
+	_, err = chain.SendRawTransaction(approveTx.Value)
+	require.NoError(t, err)
+	_, err = chain.SendRawTransaction(createTx.Value)
+	require.NoError(t, err)
+
+	// the transaction with the specified nonce doesn't reserve
+	transaction := newTransfer()
+	transaction.Nonce = "9"
+	signedTx, err := chain.BuildTransferTxWithAccount(account, transaction)
+	require.NoError(t, err)
+	_, err = chain.SendRawTransaction(signedTx.Value)
+	require.NoError(t, err)
+
+	transaction = newTransfer()
+	signedTx, err = chain.BuildTransferTxWithAccount(account, transaction)
+	require.NoError(t, err)
+	require.Equal(t, "2", transaction.Nonce)
+	require.Equal(t, []string{"0", "1", "9"}, sent)
+}
@@ -0,0 +1,601 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/base/inter"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// The Uniswap Permit2 contract, it's deployed at the same address on all chains.
+const Permit2Address = "0x000000000022D473030F116dDEE9F6B43aC78BA3"
+
+const (
+	Erc20PermitAbi      = `[{"inputs":[],"name":"PERMIT_TYPEHASH","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"DOMAIN_SEPARATOR","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"owner","type":"address"}],"name":"nonces","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"version","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"owner","type":"address"},{"internalType":"address","name":"spender","type":"address"},{"internalType":"uint256","name":"value","type":"uint256"},{"internalType":"uint256","name":"deadline","type":"uint256"},{"internalType":"uint8","name":"v","type":"uint8"},{"internalType":"bytes32","name":"r","type":"bytes32"},{"internalType":"bytes32","name":"s","type":"bytes32"}],"name":"permit","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+	Permit2AllowanceAbi = `[{"inputs":[{"internalType":"address","name":"","type":"address"},{"internalType":"address","name":"","type":"address"},{"internalType":"address","name":"","type":"address"}],"name":"allowance","outputs":[{"internalType":"uint160","name":"amount","type":"uint160"},{"internalType":"uint48","name":"expiration","type":"uint48"},{"internalType":"uint48","name":"nonce","type":"uint48"}],"stateMutability":"view","type":"function"}]`
+)
+
+var (
+	ErrPermitUnsupported    = errors.New("the token does not support the EIP-2612 permit")
+	ErrPermitUnknownDomain  = errors.New("the permit domain of the token is unknown")
+	ErrPermitDaiUnsupported = errors.New("the token uses the DAI-style permit, which is not compatible with the EIP-2612 permit")
+)
+
+var erc20PermitParsed = mustParseAbi(Erc20PermitAbi)
+
+var (
+	erc20PermitTypeHash = crypto.Keccak256Hash([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+	// The DAI-style permit `permit(holder, spender, nonce, expiry, allowed, v, r, s)` has the same domain and `nonces`,
+	// but it approves the unlimited amount with the different signature.
+	daiPermitTypeHash = crypto.Keccak256Hash([]byte("Permit(address holder,address spender,uint256 nonce,uint256 expiry,bool allowed)"))
+)
+
+var erc20PermitTypes = []TypedDataField{
+	{Name: "owner", Type: "address"},
+	{Name: "spender", Type: "address"},
+	{Name: "value", Type: "uint256"},
+	{Name: "nonce", Type: "uint256"},
+	{Name: "deadline", Type: "uint256"},
+}
+
+var permit2DetailsTypes = []TypedDataField{
+	{Name: "token", Type: "address"},
+	{Name: "amount", Type: "uint160"},
+	{Name: "expiration", Type: "uint48"},
+	{Name: "nonce", Type: "uint48"},
+}
+
+type PermitSignature struct {
+	// The typed data json that is signed.
+	TypedData string
+	Signature string
+	V         int
+	R         string
+	S         string
+	Nonce     string
+	Deadline  string
+}
+
+func (s *PermitSignature) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(s)
+}
+
+// NewErc20PermitTypedData
+// @param name the token name of the EIP-712 domain
+// @param version the version of the EIP-712 domain, it's "1" for the most tokens.
+// @return the typed data json of the EIP-2612 `Permit`
+func NewErc20PermitTypedData(name, version string, chainId int64, tokenAddress, owner, spender, value, nonce, deadline string) (*base.OptionalString, error) {
+	if !common.IsHexAddress(tokenAddress) || !common.IsHexAddress(owner) || !common.IsHexAddress(spender) {
+		return nil, base.ErrInvalidAddress
+	}
+	typedData := &TypedData{
+		Types: map[string][]TypedDataField{
+			"EIP712Domain": typedDataDomainFields[:4],
+			"Permit":       erc20PermitTypes,
+		},
+		PrimaryType: "Permit",
+		version:     TypedDataV4,
+		Domain:      erc20PermitDomain(name, version, chainId, tokenAddress),
+		Message: map[string]interface{}{
+			"owner":    owner,
+			"spender":  spender,
+			"value":    value,
+			"nonce":    nonce,
+			"deadline": deadline,
+		},
+	}
+	return typedDataJsonString(typedData)
+}
+
+// EncodeErc20Permit
+// @param signature the signature of the permit typed data
+// @return the call data of `permit(owner, spender, value, deadline, v, r, s)`
+func EncodeErc20Permit(owner, spender, value, deadline, signature string) ([]byte, error) {
+	if !common.IsHexAddress(owner) || !common.IsHexAddress(spender) {
+		return nil, base.ErrInvalidAddress
+	}
+	valueInt, ok := big.NewInt(0).SetString(value, 10)
+	if !ok {
+		return nil, base.ErrInvalidAmount
+	}
+	deadlineInt, ok := big.NewInt(0).SetString(deadline, 10)
+	if !ok {
+		return nil, errors.New("invalid deadline")
+	}
+	v, r, s, err := splitSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeContractData(Erc20PermitAbi, "permit",
+		common.HexToAddress(owner), common.HexToAddress(spender), valueInt, deadlineInt, v, r, s)
+}
+
+// SupportsPermit
+// @return true if the token has the EIP-2612 `nonces` and `DOMAIN_SEPARATOR`, and it's not the DAI-style permit.
+func (t *Erc20Token) SupportsPermit() (supported bool, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	chain, err := GetConnection(t.chain.RpcUrl)
+	if err != nil {
+		return
+	}
+	_, err = t.permitSeparator(chain)
+	switch err {
+	case nil:
+		return true, nil
+	case ErrPermitUnsupported, ErrPermitDaiUnsupported:
+		return false, nil
+	}
+	return false, err
+}
+
+// permitSeparator
+// @return the `DOMAIN_SEPARATOR` of the token,
+// `ErrPermitUnsupported` or `ErrPermitDaiUnsupported` if the token doesn't support the EIP-2612 permit.
+func (t *Erc20Token) permitSeparator(chain *EthChain) (separator [32]byte, err error) {
+	ok, err := callPermitView(chain, &separator, t.ContractAddress, "DOMAIN_SEPARATOR")
+	if err != nil {
+		return
+	}
+	if !ok {
+		return separator, ErrPermitUnsupported
+	}
+	nonce := big.NewInt(0)
+	ok, err = callPermitView(chain, &nonce, t.ContractAddress, "nonces", common.Address{})
+	if err != nil {
+		return
+	}
+	if !ok {
+		return separator, ErrPermitUnsupported
+	}
+	// the most EIP-2612 tokens don't expose the type hash, but the DAI-style tokens do.
+	var typeHash [32]byte
+	ok, err = callPermitView(chain, &typeHash, t.ContractAddress, "PERMIT_TYPEHASH")
+	if err != nil {
+		return
+	}
+	if ok {
+		switch common.Hash(typeHash) {
+		case erc20PermitTypeHash:
+		case daiPermitTypeHash:
+			return separator, ErrPermitDaiUnsupported
+		default:
+			return separator, ErrPermitUnsupported
+		}
+	}
+	return separator, nil
+}
+
+// callPermitView
+// Call the view method of the `Erc20PermitAbi`.
+// @return false if the call is reverted or the token has no such method, the rpc errors are returned.
+func callPermitView(chain *EthChain, out interface{}, token, method string, params ...interface{}) (bool, error) {
+	input, err := erc20PermitParsed.Pack(method, params...)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), chain.timeout)
+	defer cancel()
+	to := common.HexToAddress(token)
+	output, err := chain.RemoteRpcClient.CallContract(ctx, ethereum.CallMsg{To: &to, Data: input}, nil)
+	if err != nil {
+		if isExecutionReverted(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err = erc20PermitParsed.UnpackIntoInterface(out, method, output); err != nil {
+		// the output doesn't match the method, e.g. the empty output of the fallback function
+		return false, nil
+	}
+	return true, nil
+}
+
+func isExecutionReverted(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	return rpcErr.ErrorCode() == 3 || strings.Contains(rpcErr.Error(), "revert")
+}
+
+// PermitNonce
+// @return the EIP-2612 nonce of the owner
+func (t *Erc20Token) PermitNonce(owner string) (nonce *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	chain, err := GetConnection(t.chain.RpcUrl)
+	if err != nil {
+		return
+	}
+	res := big.NewInt(0)
+	err = chain.CallContractConstant(&res, t.ContractAddress, Erc20PermitAbi, "nonces", nil, common.HexToAddress(owner))
+	if err != nil {
+		return
+	}
+	return base.NewOptionalString(res.String()), nil
+}
+
+// BuildPermitTypedData
+// The domain name and version are checked with the `DOMAIN_SEPARATOR` of the token.
+// @param deadline the unix timestamp in seconds
+// @return the typed data json of the EIP-2612 `Permit`, it can be signed with `SignTypedData`
+func (t *Erc20Token) BuildPermitTypedData(owner, spender, value, deadline string) (typedData *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	typedData, _, err = t.permitTypedData(owner, spender, value, deadline)
+	return
+}
+
+// SignPermit
+// Sign the EIP-2612 permit, the spender can call the `permit` with the signature instead of the approve transaction.
+// @param deadline the unix timestamp in seconds
+func (t *Erc20Token) SignPermit(account *Account, spender, value, deadline string) (signature *PermitSignature, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	typedData, nonce, err := t.permitTypedData(account.Address(), spender, value, deadline)
+	if err != nil {
+		return nil, err
+	}
+	signature, err = signPermitTypedData(account, typedData.Value)
+	if err != nil {
+		return nil, err
+	}
+	signature.Nonce = nonce
+	signature.Deadline = deadline
+	return signature, nil
+}
+
+// permitTypedData
+// @return the typed data json and the nonce of the owner
+func (t *Erc20Token) permitTypedData(owner, spender, value, deadline string) (*base.OptionalString, string, error) {
+	domain, err := t.permitDomain()
+	if err != nil {
+		return nil, "", err
+	}
+	nonce, err := t.PermitNonce(owner)
+	if err != nil {
+		return nil, "", err
+	}
+	chainId, err := typedDataInteger(domain["chainId"])
+	if err != nil {
+		return nil, "", err
+	}
+	typedData, err := NewErc20PermitTypedData(domain["name"].(string), domain["version"].(string), chainId.Int64(),
+		t.ContractAddress, owner, spender, value, nonce.Value, deadline)
+	if err != nil {
+		return nil, "", err
+	}
+	return typedData, nonce.Value, nil
+}
+
+// permitDomain
+// @return the EIP-712 domain that matches the `DOMAIN_SEPARATOR` of the token
+func (t *Erc20Token) permitDomain() (map[string]interface{}, error) {
+	chain, err := GetConnection(t.chain.RpcUrl)
+	if err != nil {
+		return nil, err
+	}
+	separator, err := t.permitSeparator(chain)
+	if err != nil {
+		return nil, err
+	}
+	name, err := chain.TokenName(t.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+	// the most tokens use the version "1", some tokens like USDC has the `version()`
+	versions := []string{"1", "2"}
+	var version string
+	ok, err := callPermitView(chain, &version, t.ContractAddress, "version")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		versions = append([]string{version}, versions...)
+	}
+	typedData := &TypedData{Types: map[string][]TypedDataField{"EIP712Domain": typedDataDomainFields[:4]}, version: TypedDataV4}
+	for _, version := range versions {
+		domain := erc20PermitDomain(name, version, chain.chainId.Int64(), t.ContractAddress)
+		hash, err := typedData.HashStruct("EIP712Domain", domain)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(hash, separator[:]) {
+			return domain, nil
+		}
+	}
+	return nil, ErrPermitUnknownDomain
+}
+
+func erc20PermitDomain(name, version string, chainId int64, tokenAddress string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":              name,
+		"version":           version,
+		"chainId":           big.NewInt(chainId).String(),
+		"verifyingContract": tokenAddress,
+	}
+}
+
+type Permit2Details struct {
+	Token string
+	// The uint160 amount that the spender can transfer
+	Amount string
+	// The uint48 unix timestamp in seconds that the allowance expires
+	Expiration string
+	// The uint48 nonce of the owner, token and spender
+	Nonce string
+}
+
+func (d *Permit2Details) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(d)
+}
+
+type Permit2DetailsArray struct {
+	inter.AnyArray[*Permit2Details]
+}
+
+// NewPermit2SingleTypedData
+// @param sigDeadline the unix timestamp in seconds that the signature expires
+// @return the typed data json of the Permit2 `PermitSingle`
+func NewPermit2SingleTypedData(chainId int64, details *Permit2Details, spender, sigDeadline string) (*base.OptionalString, error) {
+	if !common.IsHexAddress(spender) {
+		return nil, base.ErrInvalidAddress
+	}
+	detailsValue, err := permit2DetailsValue(details)
+	if err != nil {
+		return nil, err
+	}
+	typedData := &TypedData{
+		Types: map[string][]TypedDataField{
+			"EIP712Domain": permit2DomainTypes(),
+			"PermitSingle": {
+				{Name: "details", Type: "PermitDetails"},
+				{Name: "spender", Type: "address"},
+				{Name: "sigDeadline", Type: "uint256"},
+			},
+			"PermitDetails": permit2DetailsTypes,
+		},
+		PrimaryType: "PermitSingle",
+		version:     TypedDataV4,
+		Domain:      permit2Domain(chainId),
+		Message: map[string]interface{}{
+			"details":     detailsValue,
+			"spender":     spender,
+			"sigDeadline": sigDeadline,
+		},
+	}
+	return typedDataJsonString(typedData)
+}
+
+// NewPermit2BatchTypedData
+// @param sigDeadline the unix timestamp in seconds that the signature expires
+// @return the typed data json of the Permit2 `PermitBatch`
+func NewPermit2BatchTypedData(chainId int64, details *Permit2DetailsArray, spender, sigDeadline string) (*base.OptionalString, error) {
+	if !common.IsHexAddress(spender) {
+		return nil, base.ErrInvalidAddress
+	}
+	if details.Count() == 0 {
+		return nil, errors.New("the permit details is empty")
+	}
+	detailsValues := make([]interface{}, 0, details.Count())
+	for _, item := range details.AnyArray {
+		value, err := permit2DetailsValue(item)
+		if err != nil {
+			return nil, err
+		}
+		detailsValues = append(detailsValues, value)
+	}
+	typedData := &TypedData{
+		Types: map[string][]TypedDataField{
+			"EIP712Domain": permit2DomainTypes(),
+			"PermitBatch": {
+				{Name: "details", Type: "PermitDetails[]"},
+				{Name: "spender", Type: "address"},
+				{Name: "sigDeadline", Type: "uint256"},
+			},
+			"PermitDetails": permit2DetailsTypes,
+		},
+		PrimaryType: "PermitBatch",
+		version:     TypedDataV4,
+		Domain:      permit2Domain(chainId),
+		Message: map[string]interface{}{
+			"details":     detailsValues,
+			"spender":     spender,
+			"sigDeadline": sigDeadline,
+		},
+	}
+	return typedDataJsonString(typedData)
+}
+
+// Permit2Allowance
+// @return the Permit2 allowance of the spender, the nonce should be used in the next permit.
+func (c *Chain) Permit2Allowance(owner, token, spender string) (details *Permit2Details, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	var res struct {
+		Amount     *big.Int
+		Expiration *big.Int
+		Nonce      *big.Int
+	}
+	err = chain.CallContractConstant(&res, Permit2Address, Permit2AllowanceAbi, "allowance", nil,
+		common.HexToAddress(owner), common.HexToAddress(token), common.HexToAddress(spender))
+	if err != nil {
+		return
+	}
+	return &Permit2Details{
+		Token:      token,
+		Amount:     res.Amount.String(),
+		Expiration: res.Expiration.String(),
+		Nonce:      res.Nonce.String(),
+	}, nil
+}
+
+// SignPermit2Single
+// Sign the Permit2 `PermitSingle` with the current nonce, the token should be approved to the `Permit2Address` once.
+// @param expiration the unix timestamp in seconds that the allowance expires
+// @param sigDeadline the unix timestamp in seconds that the signature expires
+func (c *Chain) SignPermit2Single(account *Account, token, spender, amount, expiration, sigDeadline string) (signature *PermitSignature, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	details, err := c.Permit2Allowance(account.Address(), token, spender)
+	if err != nil {
+		return nil, err
+	}
+	details.Amount = amount
+	details.Expiration = expiration
+	chainId, err := c.connectedChainId()
+	if err != nil {
+		return nil, err
+	}
+	typedData, err := NewPermit2SingleTypedData(chainId, details, spender, sigDeadline)
+	if err != nil {
+		return nil, err
+	}
+	signature, err = signPermitTypedData(account, typedData.Value)
+	if err != nil {
+		return nil, err
+	}
+	signature.Nonce = details.Nonce
+	signature.Deadline = sigDeadline
+	return signature, nil
+}
+
+// SignPermit2Batch
+// Sign the Permit2 `PermitBatch`, the nonce of each details is filled with the current nonce.
+// @param details the token, amount and expiration of each token, it will not be modified.
+// @param sigDeadline the unix timestamp in seconds that the signature expires
+func (c *Chain) SignPermit2Batch(account *Account, details *Permit2DetailsArray, spender, sigDeadline string) (signature *PermitSignature, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	filled := &Permit2DetailsArray{make([]*Permit2Details, 0, details.Count())}
+	for _, item := range details.AnyArray {
+		allowance, err := c.Permit2Allowance(account.Address(), item.Token, spender)
+		if err != nil {
+			return nil, err
+		}
+		copied := *item
+		copied.Nonce = allowance.Nonce
+		filled.Append(&copied)
+	}
+	chainId, err := c.connectedChainId()
+	if err != nil {
+		return nil, err
+	}
+	typedData, err := NewPermit2BatchTypedData(chainId, filled, spender, sigDeadline)
+	if err != nil {
+		return nil, err
+	}
+	signature, err = signPermitTypedData(account, typedData.Value)
+	if err != nil {
+		return nil, err
+	}
+	signature.Deadline = sigDeadline
+	return signature, nil
+}
+
+func (c *Chain) connectedChainId() (int64, error) {
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return 0, err
+	}
+	return chain.chainId.Int64(), nil
+}
+
+func permit2Domain(chainId int64) map[string]interface{} {
+	return map[string]interface{}{
+		"name":              "Permit2",
+		"chainId":           big.NewInt(chainId).String(),
+		"verifyingContract": Permit2Address,
+	}
+}
+
+// permit2DomainTypes
+// @return the domain fields of the Permit2, it has no version.
+func permit2DomainTypes() []TypedDataField {
+	return []TypedDataField{
+		{Name: "name", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	}
+}
+
+func permit2DetailsValue(details *Permit2Details) (map[string]interface{}, error) {
+	if !common.IsHexAddress(details.Token) {
+		return nil, base.ErrInvalidAddress
+	}
+	return map[string]interface{}{
+		"token":      details.Token,
+		"amount":     details.Amount,
+		"expiration": details.Expiration,
+		"nonce":      details.Nonce,
+	}, nil
+}
+
+func typedDataJsonString(typedData *TypedData) (*base.OptionalString, error) {
+	// check the values before the json is signed
+	if _, err := typedData.Hash(); err != nil {
+		return nil, err
+	}
+	return base.JsonString(typedData)
+}
+
+func signPermitTypedData(account *Account, typedData string) (*PermitSignature, error) {
+	signature, err := account.SignTypedData(typedData, TypedDataV4, 0)
+	if err != nil {
+		return nil, err
+	}
+	v, r, s, err := splitSignature(signature.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &PermitSignature{
+		TypedData: typedData,
+		Signature: signature.Value,
+		V:         int(v),
+		R:         HexType.HexEncodeToString(r[:]),
+		S:         HexType.HexEncodeToString(s[:]),
+	}, nil
+}
+
+// splitSignature
+// @return the v is 27 or 28
+func splitSignature(signature string) (v uint8, r, s [32]byte, err error) {
+	sig, err := HexType.HexDecodeString(signature)
+	if err != nil {
+		return
+	}
+	if len(sig) != crypto.SignatureLength {
+		err = errors.New("signature must be 65 bytes long")
+		return
+	}
+	copy(r[:], sig[:32])
+	copy(s[:], sig[32:64])
+	v = sig[crypto.RecoveryIDOffset]
+	if v < 27 {
+		v += 27
+	}
+	return
+}
+
+func mustParseAbi(abiString string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(abiString))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
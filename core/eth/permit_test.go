@@ -0,0 +1,181 @@
+package eth
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base/inter"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+const stubPermitTokenAddress = "0x2222222222222222222222222222222222222222"
+
+func TestPermit_DomainSeparator(t *testing.T) {
+	// the DOMAIN_SEPARATOR of the USDC on the ethereum mainnet
+	typedData := &TypedData{Types: map[string][]TypedDataField{"EIP712Domain": typedDataDomainFields[:4]}, version: TypedDataV4}
+	separator, err := typedData.HashStruct("EIP712Domain", erc20PermitDomain("USD Coin", "2", 1, "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"))
+	require.NoError(t, err)
+	require.Equal(t, "0x06c37168a7db5138defc7866392bb87a741f9b3d104deb5094588ce041cae335", HexType.HexEncodeToString(separator))
+}
+
+func TestErc20Token_SignPermit(t *testing.T) {
+	typedData := &TypedData{Types: map[string][]TypedDataField{"EIP712Domain": typedDataDomainFields[:4]}, version: TypedDataV4}
+	separator, err := typedData.HashStruct("EIP712Domain", erc20PermitDomain("Stub Token", "2", 1, stubPermitTokenAddress))
+	require.NoError(t, err)
+
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		require.Equal(t, "eth_call", method)
+		input := stubCallInput(t, params)
+		switch {
+		case strings.HasPrefix(input, stubSelector("DOMAIN_SEPARATOR()")):
+			return HexType.HexEncodeToString(separator)
+		case strings.HasPrefix(input, stubSelector("nonces(address)")):
+			return HexType.HexEncodeToString(common.LeftPadBytes([]byte{3}, 32))
+		case strings.HasPrefix(input, stubSelector("name()")):
+			data, err := AbiCoderEncode([]string{"string"}, "Stub Token")
+			require.NoError(t, err)
+			return HexType.HexEncodeToString(data)
+		}
+		// the token has no `version()`
+		return errStubReverted{}
+	})
+	defer server.Close()
+
+	token := NewErc20Token(NewChainWithRpc(server.URL), stubPermitTokenAddress)
+	supported, err := token.SupportsPermit()
+	require.NoError(t, err)
+	require.True(t, supported)
+
+	account := cowAccount(t)
+	spender := "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"
+	signature, err := token.SignPermit(account, spender, "1000000", "1700000000")
+	require.NoError(t, err)
+	require.Equal(t, "3", signature.Nonce)
+	require.Contains(t, []int{27, 28}, signature.V)
+	require.True(t, VerifyTypedDataSignature(signature.TypedData, TypedDataV4, signature.Signature, account.Address()))
+
+	// the same typed data is built offline with the known domain
+	expected, err := NewErc20PermitTypedData("Stub Token", "2", 1, stubPermitTokenAddress, account.Address(), spender, "1000000", "3", "1700000000")
+	require.NoError(t, err)
+	require.Equal(t, expected.Value, signature.TypedData)
+
+	data, err := EncodeErc20Permit(account.Address(), spender, "1000000", "1700000000", signature.Signature)
+	require.NoError(t, err)
+	require.Equal(t, "0xd505accf", HexType.HexEncodeToString(data[:4]))
+	require.Equal(t, signature.R, HexType.HexEncodeToString(data[4+32*5:4+32*6]))
+}
+
+func TestErc20Token_SupportsPermit_Unsupported(t *testing.T) {
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		return errStubReverted{}
+	})
+	defer server.Close()
+
+	token := NewErc20Token(NewChainWithRpc(server.URL), stubPermitTokenAddress)
+	supported, err := token.SupportsPermit()
+	require.NoError(t, err)
+	require.False(t, supported)
+	_, err = token.BuildPermitTypedData(cowAccount(t).Address(), stubPermitTokenAddress, "1", "1")
+	require.Equal(t, ErrPermitUnsupported, err)
+}
+
+func TestErc20Token_SupportsPermit_Dai(t *testing.T) {
+	typedData := &TypedData{Types: map[string][]TypedDataField{"EIP712Domain": typedDataDomainFields[:4]}, version: TypedDataV4}
+	separator, err := typedData.HashStruct("EIP712Domain", erc20PermitDomain("Dai Stablecoin", "1", 1, stubPermitTokenAddress))
+	require.NoError(t, err)
+
+	typeHash := daiPermitTypeHash
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		input := stubCallInput(t, params)
+		switch {
+		case strings.HasPrefix(input, stubSelector("DOMAIN_SEPARATOR()")):
+			return HexType.HexEncodeToString(separator)
+		case strings.HasPrefix(input, stubSelector("nonces(address)")):
+			return HexType.HexEncodeToString(common.LeftPadBytes([]byte{3}, 32))
+		case strings.HasPrefix(input, stubSelector("PERMIT_TYPEHASH()")):
+			return typeHash.Hex()
+		case strings.HasPrefix(input, stubSelector("name()")):
+			data, err := AbiCoderEncode([]string{"string"}, "Dai Stablecoin")
+			require.NoError(t, err)
+			return HexType.HexEncodeToString(data)
+		}
+		return errStubReverted{}
+	})
+	defer server.Close()
+
+	// the DAI-style permit has the same domain and nonces, but it's rejected
+	token := NewErc20Token(NewChainWithRpc(server.URL), stubPermitTokenAddress)
+	supported, err := token.SupportsPermit()
+	require.NoError(t, err)
+	require.False(t, supported)
+	_, err = token.SignPermit(cowAccount(t), stubPermitTokenAddress, "1", "1")
+	require.Equal(t, ErrPermitDaiUnsupported, err)
+
+	// the EIP-2612 token that exposes the type hash
+	typeHash = erc20PermitTypeHash
+	supported, err = token.SupportsPermit()
+	require.NoError(t, err)
+	require.True(t, supported)
+}
+
+func TestErc20Token_SupportsPermit_RpcError(t *testing.T) {
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		return stubRpcError{Code: -32005, Message: "limit exceeded"}
+	})
+	defer server.Close()
+
+	token := NewErc20Token(NewChainWithRpc(server.URL), stubPermitTokenAddress)
+	_, err := token.SupportsPermit()
+	require.ErrorContains(t, err, "limit exceeded")
+	_, err = token.BuildPermitTypedData(cowAccount(t).Address(), stubPermitTokenAddress, "1", "1")
+	require.ErrorContains(t, err, "limit exceeded")
+}
+
+func TestChain_SignPermit2(t *testing.T) {
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		input := stubCallInput(t, params)
+		require.True(t, strings.HasPrefix(input, stubSelector("allowance(address,address,address)")))
+		data, err := AbiCoderEncode([]string{"uint160", "uint48", "uint48"}, big.NewInt(0), big.NewInt(0), big.NewInt(7))
+		require.NoError(t, err)
+		return HexType.HexEncodeToString(data)
+	})
+	defer server.Close()
+
+	chain := NewChainWithRpc(server.URL)
+	account := cowAccount(t)
+	spender := "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"
+	signature, err := chain.SignPermit2Single(account, stubPermitTokenAddress, spender, "1000000", "1700000000", "1690000000")
+	require.NoError(t, err)
+	require.Equal(t, "7", signature.Nonce)
+	require.True(t, VerifyTypedDataSignature(signature.TypedData, TypedDataV4, signature.Signature, account.Address()))
+	typedData, err := NewTypedData(signature.TypedData, TypedDataV4)
+	require.NoError(t, err)
+	require.Equal(t, "PermitSingle(PermitDetails details,address spender,uint256 sigDeadline)PermitDetails(address token,uint160 amount,uint48 expiration,uint48 nonce)", typedData.EncodeType("PermitSingle"))
+	require.Equal(t, "EIP712Domain(string name,uint256 chainId,address verifyingContract)", typedData.EncodeType("EIP712Domain"))
+
+	details := &Permit2DetailsArray{inter.AnyArray[*Permit2Details]{
+		{Token: stubPermitTokenAddress, Amount: "1", Expiration: "1700000000"},
+		{Token: "0x3333333333333333333333333333333333333333", Amount: "2", Expiration: "1700000000"},
+	}}
+	signature, err = chain.SignPermit2Batch(account, details, spender, "1690000000")
+	require.NoError(t, err)
+	require.True(t, VerifyTypedDataSignature(signature.TypedData, TypedDataV4, signature.Signature, account.Address()))
+	// the details of the caller are not modified, the nonces are filled in the signed copy
+	require.Equal(t, "", details.ValueAt(1).Nonce)
+	filled := &Permit2DetailsArray{inter.AnyArray[*Permit2Details]{
+		{Token: stubPermitTokenAddress, Amount: "1", Expiration: "1700000000", Nonce: "7"},
+		{Token: "0x3333333333333333333333333333333333333333", Amount: "2", Expiration: "1700000000", Nonce: "7"},
+	}}
+	expected, err := NewPermit2BatchTypedData(1, filled, spender, "1690000000")
+	require.NoError(t, err)
+	require.Equal(t, expected.Value, signature.TypedData)
+
+	// the amount overflows the uint160
+	details.ValueAt(0).Amount = new(big.Int).Lsh(big.NewInt(1), 160).String()
+	_, err = NewPermit2BatchTypedData(1, details, spender, "1690000000")
+	require.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// errStubReverted is returned by the stub handler to respond the reverted call.
+type errStubReverted struct{}
+
+func (errStubReverted) Error() string { return "execution reverted" }
+
+// stubRpcError is returned by the stub handler to respond the json rpc error with the code and data.
+type stubRpcError struct {
+	Code    int
+	Message string
+	Data    string
+}
+
+// newStubRpcServer
+// The server answers `eth_chainId` with 1, the other methods are answered by the handler.
+// The batch request is supported.
+func newStubRpcServer(t *testing.T, handler func(method string, params []json.RawMessage) any) *httptest.Server {
+	type request struct {
+		Id     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	respond := func(req request) map[string]any {
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.Id}
+		if req.Method == "eth_chainId" {
+			resp["result"] = "0x1"
+			return resp
+		}
+		switch result := handler(req.Method, req.Params).(type) {
+		case errStubReverted:
+			resp["error"] = map[string]any{"code": 3, "message": "execution reverted"}
+		case stubRpcError:
+			rpcErr := map[string]any{"code": result.Code, "message": result.Message}
+			if result.Data != "" {
+				rpcErr["data"] = result.Data
+			}
+			resp["error"] = rpcErr
+		default:
+			resp["result"] = result
+		}
+		return resp
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var data []byte
+		if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+			var reqs []request
+			require.NoError(t, json.Unmarshal(body, &reqs))
+			resps := make([]map[string]any, len(reqs))
+			for i, req := range reqs {
+				resps[i] = respond(req)
+			}
+			data, err = json.Marshal(resps)
+		} else {
+			var req request
+			require.NoError(t, json.Unmarshal(body, &req))
+			data, err = json.Marshal(respond(req))
+		}
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+}
+
+// stubCallInput
+// @return the hex call data of the `eth_call`
+func stubCallInput(t *testing.T, params []json.RawMessage) string {
+	var msg struct {
+		Input string `json:"input"`
+		Data  string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(params[0], &msg))
+	return msg.Input + msg.Data
+}
+
+func stubSelector(signature string) string {
+	return HexType.HexEncodeToString(crypto.Keccak256([]byte(signature))[:4])
+}
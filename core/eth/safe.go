@@ -0,0 +1,583 @@
+package eth
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/base/inter"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The operations of the Safe transaction
+const (
+	SafeOperationCall         = 0
+	SafeOperationDelegateCall = 1
+)
+
+// The kinds of the Safe owner signature
+const (
+	// The EIP-712 signature of the safeTxHash
+	SafeSignatureEOA = "eoa"
+	// The `eth_sign` signature of the safeTxHash, the v is increased by 4
+	SafeSignatureEthSign = "eth_sign"
+	// The EIP-1271 signature of the contract owner
+	SafeSignatureContract = "contract"
+	// The owner has approved the hash by `approveHash` or is the sender of the `execTransaction`
+	SafeSignatureApprovedHash = "approved_hash"
+)
+
+const SafeLatestVersion = "1.4.1"
+
+const SafeAbi = `[
+{"inputs":[],"name":"getOwners","outputs":[{"type":"address[]"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"getThreshold","outputs":[{"type":"uint256"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"nonce","outputs":[{"type":"uint256"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"VERSION","outputs":[{"type":"string"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"hashToApprove","type":"bytes32"}],"name":"approveHash","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"name":"execTransaction","outputs":[{"type":"bool"}],"stateMutability":"payable","type":"function"}
+]`
+
+var (
+	ErrSafeVersion          = errors.New("unsupported safe version")
+	ErrSafeSignatureKind    = errors.New("unsupported safe signature kind")
+	ErrSafeDuplicateOwner   = errors.New("the owner has signed the safe transaction")
+	ErrSafeSignatureInvalid = errors.New("the signature is not signed by the owner")
+)
+
+var safeParsed = mustParseAbi(SafeAbi)
+
+// SafeTransaction is the transaction that is executed by the Safe multisig through the `execTransaction`.
+type SafeTransaction struct {
+	SafeAddress string
+	ChainId     string
+	// The version of the Safe contract, e.g. 1.3.0, it decides the EIP-712 domain and the SafeTx type.
+	Version string
+
+	To        string
+	Value     string
+	Data      string
+	Operation int
+
+	// The refund parameters, they're 0 if the executor pays the gas without refund.
+	SafeTxGas      string
+	BaseGas        string
+	GasPrice       string
+	GasToken       string
+	RefundReceiver string
+	Nonce          string
+}
+
+func NewSafeTransaction(safeAddress, chainId, version, to, value, data, nonce string) *SafeTransaction {
+	return &SafeTransaction{
+		SafeAddress:    safeAddress,
+		ChainId:        chainId,
+		Version:        version,
+		To:             to,
+		Value:          value,
+		Data:           data,
+		Operation:      SafeOperationCall,
+		SafeTxGas:      "0",
+		BaseGas:        "0",
+		GasPrice:       "0",
+		GasToken:       common.Address{}.Hex(),
+		RefundReceiver: common.Address{}.Hex(),
+		Nonce:          nonce,
+	}
+}
+
+func NewSafeTransactionWithJsonString(s string) (*SafeTransaction, error) {
+	var t SafeTransaction
+	err := base.FromJsonString(s, &t)
+	return &t, err
+}
+
+func (t *SafeTransaction) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(t)
+}
+
+// TypedData
+// @return the json of the EIP-712 SafeTx typed data, it can be signed by `eth_signTypedData_v4`.
+func (t *SafeTransaction) TypedData() (typedData *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	data, err := t.typedData()
+	if err != nil {
+		return nil, err
+	}
+	return typedDataJsonString(data)
+}
+
+// Hash
+// @return the hex string of the safeTxHash
+func (t *SafeTransaction) Hash() (hash *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	safeTxHash, err := t.hash()
+	if err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(HexType.HexEncodeToString(safeTxHash)), nil
+}
+
+// SignWithAccount
+// Sign the SafeTx typed data, the account must be the owner of the Safe.
+func (t *SafeTransaction) SignWithAccount(account *Account) (signature *SafeSignature, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	safeTxHash, err := t.hash()
+	if err != nil {
+		return nil, err
+	}
+	signed, err := account.SignHash(safeTxHash)
+	if err != nil {
+		return nil, err
+	}
+	return &SafeSignature{Owner: account.Address(), Kind: SafeSignatureEOA, Signature: HexType.HexEncodeToString(signed)}, nil
+}
+
+// EthSignWithAccount
+// Sign the safeTxHash by the `eth_sign`, it's used by the wallets that can't sign the typed data.
+func (t *SafeTransaction) EthSignWithAccount(account *Account) (signature *SafeSignature, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	safeTxHash, err := t.hash()
+	if err != nil {
+		return nil, err
+	}
+	signed, err := account.Sign(safeTxHash, "")
+	if err != nil {
+		return nil, err
+	}
+	signed[crypto.RecoveryIDOffset] += 4
+	return &SafeSignature{Owner: account.Address(), Kind: SafeSignatureEthSign, Signature: HexType.HexEncodeToString(signed)}, nil
+}
+
+// RecoverSigner
+// @return the owner of the EOA or eth_sign signature, the contract and approved hash signatures can't be recovered.
+func (t *SafeTransaction) RecoverSigner(signature *SafeSignature) (owner *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	safeTxHash, err := t.hash()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := HexType.HexDecodeString(signature.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, errors.New("signature must be 65 bytes long")
+	}
+	sig = common.CopyBytes(sig)
+	switch signature.Kind {
+	case SafeSignatureEOA:
+		if sig[crypto.RecoveryIDOffset] >= 27 {
+			sig[crypto.RecoveryIDOffset] -= 27
+		}
+	case SafeSignatureEthSign:
+		if sig[crypto.RecoveryIDOffset] < 31 {
+			return nil, errors.New("the v of the eth_sign signature must be 31 or 32")
+		}
+		sig[crypto.RecoveryIDOffset] -= 31
+		safeTxHash = SignHashForMsg(string(safeTxHash))
+	default:
+		return nil, ErrSafeSignatureKind
+	}
+	pubKey, err := crypto.SigToPub(safeTxHash, sig)
+	if err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(crypto.PubkeyToAddress(*pubKey).Hex()), nil
+}
+
+// AddSignature
+// Verify the signature and add it to the signatures, the signatures of the same owner are rejected.
+func (t *SafeTransaction) AddSignature(signatures *SafeSignatureArray, signature *SafeSignature) (err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if !common.IsHexAddress(signature.Owner) {
+		return base.ErrInvalidAddress
+	}
+	owner := common.HexToAddress(signature.Owner)
+	for _, sig := range signatures.AnyArray {
+		if common.HexToAddress(sig.Owner) == owner {
+			return ErrSafeDuplicateOwner
+		}
+	}
+	if signature.Kind == SafeSignatureEOA || signature.Kind == SafeSignatureEthSign {
+		signer, err := t.RecoverSigner(signature)
+		if err != nil {
+			return err
+		}
+		if common.HexToAddress(signer.Value) != owner {
+			return ErrSafeSignatureInvalid
+		}
+	}
+	signatures.Append(signature)
+	return nil
+}
+
+// EncodeExecTransaction
+// @param signatures the signatures of the owners, at least the threshold
+// @return the call data of the `execTransaction`, it's sent to the Safe
+func (t *SafeTransaction) EncodeExecTransaction(signatures *SafeSignatureArray) (data *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	values, err := t.values()
+	if err != nil {
+		return nil, err
+	}
+	packed, err := PackSafeSignatures(signatures)
+	if err != nil {
+		return nil, err
+	}
+	signaturesData, err := HexType.HexDecodeString(packed.Value)
+	if err != nil {
+		return nil, err
+	}
+	input, err := safeParsed.Pack("execTransaction", values.to, values.value, values.data, values.operation,
+		values.safeTxGas, values.baseGas, values.gasPrice, values.gasToken, values.refundReceiver, signaturesData)
+	if err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(HexType.HexEncodeToString(input)), nil
+}
+
+// SafeSignature is the signature of a Safe owner.
+type SafeSignature struct {
+	Owner string
+	// `SafeSignatureEOA`, `SafeSignatureEthSign`, `SafeSignatureContract` or `SafeSignatureApprovedHash`
+	Kind string
+	// The hex string of the 65 bytes signature for the EOA and eth_sign, the EIP-1271 signature data for the contract,
+	// and it's empty for the approved hash.
+	Signature string
+}
+
+// NewSafeContractSignature
+// @param signature the hex string of the signature that the contract owner verifies by the EIP-1271 `isValidSignature`
+func NewSafeContractSignature(owner, signature string) *SafeSignature {
+	return &SafeSignature{Owner: owner, Kind: SafeSignatureContract, Signature: signature}
+}
+
+// NewSafeApprovedHashSignature
+// The owner has called `approveHash` with the safeTxHash, or the owner is the sender of the `execTransaction`.
+func NewSafeApprovedHashSignature(owner string) *SafeSignature {
+	return &SafeSignature{Owner: owner, Kind: SafeSignatureApprovedHash}
+}
+
+type SafeSignatureArray struct {
+	inter.AnyArray[*SafeSignature]
+}
+
+func NewSafeSignatureArray() *SafeSignatureArray {
+	return &SafeSignatureArray{}
+}
+
+// PackSafeSignatures
+// Sort the signatures by the owner ascending and pack them as the `execTransaction` requires,
+// the 65 bytes static part of each signature, followed by the dynamic parts of the contract signatures.
+// @return the hex string of the packed signatures
+func PackSafeSignatures(signatures *SafeSignatureArray) (packed *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	sorted := make([]*SafeSignature, len(signatures.AnyArray))
+	copy(sorted, signatures.AnyArray)
+	for _, sig := range sorted {
+		if !common.IsHexAddress(sig.Owner) {
+			return nil, base.ErrInvalidAddress
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return bytes.Compare(common.HexToAddress(sorted[i].Owner).Bytes(), common.HexToAddress(sorted[j].Owner).Bytes()) < 0
+	})
+
+	static := make([]byte, 0, len(sorted)*crypto.SignatureLength)
+	dynamic := make([]byte, 0)
+	for i, sig := range sorted {
+		if i > 0 && common.HexToAddress(sorted[i-1].Owner) == common.HexToAddress(sig.Owner) {
+			return nil, ErrSafeDuplicateOwner
+		}
+		ownerWord := common.LeftPadBytes(common.HexToAddress(sig.Owner).Bytes(), 32)
+		switch sig.Kind {
+		case SafeSignatureEOA, SafeSignatureEthSign:
+			data, err := HexType.HexDecodeString(sig.Signature)
+			if err != nil {
+				return nil, err
+			}
+			if len(data) != crypto.SignatureLength {
+				return nil, errors.New("signature must be 65 bytes long")
+			}
+			data = common.CopyBytes(data)
+			v := data[crypto.RecoveryIDOffset]
+			if sig.Kind == SafeSignatureEOA && v < 27 {
+				data[crypto.RecoveryIDOffset] += 27
+			} else if sig.Kind == SafeSignatureEthSign && v < 31 {
+				return nil, errors.New("the v of the eth_sign signature must be 31 or 32")
+			}
+			static = append(static, data...)
+		case SafeSignatureContract:
+			data, err := HexType.HexDecodeString(sig.Signature)
+			if err != nil {
+				return nil, err
+			}
+			offset := big.NewInt(int64(len(sorted)*crypto.SignatureLength + len(dynamic)))
+			static = append(static, ownerWord...)
+			static = append(static, common.LeftPadBytes(offset.Bytes(), 32)...)
+			static = append(static, 0)
+			dynamic = append(dynamic, common.LeftPadBytes(big.NewInt(int64(len(data))).Bytes(), 32)...)
+			dynamic = append(dynamic, data...)
+		case SafeSignatureApprovedHash:
+			static = append(static, ownerWord...)
+			static = append(static, make([]byte, 32)...)
+			static = append(static, 1)
+		default:
+			return nil, ErrSafeSignatureKind
+		}
+	}
+	return base.NewOptionalString(HexType.HexEncodeToString(append(static, dynamic...))), nil
+}
+
+// SafeInfo is the state of the Safe.
+type SafeInfo struct {
+	Address   string
+	Version   string
+	Owners    *base.StringArray
+	Threshold int
+	Nonce     string
+}
+
+func (i *SafeInfo) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(i)
+}
+
+// SafeInfo
+// @return the owners, threshold, nonce and version of the Safe
+func (c *Chain) SafeInfo(safeAddress string) (info *SafeInfo, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if !common.IsHexAddress(safeAddress) {
+		return nil, base.ErrInvalidAddress
+	}
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		owners    []common.Address
+		threshold *big.Int
+		nonce     *big.Int
+		version   string
+	)
+	if err = chain.CallContractConstant(&owners, safeAddress, SafeAbi, "getOwners", nil); err != nil {
+		return nil, err
+	}
+	if err = chain.CallContractConstant(&threshold, safeAddress, SafeAbi, "getThreshold", nil); err != nil {
+		return nil, err
+	}
+	if err = chain.CallContractConstant(&nonce, safeAddress, SafeAbi, "nonce", nil); err != nil {
+		return nil, err
+	}
+	if err = chain.CallContractConstant(&version, safeAddress, SafeAbi, "VERSION", nil); err != nil {
+		return nil, err
+	}
+	info = &SafeInfo{
+		Address:   common.HexToAddress(safeAddress).Hex(),
+		Version:   version,
+		Owners:    base.NewStringArray(),
+		Threshold: int(threshold.Int64()),
+		Nonce:     nonce.String(),
+	}
+	for _, owner := range owners {
+		info.Owners.Append(owner.Hex())
+	}
+	return info, nil
+}
+
+// BuildSafeTransaction
+// Build the Safe transaction with the current nonce, version of the Safe and the chain id of the rpc.
+func (c *Chain) BuildSafeTransaction(safeAddress, to, value, data string) (txn *SafeTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	info, err := c.SafeInfo(safeAddress)
+	if err != nil {
+		return nil, err
+	}
+	chainId, err := c.connectedChainId()
+	if err != nil {
+		return nil, err
+	}
+	return NewSafeTransaction(info.Address, strconv.FormatInt(chainId, 10), info.Version, to, value, data, info.Nonce), nil
+}
+
+// BuildSafeExecTransaction
+// Build the `execTransaction` that is sent by the sender, the sender pays the gas.
+// @param sender the account that sends the transaction, it's not necessary to be the owner.
+func (c *Chain) BuildSafeExecTransaction(sender string, safeTransaction *SafeTransaction, signatures *SafeSignatureArray) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	data, err := safeTransaction.EncodeExecTransaction(signatures)
+	if err != nil {
+		return nil, err
+	}
+	input, err := HexType.HexDecodeString(data.Value)
+	if err != nil {
+		return nil, err
+	}
+	return c.buildContractCallTransaction(sender, safeTransaction.SafeAddress, input)
+}
+
+type safeTransactionValues struct {
+	to, gasToken, refundReceiver        common.Address
+	value, safeTxGas, baseGas, gasPrice *big.Int
+	nonce                               *big.Int
+	data                                []byte
+	operation                           uint8
+}
+
+func (t *SafeTransaction) values() (*safeTransactionValues, error) {
+	for _, address := range []string{t.SafeAddress, t.To, t.GasToken, t.RefundReceiver} {
+		if !common.IsHexAddress(address) {
+			return nil, base.ErrInvalidAddress
+		}
+	}
+	if t.Operation != SafeOperationCall && t.Operation != SafeOperationDelegateCall {
+		return nil, errors.New("invalid safe operation")
+	}
+	values := &safeTransactionValues{
+		to:             common.HexToAddress(t.To),
+		gasToken:       common.HexToAddress(t.GasToken),
+		refundReceiver: common.HexToAddress(t.RefundReceiver),
+		operation:      uint8(t.Operation),
+	}
+	var ok bool
+	for _, item := range []struct {
+		field *(*big.Int)
+		value string
+		name  string
+	}{
+		{&values.value, t.Value, "value"},
+		{&values.safeTxGas, t.SafeTxGas, "safeTxGas"},
+		{&values.baseGas, t.BaseGas, "baseGas"},
+		{&values.gasPrice, t.GasPrice, "gasPrice"},
+		{&values.nonce, t.Nonce, "nonce"},
+	} {
+		if *item.field, ok = new(big.Int).SetString(item.value, 10); !ok || (*item.field).Sign() < 0 {
+			return nil, errors.New("invalid safe transaction " + item.name)
+		}
+	}
+	if t.Data != "" {
+		data, err := HexType.HexDecodeString(t.Data)
+		if err != nil {
+			return nil, errors.New("invalid safe transaction data")
+		}
+		values.data = data
+	}
+	return values, nil
+}
+
+func (t *SafeTransaction) hash() ([]byte, error) {
+	typedData, err := t.typedData()
+	if err != nil {
+		return nil, err
+	}
+	return typedData.Hash()
+}
+
+// typedData
+// The domain has no chainId before v1.3.0, and the `baseGas` was named `dataGas` before v1.0.0.
+func (t *SafeTransaction) typedData() (*TypedData, error) {
+	version, err := parseSafeVersion(t.Version)
+	if err != nil {
+		return nil, err
+	}
+	values, err := t.values()
+	if err != nil {
+		return nil, err
+	}
+	domainFields := []TypedDataField{{Name: "verifyingContract", Type: "address"}}
+	domain := map[string]interface{}{"verifyingContract": t.SafeAddress}
+	if compareSafeVersion(version, []int{1, 3, 0}) >= 0 {
+		chainId, ok := new(big.Int).SetString(t.ChainId, 10)
+		if !ok {
+			return nil, errors.New("invalid chain id")
+		}
+		domainFields = []TypedDataField{{Name: "chainId", Type: "uint256"}, {Name: "verifyingContract", Type: "address"}}
+		domain["chainId"] = chainId.String()
+	}
+	baseGasName := "baseGas"
+	if compareSafeVersion(version, []int{1, 0, 0}) < 0 {
+		baseGasName = "dataGas"
+	}
+	return &TypedData{
+		Types: map[string][]TypedDataField{
+			"EIP712Domain": domainFields,
+			"SafeTx": {
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+				{Name: "operation", Type: "uint8"},
+				{Name: "safeTxGas", Type: "uint256"},
+				{Name: baseGasName, Type: "uint256"},
+				{Name: "gasPrice", Type: "uint256"},
+				{Name: "gasToken", Type: "address"},
+				{Name: "refundReceiver", Type: "address"},
+				{Name: "nonce", Type: "uint256"},
+			},
+		},
+		PrimaryType: "SafeTx",
+		version:     TypedDataV4,
+		Domain:      domain,
+		Message: map[string]interface{}{
+			"to":             values.to.Hex(),
+			"value":          values.value.String(),
+			"data":           HexType.HexEncodeToString(values.data),
+			"operation":      strconv.Itoa(int(values.operation)),
+			"safeTxGas":      values.safeTxGas.String(),
+			baseGasName:      values.baseGas.String(),
+			"gasPrice":       values.gasPrice.String(),
+			"gasToken":       values.gasToken.Hex(),
+			"refundReceiver": values.refundReceiver.Hex(),
+			"nonce":          values.nonce.String(),
+		},
+	}, nil
+}
+
+// parseSafeVersion
+// @param version e.g. 1.3.0 or 1.3.0+L2
+func parseSafeVersion(version string) ([]int, error) {
+	if idx := strings.IndexAny(version, "+-"); idx >= 0 {
+		version = version[:idx]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return nil, ErrSafeVersion
+	}
+	res := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, ErrSafeVersion
+		}
+		res[i] = n
+	}
+	return res, nil
+}
+
+func compareSafeVersion(a, b []int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
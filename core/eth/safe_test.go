@@ -0,0 +1,188 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const stubSafeAddress = "0x4444444444444444444444444444444444444444"
+
+func TestSafeTransaction_Hash(t *testing.T) {
+	receiver := "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"
+	// the type hashes in the Safe contracts
+	safeTxTypeHash := common.HexToHash("0xbb8310d486368db6bd6f849402fdd73ad53d316b5a4b2644ad6efe0f941286d8")
+	domainTypeHashes := map[string]common.Hash{
+		"1.3.0": common.HexToHash("0x47e79534a245952e8b16893a336b85a3d9ea9fa8c573f3d803afb92a79469218"),
+		"1.1.1": common.HexToHash("0x035aff83d86937d35b32e04f0ddc6ff469290eef2f1b692d8a815c89404d4749"),
+	}
+	for version, domainTypeHash := range domainTypeHashes {
+		txn := NewSafeTransaction(stubSafeAddress, "5", version, receiver, "1000", "0x1234", "7")
+		hash, err := txn.Hash()
+		require.NoError(t, err)
+
+		word := func(n int64) []byte { return common.BigToHash(big.NewInt(n)).Bytes() }
+		var domainSeparator []byte
+		if version == "1.3.0" {
+			domainSeparator = crypto.Keccak256(domainTypeHash.Bytes(), word(5), common.LeftPadBytes(common.FromHex(stubSafeAddress), 32))
+		} else {
+			domainSeparator = crypto.Keccak256(domainTypeHash.Bytes(), common.LeftPadBytes(common.FromHex(stubSafeAddress), 32))
+		}
+		structHash := crypto.Keccak256(safeTxTypeHash.Bytes(), common.LeftPadBytes(common.FromHex(receiver), 32), word(1000),
+			crypto.Keccak256([]byte{0x12, 0x34}), word(0), word(0), word(0), word(0), word(0), word(0), word(7))
+		expected := crypto.Keccak256([]byte{0x19, 0x01}, domainSeparator, structHash)
+		require.Equal(t, HexType.HexEncodeToString(expected), hash.Value, version)
+
+		// the wallet shows the same typed data
+		typedData, err := txn.TypedData()
+		require.NoError(t, err)
+		typedHash, err := HashTypedData(typedData.Value, TypedDataV4)
+		require.NoError(t, err)
+		require.Equal(t, hash.Value, typedHash.Value)
+	}
+
+	txn := NewSafeTransaction(stubSafeAddress, "5", "0.1.0", receiver, "0", "", "0")
+	typedData, err := txn.typedData()
+	require.NoError(t, err)
+	require.Contains(t, typedData.EncodeType("SafeTx"), "uint256 dataGas")
+	txn.Version = "v1"
+	_, err = txn.Hash()
+	require.Equal(t, ErrSafeVersion, err)
+}
+
+func TestSafeTransaction_Signatures(t *testing.T) {
+	owner1 := cowAccount(t)
+	owner2, err := NewAccountWithMnemonic("test test test test test test test test test test test junk")
+	require.NoError(t, err)
+	txn := NewSafeTransaction(stubSafeAddress, "1", SafeLatestVersion, "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB", "1", "", "0")
+
+	signatures := NewSafeSignatureArray()
+	sig1, err := txn.SignWithAccount(owner1)
+	require.NoError(t, err)
+	require.NoError(t, txn.AddSignature(signatures, sig1))
+	require.Equal(t, ErrSafeDuplicateOwner, txn.AddSignature(signatures, sig1))
+
+	sig2, err := txn.EthSignWithAccount(owner2)
+	require.NoError(t, err)
+	signer, err := txn.RecoverSigner(sig2)
+	require.NoError(t, err)
+	require.Equal(t, owner2.Address(), signer.Value)
+	// the signature is not signed by the claimed owner
+	forged := &SafeSignature{Owner: "0x0000000000000000000000000000000000000001", Kind: sig2.Kind, Signature: sig2.Signature}
+	require.Equal(t, ErrSafeSignatureInvalid, txn.AddSignature(signatures, forged))
+	require.NoError(t, txn.AddSignature(signatures, sig2))
+
+	contractOwner := "0x0000000000000000000000000000000000000002"
+	require.NoError(t, txn.AddSignature(signatures, NewSafeContractSignature(contractOwner, "0xabcdef")))
+	approvedOwner := "0x0000000000000000000000000000000000000003"
+	require.NoError(t, txn.AddSignature(signatures, NewSafeApprovedHashSignature(approvedOwner)))
+
+	packed, err := PackSafeSignatures(signatures)
+	require.NoError(t, err)
+	data := common.FromHex(packed.Value)
+	require.Len(t, data, 65*4+32+3)
+
+	safeTxHash, err := txn.hash()
+	require.NoError(t, err)
+	owners := make([]common.Address, 0)
+	for i := 0; i < 4; i++ {
+		part := data[i*65 : (i+1)*65]
+		r, s, v := part[:32], part[32:64], part[64]
+		switch {
+		case v == 0:
+			// the contract signature points to the dynamic part
+			owners = append(owners, common.BytesToAddress(r))
+			offset := new(big.Int).SetBytes(s).Int64()
+			require.Equal(t, int64(65*4), offset)
+			require.Equal(t, int64(3), new(big.Int).SetBytes(data[offset:offset+32]).Int64())
+			require.Equal(t, []byte{0xab, 0xcd, 0xef}, data[offset+32:offset+35])
+		case v == 1:
+			owners = append(owners, common.BytesToAddress(r))
+		case v > 30:
+			pubKey, err := crypto.SigToPub(SignHashForMsg(string(safeTxHash)), append(common.CopyBytes(part[:64]), v-31))
+			require.NoError(t, err)
+			owners = append(owners, crypto.PubkeyToAddress(*pubKey))
+		default:
+			pubKey, err := crypto.SigToPub(safeTxHash, append(common.CopyBytes(part[:64]), v-27))
+			require.NoError(t, err)
+			owners = append(owners, crypto.PubkeyToAddress(*pubKey))
+		}
+	}
+	// the owners are sorted ascending
+	for i := 1; i < len(owners); i++ {
+		require.Equal(t, -1, bytes.Compare(owners[i-1].Bytes(), owners[i].Bytes()))
+	}
+	require.ElementsMatch(t, []common.Address{
+		common.HexToAddress(owner1.Address()), common.HexToAddress(owner2.Address()),
+		common.HexToAddress(contractOwner), common.HexToAddress(approvedOwner),
+	}, owners)
+
+	signatures.Append(NewSafeApprovedHashSignature(approvedOwner))
+	_, err = PackSafeSignatures(signatures)
+	require.Equal(t, ErrSafeDuplicateOwner, err)
+}
+
+func TestChain_SafeInfo(t *testing.T) {
+	owner1 := cowAccount(t)
+	owner2 := "0x0000000000000000000000000000000000000002"
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		require.Equal(t, "eth_call", method)
+		input := stubCallInput(t, params)
+		var (
+			data []byte
+			err  error
+		)
+		switch {
+		case strings.HasPrefix(input, stubSelector("getOwners()")):
+			data, err = AbiCoderEncode([]string{"address[]"}, []common.Address{common.HexToAddress(owner1.Address()), common.HexToAddress(owner2)})
+		case strings.HasPrefix(input, stubSelector("getThreshold()")):
+			data, err = AbiCoderEncode([]string{"uint256"}, big.NewInt(2))
+		case strings.HasPrefix(input, stubSelector("nonce()")):
+			data, err = AbiCoderEncode([]string{"uint256"}, big.NewInt(12))
+		case strings.HasPrefix(input, stubSelector("VERSION()")):
+			data, err = AbiCoderEncode([]string{"string"}, "1.3.0+L2")
+		default:
+			return errStubReverted{}
+		}
+		require.NoError(t, err)
+		return HexType.HexEncodeToString(data)
+	})
+	defer server.Close()
+
+	chain := NewChainWithRpc(server.URL)
+	info, err := chain.SafeInfo(stubSafeAddress)
+	require.NoError(t, err)
+	require.Equal(t, []string{owner1.Address(), owner2}, []string(info.Owners.AnyArray))
+	require.Equal(t, 2, info.Threshold)
+	require.Equal(t, "12", info.Nonce)
+	require.Equal(t, "1.3.0+L2", info.Version)
+
+	txn, err := chain.BuildSafeTransaction(stubSafeAddress, stubTokenUsdt, "0", "0xa9059cbb")
+	require.NoError(t, err)
+	require.Equal(t, "1", txn.ChainId)
+	require.Equal(t, "12", txn.Nonce)
+
+	signatures := NewSafeSignatureArray()
+	signature, err := txn.SignWithAccount(owner1)
+	require.NoError(t, err)
+	require.NoError(t, txn.AddSignature(signatures, signature))
+	require.NoError(t, txn.AddSignature(signatures, NewSafeApprovedHashSignature(owner2)))
+	data, err := txn.EncodeExecTransaction(signatures)
+	require.NoError(t, err)
+	input := common.FromHex(data.Value)
+	require.Equal(t, stubSelector("execTransaction(address,uint256,bytes,uint8,uint256,uint256,uint256,address,address,bytes)"), HexType.HexEncodeToString(input[:4]))
+	values, err := safeParsed.Methods["execTransaction"].Inputs.Unpack(input[4:])
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress(stubTokenUsdt), values[0])
+	require.Equal(t, []byte{0xa9, 0x05, 0x9c, 0xbb}, values[2])
+	packed, err := PackSafeSignatures(signatures)
+	require.NoError(t, err)
+	require.Equal(t, common.FromHex(packed.Value), values[9])
+}
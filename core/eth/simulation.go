@@ -0,0 +1,501 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/base/inter"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// The standard of the native token balance change
+	BalanceChangeNative = "native"
+	// The standard of the erc-20 token balance change, the erc-721 and erc-1155 are `NftStandardErc721` and `NftStandardErc1155`
+	BalanceChangeErc20 = "erc-20"
+)
+
+var (
+	revertErrorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	revertPanicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+
+	transferEventTopic       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	transferSingleEventTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	transferBatchEventTopic  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+
+	// The `eth_simulateV1` reports the native transfers as the erc-20 logs of this address
+	simulateNativeTransferAddress = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+	panicReasons = map[uint64]string{
+		0x00: "generic compiler panic",
+		0x01: "assertion failed",
+		0x11: "arithmetic underflow or overflow",
+		0x12: "division or modulo by zero",
+		0x21: "invalid enum value",
+		0x22: "invalid storage byte array encoding",
+		0x31: "pop on empty array",
+		0x32: "array index out of bounds",
+		0x41: "out of memory",
+		0x51: "call to zero-initialized function",
+	}
+)
+
+// StateOverride overrides the state of the account during the simulation, the empty fields are not overridden.
+type StateOverride struct {
+	Address string
+	// The decimal wei amount
+	Balance string
+	Nonce   string
+	// The hex string of the runtime code
+	Code string
+	// The json object of the storage slots, it replaces the whole storage, e.g. {"0x00..00": "0x00..01"}
+	State string
+	// The json object of the storage slots, it patches the storage
+	StateDiff string
+}
+
+type StateOverrideArray struct {
+	inter.AnyArray[*StateOverride]
+}
+
+func NewStateOverrideArray() *StateOverrideArray {
+	return &StateOverrideArray{}
+}
+
+// RevertError is the decoded revert data of the failed call.
+type RevertError struct {
+	// `Error`, `Panic` or the name of the custom error, empty if the error is unknown
+	Name      string             `json:"name"`
+	Signature string             `json:"signature"`
+	Arguments []*DecodedArgument `json:"arguments"`
+	// The human-readable reason
+	Reason string `json:"reason"`
+	// The hex string of the revert data
+	Data string `json:"data"`
+}
+
+func (e *RevertError) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(e)
+}
+
+// BalanceChange is the balance change of the owner, computed from the transfer logs of the simulation.
+type BalanceChange struct {
+	// `BalanceChangeNative`, `BalanceChangeErc20`, `NftStandardErc721` or `NftStandardErc1155`
+	Standard string `json:"standard"`
+	// The contract address, empty for the native token
+	Token string `json:"token"`
+	// The nft id, empty for the native and erc-20 token
+	TokenId string `json:"tokenId"`
+	Owner   string `json:"owner"`
+	// The signed decimal amount, negative if the balance decreases
+	Amount string `json:"amount"`
+}
+
+type BalanceChangeArray struct {
+	inter.AnyArray[*BalanceChange]
+}
+
+type SimulationResult struct {
+	Success bool `json:"success"`
+	// The hex string of the return data, it's the revert data if the call failed
+	ReturnData string `json:"returnData"`
+	// Empty if the node doesn't report it
+	GasUsed string `json:"gasUsed"`
+	// The revert error is nil if the call succeeded
+	Revert *RevertError `json:"revert,omitempty"`
+
+	// Whether the logs and balance changes are available, they need the `eth_simulateV1` or the `debug_traceCall`
+	LogsAvailable bool `json:"logsAvailable"`
+	// The json array of the logs, they can be decoded by `TransactionDecoder.DecodeLogsJson`
+	Logs           string              `json:"logs"`
+	BalanceChanges *BalanceChangeArray `json:"balanceChanges"`
+}
+
+func (r *SimulationResult) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(r)
+}
+
+// SimulateTransaction
+// Run the transaction through the `eth_simulateV1`, or the `eth_call` and `debug_traceCall` if it's unsupported.
+// The failed transaction is not an error, the `SimulationResult.Revert` reports why it would fail.
+// @param from the sender of the transaction
+// @param overrides the state overrides, nil if there is no override
+// @param errorAbi the json abi that contains the custom errors of the contract, it can be empty
+func (c *Chain) SimulateTransaction(from string, transaction *Transaction, overrides *StateOverrideArray, errorAbi string) (result *SimulationResult, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	if !common.IsHexAddress(from) {
+		return nil, base.ErrInvalidAddress
+	}
+	arg, err := transaction.callArg(from)
+	if err != nil {
+		return nil, err
+	}
+	stateOverrides, err := overrides.toRpc()
+	if err != nil {
+		return nil, err
+	}
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	result, logs, err := simulateV1(chain, arg, stateOverrides)
+	if err != nil {
+		result, logs, err = simulateCall(chain, arg, stateOverrides)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !result.Success && result.Revert == nil {
+		result.Revert = DecodeRevertData(result.ReturnData, errorAbi)
+	}
+	if result.LogsAvailable {
+		data, err := json.Marshal(logs)
+		if err != nil {
+			return nil, err
+		}
+		result.Logs = string(data)
+		result.BalanceChanges = balanceChangesFromLogs(logs)
+	}
+	return result, nil
+}
+
+// DecodeRevertData
+// Decode the `Error(string)`, the `Panic(uint256)` or the custom error in the abi.
+// @param data the hex string of the revert data
+// @param errorAbi the json abi that contains the custom errors, it can be empty
+func DecodeRevertData(data string, errorAbi string) *RevertError {
+	revert := &RevertError{Data: data, Arguments: []*DecodedArgument{}}
+	raw, err := decodeHexData(data)
+	if err != nil || len(raw) < 4 {
+		revert.Reason = "execution reverted"
+		return revert
+	}
+	selector, payload := raw[:4], raw[4:]
+	switch {
+	case string(selector) == string(revertErrorSelector):
+		if reason, err := abi.UnpackRevert(raw); err == nil {
+			revert.Name, revert.Signature, revert.Reason = "Error", "Error(string)", reason
+			revert.Arguments = append(revert.Arguments, &DecodedArgument{Name: "message", Type: "string", Value: reason})
+			return revert
+		}
+	case string(selector) == string(revertPanicSelector) && len(payload) == 32:
+		code := new(big.Int).SetBytes(payload)
+		revert.Name, revert.Signature = "Panic", "Panic(uint256)"
+		revert.Arguments = append(revert.Arguments, &DecodedArgument{Name: "code", Type: "uint256", Value: code.String()})
+		reason, ok := panicReasons[code.Uint64()]
+		if !ok || !code.IsUint64() {
+			reason = "unknown panic"
+		}
+		revert.Reason = fmt.Sprintf("panic 0x%x: %v", code, reason)
+		return revert
+	}
+
+	if errorAbi != "" {
+		if parsed, err := abi.JSON(strings.NewReader(errorAbi)); err == nil {
+			for _, abiError := range parsed.Errors {
+				if string(abiError.ID[:4]) != string(selector) {
+					continue
+				}
+				values, err := abiError.Inputs.Unpack(payload)
+				if err != nil {
+					continue
+				}
+				revert.Name, revert.Signature = abiError.Name, abiError.Sig
+				revert.Arguments = decodeArguments(abiError.Inputs, values)
+				args := make([]string, len(revert.Arguments))
+				for i, arg := range revert.Arguments {
+					value, _ := json.Marshal(arg.Value)
+					args[i] = fmt.Sprintf("%v=%s", arg.Name, value)
+				}
+				revert.Reason = fmt.Sprintf("%v(%v)", abiError.Name, strings.Join(args, ", "))
+				return revert
+			}
+		}
+	}
+	revert.Reason = fmt.Sprintf("unknown custom error %v", hexutil.Encode(selector))
+	return revert
+}
+
+func (a *StateOverrideArray) toRpc() (map[common.Address]any, error) {
+	if a == nil || a.Count() == 0 {
+		return nil, nil
+	}
+	type rpcOverride struct {
+		Balance   *hexutil.Big                `json:"balance,omitempty"`
+		Nonce     *hexutil.Uint64             `json:"nonce,omitempty"`
+		Code      hexutil.Bytes               `json:"code,omitempty"`
+		State     map[common.Hash]common.Hash `json:"state,omitempty"`
+		StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+	}
+	overrides := make(map[common.Address]any)
+	for _, override := range a.AnyArray {
+		if !common.IsHexAddress(override.Address) {
+			return nil, base.ErrInvalidAddress
+		}
+		res := &rpcOverride{}
+		if override.Balance != "" {
+			balance, ok := new(big.Int).SetString(override.Balance, 10)
+			if !ok {
+				return nil, errors.New("invalid override balance")
+			}
+			res.Balance = (*hexutil.Big)(balance)
+		}
+		if override.Nonce != "" {
+			nonce, ok := new(big.Int).SetString(override.Nonce, 10)
+			if !ok || !nonce.IsUint64() {
+				return nil, errors.New("invalid override nonce")
+			}
+			res.Nonce = (*hexutil.Uint64)(new(uint64))
+			*res.Nonce = hexutil.Uint64(nonce.Uint64())
+		}
+		if override.Code != "" {
+			code, err := decodeHexData(override.Code)
+			if err != nil {
+				return nil, errors.New("invalid override code")
+			}
+			res.Code = code
+		}
+		if override.State != "" {
+			if err := json.Unmarshal([]byte(override.State), &res.State); err != nil {
+				return nil, errors.New("invalid override state")
+			}
+		}
+		if override.StateDiff != "" {
+			if err := json.Unmarshal([]byte(override.StateDiff), &res.StateDiff); err != nil {
+				return nil, errors.New("invalid override state diff")
+			}
+		}
+		overrides[common.HexToAddress(override.Address)] = res
+	}
+	return overrides, nil
+}
+
+// simulateV1
+// @return the error if the node doesn't support the `eth_simulateV1`.
+func simulateV1(chain *EthChain, arg map[string]any, overrides map[common.Address]any) (*SimulationResult, []*simulationLog, error) {
+	blockStateCall := map[string]any{"calls": []any{arg}}
+	if overrides != nil {
+		blockStateCall["stateOverrides"] = overrides
+	}
+	opts := map[string]any{
+		"blockStateCalls": []any{blockStateCall},
+		"traceTransfers":  true,
+	}
+	var blocks []struct {
+		Calls []struct {
+			ReturnData hexutil.Bytes    `json:"returnData"`
+			Logs       []*simulationLog `json:"logs"`
+			GasUsed    hexutil.Uint64   `json:"gasUsed"`
+			Status     hexutil.Uint64   `json:"status"`
+			Error      *rpcCallFailure  `json:"error"`
+		} `json:"calls"`
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), chain.timeout)
+	defer cancel()
+	if err := chain.RemoteRpcClient.Client().CallContext(ctx, &blocks, "eth_simulateV1", opts, "latest"); err != nil {
+		return nil, nil, err
+	}
+	if len(blocks) != 1 || len(blocks[0].Calls) != 1 {
+		return nil, nil, errors.New("invalid simulation result")
+	}
+	call := blocks[0].Calls[0]
+	result := &SimulationResult{
+		Success:       uint64(call.Status) == types.ReceiptStatusSuccessful,
+		ReturnData:    call.ReturnData.String(),
+		GasUsed:       strconv.FormatUint(uint64(call.GasUsed), 10),
+		LogsAvailable: true,
+	}
+	if !result.Success && call.Error != nil && call.Error.Data != "" {
+		result.ReturnData = call.Error.Data
+	}
+	logs := call.Logs
+	if logs == nil {
+		logs = []*simulationLog{}
+	}
+	return result, logs, nil
+}
+
+// simulationLog is the log of the simulation, only the fields that are used to decode the event are kept.
+type simulationLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+type rpcCallFailure struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
+
+// callFrame is the frame of the `callTracer`.
+type callFrame struct {
+	From    common.Address   `json:"from"`
+	To      common.Address   `json:"to"`
+	Value   *hexutil.Big     `json:"value"`
+	GasUsed hexutil.Uint64   `json:"gasUsed"`
+	Error   string           `json:"error"`
+	Logs    []*simulationLog `json:"logs"`
+	Calls   []*callFrame     `json:"calls"`
+}
+
+// simulateCall
+// Run the transaction by the `eth_call`, the logs are traced by the `debug_traceCall` if the node supports it.
+func simulateCall(chain *EthChain, arg map[string]any, overrides map[common.Address]any) (*SimulationResult, []*simulationLog, error) {
+	params := []any{arg, "latest"}
+	if overrides != nil {
+		params = append(params, overrides)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), chain.timeout)
+	defer cancel()
+
+	result := &SimulationResult{Success: true}
+	var returnData hexutil.Bytes
+	err := chain.RemoteRpcClient.Client().CallContext(ctx, &returnData, "eth_call", params...)
+	if err != nil {
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) {
+			return nil, nil, err
+		}
+		// the node rejects the execution, e.g. reverted or insufficient funds
+		result.Success = false
+		var dataErr rpc.DataError
+		if errors.As(err, &dataErr) {
+			if data, ok := dataErr.ErrorData().(string); ok {
+				result.ReturnData = data
+			}
+		}
+		if result.ReturnData == "" {
+			result.Revert = &RevertError{Reason: rpcErr.Error(), Arguments: []*DecodedArgument{}}
+		}
+	} else {
+		result.ReturnData = returnData.String()
+	}
+
+	tracerConfig := map[string]any{
+		"tracer":       "callTracer",
+		"tracerConfig": map[string]any{"withLog": true},
+	}
+	if overrides != nil {
+		tracerConfig["stateOverrides"] = overrides
+	}
+	var frame callFrame
+	if err := chain.RemoteRpcClient.Client().CallContext(ctx, &frame, "debug_traceCall", arg, "latest", tracerConfig); err != nil {
+		// the tracing is optional
+		return result, nil, nil
+	}
+	result.LogsAvailable = true
+	result.GasUsed = strconv.FormatUint(uint64(frame.GasUsed), 10)
+	logs := make([]*simulationLog, 0)
+	if frame.Error == "" {
+		frame.collectLogs(&logs)
+	}
+	return result, logs, nil
+}
+
+// collectLogs
+// Collect the logs and the native transfers of the succeeded frames in the execution order.
+func (f *callFrame) collectLogs(logs *[]*simulationLog) {
+	if f.Value != nil && f.Value.ToInt().Sign() > 0 {
+		*logs = append(*logs, nativeTransferLog(f.From, f.To, f.Value.ToInt()))
+	}
+	*logs = append(*logs, f.Logs...)
+	for _, call := range f.Calls {
+		if call.Error == "" {
+			call.collectLogs(logs)
+		}
+	}
+}
+
+// nativeTransferLog
+// @return the erc-20 like transfer log of the native token, as the `eth_simulateV1` reports it.
+func nativeTransferLog(from, to common.Address, value *big.Int) *simulationLog {
+	return &simulationLog{
+		Address: simulateNativeTransferAddress,
+		Topics:  []common.Hash{transferEventTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    common.BigToHash(value).Bytes(),
+	}
+}
+
+// balanceChangesFromLogs
+// @return the balance changes of the erc-20, erc-721, erc-1155 and native transfers, in the order they first appear.
+func balanceChangesFromLogs(logs []*simulationLog) *BalanceChangeArray {
+	changes := &BalanceChangeArray{}
+	amounts := make(map[string]*big.Int)
+	add := func(standard string, token common.Address, tokenId *big.Int, owner common.Address, amount *big.Int) {
+		if owner == (common.Address{}) || amount.Sign() == 0 {
+			return
+		}
+		change := &BalanceChange{Standard: standard, Owner: owner.String()}
+		if standard != BalanceChangeNative {
+			change.Token = token.String()
+		}
+		if tokenId != nil {
+			change.TokenId = tokenId.String()
+		}
+		key := strings.Join([]string{change.Standard, change.Token, change.TokenId, change.Owner}, ":")
+		if _, ok := amounts[key]; !ok {
+			amounts[key] = big.NewInt(0)
+			changes.Append(change)
+		}
+		amounts[key].Add(amounts[key], amount)
+	}
+	transfer := func(standard string, token common.Address, tokenId *big.Int, from, to common.Address, amount *big.Int) {
+		add(standard, token, tokenId, from, new(big.Int).Neg(amount))
+		add(standard, token, tokenId, to, amount)
+	}
+
+	for _, log := range logs {
+		if len(log.Topics) == 0 {
+			continue
+		}
+		switch {
+		case log.Topics[0] == transferEventTopic && len(log.Topics) == 3 && len(log.Data) == 32:
+			standard := BalanceChangeErc20
+			if log.Address == simulateNativeTransferAddress {
+				standard = BalanceChangeNative
+			}
+			transfer(standard, log.Address, nil, common.BytesToAddress(log.Topics[1].Bytes()), common.BytesToAddress(log.Topics[2].Bytes()), new(big.Int).SetBytes(log.Data))
+		case log.Topics[0] == transferEventTopic && len(log.Topics) == 4:
+			transfer(NftStandardErc721, log.Address, log.Topics[3].Big(), common.BytesToAddress(log.Topics[1].Bytes()), common.BytesToAddress(log.Topics[2].Bytes()), big.NewInt(1))
+		case log.Topics[0] == transferSingleEventTopic && len(log.Topics) == 4 && len(log.Data) == 64:
+			transfer(NftStandardErc1155, log.Address, new(big.Int).SetBytes(log.Data[:32]), common.BytesToAddress(log.Topics[2].Bytes()), common.BytesToAddress(log.Topics[3].Bytes()), new(big.Int).SetBytes(log.Data[32:]))
+		case log.Topics[0] == transferBatchEventTopic && len(log.Topics) == 4:
+			decoded, err := AbiCoderDecode([]string{"uint256[]", "uint256[]"}, log.Data)
+			if err != nil {
+				continue
+			}
+			ids, idsOk := decoded[0].([]*big.Int)
+			values, valuesOk := decoded[1].([]*big.Int)
+			if !idsOk || !valuesOk || len(ids) != len(values) {
+				continue
+			}
+			for i := range ids {
+				transfer(NftStandardErc1155, log.Address, ids[i], common.BytesToAddress(log.Topics[2].Bytes()), common.BytesToAddress(log.Topics[3].Bytes()), values[i])
+			}
+		}
+	}
+	// the balances that are changed back are dropped
+	result := &BalanceChangeArray{}
+	for _, change := range changes.AnyArray {
+		key := strings.Join([]string{change.Standard, change.Token, change.TokenId, change.Owner}, ":")
+		if amounts[key].Sign() != 0 {
+			change.Amount = amounts[key].String()
+			result.Append(change)
+		}
+	}
+	return result
+}
@@ -0,0 +1,175 @@
+package eth
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+const stubErrorAbi = `[{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}]`
+
+func stubRevertData(t *testing.T, signature string, types []string, values ...any) string {
+	data, err := AbiCoderEncode(types, values...)
+	require.NoError(t, err)
+	return stubSelector(signature) + HexType.HexEncodeToString(data)[2:]
+}
+
+func TestDecodeRevertData(t *testing.T) {
+	revert := DecodeRevertData(stubRevertData(t, "Error(string)", []string{"string"}, "ERC20: transfer amount exceeds balance"), "")
+	require.Equal(t, "Error", revert.Name)
+	require.Equal(t, "ERC20: transfer amount exceeds balance", revert.Reason)
+
+	revert = DecodeRevertData(stubRevertData(t, "Panic(uint256)", []string{"uint256"}, big.NewInt(0x11)), "")
+	require.Equal(t, "Panic", revert.Name)
+	require.Equal(t, "panic 0x11: arithmetic underflow or overflow", revert.Reason)
+
+	data := stubRevertData(t, "InsufficientBalance(uint256,uint256)", []string{"uint256", "uint256"}, big.NewInt(1), big.NewInt(2))
+	revert = DecodeRevertData(data, stubErrorAbi)
+	require.Equal(t, "InsufficientBalance", revert.Name)
+	require.Equal(t, "InsufficientBalance(uint256,uint256)", revert.Signature)
+	require.Equal(t, `InsufficientBalance(available="1", required="2")`, revert.Reason)
+	require.Equal(t, data, revert.Data)
+
+	revert = DecodeRevertData(data, "")
+	require.Equal(t, "", revert.Name)
+	require.Equal(t, "unknown custom error "+stubSelector("InsufficientBalance(uint256,uint256)"), revert.Reason)
+
+	revert = DecodeRevertData("0x", "")
+	require.Equal(t, "execution reverted", revert.Reason)
+}
+
+func stubTransferLog(token string, topics []common.Hash, data []byte) map[string]any {
+	return map[string]any{"address": token, "topics": topics, "data": HexType.HexEncodeToString(data), "transactionHash": common.Hash{}.String()}
+}
+
+func addressTopic(address string) common.Hash {
+	return common.BytesToHash(common.HexToAddress(address).Bytes())
+}
+
+func TestChain_SimulateTransaction_SimulateV1(t *testing.T) {
+	receiver := "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"
+	batchData, err := AbiCoderEncode([]string{"uint256[]", "uint256[]"}, []*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(3), big.NewInt(4)})
+	require.NoError(t, err)
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		require.Equal(t, "eth_simulateV1", method)
+		var opts struct {
+			BlockStateCalls []struct {
+				StateOverrides map[string]map[string]any `json:"stateOverrides"`
+			} `json:"blockStateCalls"`
+		}
+		require.NoError(t, json.Unmarshal(params[0], &opts))
+		require.Equal(t, "0xde0b6b3a7640000", opts.BlockStateCalls[0].StateOverrides[strings.ToLower(stubOwner)]["balance"])
+
+		logs := []map[string]any{
+			stubTransferLog(stubTokenUsdt, []common.Hash{transferEventTopic, addressTopic(stubOwner), addressTopic(receiver)}, common.BigToHash(big.NewInt(100)).Bytes()),
+			stubTransferLog(stubTokenUsdt, []common.Hash{transferEventTopic, addressTopic(receiver), addressTopic(stubOwner)}, common.BigToHash(big.NewInt(30)).Bytes()),
+			stubTransferLog(simulateNativeTransferAddress.String(), []common.Hash{transferEventTopic, addressTopic(stubOwner), addressTopic(receiver)}, common.BigToHash(big.NewInt(5)).Bytes()),
+			stubTransferLog(stubTokenMkr, []common.Hash{transferEventTopic, addressTopic(receiver), addressTopic(stubOwner), common.BigToHash(big.NewInt(9))}, nil),
+			stubTransferLog(stubTokenNone, []common.Hash{transferSingleEventTopic, addressTopic(stubOwner), {}, addressTopic(stubOwner)}, append(common.BigToHash(big.NewInt(7)).Bytes(), common.BigToHash(big.NewInt(2)).Bytes()...)),
+			stubTransferLog(stubTokenNone, []common.Hash{transferBatchEventTopic, addressTopic(stubOwner), addressTopic(stubOwner), addressTopic(receiver)}, batchData),
+		}
+		return []any{map[string]any{"calls": []any{map[string]any{
+			"returnData": "0x0000000000000000000000000000000000000000000000000000000000000001",
+			"logs":       logs,
+			"gasUsed":    "0xc350",
+			"status":     "0x1",
+		}}}}
+	})
+	defer server.Close()
+
+	overrides := NewStateOverrideArray()
+	overrides.Append(&StateOverride{Address: stubOwner, Balance: "1000000000000000000"})
+	txn := &Transaction{To: stubTokenUsdt, Value: "5", Data: "0x1234"}
+	result, err := NewChainWithRpc(server.URL).SimulateTransaction(stubOwner, txn, overrides, "")
+	require.NoError(t, err)
+	require.True(t, result.Success)
+	require.Nil(t, result.Revert)
+	require.Equal(t, "50000", result.GasUsed)
+	require.True(t, result.LogsAvailable)
+
+	events, err := NewTransactionDecoder().DecodeLogsJson(result.Logs)
+	require.NoError(t, err)
+	require.Equal(t, 6, events.Count())
+
+	changes := make([]string, 0)
+	for _, change := range result.BalanceChanges.AnyArray {
+		changes = append(changes, change.Standard+" "+change.Token+" "+change.TokenId+" "+change.Owner+" "+change.Amount)
+	}
+	require.Equal(t, []string{
+		"erc-20 " + stubTokenUsdt + "  " + stubOwner + " -70",
+		"erc-20 " + stubTokenUsdt + "  " + receiver + " 70",
+		"native   " + stubOwner + " -5",
+		"native   " + receiver + " 5",
+		"erc-721 " + stubTokenMkr + " 9 " + receiver + " -1",
+		"erc-721 " + stubTokenMkr + " 9 " + stubOwner + " 1",
+		"erc-1155 " + stubTokenNone + " 7 " + stubOwner + " 2",
+		"erc-1155 " + stubTokenNone + " 1 " + stubOwner + " -3",
+		"erc-1155 " + stubTokenNone + " 1 " + receiver + " 3",
+		"erc-1155 " + stubTokenNone + " 2 " + stubOwner + " -4",
+		"erc-1155 " + stubTokenNone + " 2 " + receiver + " 4",
+	}, changes)
+}
+
+func TestChain_SimulateTransaction_CallFallback(t *testing.T) {
+	revertData := stubRevertData(t, "InsufficientBalance(uint256,uint256)", []string{"uint256", "uint256"}, big.NewInt(1), big.NewInt(2))
+	reverted, traced := true, false
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		switch method {
+		case "eth_call":
+			if reverted {
+				return stubRpcError{Code: 3, Message: "execution reverted", Data: revertData}
+			}
+			return "0x01"
+		case "debug_traceCall":
+			if !traced {
+				break
+			}
+			var config map[string]any
+			require.NoError(t, json.Unmarshal(params[2], &config))
+			require.Equal(t, "callTracer", config["tracer"])
+			return map[string]any{
+				"from": stubOwner, "to": stubTokenUsdt, "value": "0x0", "gasUsed": "0x5208",
+				"calls": []any{
+					map[string]any{
+						"from": stubTokenUsdt, "to": stubTokenMkr, "value": "0xa", "gasUsed": "0x1",
+						"logs": []any{stubTransferLog(stubTokenMkr, []common.Hash{transferEventTopic, addressTopic(stubTokenUsdt), addressTopic(stubOwner)}, common.BigToHash(big.NewInt(8)).Bytes())},
+					},
+					map[string]any{
+						"from": stubTokenUsdt, "to": stubTokenNone, "value": "0x1", "gasUsed": "0x1", "error": "execution reverted",
+						"logs": []any{stubTransferLog(stubTokenNone, []common.Hash{transferEventTopic, addressTopic(stubTokenUsdt), addressTopic(stubOwner)}, common.BigToHash(big.NewInt(8)).Bytes())},
+					},
+				},
+			}
+		}
+		return stubRpcError{Code: -32601, Message: "the method " + method + " does not exist/is not available"}
+	})
+	defer server.Close()
+	chain := NewChainWithRpc(server.URL)
+	txn := &Transaction{To: stubTokenUsdt, Value: "0", Data: "0x1234"}
+
+	result, err := chain.SimulateTransaction(stubOwner, txn, nil, stubErrorAbi)
+	require.NoError(t, err)
+	require.False(t, result.Success)
+	require.Equal(t, revertData, result.ReturnData)
+	require.Equal(t, "InsufficientBalance", result.Revert.Name)
+	require.False(t, result.LogsAvailable)
+	require.Nil(t, result.BalanceChanges)
+
+	reverted, traced = false, true
+	result, err = chain.SimulateTransaction(stubOwner, txn, nil, stubErrorAbi)
+	require.NoError(t, err)
+	require.True(t, result.Success)
+	require.Equal(t, "0x01", result.ReturnData)
+	require.Equal(t, "21000", result.GasUsed)
+	require.True(t, result.LogsAvailable)
+	// the logs of the reverted frame are dropped
+	require.Equal(t, 4, result.BalanceChanges.Count())
+	require.Equal(t, BalanceChangeNative, result.BalanceChanges.ValueAt(0).Standard)
+	require.Equal(t, "-10", result.BalanceChanges.ValueAt(0).Amount)
+	require.Equal(t, "8", result.BalanceChanges.ValueAt(3).Amount)
+}
@@ -0,0 +1,181 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The SimpleAccountFactory of the eth-infinitism account-abstraction, they are deployed at the same address on all chains.
+const (
+	SimpleAccountFactoryV06Address = "0x9406Cc6185a346906296840746125a0E44976454"
+	SimpleAccountFactoryV07Address = "0x91E60e0613810449d098b0b5Ec8b51A0FE8c8985"
+)
+
+const (
+	SimpleAccountFactoryAbi = `[{"inputs":[{"internalType":"address","name":"owner","type":"address"},{"internalType":"uint256","name":"salt","type":"uint256"}],"name":"createAccount","outputs":[{"internalType":"contract SimpleAccount","name":"ret","type":"address"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"owner","type":"address"},{"internalType":"uint256","name":"salt","type":"uint256"}],"name":"getAddress","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"}]`
+	SimpleAccountAbi        = `[{"inputs":[{"internalType":"address","name":"dest","type":"address"},{"internalType":"uint256","name":"value","type":"uint256"},{"internalType":"bytes","name":"func","type":"bytes"}],"name":"execute","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+	EntryPointNonceAbi      = `[{"inputs":[{"internalType":"address","name":"sender","type":"address"},{"internalType":"uint192","name":"key","type":"uint192"}],"name":"getNonce","outputs":[{"internalType":"uint256","name":"nonce","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+)
+
+// SimpleAccount is the smart account that is owned by an ECDSA key, see https://github.com/eth-infinitism/account-abstraction
+type SimpleAccount struct {
+	EntryPointVersion string
+	Factory           string
+	Owner             string
+	// The decimal salt of the factory, different salts create different accounts of the same owner.
+	Salt string
+}
+
+// NewSimpleAccount
+// @param version the entry point version, it uses the canonical factory of the version and the salt 0.
+func NewSimpleAccount(version, owner string) (*SimpleAccount, error) {
+	var factory string
+	switch version {
+	case EntryPointVersion06:
+		factory = SimpleAccountFactoryV06Address
+	case EntryPointVersion07:
+		factory = SimpleAccountFactoryV07Address
+	default:
+		return nil, ErrEntryPointVersion
+	}
+	if !common.IsHexAddress(owner) {
+		return nil, base.ErrInvalidAddress
+	}
+	return &SimpleAccount{
+		EntryPointVersion: version,
+		Factory:           factory,
+		Owner:             owner,
+		Salt:              "0",
+	}, nil
+}
+
+// FactoryData
+// @return the `createAccount` call data of the factory that deploys the account
+func (a *SimpleAccount) FactoryData() ([]byte, error) {
+	if !common.IsHexAddress(a.Owner) {
+		return nil, base.ErrInvalidAddress
+	}
+	salt, ok := big.NewInt(0).SetString(a.Salt, 10)
+	if !ok {
+		return nil, errors.New("invalid salt")
+	}
+	return EncodeContractData(SimpleAccountFactoryAbi, "createAccount", common.HexToAddress(a.Owner), salt)
+}
+
+// EncodeExecute
+// @return the call data of the user operation that calls the target with the value
+func (a *SimpleAccount) EncodeExecute(to, value string, data []byte) ([]byte, error) {
+	if !common.IsHexAddress(to) {
+		return nil, base.ErrInvalidAddress
+	}
+	valueInt, ok := big.NewInt(0).SetString(value, 10)
+	if !ok {
+		return nil, base.ErrInvalidAmount
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return EncodeContractData(SimpleAccountAbi, "execute", common.HexToAddress(to), valueInt, data)
+}
+
+// SimpleAccountAddress
+// Compute the counterfactual address of the account by the factory, the account can receive assets before it's deployed.
+func (c *Chain) SimpleAccountAddress(account *SimpleAccount) (address *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	salt, ok := big.NewInt(0).SetString(account.Salt, 10)
+	if !ok {
+		return nil, errors.New("invalid salt")
+	}
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	var res common.Address
+	err = chain.CallContractConstant(&res, account.Factory, SimpleAccountFactoryAbi, "getAddress", nil, common.HexToAddress(account.Owner), salt)
+	if err != nil {
+		return
+	}
+	return base.NewOptionalString(res.String()), nil
+}
+
+// UserOperationNonce
+// @return the nonce of the sender with the key 0 in the entry point
+func (c *Chain) UserOperationNonce(entryPoint, sender string) (nonce *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	res := big.NewInt(0)
+	err = chain.CallContractConstant(&res, entryPoint, EntryPointNonceAbi, "getNonce", nil, common.HexToAddress(sender), big.NewInt(0))
+	if err != nil {
+		return
+	}
+	return base.NewOptionalString(res.String()), nil
+}
+
+// IsContractDeployed
+// @return whether the address has the contract code
+func (c *Chain) IsContractDeployed(address string) (deployed bool, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	chain, err := GetConnection(c.RpcUrl)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), chain.timeout)
+	defer cancel()
+	code, err := chain.RemoteRpcClient.CodeAt(ctx, common.HexToAddress(address), nil)
+	if err != nil {
+		return
+	}
+	return len(code) > 0, nil
+}
+
+// BuildSimpleAccountUserOperation
+// Build the unsigned user operation that calls the target with the value, the factory is set if the account is not deployed.
+// The gas price and gas limits should be set with `SetGasPrice` and `Bundler.EstimateUserOperationGas` later.
+func (c *Chain) BuildSimpleAccountUserOperation(account *SimpleAccount, to, value string, data []byte) (op *UserOperation, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	sender, err := c.SimpleAccountAddress(account)
+	if err != nil {
+		return nil, err
+	}
+	op, err = NewUserOperation(account.EntryPointVersion, sender.Value)
+	if err != nil {
+		return nil, err
+	}
+	callData, err := account.EncodeExecute(to, value, data)
+	if err != nil {
+		return nil, err
+	}
+	op.CallData = HexType.HexEncodeToString(callData)
+
+	deployed, err := c.IsContractDeployed(sender.Value)
+	if err != nil {
+		return nil, err
+	}
+	if !deployed {
+		factoryData, err := account.FactoryData()
+		if err != nil {
+			return nil, err
+		}
+		op.Factory = account.Factory
+		op.FactoryData = HexType.HexEncodeToString(factoryData)
+	}
+
+	nonce, err := c.UserOperationNonce(op.EntryPoint, sender.Value)
+	if err != nil {
+		return nil, err
+	}
+	op.Nonce = nonce.Value
+	return op, nil
+}
@@ -0,0 +1,617 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/base/inter"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// The kinds of the decoded transaction
+const (
+	TransactionKindTransfer         = "transfer"
+	TransactionKindContractCall     = "contractCall"
+	TransactionKindContractCreation = "contractCreation"
+)
+
+var ErrUnknownSelector = errors.New("unknown function selector")
+
+// The well-known functions of the tokens, multicall and routers.
+var builtinFunctionSignatures = []string{
+	// ERC-20, the `transferFrom` and `approve` are shared with ERC-721
+	"transfer(address to, uint256 amount)",
+	"transferFrom(address from, address to, uint256 value)",
+	"approve(address spender, uint256 value)",
+	"increaseAllowance(address spender, uint256 addedValue)",
+	"decreaseAllowance(address spender, uint256 subtractedValue)",
+	// ERC-2612 and DAI permit
+	"permit(address owner, address spender, uint256 value, uint256 deadline, uint8 v, bytes32 r, bytes32 s)",
+	"permit(address holder, address spender, uint256 nonce, uint256 expiry, bool allowed, uint8 v, bytes32 r, bytes32 s)",
+	// Permit2
+	"approve(address token, address spender, uint160 amount, uint48 expiration)",
+	"permit(address owner, ((address token, uint160 amount, uint48 expiration, uint48 nonce) details, address spender, uint256 sigDeadline) permitSingle, bytes signature)",
+	// ERC-721 and ERC-1155
+	"safeTransferFrom(address from, address to, uint256 tokenId)",
+	"safeTransferFrom(address from, address to, uint256 tokenId, bytes data)",
+	"setApprovalForAll(address operator, bool approved)",
+	"safeTransferFrom(address from, address to, uint256 id, uint256 amount, bytes data)",
+	"safeBatchTransferFrom(address from, address to, uint256[] ids, uint256[] amounts, bytes data)",
+	// WETH
+	"deposit()",
+	"withdraw(uint256 wad)",
+	// Multicall and Multicall3
+	"multicall(bytes[] data)",
+	"multicall(uint256 deadline, bytes[] data)",
+	"aggregate((address target, bytes callData)[] calls)",
+	"tryAggregate(bool requireSuccess, (address target, bytes callData)[] calls)",
+	"aggregate3((address target, bool allowFailure, bytes callData)[] calls)",
+	"aggregate3Value((address target, bool allowFailure, uint256 value, bytes callData)[] calls)",
+	// Uniswap V2 router
+	"swapExactTokensForTokens(uint256 amountIn, uint256 amountOutMin, address[] path, address to, uint256 deadline)",
+	"swapTokensForExactTokens(uint256 amountOut, uint256 amountInMax, address[] path, address to, uint256 deadline)",
+	"swapExactETHForTokens(uint256 amountOutMin, address[] path, address to, uint256 deadline)",
+	"swapETHForExactTokens(uint256 amountOut, address[] path, address to, uint256 deadline)",
+	"swapExactTokensForETH(uint256 amountIn, uint256 amountOutMin, address[] path, address to, uint256 deadline)",
+	"swapTokensForExactETH(uint256 amountOut, uint256 amountInMax, address[] path, address to, uint256 deadline)",
+	"addLiquidity(address tokenA, address tokenB, uint256 amountADesired, uint256 amountBDesired, uint256 amountAMin, uint256 amountBMin, address to, uint256 deadline)",
+	"addLiquidityETH(address token, uint256 amountTokenDesired, uint256 amountTokenMin, uint256 amountETHMin, address to, uint256 deadline)",
+	"removeLiquidity(address tokenA, address tokenB, uint256 liquidity, uint256 amountAMin, uint256 amountBMin, address to, uint256 deadline)",
+	"removeLiquidityETH(address token, uint256 liquidity, uint256 amountTokenMin, uint256 amountETHMin, address to, uint256 deadline)",
+	// Uniswap V3 router and SwapRouter02
+	"exactInputSingle((address tokenIn, address tokenOut, uint24 fee, address recipient, uint256 deadline, uint256 amountIn, uint256 amountOutMinimum, uint160 sqrtPriceLimitX96) params)",
+	"exactInputSingle((address tokenIn, address tokenOut, uint24 fee, address recipient, uint256 amountIn, uint256 amountOutMinimum, uint160 sqrtPriceLimitX96) params)",
+	"exactInput((bytes path, address recipient, uint256 deadline, uint256 amountIn, uint256 amountOutMinimum) params)",
+	"exactInput((bytes path, address recipient, uint256 amountIn, uint256 amountOutMinimum) params)",
+	"exactOutputSingle((address tokenIn, address tokenOut, uint24 fee, address recipient, uint256 deadline, uint256 amountOut, uint256 amountInMaximum, uint160 sqrtPriceLimitX96) params)",
+	"unwrapWETH9(uint256 amountMinimum, address recipient)",
+	"refundETH()",
+	// Uniswap universal router
+	"execute(bytes commands, bytes[] inputs, uint256 deadline)",
+	"execute(bytes commands, bytes[] inputs)",
+}
+
+var builtinEventSignatures = []string{
+	// the ERC-20 and ERC-721 events have the same topic, they're distinguished by the count of the indexed arguments.
+	"event Transfer(address indexed from, address indexed to, uint256 value)",
+	"event Transfer(address indexed from, address indexed to, uint256 indexed tokenId)",
+	"event Approval(address indexed owner, address indexed spender, uint256 value)",
+	"event Approval(address indexed owner, address indexed approved, uint256 indexed tokenId)",
+	"event ApprovalForAll(address indexed owner, address indexed operator, bool approved)",
+	"event TransferSingle(address indexed operator, address indexed from, address indexed to, uint256 id, uint256 value)",
+	"event TransferBatch(address indexed operator, address indexed from, address indexed to, uint256[] ids, uint256[] values)",
+	"event Deposit(address indexed dst, uint256 wad)",
+	"event Withdrawal(address indexed src, uint256 wad)",
+	"event Swap(address indexed sender, uint256 amount0In, uint256 amount1In, uint256 amount0Out, uint256 amount1Out, address indexed to)",
+	"event Swap(address indexed sender, address indexed recipient, int256 amount0, int256 amount1, uint160 sqrtPriceX96, uint128 liquidity, int24 tick)",
+}
+
+// The functions whose bytes arguments are the nested calls.
+var multicallFunctionNames = map[string]bool{
+	"multicall": true, "aggregate": true, "tryAggregate": true, "aggregate3": true, "aggregate3Value": true,
+}
+
+// SignatureDatabase resolves the unknown function selectors and event topics, e.g. the 4byte directory.
+type SignatureDatabase interface {
+	// FunctionSignatures
+	// @param selector the hex string of the 4 bytes selector, e.g. `0xa9059cbb`
+	// @return the candidate signatures, e.g. `transfer(address,uint256)`
+	FunctionSignatures(selector string) (*base.StringArray, error)
+	// EventSignatures
+	// @param topic the hex string of the event topic
+	// @return the candidate signatures, e.g. `Transfer(address,address,uint256)`, the indexed arguments can't be known, they're guessed by the count of topics.
+	EventSignatures(topic string) (*base.StringArray, error)
+}
+
+type DecodedArgument struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// The address is the checksum hex, the integer is the decimal string, the bytes is the hex string,
+	// the array is the list of values, and the tuple is the list of `DecodedArgument`.
+	Value interface{} `json:"value"`
+}
+
+type DecodedCall struct {
+	Selector  string             `json:"selector"`
+	Method    string             `json:"method"`
+	Signature string             `json:"signature"`
+	Arguments []*DecodedArgument `json:"arguments"`
+	// The nested calls of the multicall
+	Calls []*DecodedCall `json:"calls,omitempty"`
+}
+
+func (c *DecodedCall) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(c)
+}
+
+type DecodedTransaction struct {
+	Kind  string `json:"kind"`
+	To    string `json:"to"`
+	Value string `json:"value"`
+	// The call is nil if it's not a contract call or the selector is unknown
+	Call *DecodedCall `json:"call,omitempty"`
+	// The raw data of the unknown contract call
+	Data string `json:"data,omitempty"`
+}
+
+func (t *DecodedTransaction) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(t)
+}
+
+type DecodedEvent struct {
+	Address   string             `json:"address"`
+	Name      string             `json:"name"`
+	Signature string             `json:"signature"`
+	Arguments []*DecodedArgument `json:"arguments"`
+}
+
+func (e *DecodedEvent) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(e)
+}
+
+type DecodedEventArray struct {
+	inter.AnyArray[*DecodedEvent]
+}
+
+// TransactionDecoder decodes the calldata and logs to the human-readable preview.
+type TransactionDecoder struct {
+	database SignatureDatabase
+
+	mu        sync.RWMutex
+	functions map[[4]byte][]*abi.Method
+	events    map[common.Hash][]*abi.Event
+}
+
+// NewTransactionDecoder
+// @return the decoder that knows the ERC-20/721/1155, permit, multicall and common router functions.
+func NewTransactionDecoder() *TransactionDecoder {
+	decoder := &TransactionDecoder{
+		functions: make(map[[4]byte][]*abi.Method),
+		events:    make(map[common.Hash][]*abi.Event),
+	}
+	for _, signature := range builtinFunctionSignatures {
+		if err := decoder.AddSignature(signature); err != nil {
+			panic(err)
+		}
+	}
+	for _, signature := range builtinEventSignatures {
+		if err := decoder.AddSignature(signature); err != nil {
+			panic(err)
+		}
+	}
+	return decoder
+}
+
+// SetSignatureDatabase
+// The unknown selectors and topics are resolved by the database, nil to disable it.
+func (d *TransactionDecoder) SetSignatureDatabase(database SignatureDatabase) {
+	d.database = database
+}
+
+// AddSignature
+// @param signature the human-readable signature of the function or event, the event should start with `event `.
+func (d *TransactionDecoder) AddSignature(signature string) error {
+	if strings.HasPrefix(strings.TrimSpace(signature), "event ") {
+		event, err := ParseEventSignature(signature)
+		if err != nil {
+			return err
+		}
+		d.addEvent(event)
+		return nil
+	}
+	method, err := ParseFunctionSignature(signature)
+	if err != nil {
+		return err
+	}
+	d.addMethod(method)
+	return nil
+}
+
+// AddAbi
+// Add all the functions and events of the contract abi json.
+func (d *TransactionDecoder) AddAbi(abiJson string) error {
+	parsed, err := abi.JSON(strings.NewReader(abiJson))
+	if err != nil {
+		return err
+	}
+	for _, method := range parsed.Methods {
+		method := method
+		d.addMethod(&method)
+	}
+	for _, event := range parsed.Events {
+		event := event
+		if !event.Anonymous {
+			d.addEvent(&event)
+		}
+	}
+	return nil
+}
+
+func (d *TransactionDecoder) addMethod(method *abi.Method) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	selector := [4]byte(method.ID)
+	for _, m := range d.functions[selector] {
+		if m.Sig == method.Sig {
+			return
+		}
+	}
+	d.functions[selector] = append(d.functions[selector], method)
+}
+
+func (d *TransactionDecoder) addEvent(event *abi.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, e := range d.events[event.ID] {
+		if e.Sig == event.Sig && indexedCount(e.Inputs) == indexedCount(event.Inputs) {
+			return
+		}
+	}
+	d.events[event.ID] = append(d.events[event.ID], event)
+}
+
+// DecodeTransaction
+// @return the preview of the plain transfer, contract call or contract creation.
+func (d *TransactionDecoder) DecodeTransaction(txn *Transaction) (decoded *DecodedTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	decoded = &DecodedTransaction{To: txn.To, Value: txn.Value}
+	if decoded.Value == "" {
+		decoded.Value = "0"
+	}
+	data, err := decodeHexData(txn.Data)
+	if err != nil {
+		return
+	}
+	switch {
+	case txn.To == "":
+		decoded.Kind = TransactionKindContractCreation
+	case len(data) == 0:
+		decoded.Kind = TransactionKindTransfer
+	default:
+		decoded.Kind = TransactionKindContractCall
+		decoded.Call, err = d.decodeCall(data)
+		if err == ErrUnknownSelector {
+			decoded.Data, err = HexType.HexEncodeToString(data), nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// DecodeCalldata
+// @param data the hex string of the contract call input
+func (d *TransactionDecoder) DecodeCalldata(data string) (call *DecodedCall, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	input, err := decodeHexData(data)
+	if err != nil {
+		return
+	}
+	return d.decodeCall(input)
+}
+
+func (d *TransactionDecoder) decodeCall(data []byte) (*DecodedCall, error) {
+	if len(data) < 4 {
+		return nil, ErrUnknownSelector
+	}
+	selector := [4]byte(data[:4])
+	d.mu.RLock()
+	candidates := d.functions[selector]
+	d.mu.RUnlock()
+	if call := decodeCallWithMethods(candidates, data); call != nil {
+		d.decodeNestedCalls(call)
+		return call, nil
+	}
+
+	if d.database == nil {
+		return nil, ErrUnknownSelector
+	}
+	signatures, err := d.database.FunctionSignatures(HexType.HexEncodeToString(data[:4]))
+	if err != nil {
+		return nil, err
+	}
+	resolved := make([]*abi.Method, 0)
+	for _, signature := range signatures.AnyArray {
+		method, err := ParseFunctionSignature(signature)
+		if err == nil && bytes.Equal(method.ID, data[:4]) {
+			resolved = append(resolved, method)
+		}
+	}
+	call := decodeCallWithMethods(resolved, data)
+	if call == nil {
+		return nil, ErrUnknownSelector
+	}
+	for _, method := range resolved {
+		if method.Sig == call.Signature {
+			d.addMethod(method)
+		}
+	}
+	d.decodeNestedCalls(call)
+	return call, nil
+}
+
+// decodeCallWithMethods
+// @return the call decoded by the first method that the data is the exact encoding of the arguments.
+func decodeCallWithMethods(methods []*abi.Method, data []byte) *DecodedCall {
+	for _, method := range methods {
+		values, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			continue
+		}
+		// the go-ethereum unpacking is lenient, the re-encoding rejects the wrong signature.
+		packed, err := method.Inputs.Pack(values...)
+		if err != nil || !bytes.Equal(packed, data[4:]) {
+			continue
+		}
+		return &DecodedCall{
+			Selector:  HexType.HexEncodeToString(method.ID),
+			Method:    method.RawName,
+			Signature: method.Sig,
+			Arguments: decodeArguments(method.Inputs, values),
+		}
+	}
+	return nil
+}
+
+// decodeNestedCalls
+// The bytes in the arguments of the multicall are decoded as the calls, the unknown calls are ignored.
+func (d *TransactionDecoder) decodeNestedCalls(call *DecodedCall) {
+	if !multicallFunctionNames[call.Method] {
+		return
+	}
+	var walk func(typ string, value interface{})
+	walk = func(typ string, value interface{}) {
+		switch v := value.(type) {
+		case []*DecodedArgument:
+			for _, arg := range v {
+				walk(arg.Type, arg.Value)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(strings.TrimSuffix(typ, "[]"), item)
+			}
+		case string:
+			if typ != "bytes" {
+				return
+			}
+			data, err := decodeHexData(v)
+			if err != nil {
+				return
+			}
+			if nested, err := d.decodeCall(data); err == nil {
+				call.Calls = append(call.Calls, nested)
+			}
+		}
+	}
+	for _, arg := range call.Arguments {
+		walk(arg.Type, arg.Value)
+	}
+}
+
+// DecodeLog
+// @param topics the hex strings of the log topics
+// @param data the hex string of the log data
+// @return the decoded event, the error `ErrUnknownSelector` if the topic is unknown.
+func (d *TransactionDecoder) DecodeLog(address string, topics *base.StringArray, data string) (event *DecodedEvent, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	log := &types.Log{Address: common.HexToAddress(address)}
+	for _, topic := range topics.AnyArray {
+		hash, err := decodeHexData(topic)
+		if err != nil || len(hash) != common.HashLength {
+			return nil, errors.New("invalid log topic")
+		}
+		log.Topics = append(log.Topics, common.BytesToHash(hash))
+	}
+	if log.Data, err = decodeHexData(data); err != nil {
+		return
+	}
+	return d.decodeLog(log)
+}
+
+// DecodeLogsJson
+// @param logsJson the json array of the logs of the receipt, only the `address`, `topics` and `data` are used.
+// @return the decoded events, the unknown logs are skipped.
+func (d *TransactionDecoder) DecodeLogsJson(logsJson string) (events *DecodedEventArray, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	var logs []struct {
+		Address string   `json:"address"`
+		Topics  []string `json:"topics"`
+		Data    string   `json:"data"`
+	}
+	if err = json.Unmarshal([]byte(logsJson), &logs); err != nil {
+		return
+	}
+	events = &DecodedEventArray{}
+	for _, log := range logs {
+		event, err := d.DecodeLog(log.Address, &base.StringArray{AnyArray: log.Topics}, log.Data)
+		if err == nil {
+			events.Append(event)
+		}
+	}
+	return events, nil
+}
+
+// DecodeReceiptLogs
+// @return the decoded events of the receipt, the unknown logs are skipped.
+func (d *TransactionDecoder) DecodeReceiptLogs(receipt *Receipt) *DecodedEventArray {
+	events := &DecodedEventArray{}
+	for _, log := range receipt.Logs {
+		if event, err := d.decodeLog(log); err == nil {
+			events.Append(event)
+		}
+	}
+	return events
+}
+
+func (d *TransactionDecoder) decodeLog(log *types.Log) (*DecodedEvent, error) {
+	if len(log.Topics) == 0 {
+		return nil, ErrUnknownSelector
+	}
+	d.mu.RLock()
+	candidates := d.events[log.Topics[0]]
+	d.mu.RUnlock()
+	if event := decodeLogWithEvents(candidates, log); event != nil {
+		return event, nil
+	}
+
+	if d.database == nil {
+		return nil, ErrUnknownSelector
+	}
+	signatures, err := d.database.EventSignatures(log.Topics[0].Hex())
+	if err != nil {
+		return nil, err
+	}
+	for _, signature := range signatures.AnyArray {
+		event, err := ParseEventSignature(signature)
+		if err != nil || event.ID != log.Topics[0] {
+			continue
+		}
+		// the leading arguments are guessed as the indexed
+		for i := range event.Inputs {
+			event.Inputs[i].Indexed = i < len(log.Topics)-1
+		}
+		if decoded := decodeLogWithEvents([]*abi.Event{event}, log); decoded != nil {
+			d.addEvent(event)
+			return decoded, nil
+		}
+	}
+	return nil, ErrUnknownSelector
+}
+
+func decodeLogWithEvents(events []*abi.Event, log *types.Log) *DecodedEvent {
+	for _, event := range events {
+		if indexedCount(event.Inputs) != len(log.Topics)-1 {
+			continue
+		}
+		nonIndexed, err := event.Inputs.NonIndexed().Unpack(log.Data)
+		if err != nil {
+			continue
+		}
+		args := make([]*DecodedArgument, 0, len(event.Inputs))
+		topicIndex, dataIndex := 1, 0
+		for _, input := range event.Inputs {
+			var arg *DecodedArgument
+			if input.Indexed {
+				arg = decodeIndexedArgument(input, log.Topics[topicIndex])
+				topicIndex++
+			} else {
+				arg = decodeArgument(input.Name, input.Type, nonIndexed[dataIndex])
+				dataIndex++
+			}
+			if arg == nil {
+				args = nil
+				break
+			}
+			args = append(args, arg)
+		}
+		if args == nil && len(event.Inputs) > 0 {
+			continue
+		}
+		return &DecodedEvent{
+			Address:   log.Address.Hex(),
+			Name:      event.RawName,
+			Signature: event.Sig,
+			Arguments: args,
+		}
+	}
+	return nil
+}
+
+// decodeIndexedArgument
+// The dynamic indexed argument is the hash of the value, so the topic is returned.
+func decodeIndexedArgument(input abi.Argument, topic common.Hash) *DecodedArgument {
+	switch input.Type.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return &DecodedArgument{Name: input.Name, Type: input.Type.String(), Value: topic.Hex()}
+	}
+	values, err := abi.Arguments{{Type: input.Type}}.Unpack(topic.Bytes())
+	if err != nil {
+		return nil
+	}
+	return decodeArgument(input.Name, input.Type, values[0])
+}
+
+func decodeArguments(args abi.Arguments, values []interface{}) []*DecodedArgument {
+	res := make([]*DecodedArgument, 0, len(args))
+	for i, arg := range args {
+		res = append(res, decodeArgument(arg.Name, arg.Type, values[i]))
+	}
+	return res
+}
+
+func decodeArgument(name string, typ abi.Type, value interface{}) *DecodedArgument {
+	return &DecodedArgument{Name: name, Type: typ.String(), Value: formatAbiValue(typ, reflect.ValueOf(value))}
+}
+
+// formatAbiValue
+// @return the json friendly value of the abi type
+func formatAbiValue(typ abi.Type, value reflect.Value) interface{} {
+	switch typ.T {
+	case abi.AddressTy:
+		return value.Interface().(common.Address).Hex()
+	case abi.IntTy, abi.UintTy:
+		if b, ok := value.Interface().(*big.Int); ok {
+			return b.String()
+		}
+		return fmt.Sprint(value.Interface())
+	case abi.BoolTy:
+		return value.Bool()
+	case abi.StringTy:
+		return value.String()
+	case abi.BytesTy:
+		return HexType.HexEncodeToString(value.Bytes())
+	case abi.FixedBytesTy, abi.FunctionTy:
+		data := make([]byte, value.Len())
+		reflect.Copy(reflect.ValueOf(data), value)
+		return HexType.HexEncodeToString(data)
+	case abi.SliceTy, abi.ArrayTy:
+		items := make([]interface{}, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, formatAbiValue(*typ.Elem, value.Index(i)))
+		}
+		return items
+	case abi.TupleTy:
+		fields := make([]*DecodedArgument, 0, len(typ.TupleElems))
+		for i, elem := range typ.TupleElems {
+			fields = append(fields, &DecodedArgument{
+				Name:  typ.TupleRawNames[i],
+				Type:  elem.String(),
+				Value: formatAbiValue(*elem, value.Field(i)),
+			})
+		}
+		return fields
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+func indexedCount(args abi.Arguments) int {
+	count := 0
+	for _, arg := range args {
+		if arg.Indexed {
+			count++
+		}
+	}
+	return count
+}
+
+func decodeHexData(data string) ([]byte, error) {
+	if data == "" || data == "0x" {
+		return []byte{}, nil
+	}
+	return HexType.HexDecodeString(data)
+}
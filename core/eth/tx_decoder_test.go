@@ -0,0 +1,242 @@
+package eth
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type testSignatureDatabase struct {
+	functions map[string][]string
+	events    map[string][]string
+}
+
+func (d *testSignatureDatabase) FunctionSignatures(selector string) (*base.StringArray, error) {
+	signatures, ok := d.functions[selector]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &base.StringArray{AnyArray: signatures}, nil
+}
+
+func (d *testSignatureDatabase) EventSignatures(topic string) (*base.StringArray, error) {
+	signatures, ok := d.events[topic]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &base.StringArray{AnyArray: signatures}, nil
+}
+
+func TestParseFunctionSignature(t *testing.T) {
+	selectors := map[string]string{
+		"function transfer(address to, uint256 amount)":                                                  "a9059cbb",
+		"aggregate3((address target, bool allowFailure, bytes callData)[] calls)":                        "82ad56cb",
+		"safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)":                               "2eb2c2d6",
+		"permit(address owner, ((address,uint160,uint48,uint48) details, address, uint256), bytes)":      "2b67b570",
+		"exactInputSingle(tuple(address,address,uint24,address,uint256,uint256,uint256,uint160) params)": "414bf389",
+		"withdraw(uint wad)": "2e1a7d4d",
+	}
+	for signature, selector := range selectors {
+		method, err := ParseFunctionSignature(signature)
+		require.NoError(t, err, signature)
+		require.Equal(t, selector, hex.EncodeToString(method.ID), signature)
+	}
+
+	event, err := ParseEventSignature("event Transfer(address indexed from, address indexed to, uint256 value)")
+	require.NoError(t, err)
+	require.Equal(t, "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", event.ID.Hex())
+	require.True(t, event.Inputs[0].Indexed)
+	require.False(t, event.Inputs[2].Indexed)
+
+	for _, invalid := range []string{"transfer", "transfer(address", "transfer(address to extra)", "(address)", "transfer(unknown)"} {
+		_, err := ParseFunctionSignature(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestTransactionDecoder_DecodeTransaction(t *testing.T) {
+	decoder := NewTransactionDecoder()
+	receiver := "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"
+
+	decoded, err := decoder.DecodeTransaction(NewTransaction("0", "", "", receiver, "1000", ""))
+	require.NoError(t, err)
+	require.Equal(t, TransactionKindTransfer, decoded.Kind)
+	require.Nil(t, decoded.Call)
+
+	decoded, err = decoder.DecodeTransaction(NewTransaction("0", "", "", "", "", "0x6080604052"))
+	require.NoError(t, err)
+	require.Equal(t, TransactionKindContractCreation, decoded.Kind)
+	require.Equal(t, "0", decoded.Value)
+
+	data, err := EncodeErc20Transfer(receiver, "123456789")
+	require.NoError(t, err)
+	decoded, err = decoder.DecodeTransaction(NewTransaction("0", "", "", receiver, "0", hex.EncodeToString(data)))
+	require.NoError(t, err)
+	require.Equal(t, TransactionKindContractCall, decoded.Kind)
+	require.Equal(t, "transfer", decoded.Call.Method)
+	require.Equal(t, "0xa9059cbb", decoded.Call.Selector)
+	require.Equal(t, &DecodedArgument{Name: "to", Type: "address", Value: receiver}, decoded.Call.Arguments[0])
+	require.Equal(t, &DecodedArgument{Name: "amount", Type: "uint256", Value: "123456789"}, decoded.Call.Arguments[1])
+	jsonString, err := decoded.JsonString()
+	require.NoError(t, err)
+	require.Contains(t, jsonString.Value, `"method":"transfer"`)
+
+	// the unknown selector keeps the raw data
+	decoded, err = decoder.DecodeTransaction(NewTransaction("0", "", "", receiver, "0", "0x12345678"))
+	require.NoError(t, err)
+	require.Nil(t, decoded.Call)
+	require.Equal(t, "0x12345678", decoded.Data)
+
+	// the data that is not the exact encoding of the arguments is rejected
+	_, err = decoder.DecodeCalldata(toHexData(append(data, 1)))
+	require.Equal(t, ErrUnknownSelector, err)
+}
+
+func toHexData(data []byte) string {
+	return "0x" + hex.EncodeToString(data)
+}
+
+func TestTransactionDecoder_Multicall(t *testing.T) {
+	decoder := NewTransactionDecoder()
+	token := common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")
+	spender := common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")
+
+	approve, err := EncodeErc20Approve(spender.Hex(), big.NewInt(100))
+	require.NoError(t, err)
+	swap, err := ParseFunctionSignature("exactInputSingle((address tokenIn, address tokenOut, uint24 fee, address recipient, uint256 amountIn, uint256 amountOutMinimum, uint160 sqrtPriceLimitX96) params)")
+	require.NoError(t, err)
+	swapArgs, err := swap.Inputs.Pack(struct {
+		TokenIn           common.Address
+		TokenOut          common.Address
+		Fee               *big.Int
+		Recipient         common.Address
+		AmountIn          *big.Int
+		AmountOutMinimum  *big.Int
+		SqrtPriceLimitX96 *big.Int
+	}{token, spender, big.NewInt(3000), spender, big.NewInt(1e6), big.NewInt(9e5), big.NewInt(0)})
+	require.NoError(t, err)
+	swapData := append(swap.ID, swapArgs...)
+
+	aggregate3, err := ParseFunctionSignature("aggregate3((address target, bool allowFailure, bytes callData)[] calls)")
+	require.NoError(t, err)
+	type call3 struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	args, err := aggregate3.Inputs.Pack([]call3{{token, false, approve}, {spender, true, swapData}, {spender, true, []byte{1, 2, 3, 4}}})
+	require.NoError(t, err)
+
+	call, err := decoder.DecodeCalldata(toHexData(append(aggregate3.ID, args...)))
+	require.NoError(t, err)
+	require.Equal(t, "aggregate3", call.Method)
+	calls := call.Arguments[0].Value.([]interface{})
+	require.Len(t, calls, 3)
+	fields := calls[0].([]*DecodedArgument)
+	require.Equal(t, &DecodedArgument{Name: "target", Type: "address", Value: token.Hex()}, fields[0])
+	require.Equal(t, &DecodedArgument{Name: "allowFailure", Type: "bool", Value: false}, fields[1])
+
+	// the unknown nested call is skipped
+	require.Len(t, call.Calls, 2)
+	require.Equal(t, "approve", call.Calls[0].Method)
+	require.Equal(t, "exactInputSingle", call.Calls[1].Method)
+	params := call.Calls[1].Arguments[0].Value.([]*DecodedArgument)
+	require.Equal(t, &DecodedArgument{Name: "fee", Type: "uint24", Value: "3000"}, params[2])
+	require.Equal(t, &DecodedArgument{Name: "amountIn", Type: "uint256", Value: "1000000"}, params[4])
+}
+
+func TestTransactionDecoder_Database(t *testing.T) {
+	decoder := NewTransactionDecoder()
+	mint, err := ParseFunctionSignature("mint(address,uint256)")
+	require.NoError(t, err)
+	args, err := mint.Inputs.Pack(common.HexToAddress("0x01"), big.NewInt(7))
+	require.NoError(t, err)
+	data := toHexData(append(mint.ID, args...))
+
+	_, err = decoder.DecodeCalldata(data)
+	require.Equal(t, ErrUnknownSelector, err)
+
+	database := &testSignatureDatabase{
+		functions: map[string][]string{"0x40c10f19": {"invalid(", "mint(address,uint256)"}},
+		events:    map[string][]string{},
+	}
+	decoder.SetSignatureDatabase(database)
+	call, err := decoder.DecodeCalldata(data)
+	require.NoError(t, err)
+	require.Equal(t, "mint(address,uint256)", call.Signature)
+	require.Equal(t, "7", call.Arguments[1].Value)
+
+	// the resolved signature is cached
+	decoder.SetSignatureDatabase(nil)
+	_, err = decoder.DecodeCalldata(data)
+	require.NoError(t, err)
+
+	// the unknown event is resolved, the leading arguments are indexed
+	event, err := ParseEventSignature("Minted(address,uint256)")
+	require.NoError(t, err)
+	database.events[event.ID.Hex()] = []string{"Minted(address,uint256)"}
+	decoder.SetSignatureDatabase(database)
+	topics := base.NewStringArrayWithItem(event.ID.Hex())
+	topics.Append(common.BytesToHash(common.HexToAddress("0x02").Bytes()).Hex())
+	decoded, err := decoder.DecodeLog("0x03", topics, toHexData(common.LeftPadBytes([]byte{9}, 32)))
+	require.NoError(t, err)
+	require.Equal(t, "Minted", decoded.Name)
+	require.Equal(t, common.HexToAddress("0x02").Hex(), decoded.Arguments[0].Value)
+	require.Equal(t, "9", decoded.Arguments[1].Value)
+}
+
+func TestTransactionDecoder_DecodeLogs(t *testing.T) {
+	decoder := NewTransactionDecoder()
+	from, to := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	transferTopic := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	amount := common.LeftPadBytes(big.NewInt(500).Bytes(), 32)
+
+	receipt := &Receipt{}
+	receipt.Logs = []*types.Log{
+		// ERC-20
+		{Address: common.HexToAddress("0x0a"), Topics: []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())}, Data: amount},
+		// ERC-721
+		{Address: common.HexToAddress("0x0b"), Topics: []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes()), common.BigToHash(big.NewInt(42))}},
+		// unknown
+		{Address: common.HexToAddress("0x0c"), Topics: []common.Hash{crypto.Keccak256Hash([]byte("Unknown()"))}},
+	}
+	events := decoder.DecodeReceiptLogs(receipt)
+	require.Equal(t, 2, events.Count())
+	erc20 := events.ValueAt(0)
+	require.Equal(t, "Transfer", erc20.Name)
+	require.Equal(t, []*DecodedArgument{
+		{Name: "from", Type: "address", Value: from.Hex()},
+		{Name: "to", Type: "address", Value: to.Hex()},
+		{Name: "value", Type: "uint256", Value: "500"},
+	}, erc20.Arguments)
+	erc721 := events.ValueAt(1)
+	require.Equal(t, &DecodedArgument{Name: "tokenId", Type: "uint256", Value: "42"}, erc721.Arguments[2])
+
+	logsJson := `[{"address":"0x000000000000000000000000000000000000000a","topics":["` + transferTopic.Hex() + `","` +
+		common.BytesToHash(from.Bytes()).Hex() + `","` + common.BytesToHash(to.Bytes()).Hex() + `"],"data":"` + toHexData(amount) + `","logIndex":"0x0"}]`
+	events, err := decoder.DecodeLogsJson(logsJson)
+	require.NoError(t, err)
+	require.Equal(t, 1, events.Count())
+	require.Equal(t, erc20, events.ValueAt(0))
+}
+
+func TestDecodeContractCall(t *testing.T) {
+	data, err := EncodeErc20Transfer("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB", "1000")
+	require.NoError(t, err)
+	call, err := DecodeContractCall(Erc20AbiStr, data)
+	require.NoError(t, err)
+	require.Equal(t, "transfer", call.Method)
+	require.Equal(t, "recipient", call.Arguments[0].Name)
+	require.Equal(t, "1000", call.Arguments[1].Value)
+
+	values, err := AbiCoderDecode([]string{"address", "uint256"}, data[4:])
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1000), values[1])
+}
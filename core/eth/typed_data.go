@@ -0,0 +1,446 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The versions of the `eth_signTypedData`, the arrays are supported since v4.
+const (
+	TypedDataV3 = "V3"
+	TypedDataV4 = "V4"
+)
+
+var (
+	ErrTypedDataVersion       = errors.New("unsupported typed data version")
+	ErrTypedDataChainMismatch = errors.New("the chainId of the typed data domain does not match the chain")
+)
+
+var (
+	typedDataIntRegexp   = regexp.MustCompile(`^(u?)int(\d*)$`)
+	typedDataBytesRegexp = regexp.MustCompile(`^bytes(\d+)$`)
+	typedDataArrayRegexp = regexp.MustCompile(`^(.+)\[(\d*)\]$`)
+)
+
+// The fields of the domain in the order of the EIP-712, it's used if the types has no `EIP712Domain`.
+var typedDataDomainFields = []TypedDataField{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+	{Name: "salt", Type: "bytes32"},
+}
+
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedData is the EIP-712 typed structured data, see https://eips.ethereum.org/EIPS/eip-712
+type TypedData struct {
+	Types       map[string][]TypedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      map[string]interface{}      `json:"domain"`
+	Message     map[string]interface{}      `json:"message"`
+
+	version string
+}
+
+// NewTypedData
+// @param typedDataJson the json string that the dapp requests with `eth_signTypedData_v3` or `eth_signTypedData_v4`
+// @param version `TypedDataV3` or `TypedDataV4`, the empty string is v4.
+func NewTypedData(typedDataJson string, version string) (*TypedData, error) {
+	version = strings.ToUpper(version)
+	switch version {
+	case "":
+		version = TypedDataV4
+	case TypedDataV3, TypedDataV4:
+	default:
+		return nil, ErrTypedDataVersion
+	}
+	// the large integers are kept as `json.Number`
+	decoder := json.NewDecoder(strings.NewReader(typedDataJson))
+	decoder.UseNumber()
+	var data TypedData
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+	if data.Types == nil || data.PrimaryType == "" {
+		return nil, errors.New("invalid typed data")
+	}
+	if _, ok := data.Types["EIP712Domain"]; !ok {
+		fields := make([]TypedDataField, 0)
+		for _, field := range typedDataDomainFields {
+			if _, ok := data.Domain[field.Name]; ok {
+				fields = append(fields, field)
+			}
+		}
+		data.Types["EIP712Domain"] = fields
+	}
+	if _, ok := data.Types[data.PrimaryType]; !ok {
+		return nil, fmt.Errorf("the primary type %s is undefined", data.PrimaryType)
+	}
+	data.version = version
+	return &data, nil
+}
+
+// ChainId
+// @return the chainId of the domain, nil if it's not specified.
+func (t *TypedData) ChainId() (*big.Int, error) {
+	value, ok := t.Domain["chainId"]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	return typedDataInteger(value)
+}
+
+// CheckChainId
+// @param chainId the chain that the dapp connects to, the domain without chainId is accepted.
+func (t *TypedData) CheckChainId(chainId *big.Int) error {
+	domainChainId, err := t.ChainId()
+	if err != nil {
+		return err
+	}
+	if domainChainId != nil && domainChainId.Cmp(chainId) != 0 {
+		return ErrTypedDataChainMismatch
+	}
+	return nil
+}
+
+// Hash
+// @return keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message))
+func (t *TypedData) Hash() ([]byte, error) {
+	domainSeparator, err := t.HashStruct("EIP712Domain", t.Domain)
+	if err != nil {
+		return nil, err
+	}
+	data := append([]byte{0x19, 0x01}, domainSeparator...)
+	if t.PrimaryType != "EIP712Domain" {
+		messageHash, err := t.HashStruct(t.PrimaryType, t.Message)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, messageHash...)
+	}
+	return crypto.Keccak256(data), nil
+}
+
+// HashStruct
+// @return keccak256(typeHash ‖ encodeData(value))
+func (t *TypedData) HashStruct(typeName string, value map[string]interface{}) ([]byte, error) {
+	encoded, err := t.encodeData(typeName, value)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(encoded), nil
+}
+
+// EncodeType
+// @return the type and its referenced struct types, e.g. `Mail(Person from,Person to,string contents)Person(string name,address wallet)`
+func (t *TypedData) EncodeType(typeName string) string {
+	deps := make(map[string]bool)
+	t.findDependencies(typeName, deps)
+	delete(deps, typeName)
+	sorted := make([]string, 0, len(deps))
+	for dep := range deps {
+		sorted = append(sorted, dep)
+	}
+	sort.Strings(sorted)
+
+	var buf strings.Builder
+	for _, name := range append([]string{typeName}, sorted...) {
+		fields := make([]string, 0, len(t.Types[name]))
+		for _, field := range t.Types[name] {
+			fields = append(fields, field.Type+" "+field.Name)
+		}
+		buf.WriteString(name + "(" + strings.Join(fields, ",") + ")")
+	}
+	return buf.String()
+}
+
+func (t *TypedData) findDependencies(typeName string, deps map[string]bool) {
+	typeName = typedDataBaseType(typeName)
+	if deps[typeName] {
+		return
+	}
+	fields, ok := t.Types[typeName]
+	if !ok {
+		return
+	}
+	deps[typeName] = true
+	for _, field := range fields {
+		t.findDependencies(field.Type, deps)
+	}
+}
+
+func (t *TypedData) encodeData(typeName string, value map[string]interface{}) ([]byte, error) {
+	fields := t.Types[typeName]
+	for name := range value {
+		if !slices.ContainsFunc(fields, func(f TypedDataField) bool { return f.Name == name }) {
+			return nil, fmt.Errorf("the field %s is undefined in the type %s", name, typeName)
+		}
+	}
+	buf := bytes.NewBuffer(crypto.Keccak256([]byte(t.EncodeType(typeName))))
+	for _, field := range fields {
+		fieldValue, ok := value[field.Name]
+		if !ok && t.version == TypedDataV3 {
+			// the v3 skips the missing fields
+			continue
+		}
+		encoded, err := t.encodeField(field.Name, field.Type, fieldValue)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(encoded)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeField
+// @return the 32 bytes encoding of the field
+func (t *TypedData) encodeField(name, typeName string, value interface{}) ([]byte, error) {
+	if _, ok := t.Types[typeName]; ok {
+		if value == nil && t.version == TypedDataV4 {
+			return make([]byte, 32), nil
+		}
+		structValue, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the field %s is not the struct %s", name, typeName)
+		}
+		return t.HashStruct(typeName, structValue)
+	}
+	if value == nil {
+		return nil, fmt.Errorf("missing value for the field %s of type %s", name, typeName)
+	}
+
+	if match := typedDataArrayRegexp.FindStringSubmatch(typeName); match != nil {
+		if t.version == TypedDataV3 {
+			return nil, errors.New("arrays are unsupported in the typed data v3, use v4 instead")
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the field %s is not an array", name)
+		}
+		if match[2] != "" {
+			if length, err := strconv.Atoi(match[2]); err != nil || length != len(items) {
+				return nil, fmt.Errorf("the field %s should have %s items", name, match[2])
+			}
+		}
+		buf := bytes.Buffer{}
+		for _, item := range items {
+			encoded, err := t.encodeField(name, match[1], item)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(encoded)
+		}
+		return crypto.Keccak256(buf.Bytes()), nil
+	}
+
+	switch typeName {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("the field %s is not a string", name)
+		}
+		return crypto.Keccak256([]byte(str)), nil
+	case "bytes":
+		data, err := typedDataBytes(value)
+		if err != nil {
+			return nil, fmt.Errorf("the field %s is not the hex bytes", name)
+		}
+		return crypto.Keccak256(data), nil
+	case "bool":
+		boolValue, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("the field %s is not a bool", name)
+		}
+		if boolValue {
+			return common.LeftPadBytes([]byte{1}, 32), nil
+		}
+		return make([]byte, 32), nil
+	case "address":
+		str, ok := value.(string)
+		if !ok || !common.IsHexAddress(str) {
+			return nil, fmt.Errorf("the field %s is not an address", name)
+		}
+		return common.LeftPadBytes(common.HexToAddress(str).Bytes(), 32), nil
+	}
+
+	if match := typedDataBytesRegexp.FindStringSubmatch(typeName); match != nil {
+		length, _ := strconv.Atoi(match[1])
+		data, err := typedDataBytes(value)
+		if err != nil || length < 1 || length > 32 || len(data) > length {
+			return nil, fmt.Errorf("the field %s is not %s", name, typeName)
+		}
+		return common.RightPadBytes(data, 32), nil
+	}
+	if match := typedDataIntRegexp.FindStringSubmatch(typeName); match != nil {
+		bits := 256
+		if match[2] != "" {
+			bits, _ = strconv.Atoi(match[2])
+		}
+		integer, err := typedDataInteger(value)
+		if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, fmt.Errorf("the field %s is not %s", name, typeName)
+		}
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+		if match[1] == "u" {
+			if integer.Sign() < 0 || integer.Cmp(limit) >= 0 {
+				return nil, fmt.Errorf("the field %s overflows %s", name, typeName)
+			}
+			return common.LeftPadBytes(integer.Bytes(), 32), nil
+		}
+		limit.Rsh(limit, 1)
+		if integer.Cmp(limit) >= 0 || integer.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, fmt.Errorf("the field %s overflows %s", name, typeName)
+		}
+		// two's complement of 256 bits
+		if integer.Sign() < 0 {
+			integer = new(big.Int).Add(integer, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return common.LeftPadBytes(integer.Bytes(), 32), nil
+	}
+	return nil, fmt.Errorf("the type %s is undefined", typeName)
+}
+
+// typedDataBaseType
+// @return the element type of the array, e.g. `Person` of `Person[][2]`
+func typedDataBaseType(typeName string) string {
+	if index := strings.Index(typeName, "["); index >= 0 {
+		return typeName[:index]
+	}
+	return typeName
+}
+
+func typedDataBytes(value interface{}) ([]byte, error) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, errors.New("invalid bytes")
+	}
+	return hexutil.Decode(str)
+}
+
+// typedDataInteger
+// The integer can be the json number, the decimal or hex string.
+func typedDataInteger(value interface{}) (*big.Int, error) {
+	var str string
+	switch v := value.(type) {
+	case json.Number:
+		str = v.String()
+	case string:
+		str = v
+	default:
+		return nil, errors.New("invalid integer")
+	}
+	integer, ok := new(big.Int), false
+	if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") {
+		integer, ok = integer.SetString(str[2:], 16)
+	} else {
+		integer, ok = integer.SetString(str, 10)
+	}
+	if !ok {
+		return nil, errors.New("invalid integer")
+	}
+	return integer, nil
+}
+
+// HashTypedData
+// @param version `TypedDataV3` or `TypedDataV4`
+// @return the hex string of the EIP-712 hash that will be signed
+func HashTypedData(typedDataJson string, version string) (hash *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	typedData, err := NewTypedData(typedDataJson, version)
+	if err != nil {
+		return
+	}
+	data, err := typedData.Hash()
+	if err != nil {
+		return
+	}
+	return base.NewOptionalString(HexType.HexEncodeToString(data)), nil
+}
+
+// SignTypedData
+// Sign the EIP-712 typed data like `eth_signTypedData_v3` and `eth_signTypedData_v4`.
+// @param version `TypedDataV3` or `TypedDataV4`
+// @param chainId the chain that the dapp connects to, the domain chainId must be the same if it's specified; 0 to skip the check.
+// @return the hex string of the 65 bytes signature, the v is 27 or 28.
+func (a *Account) SignTypedData(typedDataJson string, version string, chainId int64) (signature *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	typedData, err := NewTypedData(typedDataJson, version)
+	if err != nil {
+		return
+	}
+	if chainId > 0 {
+		if err = typedData.CheckChainId(big.NewInt(chainId)); err != nil {
+			return
+		}
+	}
+	hash, err := typedData.Hash()
+	if err != nil {
+		return
+	}
+	signed, err := a.SignHash(hash)
+	if err != nil {
+		return
+	}
+	return base.NewOptionalString(HexType.HexEncodeToString(signed)), nil
+}
+
+// RecoverTypedDataSigner
+// @param signature the hex string of the 65 bytes signature, the v can be 0/1 or 27/28.
+// @return the checksum address of the signer
+func RecoverTypedDataSigner(typedDataJson string, version string, signature string) (address *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	typedData, err := NewTypedData(typedDataJson, version)
+	if err != nil {
+		return
+	}
+	hash, err := typedData.Hash()
+	if err != nil {
+		return
+	}
+	sig, err := HexType.HexDecodeString(signature)
+	if err != nil {
+		return
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, errors.New("signature must be 65 bytes long")
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pubKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return
+	}
+	return base.NewOptionalString(crypto.PubkeyToAddress(*pubKey).Hex()), nil
+}
+
+// VerifyTypedDataSignature
+// @return true if the typed data is signed by the address
+func VerifyTypedDataSignature(typedDataJson string, version string, signature string, address string) bool {
+	signer, err := RecoverTypedDataSigner(typedDataJson, version, signature)
+	if err != nil || !common.IsHexAddress(address) {
+		return false
+	}
+	return common.HexToAddress(address) == common.HexToAddress(signer.Value)
+}
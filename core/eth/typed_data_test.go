@@ -0,0 +1,191 @@
+package eth
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// The example of the EIP-712, it's signed by the private key keccak256("cow").
+const typedDataMailJson = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+// The example of the `eth_signTypedData_v4` that has the arrays.
+const typedDataGroupMailJson = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallets", "type": "address[]"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person[]"},
+			{"name": "contents", "type": "string"}
+		],
+		"Group": [
+			{"name": "name", "type": "string"},
+			{"name": "members", "type": "Person[]"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": "0x1",
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {
+			"name": "Cow",
+			"wallets": ["0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826", "0xDeaDbeefdEAdbeefdEadbEEFdeadbeEFdEaDbeeF"]
+		},
+		"to": [{
+			"name": "Bob",
+			"wallets": [
+				"0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+				"0xB0BdaBea57B0BDABeA57b0bdABEA57b0BDabEa57",
+				"0xB0B0b0b0b0b0B000000000000000000000000000"
+			]
+		}],
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func cowAccount(t *testing.T) *Account {
+	account, err := EthAccountWithPrivateKey(hex.EncodeToString(crypto.Keccak256([]byte("cow"))))
+	require.NoError(t, err)
+	require.Equal(t, "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826", account.Address())
+	return account
+}
+
+func TestTypedData_EIP712Example(t *testing.T) {
+	typedData, err := NewTypedData(typedDataMailJson, TypedDataV3)
+	require.NoError(t, err)
+	require.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", typedData.EncodeType("Mail"))
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain)
+	require.NoError(t, err)
+	require.Equal(t, "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f", hex.EncodeToString(domainSeparator))
+
+	for _, version := range []string{TypedDataV3, TypedDataV4, "v4", ""} {
+		hash, err := HashTypedData(typedDataMailJson, version)
+		require.NoError(t, err)
+		require.Equal(t, "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hash.Value)
+	}
+
+	account := cowAccount(t)
+	signature, err := account.SignTypedData(typedDataMailJson, TypedDataV3, 1)
+	require.NoError(t, err)
+	require.Equal(t, "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c", signature.Value)
+
+	signer, err := RecoverTypedDataSigner(typedDataMailJson, TypedDataV3, signature.Value)
+	require.NoError(t, err)
+	require.Equal(t, account.Address(), signer.Value)
+	require.True(t, VerifyTypedDataSignature(typedDataMailJson, TypedDataV3, signature.Value, account.Address()))
+	require.False(t, VerifyTypedDataSignature(typedDataMailJson, TypedDataV3, signature.Value, "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"))
+
+	// the signature with v 0/1
+	sig, err := hex.DecodeString(signature.Value[2:])
+	require.NoError(t, err)
+	sig[64] -= 27
+	require.True(t, VerifyTypedDataSignature(typedDataMailJson, TypedDataV3, hex.EncodeToString(sig), account.Address()))
+
+	_, err = account.SignTypedData(typedDataMailJson, TypedDataV3, 56)
+	require.Equal(t, ErrTypedDataChainMismatch, err)
+	_, err = account.SignTypedData(typedDataMailJson, "V5", 1)
+	require.Equal(t, ErrTypedDataVersion, err)
+}
+
+func TestTypedData_V4Arrays(t *testing.T) {
+	_, err := HashTypedData(typedDataGroupMailJson, TypedDataV3)
+	require.Error(t, err)
+
+	typedData, err := NewTypedData(typedDataGroupMailJson, TypedDataV4)
+	require.NoError(t, err)
+	require.Equal(t, "Mail(Person from,Person[] to,string contents)Person(string name,address[] wallets)", typedData.EncodeType("Mail"))
+	require.NoError(t, typedData.CheckChainId(big.NewInt(1)))
+	require.Equal(t, ErrTypedDataChainMismatch, typedData.CheckChainId(big.NewInt(5)))
+
+	hash, err := HashTypedData(typedDataGroupMailJson, TypedDataV4)
+	require.NoError(t, err)
+	require.Equal(t, "0xa85c2e2b118698e88db68a8105b794a8cc7cec074e89ef991cb4f5f533819cc2", hash.Value)
+
+	account := cowAccount(t)
+	signature, err := account.SignTypedData(typedDataGroupMailJson, TypedDataV4, 1)
+	require.NoError(t, err)
+	require.Equal(t, "0x65cbd956f2fae28a601bebc9b906cea0191744bd4c4247bcd27cd08f8eb6b71c78efdf7a31dc9abee78f492292721f362d296cf86b4538e07b51303b67f749061b", signature.Value)
+	require.True(t, VerifyTypedDataSignature(typedDataGroupMailJson, TypedDataV4, signature.Value, account.Address()))
+}
+
+func TestTypedData_MissingFields(t *testing.T) {
+	const permitJson = `{
+		"types": {
+			"Permit": [
+				{"name": "owner", "type": "address"},
+				{"name": "value", "type": "uint256"},
+				{"name": "delta", "type": "int8"},
+				{"name": "data", "type": "bytes"}
+			]
+		},
+		"primaryType": "Permit",
+		"domain": {"name": "Token", "chainId": 137},
+		"message": {"owner": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826", "value": 115792089237316195423570985008687907853269984665640564039457584007913129639935, "delta": -128}
+	}`
+	// the domain type is inferred from the domain fields
+	typedData, err := NewTypedData(permitJson, TypedDataV3)
+	require.NoError(t, err)
+	require.Equal(t, "EIP712Domain(string name,uint256 chainId)", typedData.EncodeType("EIP712Domain"))
+	chainId, err := typedData.ChainId()
+	require.NoError(t, err)
+	require.Equal(t, int64(137), chainId.Int64())
+
+	// the v3 skips the missing field, but the v4 requires it
+	_, err = HashTypedData(permitJson, TypedDataV3)
+	require.NoError(t, err)
+	_, err = HashTypedData(permitJson, TypedDataV4)
+	require.Error(t, err)
+
+	// the integer overflows
+	_, err = HashTypedData(strings.Replace(permitJson, "-128", "128", 1), TypedDataV3)
+	require.Error(t, err)
+	_, err = HashTypedData(strings.Replace(permitJson, "935", "936", 1), TypedDataV3)
+	require.Error(t, err)
+}
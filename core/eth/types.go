@@ -2,7 +2,9 @@ package eth
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
 	"strconv"
 	"strings"
@@ -11,7 +13,9 @@ import (
 	"github.com/coming-chat/wallet-SDK/core/base"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
 )
 
 type UrlParam struct {
@@ -134,55 +138,118 @@ type Transaction struct {
 
 	// EIP1559, Default is ""
 	MaxPriorityFeePerGas string
+
+	// The EIP-2718 type, see `TransactionTypeXxx`, Default is "" that the type is inferred from the fees and the access list.
+	Type string
+	// The chain id that is signed, Default is "" that the chain id of the rpc is used.
+	// The legacy transaction with chain id "0" is signed without the EIP-155 replay protection.
+	ChainId string
+	// EIP2930, the json of the access list, e.g. [{"address":"0x...","storageKeys":["0x..."]}], Default is ""
+	AccessList string
+
+	// EIP4844, the blob transaction can be decoded and displayed, but the blobs sidecar is not carried.
+	MaxFeePerBlobGas    string
+	BlobVersionedHashes *base.StringArray
 }
 
+const (
+	TransactionTypeLegacy     = "0"
+	TransactionTypeAccessList = "1"
+	TransactionTypeDynamicFee = "2"
+	TransactionTypeBlob       = "3"
+)
+
 func NewTransaction(nonce, gasPrice, gasLimit, to, value, data string) *Transaction {
-	return &Transaction{nonce, gasPrice, gasLimit, to, value, data, ""}
+	return &Transaction{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		GasLimit: gasLimit,
+		To:       to,
+		Value:    value,
+		Data:     data,
+	}
 }
 
 func NewTransactionNftTransfer(sender, receiver, gasPrice, gasLimit string, nft *base.NFT) *Transaction {
 	return NewTransactionNftTransferParams(sender, receiver, gasPrice, gasLimit, nft.Id, nft.ContractAddress, nft.Standard)
 }
 
+// NewTransactionNftTransferParams
+// The erc-1155 nft will transfer quantity 1, use `NewTransactionErc1155TransferParams` to transfer the chosen quantity.
 func NewTransactionNftTransferParams(sender, receiver, gasPrice, gasLimit, nftId, nftContractAddress, nftStandard string) *Transaction {
-	if strings.ToLower(nftStandard) != "erc-721" {
+	data, err := encodeNftTransfer(sender, receiver, nftId, nftStandard)
+	if err != nil {
 		return nil
 	}
-	data, err := EncodeErc721TransferFrom(sender, receiver, nftId)
+	return newContractCallTransaction(gasPrice, gasLimit, nftContractAddress, data)
+}
+
+func NewTransactionErc1155TransferParams(sender, receiver, gasPrice, gasLimit, nftId, nftContractAddress, amount string) *Transaction {
+	data, err := EncodeErc1155SafeTransferFrom(sender, receiver, nftId, amount)
 	if err != nil {
 		return nil
 	}
+	return newContractCallTransaction(gasPrice, gasLimit, nftContractAddress, data)
+}
+
+func newContractCallTransaction(gasPrice, gasLimit, contractAddress string, data []byte) *Transaction {
 	return &Transaction{
 		Nonce:    "",
 		GasPrice: gasPrice,
 		GasLimit: gasLimit,
-		To:       nftContractAddress,
+		To:       contractAddress,
 		Value:    "0",
 		Data:     common.Bytes2Hex(data),
 	}
 }
 
+// NewTransactionFromHex
+// Decode the legacy, EIP-2930, EIP-1559 or EIP-4844 transaction, it can be signed or unsigned.
 func NewTransactionFromHex(hexData string) (*Transaction, error) {
-	rawBytes, err := hex.DecodeString(hexData)
+	rawBytes, err := hex.DecodeString(strings.TrimPrefix(hexData, "0x"))
 	if err != nil {
 		return nil, err
 	}
-	decodeTx := types.NewTx(&types.DynamicFeeTx{})
+	decodeTx := new(types.Transaction)
 	err = decodeTx.UnmarshalBinary(rawBytes)
 	if err != nil {
 		return nil, err
 	}
+	to := ""
+	if decodeTx.To() != nil {
+		to = decodeTx.To().String()
+	}
 	tx := NewTransaction(
-		strconv.Itoa(int(decodeTx.Nonce())),
+		strconv.FormatUint(decodeTx.Nonce(), 10),
 		decodeTx.GasFeeCap().String(),
-		strconv.Itoa(int(decodeTx.Gas())),
-		decodeTx.To().String(),
+		strconv.FormatUint(decodeTx.Gas(), 10),
+		to,
 		decodeTx.Value().String(),
 		hex.EncodeToString(decodeTx.Data()))
-	// not equal, is eip1559; legacy feecap equal tipcap
-	if decodeTx.GasTipCap().Cmp(decodeTx.GasFeeCap()) != 0 {
+	tx.Type = strconv.Itoa(int(decodeTx.Type()))
+	if decodeTx.Type() != types.LegacyTxType {
 		tx.MaxPriorityFeePerGas = decodeTx.GasTipCap().String()
 	}
+
+	v, r, s := decodeTx.RawSignatureValues()
+	isSigned := v.Sign() != 0 || r.Sign() != 0 || s.Sign() != 0
+	if decodeTx.Type() != types.LegacyTxType && decodeTx.ChainId().Sign() != 0 || decodeTx.Type() == types.LegacyTxType && isSigned {
+		tx.ChainId = decodeTx.ChainId().String()
+	}
+	if len(decodeTx.AccessList()) > 0 {
+		accessList, err := json.Marshal(decodeTx.AccessList())
+		if err != nil {
+			return nil, err
+		}
+		tx.AccessList = string(accessList)
+	}
+	if decodeTx.Type() == types.BlobTxType {
+		tx.MaxFeePerBlobGas = decodeTx.BlobGasFeeCap().String()
+		tx.BlobVersionedHashes = base.NewStringArray()
+		for _, hash := range decodeTx.BlobHashes() {
+			tx.BlobVersionedHashes.Append(hash.String())
+		}
+	}
 	return tx, nil
 }
 
@@ -200,12 +267,13 @@ func (tx *Transaction) GetRawTx() (*types.Transaction, error) {
 	var (
 		gasPrice, value, maxFeePerGas *big.Int // default nil
 
-		nonce     uint64 = 0
-		gasLimit  uint64 = 90000 // reference https://eth.wiki/json-rpc/API method eth_sendTransaction
-		toAddress common.Address
-		data      []byte
-		valid     bool
-		err       error
+		nonce      uint64 = 0
+		gasLimit   uint64 = 90000 // reference https://eth.wiki/json-rpc/API method eth_sendTransaction
+		toAddress  *common.Address
+		data       []byte
+		accessList types.AccessList
+		valid      bool
+		err        error
 	)
 	if tx.GasPrice != "" {
 		if gasPrice, valid = big.NewInt(0).SetString(tx.GasPrice, 10); !valid {
@@ -232,38 +300,167 @@ func (tx *Transaction) GetRawTx() (*types.Transaction, error) {
 			return nil, errors.New("Invalid gas limit")
 		}
 	}
-	if tx.To != "" && !common.IsHexAddress(tx.To) {
-		return nil, errors.New("Invalid toAddress")
+	if tx.To != "" {
+		if !common.IsHexAddress(tx.To) {
+			return nil, errors.New("Invalid toAddress")
+		}
+		address := common.HexToAddress(tx.To)
+		toAddress = &address
 	}
-	toAddress = common.HexToAddress(tx.To)
 	if tx.Data != "" {
 		if data, err = HexType.HexDecodeString(tx.Data); err != nil {
 			return nil, errors.New("Invalid data string")
 		}
 	}
+	if tx.AccessList != "" {
+		if err = json.Unmarshal([]byte(tx.AccessList), &accessList); err != nil {
+			return nil, errors.New("Invalid access list")
+		}
+	}
+	chainId := big.NewInt(0)
+	if tx.ChainId != "" {
+		if chainId, valid = big.NewInt(0).SetString(tx.ChainId, 10); !valid {
+			return nil, errors.New("Invalid chain id")
+		}
+	}
 
-	if maxFeePerGas == nil || maxFeePerGas.Int64() == 0 {
-		// is legacy tx
+	switch tx.transactionType() {
+	case TransactionTypeLegacy:
 		return types.NewTx(&types.LegacyTx{
 			Nonce:    nonce,
-			To:       &toAddress,
+			To:       toAddress,
 			Value:    value,
 			Gas:      gasLimit,
 			GasPrice: gasPrice,
 			Data:     data,
 		}), nil
-	} else {
-		// is dynamic fee tx
+	case TransactionTypeAccessList:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    chainId,
+			Nonce:      nonce,
+			To:         toAddress,
+			Value:      value,
+			Gas:        gasLimit,
+			GasPrice:   gasPrice,
+			Data:       data,
+			AccessList: accessList,
+		}), nil
+	case TransactionTypeDynamicFee:
 		return types.NewTx(&types.DynamicFeeTx{
-			Nonce:     nonce,
-			To:        &toAddress,
-			Value:     value,
-			Gas:       gasLimit,
-			GasFeeCap: gasPrice,
-			GasTipCap: maxFeePerGas,
-			Data:      data,
+			ChainID:    chainId,
+			Nonce:      nonce,
+			To:         toAddress,
+			Value:      value,
+			Gas:        gasLimit,
+			GasFeeCap:  gasPrice,
+			GasTipCap:  maxFeePerGas,
+			Data:       data,
+			AccessList: accessList,
+		}), nil
+	case TransactionTypeBlob:
+		if toAddress == nil {
+			return nil, errors.New("the blob transaction cannot create contract")
+		}
+		blobFeeCap := big.NewInt(0)
+		if tx.MaxFeePerBlobGas != "" {
+			if blobFeeCap, valid = big.NewInt(0).SetString(tx.MaxFeePerBlobGas, 10); !valid {
+				return nil, errors.New("Invalid max fee per blob gas")
+			}
+		}
+		blobHashes := make([]common.Hash, 0)
+		if tx.BlobVersionedHashes != nil {
+			for _, hash := range tx.BlobVersionedHashes.AnyArray {
+				blobHashes = append(blobHashes, common.HexToHash(hash))
+			}
+		}
+		return types.NewTx(&types.BlobTx{
+			ChainID:    uint256.MustFromBig(chainId),
+			Nonce:      nonce,
+			To:         *toAddress,
+			Value:      uint256.MustFromBig(bigOrZero(value)),
+			Gas:        gasLimit,
+			GasFeeCap:  uint256.MustFromBig(bigOrZero(gasPrice)),
+			GasTipCap:  uint256.MustFromBig(bigOrZero(maxFeePerGas)),
+			Data:       data,
+			AccessList: accessList,
+			BlobFeeCap: uint256.MustFromBig(blobFeeCap),
+			BlobHashes: blobHashes,
 		}), nil
+	default:
+		return nil, errors.New("Invalid transaction type")
+	}
+}
+
+// callArg
+// @return the transaction argument of the `eth_call` like methods, the empty fees are omitted.
+func (tx *Transaction) callArg(from string) (map[string]any, error) {
+	rawTx, err := tx.GetRawTx()
+	if err != nil {
+		return nil, err
+	}
+	arg := map[string]any{
+		"from":  common.HexToAddress(from),
+		"to":    rawTx.To(),
+		"value": (*hexutil.Big)(rawTx.Value()),
+		"input": hexutil.Bytes(rawTx.Data()),
+	}
+	if tx.GasLimit != "" {
+		arg["gas"] = hexutil.Uint64(rawTx.Gas())
+	}
+	if rawTx.AccessList() != nil {
+		arg["accessList"] = rawTx.AccessList()
+	}
+	if tx.GasPrice != "" {
+		switch tx.transactionType() {
+		case TransactionTypeLegacy, TransactionTypeAccessList:
+			arg["gasPrice"] = (*hexutil.Big)(rawTx.GasPrice())
+		default:
+			arg["maxFeePerGas"] = (*hexutil.Big)(rawTx.GasFeeCap())
+			arg["maxPriorityFeePerGas"] = (*hexutil.Big)(rawTx.GasTipCap())
+		}
+	}
+	return arg, nil
+}
+
+// transactionType
+// @return the type of the transaction, it's inferred if the type is empty.
+func (tx *Transaction) transactionType() string {
+	switch {
+	case tx.Type != "":
+		return tx.Type
+	case tx.MaxPriorityFeePerGas != "" && tx.MaxPriorityFeePerGas != "0":
+		return TransactionTypeDynamicFee
+	case tx.AccessList != "":
+		return TransactionTypeAccessList
+	default:
+		return TransactionTypeLegacy
+	}
+}
+
+// signer
+// @param rpcChainId the chain id of the rpc, the transaction can only be signed for this chain.
+func (tx *Transaction) signer(rpcChainId *big.Int) (types.Signer, error) {
+	if tx.ChainId == "" {
+		return types.LatestSignerForChainID(rpcChainId), nil
+	}
+	chainId, valid := big.NewInt(0).SetString(tx.ChainId, 10)
+	if !valid {
+		return nil, errors.New("Invalid chain id")
+	}
+	if chainId.Sign() == 0 && tx.transactionType() == TransactionTypeLegacy {
+		return types.HomesteadSigner{}, nil
+	}
+	if chainId.Cmp(rpcChainId) != 0 {
+		return nil, fmt.Errorf("the chain id %v of the transaction does not match the chain id %v of the rpc", chainId, rpcChainId)
+	}
+	return types.LatestSignerForChainID(chainId), nil
+}
+
+func bigOrZero(n *big.Int) *big.Int {
+	if n == nil {
+		return big.NewInt(0)
 	}
+	return n
 }
 
 func (tx *Transaction) TransformToErc20Transaction(contractAddress string) error {
@@ -2,9 +2,16 @@ package eth
 
 import (
 	"encoding/hex"
+	"encoding/json"
+	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,6 +28,45 @@ func TestUtils_EncodeErc721TransferFrom(t *testing.T) {
 		"23b872dd000000000000000000000000151e446ca01b57e495a31d53bc622ac33bd7a0be0000000000000000000000002c32bd5f7d3eab4bc9d968c90c82debb1bdcced90000000000000000000000000000000000000000000000000000000000000001")
 }
 
+func TestUtils_EncodeErc1155SafeTransferFrom(t *testing.T) {
+	sender := "0x151e446ca01b57e495a31d53bc622ac33bd7a0be"
+	receiver := "0x2c32bd5f7d3eab4bc9d968c90c82debb1bdcced9"
+
+	data, err := EncodeErc1155SafeTransferFrom(sender, receiver, "1", "5")
+	require.Nil(t, err)
+	require.Equal(t, "f242432a"+
+		"000000000000000000000000151e446ca01b57e495a31d53bc622ac33bd7a0be"+
+		"0000000000000000000000002c32bd5f7d3eab4bc9d968c90c82debb1bdcced9"+
+		"0000000000000000000000000000000000000000000000000000000000000001"+
+		"0000000000000000000000000000000000000000000000000000000000000005"+
+		"00000000000000000000000000000000000000000000000000000000000000a0"+
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		hex.EncodeToString(data))
+
+	_, err = EncodeErc1155SafeTransferFrom(sender, receiver, "1", "0")
+	require.Equal(t, base.ErrInvalidAmount, err)
+
+	ids := &base.StringArray{AnyArray: []string{"1", "2"}}
+	amounts := &base.StringArray{AnyArray: []string{"3", "4"}}
+	data, err = EncodeErc1155SafeBatchTransferFrom(sender, receiver, ids, amounts)
+	require.Nil(t, err)
+	require.Equal(t, "2eb2c2d6", hex.EncodeToString(data[:4]))
+	decoded, err := AbiCoderDecode([]string{"address", "address", "uint256[]", "uint256[]", "bytes"}, data[4:])
+	require.Nil(t, err)
+	require.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2)}, decoded[2])
+	require.Equal(t, []*big.Int{big.NewInt(3), big.NewInt(4)}, decoded[3])
+
+	_, err = EncodeErc1155SafeBatchTransferFrom(sender, receiver, ids, base.NewStringArrayWithItem("1"))
+	require.Error(t, err)
+
+	txn := NewTransactionNftTransferParams(sender, receiver, "1", "100000", "1", receiver, "ERC-1155")
+	require.NotNil(t, txn)
+	require.True(t, strings.HasPrefix(txn.Data, "f242432a"))
+	txn = NewTransactionErc1155TransferParams(sender, receiver, "1", "100000", "1", receiver, "5")
+	require.NotNil(t, txn)
+	require.Equal(t, "0000000000000000000000000000000000000000000000000000000000000005", txn.Data[8+64*3:8+64*4])
+}
+
 func TestNewTransactionFromHex(t *testing.T) {
 	type args struct {
 		hexData string
@@ -42,6 +88,7 @@ func TestNewTransactionFromHex(t *testing.T) {
 				Value:                "0",
 				Data:                 "095ea7b30000000000000000000000007e88c5e7134e4589f6316636ca8fe8cc9f8ed50500000000000000000000000000000000000000000000000000000000000f4240",
 				MaxPriorityFeePerGas: "2250000000",
+				Type:                 TransactionTypeDynamicFee,
 			},
 			wantErr: false,
 		},
@@ -56,6 +103,7 @@ func TestNewTransactionFromHex(t *testing.T) {
 				Value:                "0",
 				Data:                 "095ea7b30000000000000000000000007e88c5e7134e4589f6316636ca8fe8cc9f8ed5050000000000000000000000000000000000000000000000000000000005f5e100",
 				MaxPriorityFeePerGas: "",
+				Type:                 TransactionTypeLegacy,
 			},
 			wantErr: false,
 		},
@@ -79,3 +127,111 @@ func TestNewTransactionFromHex(t *testing.T) {
 		})
 	}
 }
+
+func TestTransaction_SignAndDecode(t *testing.T) {
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		t.Fatalf("unexpected method %v", method)
+		return nil
+	})
+	defer server.Close()
+	chain := NewChainWithRpc(server.URL)
+	account := cowAccount(t)
+	receiver := "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"
+	accessList := `[{"address":"0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb","storageKeys":["0x0000000000000000000000000000000000000000000000000000000000000001"]}]`
+
+	tests := []struct {
+		name    string
+		txn     *Transaction
+		txType  string
+		chainId string
+	}{
+		{name: "legacy eip155", txn: &Transaction{Nonce: "1", GasPrice: "1000", GasLimit: "21000", To: receiver, Value: "1"}, txType: TransactionTypeLegacy, chainId: "1"},
+		{name: "legacy unprotected", txn: &Transaction{Nonce: "1", GasPrice: "1000", GasLimit: "21000", To: receiver, Value: "1", ChainId: "0"}, txType: TransactionTypeLegacy, chainId: "0"},
+		{name: "access list", txn: &Transaction{Nonce: "2", GasPrice: "1000", GasLimit: "30000", To: receiver, Value: "0", Data: "0x1234", AccessList: accessList}, txType: TransactionTypeAccessList, chainId: "1"},
+		{name: "dynamic fee", txn: &Transaction{Nonce: "3", GasPrice: "1000", GasLimit: "30000", To: receiver, Value: "0", MaxPriorityFeePerGas: "1000", AccessList: accessList, Type: TransactionTypeDynamicFee}, txType: TransactionTypeDynamicFee, chainId: "1"},
+		{name: "contract creation", txn: &Transaction{Nonce: "4", GasPrice: "1000", GasLimit: "90000", Value: "0", Data: "0x6080"}, txType: TransactionTypeLegacy, chainId: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, err := chain.SignTransactionWithAccount(account, tt.txn)
+			require.NoError(t, err)
+			decoded, err := NewTransactionFromHex(signed.Value)
+			require.NoError(t, err)
+			require.Equal(t, tt.txType, decoded.Type)
+			require.Equal(t, tt.chainId, decoded.ChainId)
+			require.Equal(t, tt.txn.To, decoded.To)
+			require.Equal(t, tt.txn.AccessList, decoded.AccessList)
+
+			// the decoded transaction is signed to the same transaction
+			resigned, err := chain.SignTransactionWithAccount(account, decoded)
+			require.NoError(t, err)
+			require.Equal(t, signed.Value, resigned.Value)
+		})
+	}
+
+	// the transaction of the other chain is rejected
+	_, err := chain.SignTransactionWithAccount(account, &Transaction{GasPrice: "1", To: receiver, ChainId: "56"})
+	require.Error(t, err)
+}
+
+func TestNewTransactionFromHex_Blob(t *testing.T) {
+	account := cowAccount(t)
+	blobHash := common.HexToHash("0x01b0761f87b081d5cf10757ccc89f12be355c70e2e29df288b65b30710dcbcd1")
+	signedTx, err := types.SignNewTx(account.privateKeyECDSA, types.NewCancunSigner(big.NewInt(1)), &types.BlobTx{
+		ChainID:    uint256.NewInt(1),
+		Nonce:      5,
+		To:         common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"),
+		Value:      uint256.NewInt(0),
+		Gas:        21000,
+		GasTipCap:  uint256.NewInt(1e9),
+		GasFeeCap:  uint256.NewInt(3e9),
+		BlobFeeCap: uint256.NewInt(7),
+		BlobHashes: []common.Hash{blobHash},
+	})
+	require.NoError(t, err)
+	data, err := signedTx.MarshalBinary()
+	require.NoError(t, err)
+
+	txn, err := NewTransactionFromHex(hex.EncodeToString(data))
+	require.NoError(t, err)
+	require.Equal(t, TransactionTypeBlob, txn.Type)
+	require.Equal(t, "1", txn.ChainId)
+	require.Equal(t, "5", txn.Nonce)
+	require.Equal(t, "3000000000", txn.MaxFee())
+	require.Equal(t, "1000000000", txn.MaxPriorityFeePerGas)
+	require.Equal(t, "7", txn.MaxFeePerBlobGas)
+	require.Equal(t, []string{blobHash.String()}, []string(txn.BlobVersionedHashes.AnyArray))
+
+	rawTx, err := txn.GetRawTx()
+	require.NoError(t, err)
+	resigned, err := types.SignTx(rawTx, types.NewCancunSigner(big.NewInt(1)), account.privateKeyECDSA)
+	require.NoError(t, err)
+	require.Equal(t, signedTx.Hash(), resigned.Hash())
+}
+
+func TestChain_CreateAccessList(t *testing.T) {
+	server := newStubRpcServer(t, func(method string, params []json.RawMessage) any {
+		require.Equal(t, "eth_createAccessList", method)
+		var arg map[string]any
+		require.NoError(t, json.Unmarshal(params[0], &arg))
+		require.Equal(t, "0x1234", arg["input"])
+		return map[string]any{
+			"accessList": []any{map[string]any{"address": stubTokenUsdt, "storageKeys": []string{common.Hash{1}.String()}}},
+			"gasUsed":    "0x6270",
+		}
+	})
+	defer server.Close()
+
+	chain := NewChainWithRpc(server.URL)
+	txn := &Transaction{GasPrice: "1000", To: stubTokenUsdt, Value: "0", Data: "0x1234", Type: TransactionTypeLegacy}
+	result, err := chain.CreateAccessList(stubOwner, txn)
+	require.NoError(t, err)
+	require.Equal(t, "25200", result.GasUsed)
+
+	require.NoError(t, chain.FillAccessList(stubOwner, txn))
+	require.Equal(t, result.AccessList, txn.AccessList)
+	require.Equal(t, TransactionTypeAccessList, txn.Type)
+	rawTx, err := txn.GetRawTx()
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress(stubTokenUsdt), rawTx.AccessList()[0].Address)
+}
@@ -0,0 +1,384 @@
+package eth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The versions of the ERC-4337 EntryPoint, see https://eips.ethereum.org/EIPS/eip-4337
+const (
+	EntryPointVersion06 = "0.6"
+	EntryPointVersion07 = "0.7"
+
+	EntryPointV06Address = "0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789"
+	EntryPointV07Address = "0x0000000071727De22E5E9d8BAf0edAc6f37da032"
+)
+
+var (
+	ErrEntryPointVersion    = errors.New("unsupported entry point version")
+	ErrInvalidUserOperation = errors.New("invalid user operation")
+	ErrUint128Overflow      = errors.New("the gas value of the v0.7 user operation exceeds uint128")
+)
+
+// UserOperation is the ERC-4337 pseudo-transaction that is sent to the bundler.
+// All the quantities are decimal strings and all the bytes are hex strings with 0x prefix.
+//
+// The fields are in the v0.7 form, the v0.6 `initCode` is `Factory + FactoryData`
+// and the v0.6 `paymasterAndData` is `Paymaster + PaymasterData`.
+type UserOperation struct {
+	EntryPoint        string
+	EntryPointVersion string
+
+	Sender   string
+	Nonce    string
+	CallData string
+
+	// The smart account is deployed by the factory in the first user operation, empty if it's deployed.
+	Factory     string
+	FactoryData string
+
+	CallGasLimit         string
+	VerificationGasLimit string
+	PreVerificationGas   string
+	MaxFeePerGas         string
+	MaxPriorityFeePerGas string
+
+	// The paymaster sponsors the gas fee, empty if the sender pays itself.
+	Paymaster string
+	// v0.7 only, the v0.6 paymaster gas is included in the VerificationGasLimit.
+	PaymasterVerificationGasLimit string
+	PaymasterPostOpGasLimit       string
+	PaymasterData                 string
+
+	Signature string
+}
+
+// NewUserOperation
+// @param version the entry point version, `EntryPointVersion06` or `EntryPointVersion07`, it uses the canonical entry point address.
+func NewUserOperation(version, sender string) (*UserOperation, error) {
+	var entryPoint string
+	switch version {
+	case EntryPointVersion06:
+		entryPoint = EntryPointV06Address
+	case EntryPointVersion07:
+		entryPoint = EntryPointV07Address
+	default:
+		return nil, ErrEntryPointVersion
+	}
+	return &UserOperation{
+		EntryPoint:        entryPoint,
+		EntryPointVersion: version,
+		Sender:            sender,
+		Nonce:             "0",
+		CallData:          "0x",
+	}, nil
+}
+
+func NewUserOperationWithJsonString(jsonStr string) (*UserOperation, error) {
+	var op UserOperation
+	err := json.Unmarshal([]byte(jsonStr), &op)
+	return &op, err
+}
+
+func (op *UserOperation) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(op)
+}
+
+// SetGasPrice
+// Set the MaxFeePerGas and MaxPriorityFeePerGas with the EIP-1559 gas price.
+func (op *UserOperation) SetGasPrice(price *GasPrice) {
+	op.MaxFeePerGas = price.MaxFee
+	op.MaxPriorityFeePerGas = price.MaxPriorityFee
+}
+
+// SetGasEstimate
+// Set the gas limits with the result of the bundler `eth_estimateUserOperationGas`.
+func (op *UserOperation) SetGasEstimate(estimate *UserOperationGasEstimate) {
+	op.CallGasLimit = estimate.CallGasLimit
+	op.VerificationGasLimit = estimate.VerificationGasLimit
+	op.PreVerificationGas = estimate.PreVerificationGas
+	if op.Paymaster != "" && op.EntryPointVersion == EntryPointVersion07 {
+		op.PaymasterVerificationGasLimit = estimate.PaymasterVerificationGasLimit
+		op.PaymasterPostOpGasLimit = estimate.PaymasterPostOpGasLimit
+	}
+}
+
+// Hash
+// @param chainId the chain that the entry point deployed
+// @return the userOpHash that the smart account validates
+func (op *UserOperation) Hash(chainId int64) (hash *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	hashBytes, err := op.hash(big.NewInt(chainId))
+	if err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(HexType.HexEncodeToString(hashBytes)), nil
+}
+
+func (op *UserOperation) hash(chainId *big.Int) ([]byte, error) {
+	rpcOp, err := op.toRpc()
+	if err != nil {
+		return nil, err
+	}
+	var packed []byte
+	switch op.EntryPointVersion {
+	case EntryPointVersion06:
+		packed, err = AbiCoderEncode([]string{"address", "uint256", "bytes32", "bytes32", "uint256", "uint256", "uint256", "uint256", "uint256", "bytes32"},
+			rpcOp.sender, rpcOp.nonce,
+			common.BytesToHash(crypto.Keccak256(rpcOp.initCode())),
+			common.BytesToHash(crypto.Keccak256(rpcOp.callData)),
+			rpcOp.callGasLimit, rpcOp.verificationGasLimit, rpcOp.preVerificationGas,
+			rpcOp.maxFeePerGas, rpcOp.maxPriorityFeePerGas,
+			common.BytesToHash(crypto.Keccak256(rpcOp.paymasterAndDataV06())))
+	case EntryPointVersion07:
+		var accountGasLimits, gasFees common.Hash
+		var paymasterAndData []byte
+		if accountGasLimits, err = packUint128Pair(rpcOp.verificationGasLimit, rpcOp.callGasLimit); err != nil {
+			return nil, err
+		}
+		if gasFees, err = packUint128Pair(rpcOp.maxPriorityFeePerGas, rpcOp.maxFeePerGas); err != nil {
+			return nil, err
+		}
+		if paymasterAndData, err = rpcOp.paymasterAndData(true); err != nil {
+			return nil, err
+		}
+		packed, err = AbiCoderEncode([]string{"address", "uint256", "bytes32", "bytes32", "bytes32", "uint256", "bytes32", "bytes32"},
+			rpcOp.sender, rpcOp.nonce,
+			common.BytesToHash(crypto.Keccak256(rpcOp.initCode())),
+			common.BytesToHash(crypto.Keccak256(rpcOp.callData)),
+			accountGasLimits,
+			rpcOp.preVerificationGas,
+			gasFees,
+			common.BytesToHash(crypto.Keccak256(paymasterAndData)))
+	default:
+		return nil, ErrEntryPointVersion
+	}
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := AbiCoderEncode([]string{"bytes32", "address", "uint256"},
+		common.BytesToHash(crypto.Keccak256(packed)), common.HexToAddress(op.EntryPoint), chainId)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(encoded), nil
+}
+
+// SignWithAccount
+// Sign the userOpHash as the personal message, it's the signature that the SimpleAccount validates.
+func (op *UserOperation) SignWithAccount(account *Account, chainId int64) (signature *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	hash, err := op.hash(big.NewInt(chainId))
+	if err != nil {
+		return nil, err
+	}
+	sig, err := account.Sign(hash, "")
+	if err != nil {
+		return nil, err
+	}
+	op.Signature = HexType.HexEncodeToString(sig)
+	return base.NewOptionalString(op.Signature), nil
+}
+
+// MarshalRpc
+// @return the json object of the bundler rpc, the fields depend on the entry point version.
+func (op *UserOperation) MarshalRpc() ([]byte, error) {
+	rpcOp, err := op.toRpc()
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]any{
+		"sender":               rpcOp.sender,
+		"nonce":                (*hexutil.Big)(rpcOp.nonce),
+		"callData":             hexutil.Bytes(rpcOp.callData),
+		"callGasLimit":         (*hexutil.Big)(rpcOp.callGasLimit),
+		"verificationGasLimit": (*hexutil.Big)(rpcOp.verificationGasLimit),
+		"preVerificationGas":   (*hexutil.Big)(rpcOp.preVerificationGas),
+		"maxFeePerGas":         (*hexutil.Big)(rpcOp.maxFeePerGas),
+		"maxPriorityFeePerGas": (*hexutil.Big)(rpcOp.maxPriorityFeePerGas),
+		"signature":            hexutil.Bytes(rpcOp.signature),
+	}
+	switch op.EntryPointVersion {
+	case EntryPointVersion06:
+		res["initCode"] = hexutil.Bytes(rpcOp.initCode())
+		res["paymasterAndData"] = hexutil.Bytes(rpcOp.paymasterAndDataV06())
+	case EntryPointVersion07:
+		// the v0.7 bundler rejects the empty factory and paymaster fields
+		if rpcOp.factory != nil {
+			res["factory"] = rpcOp.factory
+			res["factoryData"] = hexutil.Bytes(rpcOp.factoryData)
+		}
+		if rpcOp.paymaster != nil {
+			res["paymaster"] = rpcOp.paymaster
+			res["paymasterVerificationGasLimit"] = (*hexutil.Big)(rpcOp.paymasterVerificationGasLimit)
+			res["paymasterPostOpGasLimit"] = (*hexutil.Big)(rpcOp.paymasterPostOpGasLimit)
+			res["paymasterData"] = hexutil.Bytes(rpcOp.paymasterData)
+		}
+	default:
+		return nil, ErrEntryPointVersion
+	}
+	return json.Marshal(res)
+}
+
+// rpcUserOperation is the decoded user operation to pack.
+type rpcUserOperation struct {
+	sender   common.Address
+	nonce    *big.Int
+	callData []byte
+
+	factory     *common.Address
+	factoryData []byte
+
+	callGasLimit         *big.Int
+	verificationGasLimit *big.Int
+	preVerificationGas   *big.Int
+	maxFeePerGas         *big.Int
+	maxPriorityFeePerGas *big.Int
+
+	paymaster                     *common.Address
+	paymasterVerificationGasLimit *big.Int
+	paymasterPostOpGasLimit       *big.Int
+	paymasterData                 []byte
+
+	signature []byte
+}
+
+func (op *UserOperation) toRpc() (*rpcUserOperation, error) {
+	if !common.IsHexAddress(op.Sender) || !common.IsHexAddress(op.EntryPoint) {
+		return nil, base.ErrInvalidAddress
+	}
+	var err error
+	res := &rpcUserOperation{sender: common.HexToAddress(op.Sender)}
+	quantities := []struct {
+		value string
+		dest  **big.Int
+	}{
+		{op.Nonce, &res.nonce},
+		{op.CallGasLimit, &res.callGasLimit},
+		{op.VerificationGasLimit, &res.verificationGasLimit},
+		{op.PreVerificationGas, &res.preVerificationGas},
+		{op.MaxFeePerGas, &res.maxFeePerGas},
+		{op.MaxPriorityFeePerGas, &res.maxPriorityFeePerGas},
+		{op.PaymasterVerificationGasLimit, &res.paymasterVerificationGasLimit},
+		{op.PaymasterPostOpGasLimit, &res.paymasterPostOpGasLimit},
+	}
+	for _, q := range quantities {
+		if *q.dest, err = parseUserOperationQuantity(q.value); err != nil {
+			return nil, err
+		}
+	}
+	if res.callData, err = parseUserOperationBytes(op.CallData); err != nil {
+		return nil, err
+	}
+	if res.signature, err = parseUserOperationBytes(op.Signature); err != nil {
+		return nil, err
+	}
+	if op.Factory != "" {
+		if !common.IsHexAddress(op.Factory) {
+			return nil, base.ErrInvalidAddress
+		}
+		factory := common.HexToAddress(op.Factory)
+		res.factory = &factory
+		if res.factoryData, err = parseUserOperationBytes(op.FactoryData); err != nil {
+			return nil, err
+		}
+	}
+	if op.Paymaster != "" {
+		if !common.IsHexAddress(op.Paymaster) {
+			return nil, base.ErrInvalidAddress
+		}
+		paymaster := common.HexToAddress(op.Paymaster)
+		res.paymaster = &paymaster
+		if res.paymasterData, err = parseUserOperationBytes(op.PaymasterData); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (op *rpcUserOperation) initCode() []byte {
+	if op.factory == nil {
+		return []byte{}
+	}
+	return append(op.factory.Bytes(), op.factoryData...)
+}
+
+// paymasterAndData
+// @param withGasLimits the v0.7 packs the paymaster gas limits between the paymaster and the data.
+func (op *rpcUserOperation) paymasterAndData(withGasLimits bool) ([]byte, error) {
+	if op.paymaster == nil {
+		return []byte{}, nil
+	}
+	res := op.paymaster.Bytes()
+	if withGasLimits {
+		gasLimits, err := packUint128Pair(op.paymasterVerificationGasLimit, op.paymasterPostOpGasLimit)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, gasLimits[:]...)
+	}
+	return append(res, op.paymasterData...), nil
+}
+
+// paymasterAndDataV06
+// The v0.6 paymasterAndData has no gas limits, so it can't fail.
+func (op *rpcUserOperation) paymasterAndDataV06() []byte {
+	res, _ := op.paymasterAndData(false)
+	return res
+}
+
+// packUint128Pair
+// @return the bytes32 that the high 128 bits is the first value and the low 128 bits is the second value,
+// or `ErrUint128Overflow` if any value exceeds 128 bits.
+func packUint128Pair(high, low *big.Int) (common.Hash, error) {
+	var res common.Hash
+	for _, value := range []*big.Int{high, low} {
+		if value.BitLen() > 128 {
+			return res, fmt.Errorf("%w: %v", ErrUint128Overflow, value)
+		}
+	}
+	high.FillBytes(res[:16])
+	low.FillBytes(res[16:])
+	return res, nil
+}
+
+// parseUserOperationQuantity
+// @param value the decimal or 0x prefixed hex quantity, empty is zero.
+func parseUserOperationQuantity(value string) (*big.Int, error) {
+	if value == "" {
+		return big.NewInt(0), nil
+	}
+	var res *big.Int
+	var ok bool
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		res, ok = big.NewInt(0).SetString(value[2:], 16)
+	} else {
+		res, ok = big.NewInt(0).SetString(value, 10)
+	}
+	if !ok || res.Sign() < 0 || res.BitLen() > 256 {
+		return nil, ErrInvalidUserOperation
+	}
+	return res, nil
+}
+
+func parseUserOperationBytes(value string) ([]byte, error) {
+	if value == "" || value == "0x" {
+		return []byte{}, nil
+	}
+	data, err := HexType.HexDecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidUserOperation
+	}
+	return data, nil
+}
@@ -0,0 +1,235 @@
+package eth
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const stubSmartAccountAddress = "0x1111111111111111111111111111111111111111"
+
+// newStubBundler
+// @return the json rpc server that serves both the chain and the bundler methods
+func newStubBundler(t *testing.T, sent *[]map[string]any) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var req struct {
+			Id     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.Unmarshal(body, &req))
+
+		var result any
+		switch req.Method {
+		case "eth_chainId":
+			result = "0x1"
+		case "eth_getCode":
+			result = "0x"
+		case "eth_call":
+			var msg struct {
+				Input string `json:"input"`
+				Data  string `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(req.Params[0], &msg))
+			input := msg.Input + msg.Data
+			switch {
+			case strings.HasPrefix(input, "0x8cb84e18"): // getAddress(address,uint256)
+				result = HexType.HexEncodeToString(common.LeftPadBytes(common.HexToAddress(stubSmartAccountAddress).Bytes(), 32))
+			case strings.HasPrefix(input, "0x35567e1a"): // getNonce(address,uint192)
+				result = HexType.HexEncodeToString(common.LeftPadBytes([]byte{5}, 32))
+			default:
+				t.Fatalf("unexpected call %v", input)
+			}
+		case "eth_supportedEntryPoints":
+			result = []string{EntryPointV06Address, EntryPointV07Address}
+		case "eth_estimateUserOperationGas":
+			result = map[string]string{
+				"callGasLimit":         "0x5208",
+				"verificationGasLimit": "0x186a0",
+				"preVerificationGas":   "0xc350",
+			}
+		case "eth_sendUserOperation":
+			var op map[string]any
+			require.NoError(t, json.Unmarshal(req.Params[0], &op))
+			*sent = append(*sent, op)
+			result = "0x" + strings.Repeat("ab", 32)
+		case "eth_getUserOperationReceipt":
+			result = map[string]any{
+				"userOpHash":    "0x" + strings.Repeat("ab", 32),
+				"sender":        stubSmartAccountAddress,
+				"nonce":         "0x5",
+				"actualGasCost": "0x3e8",
+				"actualGasUsed": "0x64",
+				"success":       true,
+				"receipt": map[string]any{
+					"transactionHash": "0x" + strings.Repeat("cd", 32),
+					"blockNumber":     "0x10",
+				},
+			}
+		default:
+			t.Fatalf("unexpected method %v", req.Method)
+		}
+		resp, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": req.Id, "result": result})
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resp)
+	}))
+}
+
+func TestUserOperation_Hash(t *testing.T) {
+	op, err := NewUserOperation(EntryPointVersion06, stubSmartAccountAddress)
+	require.NoError(t, err)
+	op.CallGasLimit = "21000"
+	op.VerificationGasLimit = "100000"
+	op.PreVerificationGas = "50000"
+	op.MaxFeePerGas = "2000000000"
+	op.MaxPriorityFeePerGas = "1000000000"
+
+	hash06, err := op.Hash(1)
+	require.NoError(t, err)
+	hashOtherChain, err := op.Hash(137)
+	require.NoError(t, err)
+	require.NotEqual(t, hash06.Value, hashOtherChain.Value)
+
+	op.EntryPointVersion = EntryPointVersion07
+	op.EntryPoint = EntryPointV07Address
+	hash07, err := op.Hash(1)
+	require.NoError(t, err)
+	require.NotEqual(t, hash06.Value, hash07.Value)
+
+	// the v0.7 hash covers the paymaster gas limits
+	op.Paymaster = "0x2222222222222222222222222222222222222222"
+	op.PaymasterVerificationGasLimit = "30000"
+	hashWithPaymaster, err := op.Hash(1)
+	require.NoError(t, err)
+	op.PaymasterVerificationGasLimit = "30001"
+	hashOtherPaymasterGas, err := op.Hash(1)
+	require.NoError(t, err)
+	require.NotEqual(t, hashWithPaymaster.Value, hashOtherPaymasterGas.Value)
+
+	// the SimpleAccount recovers the owner from the personal signed userOpHash
+	account := cowAccount(t)
+	signature, err := op.SignWithAccount(account, 1)
+	require.NoError(t, err)
+	require.Equal(t, op.Signature, signature.Value)
+	hash, err := op.Hash(1)
+	require.NoError(t, err)
+	hashBytes, err := HexType.HexDecodeString(hash.Value)
+	require.NoError(t, err)
+	sig, err := HexType.HexDecodeString(signature.Value)
+	require.NoError(t, err)
+	sig[64] -= 27
+	pubkey, err := crypto.SigToPub(SignHashForMsg(string(hashBytes)), sig)
+	require.NoError(t, err)
+	require.Equal(t, account.Address(), crypto.PubkeyToAddress(*pubkey).String())
+
+	op.MaxFeePerGas = "-1"
+	_, err = op.Hash(1)
+	require.Equal(t, ErrInvalidUserOperation, err)
+
+	// the v0.7 packs the gas values into uint128 pairs
+	op.MaxFeePerGas = "0x1" + strings.Repeat("0", 32)
+	_, err = op.Hash(1)
+	require.ErrorContains(t, err, ErrUint128Overflow.Error())
+	op.MaxFeePerGas = "0x" + strings.Repeat("f", 32)
+	_, err = op.Hash(1)
+	require.NoError(t, err)
+	op.PaymasterPostOpGasLimit = "0x1" + strings.Repeat("0", 32)
+	_, err = op.Hash(1)
+	require.ErrorContains(t, err, ErrUint128Overflow.Error())
+	_, err = NewUserOperation("0.5", stubSmartAccountAddress)
+	require.Equal(t, ErrEntryPointVersion, err)
+}
+
+func TestUserOperation_MarshalRpc(t *testing.T) {
+	op, err := NewUserOperation(EntryPointVersion06, stubSmartAccountAddress)
+	require.NoError(t, err)
+	op.Factory = SimpleAccountFactoryV06Address
+	op.FactoryData = "0x5fbfb9cf"
+	op.Paymaster = "0x2222222222222222222222222222222222222222"
+	op.PaymasterData = "0x1234"
+	op.CallGasLimit = "21000"
+
+	data, err := op.MarshalRpc()
+	require.NoError(t, err)
+	var fields map[string]string
+	require.NoError(t, json.Unmarshal(data, &fields))
+	require.Equal(t, strings.ToLower(SimpleAccountFactoryV06Address)+"5fbfb9cf", strings.ToLower(fields["initCode"]))
+	require.Equal(t, "0x22222222222222222222222222222222222222221234", fields["paymasterAndData"])
+	require.Equal(t, "0x5208", fields["callGasLimit"])
+	require.NotContains(t, fields, "factory")
+
+	op.EntryPointVersion = EntryPointVersion07
+	data, err = op.MarshalRpc()
+	require.NoError(t, err)
+	fields = map[string]string{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	require.Equal(t, strings.ToLower(SimpleAccountFactoryV06Address), fields["factory"])
+	require.Equal(t, "0x5fbfb9cf", fields["factoryData"])
+	require.Equal(t, "0x1234", fields["paymasterData"])
+	require.Equal(t, "0x0", fields["paymasterPostOpGasLimit"])
+	require.NotContains(t, fields, "initCode")
+	require.NotContains(t, fields, "paymasterAndData")
+}
+
+func TestBundler_SimpleAccount(t *testing.T) {
+	var sent []map[string]any
+	server := newStubBundler(t, &sent)
+	defer server.Close()
+
+	owner := cowAccount(t)
+	account, err := NewSimpleAccount(EntryPointVersion07, owner.Address())
+	require.NoError(t, err)
+	factoryData, err := account.FactoryData()
+	require.NoError(t, err)
+	require.Equal(t, "5fbfb9cf", hex.EncodeToString(factoryData[:4]))
+
+	chain := NewChainWithRpc(server.URL)
+	op, err := chain.BuildSimpleAccountUserOperation(account, "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB", "1000", nil)
+	require.NoError(t, err)
+	require.Equal(t, stubSmartAccountAddress, strings.ToLower(op.Sender))
+	require.Equal(t, "5", op.Nonce)
+	require.Equal(t, SimpleAccountFactoryV07Address, op.Factory)
+	require.True(t, strings.HasPrefix(op.CallData, "0xb61d27f6")) // execute(address,uint256,bytes)
+
+	bundler := NewBundler(server.URL)
+	entryPoints, err := bundler.SupportedEntryPoints()
+	require.NoError(t, err)
+	require.Equal(t, 2, entryPoints.Count())
+
+	estimate, err := bundler.EstimateUserOperationGas(op)
+	require.NoError(t, err)
+	require.Equal(t, "21000", estimate.CallGasLimit)
+	require.Equal(t, "100000", estimate.VerificationGasLimit)
+	require.Equal(t, "50000", estimate.PreVerificationGas)
+	require.Equal(t, "", op.Signature)
+	op.SetGasEstimate(estimate)
+	op.SetGasPrice(&GasPrice{MaxFee: "2000000000", MaxPriorityFee: "1000000000"})
+
+	_, err = op.SignWithAccount(owner, 1)
+	require.NoError(t, err)
+	hash, err := bundler.SendUserOperation(op)
+	require.NoError(t, err)
+	require.Equal(t, "0x"+strings.Repeat("ab", 32), hash.Value)
+	require.Len(t, sent, 1)
+	require.Equal(t, op.Signature, sent[0]["signature"])
+	require.Equal(t, "0x5208", sent[0]["callGasLimit"])
+
+	receipt, err := bundler.GetUserOperationReceipt(hash.Value)
+	require.NoError(t, err)
+	require.True(t, receipt.Success)
+	require.Equal(t, "1000", receipt.ActualGasCost)
+	require.Equal(t, "16", receipt.BlockNumber)
+	require.Equal(t, "0x"+strings.Repeat("cd", 32), receipt.TransactionHash)
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	HexType "github.com/centrifuge/go-substrate-rpc-client/v4/types"
 	"github.com/coming-chat/wallet-SDK/core/base"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -97,3 +98,29 @@ func DecodeContractParams(abiString string, data []byte) (string, []interface{},
 	params, err := method.Inputs.Unpack(data[4:])
 	return method.RawName, params, err
 }
+
+// DecodeContractCall
+// Same as `DecodeContractParams`, but the arguments are decoded with the names and json friendly values.
+func DecodeContractCall(abiString string, data []byte) (*DecodedCall, error) {
+	if len(data) < 4 {
+		return nil, ErrUnknownSelector
+	}
+	parsedAbi, err := abi.JSON(strings.NewReader(abiString))
+	if err != nil {
+		return nil, err
+	}
+	method, err := parsedAbi.MethodById(data[:4])
+	if err != nil {
+		return nil, err
+	}
+	params, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return &DecodedCall{
+		Selector:  HexType.HexEncodeToString(method.ID),
+		Method:    method.RawName,
+		Signature: method.Sig,
+		Arguments: decodeArguments(method.Inputs, params),
+	}, nil
+}
@@ -0,0 +1,152 @@
+package ltc
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/btc"
+)
+
+type Account struct {
+	privateKey  *btcec.PrivateKey
+	address     string
+	addressType AddressType
+	Chainnet    string
+}
+
+// NewAccountWithMnemonic
+// @param addressType the key is derived with the path of the address type, see `AddressTypeDerivePath`
+func NewAccountWithMnemonic(mnemonic, chainnet string, addressType AddressType) (*Account, error) {
+	path := AddressTypeDerivePath(addressType)
+	if path == "--" {
+		return nil, ErrUnsupportedAddress
+	}
+	privateKey, err := btc.Derivation(mnemonic, path)
+	if err != nil {
+		return nil, err
+	}
+	return newAccount(privateKey, chainnet, addressType)
+}
+
+// AccountWithPrivateKey
+// @param prikey the hex or WIF private key
+func AccountWithPrivateKey(prikey, chainnet string, addressType AddressType) (*Account, error) {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	var privateKey *btcec.PrivateKey
+	wif, err := btcutil.DecodeWIF(prikey)
+	if err != nil {
+		seed, err := types.HexDecodeString(prikey)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, _ = btcec.PrivKeyFromBytes(seed)
+	} else {
+		if !wif.IsForNet(net) {
+			return nil, fmt.Errorf("the specified chainnet does not match the wif private key")
+		}
+		privateKey = wif.PrivKey
+	}
+	return newAccount(privateKey, chainnet, addressType)
+}
+
+func newAccount(privateKey *btcec.PrivateKey, chainnet string, addressType AddressType) (*Account, error) {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	address, err := encodePubKeyToAddress(privateKey.PubKey(), net, addressType)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		privateKey:  privateKey,
+		address:     address,
+		addressType: addressType,
+		Chainnet:    chainnet,
+	}, nil
+}
+
+func (a *Account) AddressType() AddressType {
+	return a.addressType
+}
+
+func (a *Account) DerivePath() string {
+	return AddressTypeDerivePath(a.addressType)
+}
+
+func (a *Account) WIFPrivateKeyString() (*base.OptionalString, error) {
+	net, err := netParamsOf(a.Chainnet)
+	if err != nil {
+		return nil, err
+	}
+	str, err := btc.PrivateKeyToWIF(a.privateKey, net)
+	if err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(str), nil
+}
+
+// MARK - Implement the protocol Account
+
+// @return privateKey data
+func (a *Account) PrivateKey() ([]byte, error) {
+	return a.privateKey.Serialize(), nil
+}
+
+// @return privateKey string that will start with 0x.
+func (a *Account) PrivateKeyHex() (string, error) {
+	return types.HexEncodeToString(a.privateKey.Serialize()), nil
+}
+
+// @return publicKey data
+func (a *Account) PublicKey() []byte {
+	return a.privateKey.PubKey().SerializeCompressed()
+}
+
+// @return publicKey string that will start with 0x.
+func (a *Account) PublicKeyHex() string {
+	return types.HexEncodeToString(a.PublicKey())
+}
+
+func (a *Account) Address() string {
+	return a.address
+}
+
+// TODO: function not implement yet.
+func (a *Account) Sign(message []byte, password string) ([]byte, error) {
+	return nil, base.ErrUnsupportedFunction
+}
+
+// TODO: function not implement yet.
+func (a *Account) SignHex(messageHex string, password string) (*base.OptionalString, error) {
+	return nil, base.ErrUnsupportedFunction
+}
+
+// MARK - Implement the protocol AddressUtil
+
+// @param publicKey can start with 0x or not.
+func (a *Account) EncodePublicKeyToAddress(publicKey string) (string, error) {
+	return EncodePublicKeyToAddress(publicKey, a.Chainnet, a.addressType)
+}
+
+func (a *Account) DecodeAddressToPublicKey(address string) (string, error) {
+	return "", ErrDecodeAddress
+}
+
+func (a *Account) IsValidAddress(address string) bool {
+	return IsValidAddress(address, a.Chainnet)
+}
+
+func AsLitecoinAccount(account base.Account) *Account {
+	if r, ok := account.(*Account); ok {
+		return r
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package ltc
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "antenna chaos arrive hungry distance human question history decade deal impose color"
+
+func TestAccount(t *testing.T) {
+	privateKey := "0x0000000000000000000000000000000000000000000000000000000000000001"
+	cases := map[AddressType]string{
+		AddressTypeLegacy:       "LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ",
+		AddressTypeNestedSegwit: "MR8UQSBr5ULwWheBHznrHk2jxyxkHQu8vB",
+		AddressTypeNativeSegwit: "ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9",
+	}
+	for addressType, address := range cases {
+		account, err := AccountWithPrivateKey(privateKey, ChainMainnet, addressType)
+		require.NoError(t, err)
+		require.Equal(t, address, account.Address())
+		require.True(t, IsValidAddress(address, ChainMainnet))
+		require.False(t, IsValidAddress(address, ChainTestnet))
+
+		wif, err := account.WIFPrivateKeyString()
+		require.NoError(t, err)
+		wifAccount, err := AccountWithPrivateKey(wif.Value, ChainMainnet, addressType)
+		require.NoError(t, err)
+		require.Equal(t, address, wifAccount.Address())
+	}
+
+	account, err := NewAccountWithMnemonic(testMnemonic, ChainMainnet, AddressTypeNativeSegwit)
+	require.NoError(t, err)
+	require.Equal(t, "ltc1q9t252akkf2sg4gfng4nd2924zymr7s8dlxstus", account.Address())
+	require.Equal(t, "m/84'/2'/0'/0/0", account.DerivePath())
+
+	testnetAccount, err := NewAccountWithMnemonic(testMnemonic, ChainTestnet, AddressTypeTaproot)
+	require.NoError(t, err)
+	require.Regexp(t, "^tltc1p", testnetAccount.Address())
+	require.True(t, testnetAccount.IsValidAddress(testnetAccount.Address()))
+
+	_, err = NewAccountWithMnemonic(testMnemonic, ChainMainnet, AddressType(100))
+	require.Error(t, err)
+}
+
+func TestIsValidAddress(t *testing.T) {
+	// the bitcoin addresses are invalid
+	require.False(t, IsValidAddress("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", ChainMainnet))
+	require.False(t, IsValidAddress("1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", ChainMainnet))
+	require.False(t, IsValidAddress("ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n8", ChainMainnet))
+
+	mweb := newTestMwebAddress(t, "ltcmweb")
+	require.True(t, IsMwebAddress(mweb, ChainMainnet))
+	require.True(t, IsValidAddress(mweb, ChainMainnet))
+	require.False(t, IsValidAddress(mweb, ChainTestnet))
+	require.True(t, IsMwebAddress(newTestMwebAddress(t, "tmweb"), ChainTestnet))
+	require.False(t, IsMwebAddress("ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9", ChainMainnet))
+
+	_, err := addressToPkScript(mweb, ChainMainnet)
+	require.Equal(t, ErrMwebUnsupported, err)
+}
+
+func newTestMwebAddress(t *testing.T, hrp string) string {
+	scan, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	spend, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	keys := append(scan.PubKey().SerializeCompressed(), spend.PubKey().SerializeCompressed()...)
+	data, err := bech32.ConvertBits(keys, 8, 5, true)
+	require.NoError(t, err)
+	address, err := bech32.Encode(hrp, append([]byte{0}, data...))
+	require.NoError(t, err)
+	return address
+}
@@ -0,0 +1,207 @@
+package ltc
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/coming-chat/wallet-SDK/core/btc"
+)
+
+type AddressType = btc.AddressType
+
+const (
+	AddressTypeNativeSegwit = btc.AddressTypeNativeSegwit
+	AddressTypeNestedSegwit = btc.AddressTypeNestedSegwit
+	AddressTypeTaproot      = btc.AddressTypeTaproot
+	AddressTypeLegacy       = btc.AddressTypeLegacy
+)
+
+var (
+	ErrDecodeAddress      = errors.New("Litecoin cannot support decode address to public key")
+	ErrMwebUnsupported    = errors.New("the MWEB address is not supported, please use the canonical address")
+	ErrUnsupportedAddress = errors.New("unsupported LTC address type")
+)
+
+func AddressTypeDerivePath(t AddressType) string {
+	switch t {
+	case AddressTypeNativeSegwit:
+		return "m/84'/2'/0'/0/0"
+	case AddressTypeNestedSegwit:
+		return "m/49'/2'/0'/0/0"
+	case AddressTypeTaproot:
+		return "m/86'/2'/0'/0/0"
+	case AddressTypeLegacy:
+		return "m/44'/2'/0'/0/0"
+	}
+	return "--"
+}
+
+type Util struct {
+	Chainnet string
+}
+
+func NewUtilWithChainnet(chainnet string) (*Util, error) {
+	if isValidChain(chainnet) {
+		return &Util{Chainnet: chainnet}, nil
+	} else {
+		return nil, ErrUnsupportedChain
+	}
+}
+
+// MARK - Implement the protocol Util
+
+// @param publicKey can start with 0x or not.
+// @return the native segwit address
+func (u *Util) EncodePublicKeyToAddress(publicKey string) (string, error) {
+	return EncodePublicKeyToAddress(publicKey, u.Chainnet, AddressTypeNativeSegwit)
+}
+
+// @return the native segwit address
+func (u *Util) EncodePublicDataToAddress(public []byte) (string, error) {
+	return EncodePublicDataToAddress(public, u.Chainnet, AddressTypeNativeSegwit)
+}
+
+// Warning: Litecoin cannot support decode address to public key
+func (u *Util) DecodeAddressToPublicKey(address string) (string, error) {
+	return "", ErrDecodeAddress
+}
+
+func (u *Util) IsValidAddress(address string) bool {
+	return IsValidAddress(address, u.Chainnet)
+}
+
+// MARK - like Util
+
+// @param publicKey can start with 0x or not.
+func EncodePublicKeyToAddress(publicKey, chainnet string, addressType AddressType) (string, error) {
+	pubData, err := types.HexDecodeString(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return EncodePublicDataToAddress(pubData, chainnet, addressType)
+}
+
+func EncodePublicDataToAddress(public []byte, chainnet string, addressType AddressType) (string, error) {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return "", err
+	}
+	pubkey, err := btcec.ParsePubKey(public)
+	if err != nil {
+		return "", err
+	}
+	return encodePubKeyToAddress(pubkey, net, addressType)
+}
+
+func encodePubKeyToAddress(pubkey *btcec.PublicKey, net *chaincfg.Params, addressType AddressType) (string, error) {
+	if addressType == btc.AddressTypeComingTaproot {
+		return "", ErrUnsupportedAddress
+	}
+	return btc.EncodePubKeyToAddress(pubkey, net, addressType)
+}
+
+// IsValidAddress
+// Both the canonical address (legacy, segwit and taproot) and the MWEB address are valid.
+func IsValidAddress(address, chainnet string) bool {
+	if _, err := decodeAddress(address, chainnet); err == nil {
+		return true
+	}
+	return IsMwebAddress(address, chainnet)
+}
+
+// IsMwebAddress
+// @return true if the address is a valid MWEB (MimbleWimble Extension Block) stealth address,
+// it contains the scan public key and spend public key, e.g. `ltcmweb1...`
+func IsMwebAddress(address, chainnet string) bool {
+	hrp, err := mwebHrpOf(chainnet)
+	if err != nil || !strings.HasPrefix(strings.ToLower(address), hrp+"1") {
+		return false
+	}
+	decodedHrp, data, err := bech32.DecodeNoLimit(address)
+	if err != nil || decodedHrp != hrp || len(data) == 0 || data[0] != 0 {
+		return false
+	}
+	keys, err := bech32.ConvertBits(data[1:], 5, 8, false)
+	if err != nil || len(keys) != 2*btcec.PubKeyBytesLenCompressed {
+		return false
+	}
+	for _, key := range [][]byte{keys[:33], keys[33:]} {
+		if _, err := btcec.ParsePubKey(key); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeAddress
+// Decode the canonical address, the litecoin's bech32 prefix is not registered to the `chaincfg`,
+// so the segwit address is decoded manually.
+func decodeAddress(address, chainnet string) (btcutil.Address, error) {
+	net, err := netParamsOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range []byte(address) {
+		valid := (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+		if !valid {
+			return nil, errors.New("invalid address")
+		}
+	}
+	if strings.HasPrefix(strings.ToLower(address), net.Bech32HRPSegwit+"1") {
+		return decodeSegwitAddress(address, net)
+	}
+	addr, err := btcutil.DecodeAddress(address, net)
+	if err != nil {
+		return nil, err
+	}
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash, *btcutil.AddressScriptHash:
+		if addr.IsForNet(net) {
+			return addr, nil
+		}
+	}
+	return nil, errors.New("invalid address")
+}
+
+func decodeSegwitAddress(address string, net *chaincfg.Params) (btcutil.Address, error) {
+	hrp, data, version, err := bech32.DecodeGeneric(address)
+	if err != nil {
+		return nil, err
+	}
+	if hrp != net.Bech32HRPSegwit || len(data) == 0 {
+		return nil, errors.New("invalid segwit address")
+	}
+	witnessVersion := data[0]
+	program, err := bech32.ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case witnessVersion == 0 && version == bech32.Version0 && len(program) == 20:
+		return btcutil.NewAddressWitnessPubKeyHash(program, net)
+	case witnessVersion == 0 && version == bech32.Version0 && len(program) == 32:
+		return btcutil.NewAddressWitnessScriptHash(program, net)
+	case witnessVersion == 1 && version == bech32.VersionM && len(program) == 32:
+		return btcutil.NewAddressTaproot(program, net)
+	}
+	return nil, errors.New("unsupported segwit address")
+}
+
+// addressToPkScript
+// @return the output script of the canonical address, the MWEB address is not supported.
+func addressToPkScript(address, chainnet string) ([]byte, error) {
+	if IsMwebAddress(address, chainnet) {
+		return nil, ErrMwebUnsupported
+	}
+	addr, err := decodeAddress(address, chainnet)
+	if err != nil {
+		return nil, err
+	}
+	return txscript.PayToAddrScript(addr)
+}
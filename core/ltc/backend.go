@@ -0,0 +1,82 @@
+package ltc
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/btc"
+)
+
+var (
+	ErrBackendNotConfigured = errors.New("the backend service of the LTC chainnet is not configured")
+)
+
+// Backend is the services that a chainnet depends on.
+// The empty field means the default service of the chainnet is used.
+type Backend struct {
+	// The esplora compatible api url, e.g. "https://litecoinspace.org/api"
+	EsploraUrl string `json:"esploraUrl"`
+}
+
+func NewBackend(esploraUrl string) *Backend {
+	return &Backend{EsploraUrl: esploraUrl}
+}
+
+func (b *Backend) JsonString() (*base.OptionalString, error) {
+	return base.JsonString(b)
+}
+
+var defaultBackends = map[string]Backend{
+	ChainMainnet: {EsploraUrl: "https://litecoinspace.org/api"},
+	ChainTestnet: {EsploraUrl: "https://litecoinspace.org/testnet/api"},
+}
+
+var customBackends = make(map[string]Backend)
+var backendLock sync.RWMutex
+
+// SetBackend
+// Configure the services of the chainnet, the empty fields of the backend use the default services.
+// @param backend the custom backend, nil to restore the default services.
+func SetBackend(chainnet string, backend *Backend) error {
+	if !isValidChain(chainnet) {
+		return ErrUnsupportedChain
+	}
+	backendLock.Lock()
+	defer backendLock.Unlock()
+	if backend == nil {
+		delete(customBackends, chainnet)
+	} else {
+		customBackends[chainnet] = *backend
+	}
+	return nil
+}
+
+// BackendOf
+// @return the services that the chainnet is using, the custom fields override the default services.
+func BackendOf(chainnet string) (*Backend, error) {
+	if !isValidChain(chainnet) {
+		return nil, ErrUnsupportedChain
+	}
+	backend := defaultBackends[chainnet]
+
+	backendLock.RLock()
+	custom, ok := customBackends[chainnet]
+	backendLock.RUnlock()
+	if ok && custom.EsploraUrl != "" {
+		backend.EsploraUrl = custom.EsploraUrl
+	}
+	return &backend, nil
+}
+
+func esploraClientOf(chainnet string) (*btc.EsploraClient, error) {
+	backend, err := BackendOf(chainnet)
+	if err != nil {
+		return nil, err
+	}
+	if backend.EsploraUrl == "" {
+		return nil, ErrBackendNotConfigured
+	}
+	return btc.NewEsploraClient(strings.TrimSuffix(backend.EsploraUrl, "/")), nil
+}
@@ -0,0 +1,173 @@
+package ltc
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/btc"
+)
+
+type FeeRate = btc.FeeRate
+type UTXO = btc.UTXO
+
+type Chain struct {
+	*Util
+}
+
+func NewChainWithChainnet(chainnet string) (*Chain, error) {
+	util, err := NewUtilWithChainnet(chainnet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Chain{Util: util}, nil
+}
+
+// MARK - Implement the protocol Chain
+
+func (c *Chain) MainToken() base.Token {
+	return c
+}
+
+func (c *Chain) BalanceOfAddress(address string) (b *base.Balance, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := esploraClientOf(c.Chainnet)
+	if err != nil {
+		return
+	}
+	if IsMwebAddress(address, c.Chainnet) {
+		return nil, ErrMwebUnsupported
+	}
+	balance, err := client.QueryBalance(address)
+	if err != nil {
+		return
+	}
+	return base.NewBalance(balance), nil
+}
+func (c *Chain) BalanceOfPublicKey(publicKey string) (*base.Balance, error) {
+	address, err := EncodePublicKeyToAddress(publicKey, c.Chainnet, AddressTypeNativeSegwit)
+	if err != nil {
+		return nil, err
+	}
+	return c.BalanceOfAddress(address)
+}
+func (c *Chain) BalanceOfAccount(account base.Account) (*base.Balance, error) {
+	return c.BalanceOfAddress(account.Address())
+}
+
+// Send the raw transaction on-chain
+// @return the hex hash string
+func (c *Chain) SendRawTransaction(signedTx string) (hash string, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := esploraClientOf(c.Chainnet)
+	if err != nil {
+		return
+	}
+	return client.SendRawTransaction(signedTx)
+}
+
+func (c *Chain) SendSignedTransaction(signedTxn base.SignedTransaction) (hash *base.OptionalString, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	txn, ok := signedTxn.(*SignedTransaction)
+	if !ok {
+		return nil, base.ErrInvalidTransactionType
+	}
+	txHex, err := txn.HexString()
+	if err != nil {
+		return
+	}
+	hashString, err := c.SendRawTransaction(txHex.Value)
+	if err != nil {
+		return
+	}
+	return base.NewOptionalString(hashString), nil
+}
+
+// Fetch transaction details through transaction hash
+// Only the status and the finish timestamp are queried.
+func (c *Chain) FetchTransactionDetail(hash string) (detail *base.TransactionDetail, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := esploraClientOf(c.Chainnet)
+	if err != nil {
+		return
+	}
+	return client.FetchTransactionStatus(hash)
+}
+
+func (c *Chain) FetchTransactionStatus(hash string) base.TransactionStatus {
+	detail, err := c.FetchTransactionDetail(hash)
+	if err != nil {
+		return base.TransactionStatusNone
+	}
+	return detail.Status
+}
+
+func (c *Chain) BatchFetchTransactionStatus(hashListString string) string {
+	hashList := strings.Split(hashListString, ",")
+	statuses, _ := base.MapListConcurrentStringToString(hashList, func(s string) (string, error) {
+		return strconv.Itoa(c.FetchTransactionStatus(s)), nil
+	})
+	return strings.Join(statuses, ",")
+}
+
+func (c *Chain) EstimateTransactionFee(transaction base.Transaction) (fee *base.OptionalString, err error) {
+	txn, ok := transaction.(*Transaction)
+	if !ok {
+		return nil, base.ErrInvalidTransactionType
+	}
+	return base.NewOptionalString(strconv.FormatInt(txn.Fee(), 10)), nil
+}
+func (c *Chain) EstimateTransactionFeeUsePublicKey(transaction base.Transaction, pubkey string) (fee *base.OptionalString, err error) {
+	return c.EstimateTransactionFee(transaction)
+}
+
+// FetchUtxos
+// @return the json string of the utxos list, sorted from largest to smallest value.
+func (c *Chain) FetchUtxos(address string) (*base.OptionalString, error) {
+	utxos, err := c.fetchUtxos(address)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(utxos)
+	if err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(string(data)), nil
+}
+
+func (c *Chain) fetchUtxos(address string) (utxos []*UTXO, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := esploraClientOf(c.Chainnet)
+	if err != nil {
+		return
+	}
+	utxos, err = client.FetchUtxos(address)
+	if err != nil {
+		return
+	}
+	slices.SortStableFunc(utxos, func(a, b *UTXO) int {
+		return cmp.Compare(b.Value, a.Value)
+	})
+	return utxos, nil
+}
+
+// SuggestFeeRate
+// @return the fee rates (litoshi/vbyte)
+func (c *Chain) SuggestFeeRate() (rates *FeeRate, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	client, err := esploraClientOf(c.Chainnet)
+	if err != nil {
+		return
+	}
+	return client.SuggestFeeRate()
+}
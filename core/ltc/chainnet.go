@@ -0,0 +1,87 @@
+package ltc
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	ChainMainnet = "mainnet"
+	ChainTestnet = "testnet"
+)
+
+var (
+	ErrUnsupportedChain = errors.New("Unsupported LTC chainnet")
+
+	// https://github.com/litecoin-project/litecoin/blob/master/src/chainparams.cpp
+	mainnetCfg = chaincfg.Params{
+		Name: "mainnet",
+		Net:  0xdbb6c0fb,
+
+		PubKeyHashAddrID: 0x30,
+		ScriptHashAddrID: 0x32,
+		PrivateKeyID:     0xb0,
+
+		HDPrivateKeyID: [4]byte{0x04, 0x88, 0xad, 0xe4},
+		HDPublicKeyID:  [4]byte{0x04, 0x88, 0xb2, 0x1e},
+		HDCoinType:     2,
+
+		Bech32HRPSegwit: "ltc",
+	}
+	testnetCfg = chaincfg.Params{
+		Name: "testnet4",
+		Net:  0xf1c8d2fd,
+
+		PubKeyHashAddrID: 0x6f,
+		ScriptHashAddrID: 0x3a,
+		PrivateKeyID:     0xef,
+
+		HDPrivateKeyID: [4]byte{0x04, 0x35, 0x83, 0x94},
+		HDPublicKeyID:  [4]byte{0x04, 0x35, 0x87, 0xcf},
+		HDCoinType:     1,
+
+		Bech32HRPSegwit: "tltc",
+	}
+)
+
+func isValidChain(chainnet string) bool {
+	switch chainnet {
+	case ChainMainnet, ChainTestnet:
+		return true
+	default:
+		return false
+	}
+}
+
+func netParamsOf(chainnet string) (*chaincfg.Params, error) {
+	switch chainnet {
+	case ChainMainnet:
+		return &mainnetCfg, nil
+	case ChainTestnet:
+		return &testnetCfg, nil
+	}
+	return nil, ErrUnsupportedChain
+}
+
+// mwebHrpOf
+// @return the human-readable part of the MWEB (MimbleWimble Extension Block) address
+func mwebHrpOf(chainnet string) (string, error) {
+	switch chainnet {
+	case ChainMainnet:
+		return "ltcmweb", nil
+	case ChainTestnet:
+		return "tmweb", nil
+	}
+	return "", ErrUnsupportedChain
+}
+
+func nameOf(chainnet string) (string, error) {
+	switch chainnet {
+	case ChainMainnet:
+		return "LTC", nil
+	case ChainTestnet:
+		return "tLTC", nil
+	}
+	return "", ErrUnsupportedChain
+}
@@ -0,0 +1,13 @@
+package ltc
+
+import (
+	"github.com/coming-chat/wallet-SDK/core/base"
+)
+
+var (
+	_ base.Account     = (*Account)(nil)
+	_ base.AddressUtil = (*Util)(nil)
+	_ base.Chain       = (*Chain)(nil)
+	_ base.Token       = (*Chain)(nil)
+	_ base.Transaction = (*Transaction)(nil)
+)
@@ -0,0 +1,202 @@
+package ltc
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/btc"
+	"github.com/coming-chat/wallet-SDK/util/hexutil"
+)
+
+const (
+	// The default minimum relay fee rate 0.00001 LTC/kvB (litoshi/vbyte) of Litecoin Core.
+	MinFeeRate int64 = 1
+)
+
+var (
+	ErrInsufficientBalance = btc.ErrInsufficientBalance
+	ErrDustAmount          = btc.ErrDustAmount
+	ErrNotSenderAccount    = errors.New("the account is not the sender of the transaction")
+)
+
+// Transaction is the unsigned transaction that transfers LTC,
+// all the inputs are spent from the sender, and the change returns to the sender.
+type Transaction struct {
+	chainnet string
+	utxoTx   *btc.UtxoTransaction
+}
+
+// BuildTransferWithFeeRate
+// Build a transfer transaction, the utxos of the sender will be selected automatically.
+// @param amount the litoshi amount to transfer
+// @param feeRate litoshi/vbyte, the suggest average fee rate will be used if it <= 0.
+func (c *Chain) BuildTransferWithFeeRate(sender, receiver, amount string, feeRate int64) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil || value <= 0 {
+		return nil, errors.New("invalid transfer amount")
+	}
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchUtxos(sender)
+	if err != nil {
+		return
+	}
+	return NewTransactionWithUtxos(c.Chainnet, sender, receiver, value, feeRate, utxos)
+}
+
+// BuildTransferAllWithFeeRate
+// Build a transaction that spend all the utxos of the sender to the receiver.
+// @param feeRate litoshi/vbyte, the suggest average fee rate will be used if it <= 0.
+func (c *Chain) BuildTransferAllWithFeeRate(sender, receiver string, feeRate int64) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	feeRate, err = c.feeRateOrSuggest(feeRate)
+	if err != nil {
+		return
+	}
+	utxos, err := c.fetchUtxos(sender)
+	if err != nil {
+		return
+	}
+	txn, err = newTransaction(c.Chainnet, sender)
+	if err != nil {
+		return
+	}
+	receiverScript, err := addressToPkScript(receiver, c.Chainnet)
+	if err != nil {
+		return
+	}
+	if err = txn.utxoTx.SpendAll(utxos, receiverScript, feeRate); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+func (c *Chain) feeRateOrSuggest(feeRate int64) (int64, error) {
+	if feeRate <= 0 {
+		rates, err := c.SuggestFeeRate()
+		if err != nil {
+			return 0, err
+		}
+		feeRate = rates.Average
+	}
+	return base.Max(feeRate, MinFeeRate), nil
+}
+
+// NewTransactionWithUtxos
+// Build the transfer transaction with the specified utxos, the utxos are selected by the bitcoin's coin selection.
+// @param amount the litoshi amount to transfer
+// @param feeRate litoshi/vbyte, it's at least `MinFeeRate`.
+func NewTransactionWithUtxos(chainnet, sender, receiver string, amount, feeRate int64, utxos []*UTXO) (txn *Transaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	txn, err = newTransaction(chainnet, sender)
+	if err != nil {
+		return
+	}
+	receiverScript, err := addressToPkScript(receiver, chainnet)
+	if err != nil {
+		return
+	}
+	if err = txn.utxoTx.AddOutput(receiverScript, amount); err != nil {
+		return nil, err
+	}
+	if err = txn.utxoTx.Fund(utxos, base.Max(feeRate, MinFeeRate)); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+func newTransaction(chainnet, sender string) (*Transaction, error) {
+	if IsMwebAddress(sender, chainnet) {
+		return nil, ErrMwebUnsupported
+	}
+	senderAddress, err := decodeAddress(sender, chainnet)
+	if err != nil {
+		return nil, err
+	}
+	senderScript, err := txscript.PayToAddrScript(senderAddress)
+	if err != nil {
+		return nil, err
+	}
+	estimate := func(tx *wire.MsgTx) int64 {
+		return btc.EstimateTxSize(tx, senderAddress)
+	}
+	return &Transaction{
+		chainnet: chainnet,
+		utxoTx:   btc.NewUtxoTransaction(senderScript, estimate),
+	}, nil
+}
+
+// Fee
+// @return the network fee (litoshi) of the transaction
+func (t *Transaction) Fee() int64 {
+	return t.utxoTx.Fee()
+}
+
+// EstimateSize
+// @return the virtual size of the transaction after all inputs are signed
+func (t *Transaction) EstimateSize() int64 {
+	return t.utxoTx.EstimateSize()
+}
+
+// MARK - Implement the protocol Transaction
+
+func (t *Transaction) SignWithAccount(account base.Account) (signedTxn *base.OptionalString, err error) {
+	txn, err := t.SignedTransactionWithAccount(account)
+	if err != nil {
+		return nil, err
+	}
+	return txn.HexString()
+}
+
+func (t *Transaction) SignedTransactionWithAccount(account base.Account) (signedTxn base.SignedTransaction, err error) {
+	defer base.CatchPanicAndMapToBasicError(&err)
+
+	ltcAcc := AsLitecoinAccount(account)
+	if ltcAcc == nil {
+		return nil, base.ErrInvalidAccountType
+	}
+	if ltcAcc.Chainnet != t.chainnet {
+		return nil, ErrNotSenderAccount
+	}
+	accountScript, err := addressToPkScript(ltcAcc.address, ltcAcc.Chainnet)
+	if err != nil {
+		return
+	}
+	if !bytes.Equal(accountScript, t.utxoTx.SenderScript) {
+		return nil, ErrNotSenderAccount
+	}
+
+	tx := t.utxoTx.MsgTx.Copy()
+	if err = btc.Sign(tx, ltcAcc.privateKey, t.utxoTx.PrevOuts, false); err != nil {
+		return
+	}
+	return &SignedTransaction{msgTx: tx}, nil
+}
+
+type SignedTransaction struct {
+	msgTx *wire.MsgTx
+}
+
+func (t *SignedTransaction) HexString() (res *base.OptionalString, err error) {
+	var buf bytes.Buffer
+	if err := t.msgTx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return base.NewOptionalString(hexutil.HexEncodeToString(buf.Bytes())), nil
+}
+
+// TxHash
+// @return the hash of the signed transaction
+func (t *SignedTransaction) TxHash() string {
+	return t.msgTx.TxHash().String()
+}
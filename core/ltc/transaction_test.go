@@ -0,0 +1,112 @@
+package ltc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUtxos(values ...int64) []*UTXO {
+	utxos := make([]*UTXO, len(values))
+	for i, value := range values {
+		hash := chainhash.DoubleHashH([]byte{byte(i)})
+		utxos[i] = &UTXO{Txid: hash.String(), Vout: int64(i), Value: value}
+	}
+	return utxos
+}
+
+func verifyTransaction(t *testing.T, txn *Transaction, signed *SignedTransaction) {
+	for i := range signed.msgTx.TxIn {
+		prevOut := txn.utxoTx.PrevOuts.FetchPrevOutput(signed.msgTx.TxIn[i].PreviousOutPoint)
+		vm, err := txscript.NewEngine(prevOut.PkScript, signed.msgTx, i, txscript.StandardVerifyFlags, nil,
+			txscript.NewTxSigHashes(signed.msgTx, txn.utxoTx.PrevOuts), prevOut.Value, txn.utxoTx.PrevOuts)
+		require.NoError(t, err)
+		require.NoError(t, vm.Execute())
+	}
+}
+
+func TestTransaction_Sign(t *testing.T) {
+	for _, addressType := range []AddressType{AddressTypeNativeSegwit, AddressTypeNestedSegwit, AddressTypeTaproot, AddressTypeLegacy} {
+		account, err := NewAccountWithMnemonic(testMnemonic, ChainMainnet, addressType)
+		require.NoError(t, err)
+		receiver := "ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9"
+
+		txn, err := NewTransactionWithUtxos(ChainMainnet, account.Address(), receiver, 150000, 10, newTestUtxos(100000, 20000, 100000))
+		require.NoError(t, err)
+		require.Len(t, txn.utxoTx.MsgTx.TxIn, 2)
+		require.Len(t, txn.utxoTx.MsgTx.TxOut, 2)
+		require.Equal(t, txn.EstimateSize()*10, txn.Fee())
+
+		signedTxn, err := txn.SignedTransactionWithAccount(account)
+		require.NoError(t, err)
+		signed := signedTxn.(*SignedTransaction)
+		verifyTransaction(t, txn, signed)
+		require.InDelta(t, txn.EstimateSize(), signed.msgTx.SerializeSizeStripped()+(signed.msgTx.SerializeSize()-signed.msgTx.SerializeSizeStripped()+3)/4, 3)
+	}
+}
+
+func TestTransaction_Build(t *testing.T) {
+	account, err := NewAccountWithMnemonic(testMnemonic, ChainMainnet, AddressTypeNativeSegwit)
+	require.NoError(t, err)
+	receiver := "MR8UQSBr5ULwWheBHznrHk2jxyxkHQu8vB"
+
+	// the change is too small, it's paid as the fee.
+	txn, err := NewTransactionWithUtxos(ChainMainnet, account.Address(), receiver, 98500, 10, newTestUtxos(100000))
+	require.NoError(t, err)
+	require.Len(t, txn.utxoTx.MsgTx.TxOut, 1)
+	require.Equal(t, int64(1500), txn.Fee())
+
+	_, err = NewTransactionWithUtxos(ChainMainnet, account.Address(), receiver, 100000, 10, newTestUtxos(100000))
+	require.Equal(t, ErrInsufficientBalance, err)
+	_, err = NewTransactionWithUtxos(ChainMainnet, account.Address(), receiver, 100, 10, newTestUtxos(100000))
+	require.Equal(t, ErrDustAmount, err)
+	_, err = NewTransactionWithUtxos(ChainMainnet, account.Address(), newTestMwebAddress(t, "ltcmweb"), 10000, 10, newTestUtxos(100000))
+	require.Equal(t, ErrMwebUnsupported, err)
+
+	other, err := NewAccountWithMnemonic(testMnemonic, ChainMainnet, AddressTypeLegacy)
+	require.NoError(t, err)
+	_, err = txn.SignedTransactionWithAccount(other)
+	require.Equal(t, ErrNotSenderAccount, err)
+}
+
+func TestChain_CustomBackend(t *testing.T) {
+	defer SetBackend(ChainMainnet, nil)
+
+	account, err := NewAccountWithMnemonic(testMnemonic, ChainMainnet, AddressTypeNativeSegwit)
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/address/" + account.Address() + "/utxo":
+			_, _ = w.Write([]byte(`[{"txid":"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b","vout":0,"value":20000},
+				{"txid":"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b","vout":1,"value":500000}]`))
+		case "/fee-estimates":
+			_, _ = w.Write([]byte(`{"1":20.5,"3":8.1,"6":2}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	require.NoError(t, SetBackend(ChainMainnet, NewBackend(server.URL+"/")))
+
+	chain, err := NewChainWithChainnet(ChainMainnet)
+	require.NoError(t, err)
+	rates, err := chain.SuggestFeeRate()
+	require.NoError(t, err)
+	require.Equal(t, FeeRate{Low: 2, Average: 8, High: 21}, *rates)
+
+	txn, err := chain.BuildTransferAllWithFeeRate(account.Address(), "LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ", 0)
+	require.NoError(t, err)
+	require.Len(t, txn.utxoTx.MsgTx.TxIn, 2)
+	require.Equal(t, txn.EstimateSize()*8, txn.Fee())
+	fee, err := chain.EstimateTransactionFee(txn)
+	require.NoError(t, err)
+	require.NotEqual(t, "0", fee.Value)
+
+	signed, err := txn.SignedTransactionWithAccount(account)
+	require.NoError(t, err)
+	verifyTransaction(t, txn, signed.(*SignedTransaction))
+}
@@ -6,10 +6,12 @@ import (
 
 	"github.com/coming-chat/wallet-SDK/core/aptos"
 	"github.com/coming-chat/wallet-SDK/core/base"
+	"github.com/coming-chat/wallet-SDK/core/bch"
 	"github.com/coming-chat/wallet-SDK/core/btc"
 	"github.com/coming-chat/wallet-SDK/core/cosmos"
 	"github.com/coming-chat/wallet-SDK/core/doge"
 	"github.com/coming-chat/wallet-SDK/core/eth"
+	"github.com/coming-chat/wallet-SDK/core/ltc"
 	"github.com/coming-chat/wallet-SDK/core/polka"
 	"github.com/coming-chat/wallet-SDK/core/solana"
 	"github.com/coming-chat/wallet-SDK/core/starcoin"
@@ -170,6 +172,21 @@ func (w *CacheWallet) BitcoinAccountInfo(chainnet string, addressType btc.Addres
 	}
 }
 
+// BitcoinDescriptorWallet
+// Create the bitcoin watch-only wallet when the watch address is an output descriptor or extended public key.
+func (w *CacheWallet) BitcoinDescriptorWallet(chainnet string) (*btc.DescriptorWallet, error) {
+	watchKey := w.watchAddress
+	if watchKey == "" {
+		if typ, val := w.readValue(WalletTypeWatch); typ == WalletTypeWatch {
+			watchKey = val
+		}
+	}
+	if watchKey == "" {
+		return nil, errors.New("The wallet is not a watch wallet")
+	}
+	return btc.NewDescriptorWalletWithWatchKey(watchKey, chainnet)
+}
+
 func (w *CacheWallet) EthereumAccountInfo() *AccountInfo {
 	return &AccountInfo{
 		Wallet:   w,
@@ -209,6 +226,32 @@ func (w *CacheWallet) DogecoinAccountInfo(chainnet string) *AccountInfo {
 	}
 }
 
+func (w *CacheWallet) LitecoinAccountInfo(chainnet string, addressType ltc.AddressType) *AccountInfo {
+	return &AccountInfo{
+		Wallet:   w,
+		cacheKey: fmt.Sprintf("litecoin-%v-%v", chainnet, addressType),
+		mnemonicCreator: func(val string) (base.Account, error) {
+			return ltc.NewAccountWithMnemonic(val, chainnet, addressType)
+		},
+		privkeyCreator: func(val string) (base.Account, error) {
+			return ltc.AccountWithPrivateKey(val, chainnet, addressType)
+		},
+	}
+}
+
+func (w *CacheWallet) BitcoinCashAccountInfo(chainnet string) *AccountInfo {
+	return &AccountInfo{
+		Wallet:   w,
+		cacheKey: fmt.Sprintf("bitcoincash-%v", chainnet),
+		mnemonicCreator: func(val string) (base.Account, error) {
+			return bch.NewAccountWithMnemonic(val, chainnet)
+		},
+		privkeyCreator: func(val string) (base.Account, error) {
+			return bch.AccountWithPrivateKey(val, chainnet)
+		},
+	}
+}
+
 func (w *CacheWallet) SolanaAccountInfo() *AccountInfo {
 	return &AccountInfo{
 		Wallet:   w,
@@ -8,9 +8,11 @@ import (
 	"github.com/coming-chat/wallet-SDK/core/aptos"
 	"github.com/coming-chat/wallet-SDK/core/base"
 	"github.com/coming-chat/wallet-SDK/core/base/inter"
+	"github.com/coming-chat/wallet-SDK/core/bch"
 	"github.com/coming-chat/wallet-SDK/core/btc"
 	"github.com/coming-chat/wallet-SDK/core/cosmos"
 	"github.com/coming-chat/wallet-SDK/core/eth"
+	"github.com/coming-chat/wallet-SDK/core/ltc"
 	"github.com/coming-chat/wallet-SDK/core/polka"
 	"github.com/coming-chat/wallet-SDK/core/solana"
 	"github.com/coming-chat/wallet-SDK/core/starcoin"
@@ -34,6 +36,9 @@ const (
 	ChainTypeSui      = "sui"
 	ChainTypeStarcoin = "starcoin"
 	ChainTypeStarknet = "starknet"
+
+	ChainTypeLitecoin    = "litecoin"
+	ChainTypeBitcoinCash = "bitcoincash"
 )
 
 // Deprecated: renamed to `ChainTypeOfWatchAddress()`.
@@ -41,9 +46,25 @@ func ChainTypeFrom(address string) *base.StringArray {
 	return ChainTypeOfWatchAddress(address)
 }
 
-// Only support evm, btc, cosmos, solana now.
+// Only support evm, btc, ltc, bch, cosmos, solana now.
+// The btc output descriptor and extended public key (xpub/ypub/zpub) are also supported.
+// The bch address is only matched with the CashAddr format, because the legacy address is the same as btc's.
 func ChainTypeOfWatchAddress(address string) *base.StringArray {
 	res := &base.StringArray{}
+	// the bitcoin output descriptor or extended public key
+	if btc.IsValidWatchKey(address, btc.ChainMainnet) {
+		res.Append(ChainTypeBitcoin)
+		return res
+	}
+	if btc.IsValidWatchKey(address, btc.ChainSignet) {
+		res.Append(ChainTypeSignet)
+		return res
+	}
+	// the CashAddr contains the prefix separator ':'
+	if bch.IsCashAddress(address, bch.ChainMainnet) {
+		res.Append(ChainTypeBitcoinCash)
+		return res
+	}
 	for _, ch := range []byte(address) {
 		valid := (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
 		if !valid {
@@ -73,6 +94,10 @@ func ChainTypeOfWatchAddress(address string) *base.StringArray {
 		if btc.IsValidAddress(address, btc.ChainMainnet) {
 			res.Append(ChainTypeBitcoin)
 		}
+		// the MWEB address cannot be watched, its balance is invisible
+		if ltc.IsValidAddress(address, ltc.ChainMainnet) && !ltc.IsMwebAddress(address, ltc.ChainMainnet) {
+			res.Append(ChainTypeLitecoin)
+		}
 		// if btc.IsValidAddress(address, btc.ChainSignet) {
 		// 	res.Append(ChainTypeSignet)
 		// }
@@ -3,6 +3,8 @@ package wallet
 import (
 	"testing"
 
+	"github.com/coming-chat/wallet-SDK/core/btc"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -55,3 +57,28 @@ func TestChainTypeOfPrivateKey_btc(t *testing.T) {
 	typeArr := ChainTypeOfPrivateKey(prikey)
 	t.Log(typeArr)
 }
+
+func TestChainTypeOfWatchAddress_btcWatchKey(t *testing.T) {
+	zpub := "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+	require.Equal(t, []string{ChainTypeBitcoin}, []string(ChainTypeOfWatchAddress(zpub).AnyArray))
+
+	descriptor := "wpkh(02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9)"
+	require.Equal(t, []string{ChainTypeBitcoin}, []string(ChainTypeOfWatchAddress(descriptor).AnyArray))
+
+	wallet, err := NewWatchWallet(zpub)
+	require.NoError(t, err)
+	descWallet, err := wallet.BitcoinDescriptorWallet(btc.ChainMainnet)
+	require.NoError(t, err)
+	address, err := descWallet.Descriptor.ReceiveAddress(0)
+	require.NoError(t, err)
+	require.Equal(t, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", address)
+}
+
+func TestChainTypeOfWatchAddress_utxoChains(t *testing.T) {
+	require.Equal(t, []string{ChainTypeLitecoin}, []string(ChainTypeOfWatchAddress("ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9").AnyArray))
+	require.Equal(t, []string{ChainTypeLitecoin}, []string(ChainTypeOfWatchAddress("LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ").AnyArray))
+	require.Equal(t, []string{ChainTypeBitcoinCash}, []string(ChainTypeOfWatchAddress("bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a").AnyArray))
+	require.Equal(t, []string{ChainTypeBitcoinCash}, []string(ChainTypeOfWatchAddress("qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a").AnyArray))
+	// the legacy bch address is the same as btc
+	require.Equal(t, []string{ChainTypeBitcoin}, []string(ChainTypeOfWatchAddress("1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu").AnyArray))
+}
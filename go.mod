@@ -21,7 +21,9 @@ require (
 	github.com/decred/base58 v1.0.3
 	github.com/ethereum/go-ethereum v1.13.12
 	github.com/fardream/go-bcs v0.2.1
+	github.com/fxamacker/cbor/v2 v2.5.0
 	github.com/gtank/ristretto255 v0.1.2
+	github.com/holiman/uint256 v1.2.4
 	github.com/itering/subscan v0.1.0
 	github.com/mr-tron/base58 v1.2.0
 	github.com/novifinancial/serde-reflection/serde-generate/runtime/golang v0.0.0-20210526181959-1694c58d103e
@@ -83,7 +85,6 @@ require (
 	github.com/dvsekhvalnov/jose2go v1.5.0 // indirect
 	github.com/ethereum/c-kzg-4844 v0.4.0 // indirect
 	github.com/fsnotify/fsnotify v1.7.0 // indirect
-	github.com/fxamacker/cbor/v2 v2.5.0 // indirect
 	github.com/go-kit/kit v0.12.0 // indirect
 	github.com/go-kit/log v0.2.1 // indirect
 	github.com/go-kratos/kratos v0.5.0 // indirect
@@ -106,7 +107,6 @@ require (
 	github.com/hashicorp/golang-lru v1.0.2 // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/hdevalence/ed25519consensus v0.1.0 // indirect
-	github.com/holiman/uint256 v1.2.4 // indirect
 	github.com/huandu/skiplist v1.2.0 // indirect
 	github.com/huandu/xstrings v1.3.2 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect